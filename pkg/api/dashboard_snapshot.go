@@ -190,6 +190,69 @@ func (hs *HTTPServer) CreateDashboardSnapshot(c *contextmodel.ReqContext) respon
 	return nil
 }
 
+// swagger:route POST /snapshots/capture snapshots captureDashboardSnapshot
+//
+// Capture a snapshot of a dashboard on the server.
+//
+// Runs the queries of all panels of the dashboard with the permissions of the signed in user and stores the results
+// in a new snapshot. The time range and the values of template variables can be set in the request.
+//
+// Responses:
+// 200: captureDashboardSnapshotResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) CaptureDashboardSnapshot(c *contextmodel.ReqContext) response.Response {
+	if !hs.Cfg.SnapshotEnabled {
+		return response.Error(http.StatusForbidden, "Dashboard Snapshots are disabled", nil)
+	}
+
+	cmd := dashboardsnapshots.CaptureDashboardSnapshotCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	g, err := guardian.NewByUID(c.Req.Context(), cmd.DashboardUID, c.OrgID, c.SignedInUser)
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return response.Error(http.StatusNotFound, "Dashboard not found", err)
+		}
+		return response.Err(err)
+	}
+	if canView, err := g.CanView(); err != nil || !canView {
+		return dashboardGuardianResponse(err)
+	}
+
+	cmd.OrgID = c.OrgID
+	cmd.SignedInUser = c.SignedInUser
+	cmd.Key, err = util.GetRandomString(32)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Could not generate random string", err)
+	}
+	cmd.DeleteKey, err = util.GetRandomString(32)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Could not generate random string", err)
+	}
+
+	result, err := hs.dashboardsnapshotsService.CaptureDashboardSnapshot(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to capture snapshot", err)
+	}
+
+	metrics.MApiDashboardSnapshotCreate.Inc()
+
+	return response.JSON(http.StatusOK, util.DynMap{
+		"key":         cmd.Key,
+		"deleteKey":   cmd.DeleteKey,
+		"url":         setting.ToAbsUrl("dashboard/snapshot/" + cmd.Key),
+		"deleteUrl":   setting.ToAbsUrl("api/snapshots-delete/" + cmd.DeleteKey),
+		"id":          result.Snapshot.ID,
+		"panelErrors": result.PanelErrors,
+	})
+}
+
 // GET /api/snapshots/:key
 // swagger:route GET /snapshots/{key} snapshots getDashboardSnapshot
 //
@@ -452,6 +515,13 @@ type CreateSnapshotParams struct {
 	Body dashboardsnapshots.CreateDashboardSnapshotCommand `json:"body"`
 }
 
+// swagger:parameters captureDashboardSnapshot
+type CaptureSnapshotParams struct {
+	// in:body
+	// required:true
+	Body dashboardsnapshots.CaptureDashboardSnapshotCommand `json:"body"`
+}
+
 // swagger:parameters searchDashboardSnapshots
 type GetSnapshotsParams struct {
 	// Search Query
@@ -496,6 +566,23 @@ type CreateSnapshotResponse struct {
 	} `json:"body"`
 }
 
+// swagger:response captureDashboardSnapshotResponse
+type CaptureSnapshotResponse struct {
+	// in:body
+	Body struct {
+		// Unique key
+		Key string `json:"key"`
+		// Unique key used to delete the snapshot. It is different from the key so that only the creator can delete the snapshot.
+		DeleteKey string `json:"deleteKey"`
+		URL       string `json:"url"`
+		DeleteURL string `json:"deleteUrl"`
+		// Snapshot id
+		ID int64 `json:"id"`
+		// Errors of the panels whose queries failed by panel ID. These panels show no data in the snapshot.
+		PanelErrors map[string]string `json:"panelErrors"`
+	} `json:"body"`
+}
+
 // swagger:response searchDashboardSnapshotsResponse
 type SearchDashboardSnapshotsResponse struct {
 	// in:body
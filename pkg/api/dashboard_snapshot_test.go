@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -20,8 +21,10 @@ import (
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/team/teamtest"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util/errutil"
+	"github.com/grafana/grafana/pkg/web/webtest"
 )
 
 func TestDashboardSnapshotAPIEndpoint_singleSnapshot(t *testing.T) {
@@ -433,6 +436,57 @@ func TestGetDashboardSnapshotFailure(t *testing.T) {
 		}, sqlmock)
 }
 
+func TestCaptureDashboardSnapshot(t *testing.T) {
+	origNewByUID := guardian.NewByUID
+	t.Cleanup(func() {
+		guardian.NewByUID = origNewByUID
+	})
+
+	setupServer := func(t *testing.T, snapshotEnabled bool, canView bool, svc dashboardsnapshots.Service) *webtest.Server {
+		guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: canView})
+		return SetupAPITestServer(t, func(hs *HTTPServer) {
+			hs.Cfg = setting.NewCfg()
+			hs.Cfg.SnapshotEnabled = snapshotEnabled
+			hs.dashboardsnapshotsService = svc
+		})
+	}
+	capture := func(t *testing.T, server *webtest.Server) *http.Response {
+		req := server.NewPostRequest("/api/snapshots/capture", strings.NewReader(`{"dashboardUid": "dash", "from": "now-1h", "to": "now"}`))
+		res, err := server.SendJSON(webtest.RequestWithSignedInUser(req, &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleViewer}))
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, res.Body.Close()) })
+		return res
+	}
+
+	t.Run("should capture a snapshot of the dashboard", func(t *testing.T) {
+		svc := dashboardsnapshots.NewMockService(t)
+		svc.On("CaptureDashboardSnapshot", mock.Anything, mock.MatchedBy(func(cmd *dashboardsnapshots.CaptureDashboardSnapshotCommand) bool {
+			return cmd.DashboardUID == "dash" && cmd.From == "now-1h" && cmd.OrgID == 1 && cmd.Key != "" && cmd.DeleteKey != ""
+		})).Return(&dashboardsnapshots.CaptureDashboardSnapshotResult{
+			Snapshot:    &dashboardsnapshots.DashboardSnapshot{ID: 3},
+			PanelErrors: map[int64]string{2: "query A failed"},
+		}, nil)
+
+		res := capture(t, setupServer(t, true, true, svc))
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+		require.Equal(t, float64(3), body["id"])
+		require.Equal(t, map[string]interface{}{"2": "query A failed"}, body["panelErrors"])
+	})
+
+	t.Run("should return 403 if the user cannot view the dashboard", func(t *testing.T) {
+		res := capture(t, setupServer(t, true, false, dashboardsnapshots.NewMockService(t)))
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+
+	t.Run("should return 403 if snapshots are disabled", func(t *testing.T) {
+		res := capture(t, setupServer(t, false, true, dashboardsnapshots.NewMockService(t)))
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+}
+
 func buildHttpServer(d dashboardsnapshots.Service, snapshotEnabled bool) *HTTPServer {
 	hs := &HTTPServer{
 		dashboardsnapshotsService: d,
@@ -220,6 +220,42 @@ grafana-cli user-manager conflicts ingest-file <filepath>
 	},
 }
 
+var alertingCommands = []*cli.Command{
+	{
+		Name:   "convert-prometheus-rules",
+		Usage:  "convert-prometheus-rules <path to Prometheus rule file>",
+		Action: convertPrometheusRulesCommand,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "datasource-uid",
+				Usage: "The UID of the Prometheus data source that the converted rules query",
+			},
+			&cli.StringFlag{
+				Name:  "folder",
+				Usage: "The title of the folder of the converted rules",
+			},
+			&cli.StringFlag{
+				Name:  "folder-uid",
+				Usage: "The UID of the folder, used to derive the same rule UIDs as the import API",
+			},
+			&cli.IntFlag{
+				Name:  "org-id",
+				Usage: "The ID of the organization of the converted rules",
+				Value: 1,
+			},
+			&cli.IntFlag{
+				Name:  "default-interval",
+				Usage: "The evaluation interval in seconds of groups that do not specify one",
+				Value: 60,
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "The path of the provisioning file to write. The file is written to stdout if it is not set",
+			},
+		},
+	},
+}
+
 var Commands = []*cli.Command{
 	{
 		Name:        "plugins",
@@ -231,4 +267,9 @@ var Commands = []*cli.Command{
 		Usage:       "Grafana admin commands",
 		Subcommands: adminCommands,
 	},
+	{
+		Name:        "alerting",
+		Usage:       "Grafana Alerting commands",
+		Subcommands: alertingCommands,
+	},
 }
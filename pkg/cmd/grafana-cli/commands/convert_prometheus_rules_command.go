@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+)
+
+// convertPrometheusRulesCommand converts a Prometheus rule file to an alerting provisioning file.
+// Recording rules are not supported because provisioning files cannot define them.
+// It works offline, so it does not set up plugins or the server like other commands.
+func convertPrometheusRulesCommand(c *cli.Context) error {
+	path := c.Args().First()
+	if path == "" {
+		return errors.New("missing path to the Prometheus rule file")
+	}
+	datasourceUID := c.String("datasource-uid")
+	if datasourceUID == "" {
+		return errors.New("missing datasource-uid flag")
+	}
+	folder := c.String("folder")
+	if folder == "" {
+		return errors.New("missing folder flag")
+	}
+	// UIDs of the converted rules are derived from the folder UID. Without it, the title of the folder is used,
+	// which is stable as well but produces different UIDs than the import API.
+	namespace := c.String("folder-uid")
+	if namespace == "" {
+		namespace = folder
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read the rule file: %w", err)
+	}
+	promGroups, err := prom.ParseRuleGroups(content)
+	if err != nil {
+		return err
+	}
+	orgID := int64(c.Int("org-id"))
+	groups, warnings, err := prom.ConvertRuleGroups(orgID, namespace, promGroups, prom.Config{
+		DatasourceUID:   datasourceUID,
+		DefaultInterval: time.Duration(c.Int("default-interval")) * time.Second,
+	})
+	if err != nil {
+		return err
+	}
+	// the converted rules may be written to stdout
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+
+	out, err := prom.ProvisioningFile(orgID, folder, groups)
+	if err != nil {
+		return err
+	}
+
+	output := c.String("output")
+	if output == "" {
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	if err := os.WriteFile(output, out, 0600); err != nil {
+		return fmt.Errorf("failed to write the provisioning file: %w", err)
+	}
+	logger.Infof("Converted %d rule groups to %s\n", len(groups), output)
+	return nil
+}
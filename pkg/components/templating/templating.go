@@ -0,0 +1,231 @@
+// Package templating interpolates dashboard template variables on the server, for features that run the queries of a
+// dashboard without the frontend.
+package templating
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+const allValue = "$__all"
+
+// Formats of variable values, see https://grafana.com/docs/grafana/latest/dashboards/variables/variable-syntax/#advanced-variable-format-options
+const (
+	FormatCSV         = "csv"
+	FormatPipe        = "pipe"
+	FormatRegex       = "regex"
+	FormatGlob        = "glob"
+	FormatRaw         = "raw"
+	FormatJSON        = "json"
+	FormatSingleQuote = "singlequote"
+	FormatDoubleQuote = "doublequote"
+	FormatSQLString   = "sqlstring"
+	FormatLucene      = "lucene"
+)
+
+// defaultFormats are the formats of variables with multiple values and without an explicit format in the queries of
+// data sources, by the type of the data source. They match the formatting of the data sources in the frontend. Queries
+// of other data sources use FormatGlob.
+var defaultFormats = map[string]string{
+	"prometheus":                    FormatRegex,
+	"loki":                          FormatRegex,
+	"influxdb":                      FormatRegex,
+	"mysql":                         FormatSQLString,
+	"postgres":                      FormatSQLString,
+	"grafana-postgresql-datasource": FormatSQLString,
+	"mssql":                         FormatSQLString,
+	"elasticsearch":                 FormatLucene,
+}
+
+// luceneSpecialChars matches the characters that are escaped in Lucene queries, the same as the frontend.
+var luceneSpecialChars = regexp.MustCompile(`([!*+\-=<>\s&|()\[\]{}^~?:\\/"])`)
+
+// variableRegex matches $var, ${var}, ${var:format} and [[var]] or [[var:format]], the same syntax as the frontend.
+var variableRegex = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::(\w+))?\]\]|\$\{(\w+)(?:\.[^:^\}]+)?(?::([^\}]+))?\}`)
+
+// Variables are the values of template variables by the name of the variable.
+type Variables map[string][]string
+
+// FromDashboard returns the current values of the template variables of the dashboard model. The value "All" is
+// replaced by the custom all value of the variable or by all of its options. Variables whose value cannot be
+// determined are omitted.
+func FromDashboard(dashboard *simplejson.Json) Variables {
+	vars := Variables{}
+	for _, item := range dashboard.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(item)
+		name := variable.Get("name").MustString()
+		if name == "" {
+			continue
+		}
+		values := jsonStrings(variable.GetPath("current", "value"))
+		if len(values) == 1 && values[0] == allValue {
+			values = allValues(variable)
+		}
+		if values == nil {
+			continue
+		}
+		vars[name] = values
+	}
+	return vars
+}
+
+func allValues(variable *simplejson.Json) []string {
+	if custom := variable.Get("allValue").MustString(); custom != "" {
+		return []string{custom}
+	}
+	var values []string
+	for _, option := range variable.Get("options").MustArray() {
+		value := jsonStrings(simplejson.NewFromAny(option).Get("value"))
+		if len(value) == 1 && value[0] != allValue {
+			values = append(values, value[0])
+		}
+	}
+	return values
+}
+
+// jsonStrings returns the value of a variable that can be either a string or an array of strings.
+func jsonStrings(value *simplejson.Json) []string {
+	if s, err := value.String(); err == nil {
+		return []string{s}
+	}
+	arr, err := value.Array()
+	if err != nil {
+		return nil
+	}
+	values := make([]string, 0, len(arr))
+	for _, v := range arr {
+		if s, ok := v.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// Merge returns a copy of the variables with the values of the overrides.
+func (v Variables) Merge(overrides Variables) Variables {
+	result := make(Variables, len(v)+len(overrides))
+	for name, values := range v {
+		result[name] = values
+	}
+	for name, values := range overrides {
+		result[name] = values
+	}
+	return result
+}
+
+// Interpolate replaces the template variables in the text with their formatted values. Variables that are not
+// defined are kept as they are, so that they can still be replaced by the data source, for example $__interval.
+// Variables with multiple values and without an explicit format use FormatGlob.
+func (v Variables) Interpolate(text string) string {
+	return v.interpolate(text, FormatGlob)
+}
+
+// InterpolateJSON replaces the template variables in all string values of the JSON model of a query. Variables with
+// multiple values and without an explicit format are formatted like the data source of the query does in the frontend.
+func (v Variables) InterpolateJSON(model *simplejson.Json) {
+	defaultFormat, ok := defaultFormats[model.GetPath("datasource", "type").MustString()]
+	if !ok {
+		defaultFormat = FormatGlob
+	}
+	model.SetPath(nil, v.interpolateValue(model.Interface(), defaultFormat))
+}
+
+func (v Variables) interpolate(text string, defaultFormat string) string {
+	if !strings.ContainsAny(text, "$[") {
+		return text
+	}
+	return variableRegex.ReplaceAllStringFunc(text, func(match string) string {
+		groups := variableRegex.FindStringSubmatch(match)
+		name, format := groups[1], ""
+		switch {
+		case groups[2] != "":
+			name, format = groups[2], groups[3]
+		case groups[4] != "":
+			name, format = groups[4], groups[5]
+		}
+		values, ok := v[name]
+		if !ok {
+			return match
+		}
+		// like in the frontend, a single value is used as it is unless a format is given
+		if format == "" && len(values) > 1 {
+			format = defaultFormat
+		}
+		return formatValues(values, format)
+	})
+}
+
+func (v Variables) interpolateValue(value interface{}, defaultFormat string) interface{} {
+	switch val := value.(type) {
+	case string:
+		return v.interpolate(val, defaultFormat)
+	case map[string]interface{}:
+		for key, item := range val {
+			val[key] = v.interpolateValue(item, defaultFormat)
+		}
+		return val
+	case []interface{}:
+		for i, item := range val {
+			val[i] = v.interpolateValue(item, defaultFormat)
+		}
+		return val
+	default:
+		return value
+	}
+}
+
+func formatValues(values []string, format string) string {
+	switch format {
+	case FormatCSV, FormatRaw:
+		return strings.Join(values, ",")
+	case FormatPipe:
+		return strings.Join(values, "|")
+	case FormatRegex:
+		escaped := make([]string, 0, len(values))
+		for _, value := range values {
+			escaped = append(escaped, regexp.QuoteMeta(value))
+		}
+		if len(escaped) == 1 {
+			return escaped[0]
+		}
+		return "(" + strings.Join(escaped, "|") + ")"
+	case FormatJSON:
+		b, err := json.Marshal(values)
+		if err != nil {
+			return strings.Join(values, ",")
+		}
+		return string(b)
+	case FormatSingleQuote:
+		return quoteValues(values, "'", `\'`)
+	case FormatDoubleQuote:
+		return quoteValues(values, `"`, `\"`)
+	case FormatSQLString:
+		return quoteValues(values, "'", "''")
+	case FormatLucene:
+		escaped := make([]string, 0, len(values))
+		for _, value := range values {
+			escaped = append(escaped, luceneSpecialChars.ReplaceAllString(value, `\$1`))
+		}
+		if len(escaped) == 1 {
+			return escaped[0]
+		}
+		return `("` + strings.Join(escaped, `" OR "`) + `")`
+	default:
+		// glob
+		if len(values) == 1 {
+			return values[0]
+		}
+		return "{" + strings.Join(values, ",") + "}"
+	}
+}
+
+func quoteValues(values []string, quote string, escapedQuote string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, quote+strings.ReplaceAll(value, quote, escapedQuote)+quote)
+	}
+	return strings.Join(quoted, ",")
+}
@@ -0,0 +1,113 @@
+package templating
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestInterpolate(t *testing.T) {
+	vars := Variables{
+		"single": {"a.b"},
+		"multi":  {"a", "b'c"},
+	}
+
+	testCases := []struct {
+		text     string
+		expected string
+	}{
+		{text: "up{job=\"$single\"}", expected: "up{job=\"a.b\"}"},
+		{text: "${single}", expected: "a.b"},
+		{text: "[[single]]", expected: "a.b"},
+		{text: "$multi", expected: "{a,b'c}"},
+		{text: "${multi:csv}", expected: "a,b'c"},
+		{text: "[[multi:pipe]]", expected: "a|b'c"},
+		{text: "${single:regex}", expected: `a\.b`},
+		{text: "${multi:regex}", expected: "(a|b'c)"},
+		{text: "${multi:json}", expected: `["a","b'c"]`},
+		{text: "${multi:singlequote}", expected: `'a','b\'c'`},
+		{text: "${multi:doublequote}", expected: `"a","b'c"`},
+		{text: "${multi:sqlstring}", expected: "'a','b''c'"},
+		{text: "${multi:lucene}", expected: `("a" OR "b'c")`},
+		{text: "${single:lucene}", expected: "a.b"},
+		{text: "rate(x[$__interval]) + $unknown", expected: "rate(x[$__interval]) + $unknown"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.text, func(t *testing.T) {
+			require.Equal(t, tc.expected, vars.Interpolate(tc.text))
+		})
+	}
+}
+
+func TestInterpolateJSON(t *testing.T) {
+	query := simplejson.NewFromAny(map[string]interface{}{
+		"refId": "A",
+		"expr":  "up{job=\"$job\"}",
+		"nested": map[string]interface{}{
+			"tags": []interface{}{"$job", 1},
+		},
+	})
+
+	Variables{"job": {"api"}}.InterpolateJSON(query)
+
+	require.Equal(t, "up{job=\"api\"}", query.Get("expr").MustString())
+	require.Equal(t, []interface{}{"api", 1}, query.GetPath("nested", "tags").MustArray())
+}
+
+func TestInterpolateJSONDefaultFormat(t *testing.T) {
+	vars := Variables{"single": {"a.b"}, "multi": {"a.b", "c"}}
+	testCases := []struct {
+		dsType   string
+		expected string
+	}{
+		{dsType: "prometheus", expected: `a.b (a\.b|c)`},
+		{dsType: "loki", expected: `a.b (a\.b|c)`},
+		{dsType: "postgres", expected: "a.b 'a.b','c'"},
+		{dsType: "mysql", expected: "a.b 'a.b','c'"},
+		{dsType: "elasticsearch", expected: `a.b ("a.b" OR "c")`},
+		{dsType: "graphite", expected: "a.b {a.b,c}"},
+		{dsType: "", expected: "a.b {a.b,c}"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.dsType, func(t *testing.T) {
+			query := simplejson.NewFromAny(map[string]interface{}{
+				"datasource": map[string]interface{}{"type": tc.dsType},
+				"expr":       "$single $multi",
+				"csv":        "${multi:csv}",
+			})
+			vars.InterpolateJSON(query)
+			require.Equal(t, tc.expected, query.Get("expr").MustString())
+			require.Equal(t, "a.b,c", query.Get("csv").MustString(), "explicit formats are kept")
+		})
+	}
+}
+
+func TestFromDashboard(t *testing.T) {
+	dashboard, err := simplejson.NewJson([]byte(`{
+		"templating": {
+			"list": [
+				{"name": "single", "current": {"value": "a"}},
+				{"name": "multi", "current": {"value": ["a", "b"]}},
+				{"name": "all", "current": {"value": ["$__all"]}, "options": [{"value": "$__all"}, {"value": "x"}, {"value": "y"}]},
+				{"name": "customAll", "allValue": ".*", "current": {"value": "$__all"}},
+				{"name": "unknown", "current": {}}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+
+	vars := FromDashboard(dashboard)
+
+	require.Equal(t, Variables{
+		"single":    {"a"},
+		"multi":     {"a", "b"},
+		"all":       {"x", "y"},
+		"customAll": {".*"},
+	}, vars)
+	require.Equal(t, Variables{"single": {"b"}, "multi": {"a", "b"}, "all": {"x", "y"}, "customAll": {".*"}},
+		vars.Merge(Variables{"single": {"b"}}))
+}
@@ -0,0 +1,148 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"gonum.org/v1/gonum/graph/simple"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+const (
+	// alertStateDatasourceID is a fake ID for AlertStateNode, similar to DatasourceID of CMDNode.
+	alertStateDatasourceID = -300
+
+	// AlertStateDatasourceUID is the string constant used as the datasource name in requests
+	// to identify the query as a query of the current state of alert rules.
+	AlertStateDatasourceUID = "__alert_state__"
+
+	// alertStateFiring is the state of an alert instance that is reported as 1 by AlertStateNode.
+	alertStateFiring = "Alerting"
+)
+
+var errAlertStateReaderMissing = errors.New("the state of alert rules is not available in this context")
+
+// AlertInstanceState is the current state of a single alert instance of an alert rule.
+type AlertInstanceState struct {
+	RuleUID string
+	Labels  data.Labels
+	// State is the name of the state of the instance, e.g. Normal, Pending or Alerting.
+	State string
+	// Values contains the values of the queries and expressions of the latest evaluation of the instance.
+	Values map[string]float64
+}
+
+// AlertStateReader provides access to the current state of alert instances.
+type AlertStateReader interface {
+	// GetAlertInstanceStates returns the current states of alert instances of the rules with the given UIDs.
+	// If ruleUIDs is empty, states of all rules of the organization are returned.
+	GetAlertInstanceStates(ctx context.Context, orgID int64, ruleUIDs []string) []AlertInstanceState
+}
+
+// AlertStateQuery is the model of a query of the current state of alert rules.
+type AlertStateQuery struct {
+	// RuleUIDs selects the instances of the rules with the given UIDs. If empty, instances of all rules are selected.
+	RuleUIDs []string `json:"ruleUIDs,omitempty"`
+	// Labels selects the instances that have all the labels with the given values.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Value is the RefID of the query or expression of the selected rules whose value is returned.
+	// If empty, the value is 1 if the instance is firing and 0 otherwise.
+	Value string `json:"value,omitempty"`
+}
+
+// UnmarshalAlertStateQuery parses the model of a query of the current state of alert rules.
+func UnmarshalAlertStateQuery(raw json.RawMessage) (*AlertStateQuery, error) {
+	q := &AlertStateQuery{}
+	if err := json.Unmarshal(raw, q); err != nil {
+		return nil, fmt.Errorf("failed to parse alert state query: %w", err)
+	}
+	if len(q.RuleUIDs) == 0 && len(q.Labels) == 0 {
+		return nil, errors.New("alert state query must select rules by UID or labels")
+	}
+	return q, nil
+}
+
+// AlertStateNode is a node of expression tree that returns the current state of alert instances of other alert rules.
+// This lets an alert rule depend on the result of other rules.
+type AlertStateNode struct {
+	baseNode
+	query   *AlertStateQuery
+	request *Request
+}
+
+// NodeType returns the data pipeline node type.
+func (n *AlertStateNode) NodeType() NodeType {
+	return TypeAlertStateNode
+}
+
+// Execute reads the states of the selected alert instances and returns a number per instance.
+// Returns NoData if no instance is selected.
+func (n *AlertStateNode) Execute(ctx context.Context, _ time.Time, _ mathexp.Vars, _ *Service) (mathexp.Results, error) {
+	if n.request.AlertStates == nil {
+		return mathexp.Results{}, MakeQueryError(n.refID, AlertStateDatasourceUID, errAlertStateReaderMissing)
+	}
+
+	states := n.request.AlertStates.GetAlertInstanceStates(ctx, n.request.OrgId, n.query.RuleUIDs)
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].RuleUID != states[j].RuleUID {
+			return states[i].RuleUID < states[j].RuleUID
+		}
+		return states[i].Labels.String() < states[j].Labels.String()
+	})
+
+	vals := make([]mathexp.Value, 0, len(states))
+	for _, s := range states {
+		if !matchesLabels(s.Labels, n.query.Labels) {
+			continue
+		}
+		var value float64
+		if n.query.Value == "" {
+			if s.State == alertStateFiring {
+				value = 1
+			}
+		} else {
+			v, ok := s.Values[n.query.Value]
+			if !ok {
+				continue
+			}
+			value = v
+		}
+		number := mathexp.NewNumber(n.refID, s.Labels.Copy())
+		number.SetValue(&value)
+		vals = append(vals, number)
+	}
+	if len(vals) == 0 {
+		return mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}}, nil
+	}
+	return mathexp.Results{Values: vals}, nil
+}
+
+func matchesLabels(labels data.Labels, selector map[string]string) bool {
+	for name, value := range selector {
+		if v, ok := labels[name]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+func buildAlertStateNode(dp *simple.DirectedGraph, rn *rawNode, req *Request) (Node, error) {
+	q, err := UnmarshalAlertStateQuery(rn.QueryRaw)
+	if err != nil {
+		return nil, err
+	}
+	return &AlertStateNode{
+		baseNode: baseNode{
+			id:    dp.NewNode().ID(),
+			refID: rn.RefID,
+		},
+		query:   q,
+		request: req,
+	}, nil
+}
@@ -0,0 +1,140 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	"gonum.org/v1/gonum/graph/simple"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestAlertStateNodeExecute(t *testing.T) {
+	reader := &fakeAlertStateReader{states: []AlertInstanceState{
+		{RuleUID: "db", Labels: data.Labels{"cluster": "b"}, State: "Normal", Values: map[string]float64{"A": 10}},
+		{RuleUID: "db", Labels: data.Labels{"cluster": "a"}, State: "Alerting", Values: map[string]float64{"A": 20}},
+		{RuleUID: "api", Labels: data.Labels{"cluster": "a", "team": "web"}, State: "Pending"},
+	}}
+
+	execute := func(t *testing.T, query string) mathexp.Results {
+		t.Helper()
+		dp := simple.NewDirectedGraph()
+		node, err := buildAlertStateNode(dp, &rawNode{RefID: "S", QueryRaw: json.RawMessage(query)}, &Request{OrgId: 1, AlertStates: reader})
+		require.NoError(t, err)
+		res, err := node.Execute(context.Background(), time.Now(), nil, nil)
+		require.NoError(t, err)
+		return res
+	}
+	numbers := func(t *testing.T, res mathexp.Results) map[string]float64 {
+		t.Helper()
+		result := map[string]float64{}
+		for _, v := range res.Values {
+			n, ok := v.(mathexp.Number)
+			require.Truef(t, ok, "expected Number but got %T", v)
+			result[n.GetLabels().String()] = *n.GetFloat64Value()
+		}
+		return result
+	}
+
+	t.Run("returns 1 for firing instances and 0 otherwise", func(t *testing.T) {
+		res := execute(t, `{"ruleUIDs": ["db", "api"]}`)
+		require.Equal(t, map[string]float64{"cluster=a": 1, "cluster=b": 0, "cluster=a, team=web": 0}, numbers(t, res))
+		require.Equal(t, []string{"db", "api"}, reader.lastRuleUIDs)
+	})
+
+	t.Run("filters instances by labels", func(t *testing.T) {
+		res := execute(t, `{"labels": {"cluster": "a"}}`)
+		require.Equal(t, map[string]float64{"cluster=a": 1, "cluster=a, team=web": 0}, numbers(t, res))
+		require.Empty(t, reader.lastRuleUIDs)
+	})
+
+	t.Run("returns values of the selected refID", func(t *testing.T) {
+		res := execute(t, `{"ruleUIDs": ["db", "api"], "value": "A"}`)
+		require.Equal(t, map[string]float64{"cluster=a": 20, "cluster=b": 10}, numbers(t, res))
+	})
+
+	t.Run("returns NoData if no instance is selected", func(t *testing.T) {
+		res := execute(t, `{"labels": {"cluster": "unknown"}}`)
+		require.Len(t, res.Values, 1)
+		require.Equal(t, parse.TypeNoData, res.Values[0].Type())
+	})
+
+	t.Run("fails if state reader is not available", func(t *testing.T) {
+		dp := simple.NewDirectedGraph()
+		node, err := buildAlertStateNode(dp, &rawNode{RefID: "S", QueryRaw: json.RawMessage(`{"ruleUIDs": ["db"]}`)}, &Request{OrgId: 1})
+		require.NoError(t, err)
+		_, err = node.Execute(context.Background(), time.Now(), nil, nil)
+		require.ErrorIs(t, err, errAlertStateReaderMissing)
+	})
+
+	t.Run("fails to build if query selects nothing", func(t *testing.T) {
+		dp := simple.NewDirectedGraph()
+		_, err := buildAlertStateNode(dp, &rawNode{RefID: "S", QueryRaw: json.RawMessage(`{}`)}, &Request{})
+		require.Error(t, err)
+	})
+}
+
+func TestAlertStateNodeInPipeline(t *testing.T) {
+	s := ProvideService(&setting.Cfg{ExpressionsEnabled: true}, nil, nil, &featuremgmt.FeatureManager{}, nil, tracing.InitializeTracerForTest())
+	stateDS, err := DataSourceModelFromNodeType(TypeAlertStateNode)
+	require.NoError(t, err)
+	exprDS, err := DataSourceModelFromNodeType(TypeCMDNode)
+	require.NoError(t, err)
+
+	req := &Request{
+		OrgId: 1,
+		AlertStates: &fakeAlertStateReader{states: []AlertInstanceState{
+			{RuleUID: "db", Labels: data.Labels{"cluster": "a"}, State: "Alerting"},
+			{RuleUID: "db", Labels: data.Labels{"cluster": "b"}, State: "Alerting"},
+			{RuleUID: "api", Labels: data.Labels{"cluster": "a"}, State: "Alerting"},
+			{RuleUID: "api", Labels: data.Labels{"cluster": "b"}, State: "Normal"},
+		}},
+		Queries: []Query{
+			{RefID: "A", DataSource: stateDS, JSON: json.RawMessage(`{"ruleUIDs": ["db"]}`)},
+			{RefID: "B", DataSource: stateDS, JSON: json.RawMessage(`{"ruleUIDs": ["api"]}`)},
+			{RefID: "C", DataSource: exprDS, JSON: json.RawMessage(`{"type": "math", "expression": "$A && $B"}`)},
+		},
+	}
+
+	pipeline, err := s.BuildPipeline(req)
+	require.NoError(t, err)
+	resp, err := s.ExecutePipeline(context.Background(), time.Now(), pipeline)
+	require.NoError(t, err)
+
+	result := map[string]float64{}
+	for _, frame := range resp.Responses["C"].Frames {
+		v, _ := frame.Fields[0].At(0).(*float64)
+		require.NotNil(t, v)
+		result[frame.Fields[0].Labels.String()] = *v
+	}
+	require.Equal(t, map[string]float64{"cluster=a": 1, "cluster=b": 0}, result)
+}
+
+type fakeAlertStateReader struct {
+	states       []AlertInstanceState
+	lastRuleUIDs []string
+}
+
+func (f *fakeAlertStateReader) GetAlertInstanceStates(_ context.Context, _ int64, ruleUIDs []string) []AlertInstanceState {
+	f.lastRuleUIDs = ruleUIDs
+	if len(ruleUIDs) == 0 {
+		return append([]AlertInstanceState(nil), f.states...)
+	}
+	var result []AlertInstanceState
+	for _, s := range f.states {
+		for _, uid := range ruleUIDs {
+			if s.RuleUID == uid {
+				result = append(result, s)
+			}
+		}
+	}
+	return result
+}
@@ -298,6 +298,243 @@ func (gr *ResampleCommand) Execute(ctx context.Context, now time.Time, vars math
 	return newRes, nil
 }
 
+// WindowCommand is an expression command that applies a sliding window function to a timeseries,
+// such as a moving average, a rate or a cumulative sum.
+type WindowCommand struct {
+	Function    string
+	Window      time.Duration
+	Alignment   string
+	VarToWindow string
+	refID       string
+}
+
+// NewWindowCommand creates a new WindowCommand.
+func NewWindowCommand(refID, function, rawWindow, alignment, varToWindow string) (*WindowCommand, error) {
+	var window time.Duration
+	if rawWindow != "" {
+		var err error
+		window, err = gtime.ParseDuration(rawWindow)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to parse "window" duration field %q: %w`, rawWindow, err)
+		}
+	}
+	if alignment == "" {
+		alignment = mathexp.WindowAlignTrailing
+	}
+	if err := mathexp.ValidateWindow(function, window, alignment); err != nil {
+		return nil, err
+	}
+	return &WindowCommand{
+		Function:    function,
+		Window:      window,
+		Alignment:   alignment,
+		VarToWindow: varToWindow,
+		refID:       refID,
+	}, nil
+}
+
+// UnmarshalWindowCommand creates a WindowCommand from Grafana's frontend query.
+func UnmarshalWindowCommand(rn *rawNode) (*WindowCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, errors.New("no expression ID is specified to apply window function to. Must be a reference to an existing query or expression")
+	}
+	varToWindow, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expression ID is expected to be a string, got %T", rawVar)
+	}
+	varToWindow = strings.TrimPrefix(varToWindow, "$")
+
+	rawFunction, ok := rn.Query["function"]
+	if !ok {
+		return nil, errors.New("no window function specified")
+	}
+	function, ok := rawFunction.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected window function to be a string, got %T", rawFunction)
+	}
+
+	var window string
+	if rawWindow, ok := rn.Query["window"]; ok {
+		window, ok = rawWindow.(string)
+		if !ok {
+			return nil, fmt.Errorf("window is expected to be a string, got %T", rawWindow)
+		}
+	}
+
+	var alignment string
+	if rawAlignment, ok := rn.Query["alignment"]; ok {
+		alignment, ok = rawAlignment.(string)
+		if !ok {
+			return nil, fmt.Errorf("window alignment is expected to be a string, got %T", rawAlignment)
+		}
+	}
+
+	return NewWindowCommand(rn.RefID, function, window, alignment, varToWindow)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (gw *WindowCommand) NeedsVars() []string {
+	return []string{gw.VarToWindow}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (gw *WindowCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteWindow")
+	defer span.End()
+
+	span.SetAttributes("function", gw.Function, attribute.Key("function").String(gw.Function))
+
+	newRes := mathexp.Results{}
+	for _, val := range vars[gw.VarToWindow].Values {
+		if val == nil {
+			continue
+		}
+		switch v := val.(type) {
+		case mathexp.Series:
+			s, err := v.Window(gw.refID, gw.Function, gw.Window, gw.Alignment)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, s)
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("can only apply window function to type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// AnomalyCommand is an expression command that detects anomalies in a timeseries by comparing every point with a baseline
+// calculated from the series itself. It runs locally and does not require the Machine Learning service.
+type AnomalyCommand struct {
+	Options      mathexp.AnomalyOptions
+	IncludeBands bool
+	VarToDetect  string
+	refID        string
+}
+
+// NewAnomalyCommand creates a new AnomalyCommand.
+func NewAnomalyCommand(refID, varToDetect string, options mathexp.AnomalyOptions, includeBands bool) (*AnomalyCommand, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	return &AnomalyCommand{
+		Options:      options,
+		IncludeBands: includeBands,
+		VarToDetect:  varToDetect,
+		refID:        refID,
+	}, nil
+}
+
+// UnmarshalAnomalyCommand creates an AnomalyCommand from Grafana's frontend query.
+func UnmarshalAnomalyCommand(rn *rawNode) (*AnomalyCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, errors.New("no expression ID is specified to detect anomalies in. Must be a reference to an existing query or expression")
+	}
+	varToDetect, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expression ID is expected to be a string, got %T", rawVar)
+	}
+	varToDetect = strings.TrimPrefix(varToDetect, "$")
+
+	options := mathexp.AnomalyOptions{
+		Method:      mathexp.AnomalyMethodStdDev,
+		Sensitivity: mathexp.DefaultAnomalySensitivity,
+		Seasonality: mathexp.SeasonalityNone,
+	}
+	if rawMethod, ok := rn.Query["method"]; ok {
+		options.Method, ok = rawMethod.(string)
+		if !ok {
+			return nil, fmt.Errorf("method is expected to be a string, got %T", rawMethod)
+		}
+	}
+
+	rawWindow, ok := rn.Query["window"]
+	if !ok {
+		return nil, errors.New("no baseline window specified")
+	}
+	window, ok := rawWindow.(string)
+	if !ok {
+		return nil, fmt.Errorf("window is expected to be a string, got %T", rawWindow)
+	}
+	var err error
+	options.Window, err = gtime.ParseDuration(window)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to parse "window" duration field %q: %w`, window, err)
+	}
+
+	if rawSensitivity, ok := rn.Query["sensitivity"]; ok {
+		options.Sensitivity, ok = rawSensitivity.(float64)
+		if !ok {
+			return nil, fmt.Errorf("sensitivity is expected to be a number, got %T", rawSensitivity)
+		}
+	}
+
+	if rawSeasonality, ok := rn.Query["seasonality"]; ok {
+		options.Seasonality, ok = rawSeasonality.(string)
+		if !ok {
+			return nil, fmt.Errorf("seasonality is expected to be a string, got %T", rawSeasonality)
+		}
+		if options.Seasonality == "" {
+			options.Seasonality = mathexp.SeasonalityNone
+		}
+	}
+
+	includeBands := true
+	if rawBands, ok := rn.Query["bands"]; ok {
+		includeBands, ok = rawBands.(bool)
+		if !ok {
+			return nil, fmt.Errorf("bands is expected to be a boolean, got %T", rawBands)
+		}
+	}
+
+	return NewAnomalyCommand(rn.RefID, varToDetect, options, includeBands)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ga *AnomalyCommand) NeedsVars() []string {
+	return []string{ga.VarToDetect}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute. For every input series, the result contains the anomaly score series,
+// followed by the upper and lower band series if bands are included.
+func (ga *AnomalyCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteAnomaly")
+	defer span.End()
+
+	span.SetAttributes("method", ga.Options.Method, attribute.Key("method").String(ga.Options.Method))
+
+	newRes := mathexp.Results{}
+	for _, val := range vars[ga.VarToDetect].Values {
+		if val == nil {
+			continue
+		}
+		switch v := val.(type) {
+		case mathexp.Series:
+			r, err := v.DetectAnomalies(ga.refID, ga.Options)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, r.Score)
+			if ga.IncludeBands {
+				newRes.Values = append(newRes.Values, r.Upper, r.Lower)
+			}
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("can only detect anomalies in type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
 // CommandType is the type of the expression command.
 type CommandType int
 
@@ -314,6 +551,10 @@ const (
 	TypeClassicConditions
 	// TypeThreshold is the CMDType for checking if a threshold has been crossed
 	TypeThreshold
+	// TypeWindow is the CMDType for applying a sliding window function to a timeseries.
+	TypeWindow
+	// TypeAnomaly is the CMDType for detecting anomalies in a timeseries.
+	TypeAnomaly
 )
 
 func (gt CommandType) String() string {
@@ -326,6 +567,10 @@ func (gt CommandType) String() string {
 		return "resample"
 	case TypeClassicConditions:
 		return "classic_conditions"
+	case TypeWindow:
+		return "window"
+	case TypeAnomaly:
+		return "anomaly"
 	default:
 		return "unknown"
 	}
@@ -344,6 +589,10 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeClassicConditions, nil
 	case "threshold":
 		return TypeThreshold, nil
+	case "window":
+		return TypeWindow, nil
+	case "anomaly":
+		return TypeAnomaly, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
@@ -230,3 +230,225 @@ func TestResampleCommand_Execute(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestWindowCommand_Execute(t *testing.T) {
+	varToWindow := util.GenerateShortUID()
+	cmd, err := NewWindowCommand(util.GenerateShortUID(), "moving_avg", "5m", "", varToWindow)
+	require.NoError(t, err)
+	require.Equal(t, mathexp.WindowAlignTrailing, cmd.Alignment)
+
+	var tests = []struct {
+		name         string
+		vals         mathexp.Value
+		isError      bool
+		expectedType parse.ReturnType
+	}{
+		{
+			name:         "should apply window function when input Series",
+			vals:         mathexp.NewSeries(varToWindow, nil, 100),
+			expectedType: parse.TypeSeriesSet,
+		},
+		{
+			name:         "should return NoData when input NoData",
+			vals:         mathexp.NoData{},
+			expectedType: parse.TypeNoData,
+		}, {
+			name:    "should return error when input Number",
+			vals:    mathexp.NewNumber("test", nil),
+			isError: true,
+		}, {
+			name:    "should return error when input Scalar",
+			vals:    mathexp.NewScalar("test", util.Pointer(rand.Float64())),
+			isError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+				varToWindow: mathexp.Results{Values: mathexp.Values{test.vals}},
+			}, tracing.NewFakeTracer())
+			if test.isError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Len(t, result.Values, 1)
+				res := result.Values[0]
+				require.Equal(t, test.expectedType, res.Type())
+			}
+		})
+	}
+}
+
+func TestUnmarshalWindowCommand(t *testing.T) {
+	t.Run("should parse window command", func(t *testing.T) {
+		cmd, err := UnmarshalWindowCommand(&rawNode{
+			RefID: "B",
+			Query: map[string]interface{}{
+				"expression": "$A",
+				"function":   "rate",
+				"window":     "1m",
+				"alignment":  "center",
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "A", cmd.VarToWindow)
+		require.Equal(t, "rate", cmd.Function)
+		require.Equal(t, time.Minute, cmd.Window)
+		require.Equal(t, mathexp.WindowAlignCenter, cmd.Alignment)
+	})
+
+	t.Run("should not require window for cumulative_sum", func(t *testing.T) {
+		_, err := UnmarshalWindowCommand(&rawNode{
+			RefID: "B",
+			Query: map[string]interface{}{
+				"expression": "A",
+				"function":   "cumulative_sum",
+			},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("should fail if function is not supported", func(t *testing.T) {
+		_, err := UnmarshalWindowCommand(&rawNode{
+			RefID: "B",
+			Query: map[string]interface{}{
+				"expression": "A",
+				"function":   "unknown",
+				"window":     "1m",
+			},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("should fail if window is missing", func(t *testing.T) {
+		_, err := UnmarshalWindowCommand(&rawNode{
+			RefID: "B",
+			Query: map[string]interface{}{
+				"expression": "A",
+				"function":   "moving_avg",
+			},
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestUnmarshalAnomalyCommand(t *testing.T) {
+	t.Run("should parse anomaly command with defaults", func(t *testing.T) {
+		cmd, err := UnmarshalAnomalyCommand(&rawNode{
+			RefID: "B",
+			Query: map[string]interface{}{
+				"expression": "$A",
+				"window":     "1h",
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "A", cmd.VarToDetect)
+		require.True(t, cmd.IncludeBands)
+		require.Equal(t, mathexp.AnomalyOptions{
+			Method:      mathexp.AnomalyMethodStdDev,
+			Window:      time.Hour,
+			Sensitivity: mathexp.DefaultAnomalySensitivity,
+			Seasonality: mathexp.SeasonalityNone,
+		}, cmd.Options)
+	})
+
+	t.Run("should parse all settings", func(t *testing.T) {
+		cmd, err := UnmarshalAnomalyCommand(&rawNode{
+			RefID: "B",
+			Query: map[string]interface{}{
+				"expression":  "A",
+				"method":      "mad",
+				"window":      "30m",
+				"sensitivity": 2.5,
+				"seasonality": "weekly",
+				"bands":       false,
+			},
+		})
+		require.NoError(t, err)
+		require.False(t, cmd.IncludeBands)
+		require.Equal(t, mathexp.AnomalyOptions{
+			Method:      mathexp.AnomalyMethodMAD,
+			Window:      30 * time.Minute,
+			Sensitivity: 2.5,
+			Seasonality: mathexp.SeasonalityWeekly,
+		}, cmd.Options)
+	})
+
+	t.Run("should fail if window is missing", func(t *testing.T) {
+		_, err := UnmarshalAnomalyCommand(&rawNode{
+			RefID: "B",
+			Query: map[string]interface{}{
+				"expression": "A",
+			},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("should fail if method is not supported", func(t *testing.T) {
+		_, err := UnmarshalAnomalyCommand(&rawNode{
+			RefID: "B",
+			Query: map[string]interface{}{
+				"expression": "A",
+				"window":     "1h",
+				"method":     "prophet",
+			},
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestAnomalyCommandExecute(t *testing.T) {
+	series := mathexp.NewSeries("A", data.Labels{"host": "a"}, 3)
+	for i, v := range []float64{1, 3, 10} {
+		v := v
+		series.SetPoint(i, time.Unix(int64(i*10), 0), &v)
+	}
+	vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{series, mathexp.NoData{}.New()}}}
+
+	t.Run("should return score and bands", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", mathexp.AnomalyOptions{
+			Method:      mathexp.AnomalyMethodStdDev,
+			Window:      time.Minute,
+			Sensitivity: 3,
+			Seasonality: mathexp.SeasonalityNone,
+		}, true)
+		require.NoError(t, err)
+
+		result, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.NewFakeTracer())
+		require.NoError(t, err)
+		require.Len(t, result.Values, 4)
+		require.Equal(t, data.Labels{"host": "a"}, result.Values[0].GetLabels())
+		require.Equal(t, mathexp.AnomalyBandUpper, result.Values[1].GetLabels()[mathexp.AnomalyBandLabel])
+		require.Equal(t, mathexp.AnomalyBandLower, result.Values[2].GetLabels()[mathexp.AnomalyBandLabel])
+		require.Equal(t, parse.TypeNoData, result.Values[3].Type())
+	})
+
+	t.Run("should return only score if bands are excluded", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", mathexp.AnomalyOptions{
+			Method:      mathexp.AnomalyMethodMAD,
+			Window:      time.Minute,
+			Sensitivity: 3,
+			Seasonality: mathexp.SeasonalityNone,
+		}, false)
+		require.NoError(t, err)
+
+		result, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.NewFakeTracer())
+		require.NoError(t, err)
+		require.Len(t, result.Values, 2)
+	})
+
+	t.Run("should fail for numbers", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", mathexp.AnomalyOptions{
+			Method:      mathexp.AnomalyMethodMAD,
+			Window:      time.Minute,
+			Sensitivity: 3,
+			Seasonality: mathexp.SeasonalityNone,
+		}, false)
+		require.NoError(t, err)
+
+		_, err = cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNumber("A", nil)}},
+		}, tracing.NewFakeTracer())
+		require.Error(t, err)
+	})
+}
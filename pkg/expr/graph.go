@@ -24,6 +24,8 @@ const (
 	TypeDatasourceNode
 	// TypeMLNode is a NodeType for Machine Learning queries.
 	TypeMLNode
+	// TypeAlertStateNode is a NodeType for queries of the current state of alert rules.
+	TypeAlertStateNode
 )
 
 func (nt NodeType) String() string {
@@ -34,6 +36,8 @@ func (nt NodeType) String() string {
 		return "Datasource"
 	case TypeMLNode:
 		return "Machine Learning"
+	case TypeAlertStateNode:
+		return "Alert State"
 	default:
 		return "Unknown"
 	}
@@ -55,6 +59,7 @@ type DataPipeline []Node
 // map of the refId of the of each command
 func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (mathexp.Vars, error) {
 	vars := make(mathexp.Vars)
+	trace := traceFromContext(c)
 	for _, node := range *dp {
 		c, span := s.tracer.Start(c, "SSE.ExecuteNode")
 		span.SetAttributes("node.refId", node.RefID(), attribute.Key("node.refId").String(node.RefID()))
@@ -65,7 +70,9 @@ func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (m
 		}
 		defer span.End()
 
+		nodeTrace := trace.start(node)
 		res, err := node.Execute(c, now, vars, s)
+		nodeTrace.finish(res, err)
 		if err != nil {
 			return nil, err
 		}
@@ -184,6 +191,11 @@ func (s *Service) buildGraph(req *Request) (*simple.DirectedGraph, error) {
 					err = fmt.Errorf("fail to parse expression with refID %v: %w", rn.RefID, err)
 				}
 			}
+		case TypeAlertStateNode:
+			node, err = buildAlertStateNode(dp, rn, req)
+			if err != nil {
+				err = fmt.Errorf("fail to parse alert state query with refID %v: %w", rn.RefID, err)
+			}
 		}
 
 		if node == nil && err == nil {
@@ -0,0 +1,180 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// aggregateFunc calculates a single value from the non-null values of a group.
+type aggregateFunc = func(values []float64) float64
+
+func getAggregateFunc(op string) (aggregateFunc, error) {
+	switch op {
+	case "sum":
+		return func(values []float64) float64 {
+			var f float64
+			for _, v := range values {
+				f += v
+			}
+			return f
+		}, nil
+	case "avg":
+		return func(values []float64) float64 {
+			var f float64
+			for _, v := range values {
+				f += v
+			}
+			return f / float64(len(values))
+		}, nil
+	case "min":
+		return func(values []float64) float64 {
+			f := values[0]
+			for _, v := range values[1:] {
+				f = math.Min(f, v)
+			}
+			return f
+		}, nil
+	case "max":
+		return func(values []float64) float64 {
+			f := values[0]
+			for _, v := range values[1:] {
+				f = math.Max(f, v)
+			}
+			return f
+		}, nil
+	case "count":
+		return func(values []float64) float64 {
+			return float64(len(values))
+		}, nil
+	default:
+		return nil, fmt.Errorf("aggregation %s is not supported", op)
+	}
+}
+
+// groupingLabels returns the labels that identify the group of an item, i.e. only the labels listed in the grouping
+// or all labels except the listed ones if the grouping is "without".
+func groupingLabels(labels data.Labels, node *parse.AggregateNode) data.Labels {
+	return matchingLabels(labels, &parse.VectorMatching{On: !node.Without, Labels: node.Grouping})
+}
+
+// walkAggregate groups the items of the result by labels and aggregates every group into a single item.
+// Numbers are aggregated into one Number per group, and Series are aggregated into one Series per group where
+// the value of each timestamp is calculated from the points of the series of the group at that timestamp.
+// Null values are ignored. If a group does not have any non-null values, the result value is null.
+func (e *State) walkAggregate(node *parse.AggregateNode) (Results, error) {
+	res := Results{Values{}}
+	ar, err := e.walk(node.Arg)
+	if err != nil {
+		return res, err
+	}
+	fn, err := getAggregateFunc(node.Op)
+	if err != nil {
+		return res, err
+	}
+
+	type group struct {
+		labels data.Labels
+		values []Value
+	}
+	groups := map[data.Fingerprint]*group{}
+	var order []data.Fingerprint
+	noData := false
+	for _, v := range ar.Values {
+		switch v.(type) {
+		case Number, Series:
+		case NoData:
+			noData = true
+			continue
+		default:
+			return res, fmt.Errorf("can not aggregate %s using %s, expected %s or %s", v.Type(), node.Op, parse.TypeNumberSet, parse.TypeSeriesSet)
+		}
+		labels := groupingLabels(v.GetLabels(), node)
+		fp := labels.Fingerprint()
+		g, ok := groups[fp]
+		if !ok {
+			g = &group{labels: labels}
+			groups[fp] = g
+			order = append(order, fp)
+		}
+		g.values = append(g.values, v)
+	}
+
+	if len(order) == 0 {
+		if noData {
+			return Results{Values: Values{NewNoData()}}, nil
+		}
+		return res, nil
+	}
+
+	for _, fp := range order {
+		g := groups[fp]
+		value, err := e.aggregateGroup(node.Op, fn, g.labels, g.values)
+		if err != nil {
+			return res, err
+		}
+		res.Values = append(res.Values, value)
+	}
+	return res, nil
+}
+
+func (e *State) aggregateGroup(op string, fn aggregateFunc, labels data.Labels, values []Value) (Value, error) {
+	switch values[0].(type) {
+	case Number:
+		nums := make([]float64, 0, len(values))
+		for _, v := range values {
+			n, ok := v.(Number)
+			if !ok {
+				return nil, fmt.Errorf("can not aggregate %s and %s using %s", parse.TypeNumberSet, v.Type(), op)
+			}
+			if f := n.GetFloat64Value(); f != nil {
+				nums = append(nums, *f)
+			}
+		}
+		result := NewNumber(e.RefID, labels)
+		if len(nums) > 0 {
+			f := fn(nums)
+			result.SetValue(&f)
+		}
+		return result, nil
+	default:
+		points := map[time.Time][]float64{}
+		for _, v := range values {
+			s, ok := v.(Series)
+			if !ok {
+				return nil, fmt.Errorf("can not aggregate %s and %s using %s", parse.TypeSeriesSet, v.Type(), op)
+			}
+			for i := 0; i < s.Len(); i++ {
+				t, f := s.GetPoint(i)
+				if _, ok := points[t]; !ok {
+					points[t] = []float64{}
+				}
+				if f != nil {
+					points[t] = append(points[t], *f)
+				}
+			}
+		}
+		times := make([]time.Time, 0, len(points))
+		for t := range points {
+			times = append(times, t)
+		}
+		sort.Slice(times, func(i, j int) bool {
+			return times[i].Before(times[j])
+		})
+		result := NewSeries(e.RefID, labels, len(times))
+		for i, t := range times {
+			var f *float64
+			if nums := points[t]; len(nums) > 0 {
+				v := fn(nums)
+				f = &v
+			}
+			result.SetPoint(i, t, f)
+		}
+		return result, nil
+	}
+}
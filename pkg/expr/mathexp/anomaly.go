@@ -0,0 +1,221 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	// AnomalyMethodStdDev uses the mean and the standard deviation of the baseline.
+	AnomalyMethodStdDev = "stddev"
+	// AnomalyMethodMAD uses the median and the median absolute deviation of the baseline, which is less sensitive to outliers in the baseline.
+	AnomalyMethodMAD = "mad"
+
+	// SeasonalityNone makes the baseline of a point consist of the points of the window right before it.
+	SeasonalityNone = "none"
+	// SeasonalityDaily makes the baseline of a point consist of the points around the same time of the previous days.
+	SeasonalityDaily = "daily"
+	// SeasonalityWeekly makes the baseline of a point consist of the points around the same time of the same day of the previous weeks.
+	SeasonalityWeekly = "weekly"
+
+	// AnomalyBandLabel is the label that distinguishes the band series from the score series.
+	AnomalyBandLabel = "anomaly_band"
+	// AnomalyBandUpper is the value of AnomalyBandLabel of the upper band series.
+	AnomalyBandUpper = "upper"
+	// AnomalyBandLower is the value of AnomalyBandLabel of the lower band series.
+	AnomalyBandLower = "lower"
+
+	// DefaultAnomalySensitivity is the default number of deviations from the baseline at which a point is considered an anomaly.
+	DefaultAnomalySensitivity = 3.0
+
+	// madScale makes the median absolute deviation a consistent estimator of the standard deviation of normally distributed data.
+	madScale = 1.4826
+	// minBaselinePoints is the minimal number of points in the baseline required to calculate the bands.
+	minBaselinePoints = 2
+)
+
+// AnomalyOptions configure the detection of anomalies.
+type AnomalyOptions struct {
+	// Method is the way the center and the spread of the baseline are calculated. One of AnomalyMethodStdDev or AnomalyMethodMAD.
+	Method string
+	// Window is the size of the baseline window. Without seasonality, this is the window that precedes a point.
+	// With seasonality, this is the window centered at the same time of the previous seasons.
+	Window time.Duration
+	// Sensitivity is the number of deviations from the center of the baseline at which the bands are drawn.
+	Sensitivity float64
+	// Seasonality is one of SeasonalityNone, SeasonalityDaily or SeasonalityWeekly.
+	Seasonality string
+}
+
+// Validate returns an error if the options are not supported.
+func (o AnomalyOptions) Validate() error {
+	switch o.Method {
+	case AnomalyMethodStdDev, AnomalyMethodMAD:
+	default:
+		return fmt.Errorf("anomaly detection method %v is not supported. Supported only: [%s,%s]", o.Method, AnomalyMethodStdDev, AnomalyMethodMAD)
+	}
+	switch o.Seasonality {
+	case SeasonalityNone, SeasonalityDaily, SeasonalityWeekly:
+	default:
+		return fmt.Errorf("seasonality %v is not supported. Supported only: [%s,%s,%s]", o.Seasonality, SeasonalityNone, SeasonalityDaily, SeasonalityWeekly)
+	}
+	if o.Window <= 0 {
+		return fmt.Errorf("window must be greater than zero, got %v", o.Window)
+	}
+	if o.Sensitivity <= 0 || math.IsNaN(o.Sensitivity) || math.IsInf(o.Sensitivity, 0) {
+		return fmt.Errorf("sensitivity must be a positive number, got %v", o.Sensitivity)
+	}
+	if p := o.period(); p > 0 && o.Window > p {
+		return fmt.Errorf("window %v must not be longer than the season %v", o.Window, p)
+	}
+	return nil
+}
+
+func (o AnomalyOptions) period() time.Duration {
+	switch o.Seasonality {
+	case SeasonalityDaily:
+		return 24 * time.Hour
+	case SeasonalityWeekly:
+		return 7 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// AnomalyResult contains the series produced by anomaly detection.
+type AnomalyResult struct {
+	// Score is the absolute distance of a point from the center of its baseline measured in deviations of the baseline.
+	// The point is outside the bands if the score is greater than the sensitivity.
+	Score Series
+	// Upper is the upper band, i.e. the center of the baseline plus sensitivity times the deviation.
+	Upper Series
+	// Lower is the lower band, i.e. the center of the baseline minus sensitivity times the deviation.
+	Lower Series
+}
+
+// DetectAnomalies compares every point of the series with a baseline calculated from the preceding points of the same series,
+// and returns the score and the bands of each point. The score keeps the labels of the series, and the bands get the additional
+// label AnomalyBandLabel. Null and NaN values are ignored. If the baseline has too few points, the values of the point are null.
+// If the deviation of the baseline is zero, the score is either 0 or +Inf.
+func (s Series) DetectAnomalies(refID string, opts AnomalyOptions) (AnomalyResult, error) {
+	if err := opts.Validate(); err != nil {
+		return AnomalyResult{}, fmt.Errorf("invalid expression '%s': %w", refID, err)
+	}
+
+	sorted := NewSeries(refID, s.GetLabels(), s.Len())
+	for i := 0; i < s.Len(); i++ {
+		sorted.SetPoint(i, s.GetTime(i), s.GetValue(i))
+	}
+	sorted.SortByTime(false)
+
+	points := make([]windowPoint, 0, sorted.Len())
+	for i := 0; i < sorted.Len(); i++ {
+		t, v := sorted.GetPoint(i)
+		if v == nil || math.IsNaN(*v) {
+			continue
+		}
+		points = append(points, windowPoint{t: t, v: *v})
+	}
+
+	result := AnomalyResult{
+		Score: NewSeries(refID, s.GetLabels(), sorted.Len()),
+		Upper: NewSeries(refID, bandLabels(s.GetLabels(), AnomalyBandUpper), sorted.Len()),
+		Lower: NewSeries(refID, bandLabels(s.GetLabels(), AnomalyBandLower), sorted.Len()),
+	}
+	baseline := make([]float64, 0)
+	for i := 0; i < sorted.Len(); i++ {
+		t, v := sorted.GetPoint(i)
+		result.Score.SetPoint(i, t, nil)
+		result.Upper.SetPoint(i, t, nil)
+		result.Lower.SetPoint(i, t, nil)
+
+		baseline = opts.baseline(baseline[:0], points, t)
+		if len(baseline) < minBaselinePoints {
+			continue
+		}
+		center, spread := opts.centerAndSpread(baseline)
+		upper, lower := center+opts.Sensitivity*spread, center-opts.Sensitivity*spread
+		result.Upper.SetPoint(i, t, &upper)
+		result.Lower.SetPoint(i, t, &lower)
+
+		if v == nil || math.IsNaN(*v) {
+			continue
+		}
+		var score float64
+		switch distance := math.Abs(*v - center); {
+		case spread > 0:
+			score = distance / spread
+		case distance > 0:
+			score = math.Inf(1)
+		}
+		result.Score.SetPoint(i, t, &score)
+	}
+	return result, nil
+}
+
+// baseline appends the values of the points that form the baseline of the point at time t to dst.
+// Points are sorted by time.
+func (o AnomalyOptions) baseline(dst []float64, points []windowPoint, t time.Time) []float64 {
+	period := o.period()
+	if period == 0 {
+		return appendWindow(dst, points, t.Add(-o.Window), t)
+	}
+	for offset := period; len(points) > 0 && !t.Add(-offset).Add(o.Window/2).Before(points[0].t); offset += period {
+		seasonal := t.Add(-offset)
+		dst = appendWindow(dst, points, seasonal.Add(-o.Window/2), seasonal.Add(o.Window/2))
+	}
+	return dst
+}
+
+// appendWindow appends the values of the points within the window [from, to) to dst.
+func appendWindow(dst []float64, points []windowPoint, from, to time.Time) []float64 {
+	start := sort.Search(len(points), func(i int) bool {
+		return !points[i].t.Before(from)
+	})
+	for i := start; i < len(points) && points[i].t.Before(to); i++ {
+		dst = append(dst, points[i].v)
+	}
+	return dst
+}
+
+func (o AnomalyOptions) centerAndSpread(values []float64) (float64, float64) {
+	if o.Method == AnomalyMethodMAD {
+		center := median(values)
+		deviations := make([]float64, 0, len(values))
+		for _, v := range values {
+			deviations = append(deviations, math.Abs(v-center))
+		}
+		return center, madScale * median(deviations)
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(values)))
+}
+
+func median(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func bandLabels(labels data.Labels, band string) data.Labels {
+	result := labels.Copy()
+	result[AnomalyBandLabel] = band
+	return result
+}
@@ -0,0 +1,154 @@
+package mathexp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectAnomalies(t *testing.T) {
+	values := func(s Series) []*float64 {
+		result := make([]*float64, 0, s.Len())
+		for i := 0; i < s.Len(); i++ {
+			result = append(result, s.GetValue(i))
+		}
+		return result
+	}
+
+	t.Run("stddev bands over trailing window", func(t *testing.T) {
+		input := makeSeries("", data.Labels{"host": "a"},
+			tp{time.Unix(0, 0), float64Pointer(1)},
+			tp{time.Unix(10, 0), float64Pointer(3)},
+			tp{time.Unix(20, 0), float64Pointer(1)},
+			tp{time.Unix(30, 0), float64Pointer(3)},
+			tp{time.Unix(40, 0), float64Pointer(10)},
+		)
+
+		res, err := input.DetectAnomalies("B", AnomalyOptions{
+			Method:      AnomalyMethodStdDev,
+			Window:      40 * time.Second,
+			Sensitivity: 2,
+			Seasonality: SeasonalityNone,
+		})
+		require.NoError(t, err)
+
+		// baseline of the point at 40s is [1, 3, 1, 3], i.e. mean 2 and stddev 1
+		scores := values(res.Score)
+		require.Nil(t, scores[0])
+		require.Nil(t, scores[1])
+		require.Equal(t, float64Pointer(1), scores[2])
+		require.InDelta(t, math.Sqrt2, *scores[3], 1e-9)
+		require.Equal(t, float64Pointer(8), scores[4])
+		require.Equal(t, float64Pointer(4), res.Upper.GetValue(4))
+		require.Equal(t, float64Pointer(0), res.Lower.GetValue(4))
+		require.Nil(t, res.Upper.GetValue(1))
+
+		require.Equal(t, data.Labels{"host": "a"}, res.Score.GetLabels())
+		require.Equal(t, data.Labels{"host": "a", AnomalyBandLabel: AnomalyBandUpper}, res.Upper.GetLabels())
+		require.Equal(t, data.Labels{"host": "a", AnomalyBandLabel: AnomalyBandLower}, res.Lower.GetLabels())
+	})
+
+	t.Run("mad ignores outliers in the baseline", func(t *testing.T) {
+		input := makeSeries("", nil,
+			tp{time.Unix(0, 0), float64Pointer(1)},
+			tp{time.Unix(10, 0), float64Pointer(2)},
+			tp{time.Unix(20, 0), float64Pointer(100)},
+			tp{time.Unix(30, 0), float64Pointer(3)},
+			tp{time.Unix(40, 0), float64Pointer(2)},
+		)
+
+		res, err := input.DetectAnomalies("B", AnomalyOptions{
+			Method:      AnomalyMethodMAD,
+			Window:      time.Minute,
+			Sensitivity: 3,
+			Seasonality: SeasonalityNone,
+		})
+		require.NoError(t, err)
+
+		// baseline of the point at 40s is [1, 2, 100, 3], i.e. median 2.5 and MAD 1
+		require.InDelta(t, 0.5/madScale, *res.Score.GetValue(4), 1e-9)
+		require.InDelta(t, 2.5+3*madScale, *res.Upper.GetValue(4), 1e-9)
+	})
+
+	t.Run("seasonal baseline uses the same time of previous seasons", func(t *testing.T) {
+		day := 24 * time.Hour
+		start := time.Unix(0, 0)
+		input := makeSeries("", nil,
+			tp{start, float64Pointer(10)},
+			tp{start.Add(12 * time.Hour), float64Pointer(100)},
+			tp{start.Add(day), float64Pointer(12)},
+			tp{start.Add(day + 12*time.Hour), float64Pointer(100)},
+			tp{start.Add(2 * day), float64Pointer(11)},
+			tp{start.Add(2*day + 12*time.Hour), float64Pointer(100)},
+		)
+
+		res, err := input.DetectAnomalies("B", AnomalyOptions{
+			Method:      AnomalyMethodStdDev,
+			Window:      time.Hour,
+			Sensitivity: 3,
+			Seasonality: SeasonalityDaily,
+		})
+		require.NoError(t, err)
+
+		// the point at the beginning of the third day is compared with [10, 12] and ignores the values of the middle of the day
+		require.Equal(t, float64Pointer(0), res.Score.GetValue(4))
+		require.Equal(t, float64Pointer(14), res.Upper.GetValue(4))
+		require.Equal(t, float64Pointer(8), res.Lower.GetValue(4))
+		// the third point of the middle of the day has a constant baseline
+		require.Equal(t, float64Pointer(0), res.Score.GetValue(5))
+	})
+
+	t.Run("score is infinite if baseline is constant", func(t *testing.T) {
+		input := makeSeries("", nil,
+			tp{time.Unix(0, 0), float64Pointer(1)},
+			tp{time.Unix(10, 0), float64Pointer(1)},
+			tp{time.Unix(20, 0), float64Pointer(5)},
+		)
+		res, err := input.DetectAnomalies("B", AnomalyOptions{
+			Method:      AnomalyMethodStdDev,
+			Window:      time.Minute,
+			Sensitivity: 3,
+			Seasonality: SeasonalityNone,
+		})
+		require.NoError(t, err)
+		require.True(t, math.IsInf(*res.Score.GetValue(2), 1))
+	})
+
+	t.Run("null values are skipped", func(t *testing.T) {
+		input := makeSeries("", nil,
+			tp{time.Unix(0, 0), float64Pointer(1)},
+			tp{time.Unix(10, 0), float64Pointer(3)},
+			tp{time.Unix(20, 0), nil},
+			tp{time.Unix(30, 0), float64Pointer(2)},
+		)
+		res, err := input.DetectAnomalies("B", AnomalyOptions{
+			Method:      AnomalyMethodStdDev,
+			Window:      time.Minute,
+			Sensitivity: 3,
+			Seasonality: SeasonalityNone,
+		})
+		require.NoError(t, err)
+		require.Nil(t, res.Score.GetValue(2))
+		require.NotNil(t, res.Upper.GetValue(2))
+		require.Equal(t, float64Pointer(0), res.Score.GetValue(3))
+	})
+}
+
+func TestAnomalyOptionsValidate(t *testing.T) {
+	valid := AnomalyOptions{Method: AnomalyMethodStdDev, Window: time.Hour, Sensitivity: 3, Seasonality: SeasonalityNone}
+	require.NoError(t, valid.Validate())
+
+	invalid := []AnomalyOptions{
+		{Method: "unknown", Window: time.Hour, Sensitivity: 3, Seasonality: SeasonalityNone},
+		{Method: AnomalyMethodMAD, Window: time.Hour, Sensitivity: 3, Seasonality: "monthly"},
+		{Method: AnomalyMethodMAD, Window: 0, Sensitivity: 3, Seasonality: SeasonalityNone},
+		{Method: AnomalyMethodMAD, Window: time.Hour, Sensitivity: 0, Seasonality: SeasonalityNone},
+		{Method: AnomalyMethodMAD, Window: 48 * time.Hour, Sensitivity: 3, Seasonality: SeasonalityDaily},
+	}
+	for _, opts := range invalid {
+		require.Error(t, opts.Validate(), "expected options %+v to be invalid", opts)
+	}
+}
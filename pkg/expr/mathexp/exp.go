@@ -94,6 +94,8 @@ func (e *State) walk(node parse.Node) (res Results, err error) {
 		res, err = e.walkUnary(node)
 	case *parse.FuncNode:
 		res, err = e.walkFunc(node)
+	case *parse.AggregateNode:
+		res, err = e.walkAggregate(node)
 	default:
 		return res, fmt.Errorf("expr: can not walk node type: %s", node.Type())
 	}
@@ -258,6 +260,91 @@ func union(aResults, bResults Results) []*Union {
 	return unions
 }
 
+// matchingLabels returns the labels that are used to match an item when the vector matching is applied,
+// i.e. only the labels listed in the matching if it is "on" or all labels except the listed ones if it is "ignoring".
+func matchingLabels(labels data.Labels, matching *parse.VectorMatching) data.Labels {
+	result := data.Labels{}
+	if matching.On {
+		for _, name := range matching.Labels {
+			if v, ok := labels[name]; ok {
+				result[name] = v
+			}
+		}
+		return result
+	}
+	for k, v := range labels {
+		result[k] = v
+	}
+	for _, name := range matching.Labels {
+		delete(result, name)
+	}
+	return result
+}
+
+// unionWithMatching creates Union objects by matching items of the sets using only the labels defined by the vector matching.
+// One-to-one and many-to-one (one-to-many) matching is supported. If several items on both sides share the same
+// matching labels, the matching is ambiguous and an error is returned.
+// The labels of the Union are the matching labels when the matching is "on" and the labels of the item without the ignored labels
+// when the matching is "ignoring". In the case of many-to-one matching, the Union gets the labels of the item on the "many" side.
+// If either side is a Scalar or NoData, the matching has no effect and the result is the same as union.
+func unionWithMatching(aResults, bResults Results, matching *parse.VectorMatching) ([]*Union, error) {
+	unions := []*Union{}
+	if len(aResults.Values) == 0 || len(bResults.Values) == 0 {
+		return unions, nil
+	}
+	for _, r := range []Results{aResults, bResults} {
+		if len(r.Values) == 1 {
+			if t := r.Values[0].Type(); t == parse.TypeScalar || t == parse.TypeNoData {
+				return union(aResults, bResults), nil
+			}
+		}
+	}
+
+	bGroups := make(map[data.Fingerprint][]Value, len(bResults.Values))
+	for _, b := range bResults.Values {
+		fp := matchingLabels(b.GetLabels(), matching).Fingerprint()
+		bGroups[fp] = append(bGroups[fp], b)
+	}
+	aGroups := make(map[data.Fingerprint][]Value, len(aResults.Values))
+	var order []data.Fingerprint
+	for _, a := range aResults.Values {
+		fp := matchingLabels(a.GetLabels(), matching).Fingerprint()
+		if _, ok := aGroups[fp]; !ok {
+			order = append(order, fp)
+		}
+		aGroups[fp] = append(aGroups[fp], a)
+	}
+
+	for _, fp := range order {
+		as, bs := aGroups[fp], bGroups[fp]
+		if len(bs) == 0 {
+			continue
+		}
+		if len(as) > 1 && len(bs) > 1 {
+			return nil, fmt.Errorf("many-to-many matching is not allowed: multiple items on both sides match labels {%s} using %s", matchingLabels(as[0].GetLabels(), matching), matching)
+		}
+		for _, a := range as {
+			for _, b := range bs {
+				var labels data.Labels
+				switch {
+				case len(as) > 1:
+					labels = a.GetLabels()
+				case len(bs) > 1:
+					labels = b.GetLabels()
+				default:
+					labels = matchingLabels(a.GetLabels(), matching)
+				}
+				unions = append(unions, &Union{
+					Labels: labels,
+					A:      a,
+					B:      b,
+				})
+			}
+		}
+	}
+	return unions, nil
+}
+
 func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	res := Results{Values{}}
 	ar, err := e.walk(node.Args[0])
@@ -268,7 +355,15 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	if err != nil {
 		return res, err
 	}
-	unions := union(ar, br)
+	var unions []*Union
+	if node.Matching != nil {
+		unions, err = unionWithMatching(ar, br, node.Matching)
+		if err != nil {
+			return res, err
+		}
+	} else {
+		unions = union(ar, br)
+	}
 	for _, uni := range unions {
 		var value Value
 		switch at := uni.A.(type) {
@@ -526,6 +621,8 @@ func (e *State) walkFunc(node *parse.FuncNode) (Results, error) {
 			v, err = e.walkUnary(t)
 		case *parse.BinaryNode:
 			v, err = e.walkBinary(t)
+		case *parse.AggregateNode:
+			v, err = e.walkAggregate(t)
 		default:
 			return res, fmt.Errorf("expr: unknown func arg type: %T", t)
 		}
@@ -0,0 +1,243 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestVectorMatchingExpr(t *testing.T) {
+	errors := Results{[]Value{
+		makeNumber("errors", data.Labels{"service": "api", "instance": "1", "code": "500"}, float64Pointer(2)),
+		makeNumber("errors", data.Labels{"service": "db", "instance": "2", "code": "500"}, float64Pointer(3)),
+	}}
+	requests := Results{[]Value{
+		makeNumber("requests", data.Labels{"service": "api", "instance": "1"}, float64Pointer(10)),
+		makeNumber("requests", data.Labels{"service": "db", "instance": "2"}, float64Pointer(30)),
+	}}
+
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  assert.ErrorAssertionFunc
+		execErrIs assert.ErrorAssertionFunc
+		resultIs  assert.ComparisonAssertionFunc
+		Results   Results
+	}{
+		{
+			name:      "on: matches only by listed labels",
+			expr:      "$A / on(service) $B",
+			vars:      Vars{"A": errors, "B": requests},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			Results: Results{[]Value{
+				makeNumber("", data.Labels{"service": "api"}, float64Pointer(0.2)),
+				makeNumber("", data.Labels{"service": "db"}, float64Pointer(0.1)),
+			}},
+		},
+		{
+			name:      "ignoring: matches by all labels except listed",
+			expr:      "$A / ignoring(code) $B",
+			vars:      Vars{"A": errors, "B": requests},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			Results: Results{[]Value{
+				makeNumber("", data.Labels{"service": "api", "instance": "1"}, float64Pointer(0.2)),
+				makeNumber("", data.Labels{"service": "db", "instance": "2"}, float64Pointer(0.1)),
+			}},
+		},
+		{
+			name: "many-to-one: result gets labels of the many side",
+			expr: `$A * on("service") $B`,
+			vars: Vars{
+				"A": Results{[]Value{
+					makeNumber("a", data.Labels{"service": "api", "instance": "1"}, float64Pointer(1)),
+					makeNumber("a", data.Labels{"service": "api", "instance": "2"}, float64Pointer(2)),
+				}},
+				"B": Results{[]Value{
+					makeNumber("b", data.Labels{"service": "api"}, float64Pointer(10)),
+				}},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			Results: Results{[]Value{
+				makeNumber("", data.Labels{"service": "api", "instance": "1"}, float64Pointer(10)),
+				makeNumber("", data.Labels{"service": "api", "instance": "2"}, float64Pointer(20)),
+			}},
+		},
+		{
+			name: "many-to-many matching is an error",
+			expr: "$A + on(service) $A",
+			vars: Vars{
+				"A": Results{[]Value{
+					makeNumber("a", data.Labels{"service": "api", "instance": "1"}, float64Pointer(1)),
+					makeNumber("a", data.Labels{"service": "api", "instance": "2"}, float64Pointer(2)),
+				}},
+			},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+			resultIs:  assert.Equal,
+			Results:   Results{Values{}},
+		},
+		{
+			name:      "matching is ignored for scalars",
+			expr:      "$A * on(service) 2",
+			vars:      Vars{"A": requests},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			Results: Results{[]Value{
+				makeNumber("", data.Labels{"service": "api", "instance": "1"}, float64Pointer(20)),
+				makeNumber("", data.Labels{"service": "db", "instance": "2"}, float64Pointer(60)),
+			}},
+		},
+		{
+			name:      "matching requires label list",
+			expr:      "$A / on $B",
+			vars:      Vars{"A": errors, "B": requests},
+			newErrIs:  assert.Error,
+			execErrIs: assert.NoError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars, tracing.NewFakeTracer())
+				tt.execErrIs(t, err)
+				tt.resultIs(t, tt.Results, res)
+			}
+		})
+	}
+}
+
+func TestAggregateExpr(t *testing.T) {
+	numbers := Results{[]Value{
+		makeNumber("a", data.Labels{"service": "api", "instance": "1"}, float64Pointer(1)),
+		makeNumber("a", data.Labels{"service": "api", "instance": "2"}, float64Pointer(3)),
+		makeNumber("a", data.Labels{"service": "db", "instance": "3"}, float64Pointer(5)),
+		makeNumber("a", data.Labels{"service": "db", "instance": "4"}, nil),
+	}}
+
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  assert.ErrorAssertionFunc
+		execErrIs assert.ErrorAssertionFunc
+		resultIs  assert.ComparisonAssertionFunc
+		Results   Results
+	}{
+		{
+			name:      "sum by",
+			expr:      "sum by (service) ($A)",
+			vars:      Vars{"A": numbers},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			Results: Results{[]Value{
+				makeNumber("", data.Labels{"service": "api"}, float64Pointer(4)),
+				makeNumber("", data.Labels{"service": "db"}, float64Pointer(5)),
+			}},
+		},
+		{
+			name:      "avg without with trailing grouping",
+			expr:      "avg($A) without (instance)",
+			vars:      Vars{"A": numbers},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			Results: Results{[]Value{
+				makeNumber("", data.Labels{"service": "api"}, float64Pointer(2)),
+				makeNumber("", data.Labels{"service": "db"}, float64Pointer(5)),
+			}},
+		},
+		{
+			name:      "count without grouping aggregates everything",
+			expr:      "count($A)",
+			vars:      Vars{"A": numbers},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			Results: Results{[]Value{
+				makeNumber("", data.Labels{}, float64Pointer(3)),
+			}},
+		},
+		{
+			name: "max by of series aggregates points by time",
+			expr: "max by (service) ($A)",
+			vars: Vars{"A": Results{[]Value{
+				makeSeries("a", data.Labels{"service": "api", "instance": "1"},
+					tp{time.Unix(5, 0), float64Pointer(1)},
+					tp{time.Unix(10, 0), float64Pointer(4)},
+				),
+				makeSeries("a", data.Labels{"service": "api", "instance": "2"},
+					tp{time.Unix(10, 0), float64Pointer(2)},
+					tp{time.Unix(15, 0), nil},
+				),
+			}}},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			Results: Results{[]Value{
+				makeSeries("", data.Labels{"service": "api"},
+					tp{time.Unix(5, 0), float64Pointer(1)},
+					tp{time.Unix(10, 0), float64Pointer(4)},
+					tp{time.Unix(15, 0), nil},
+				),
+			}},
+		},
+		{
+			name:      "aggregation of no data is no data",
+			expr:      "sum by (service) ($A)",
+			vars:      Vars{"A": Results{[]Value{NewNoData()}}},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			Results:   Results{[]Value{NewNoData()}},
+		},
+		{
+			name:      "aggregation combined with vector matching",
+			expr:      "$A / on(service) sum by (service) ($A)",
+			vars:      Vars{"A": Results{numbers.Values[:3]}},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			resultIs:  assert.Equal,
+			Results: Results{[]Value{
+				makeNumber("", data.Labels{"service": "api", "instance": "1"}, float64Pointer(0.25)),
+				makeNumber("", data.Labels{"service": "api", "instance": "2"}, float64Pointer(0.75)),
+				// one-to-one matching keeps only the matching labels
+				makeNumber("", data.Labels{"service": "db"}, float64Pointer(1)),
+			}},
+		},
+		{
+			name:      "aggregation of scalar is a parse error",
+			expr:      "sum by (service) (1)",
+			vars:      Vars{},
+			newErrIs:  assert.Error,
+			execErrIs: assert.NoError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars, tracing.NewFakeTracer())
+				tt.execErrIs(t, err)
+				tt.resultIs(t, tt.Results, res)
+			}
+		})
+	}
+}
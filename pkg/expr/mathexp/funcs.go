@@ -1,6 +1,7 @@
 package mathexp
 
 import (
+	"fmt"
 	"math"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
@@ -68,6 +69,12 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             floor,
 	},
+	"reduce": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeNumberSet,
+		F:      reduce,
+		Check:  checkReducer,
+	},
 }
 
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
@@ -307,3 +314,40 @@ func floor(e *State, varSet Results) (Results, error) {
 	}
 	return newRes, nil
 }
+
+// reduce turns each Series into a Number using the reducer function with the given name, e.g. reduce($A, "p95").
+// Numbers are returned as is.
+func reduce(e *State, varSet Results, rFunc string) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		switch v := res.(type) {
+		case Series:
+			n, err := v.Reduce(e.RefID, rFunc, nil)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, n)
+		case Number:
+			n := NewNumber(e.RefID, v.GetLabels())
+			n.SetValue(v.GetFloat64Value())
+			newRes.Values = append(newRes.Values, n)
+		case NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("can only reduce type series, got type %v", res.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// checkReducer validates the name of the reducer passed to the reduce function.
+func checkReducer(_ *parse.Tree, f *parse.FuncNode) error {
+	reducer, ok := f.Args[1].(*parse.StringNode)
+	if !ok {
+		return fmt.Errorf("parse: expected reducer name to be a string, got %v", f.Args[1])
+	}
+	if _, err := GetReduceFunc(reducer.Text); err != nil {
+		return fmt.Errorf("parse: %w", err)
+	}
+	return nil
+}
@@ -160,3 +160,39 @@ func TestIsNumberFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestReduceFunc(t *testing.T) {
+	t.Run("reduces series using the reducer", func(t *testing.T) {
+		e, err := New(`reduce($A, "p50")`)
+		require.NoError(t, err)
+		res, err := e.Execute("", aSeries, tracing.NewFakeTracer())
+		require.NoError(t, err)
+		require.Equal(t, Results{[]Value{makeNumber("", nil, float64Pointer(1.5))}}, res)
+	})
+
+	t.Run("result can be used in math expression", func(t *testing.T) {
+		e, err := New(`reduce($A, "max") - reduce($A, "min")`)
+		require.NoError(t, err)
+		res, err := e.Execute("", aSeries, tracing.NewFakeTracer())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.Equal(t, float64(1), *res.Values[0].(Number).GetFloat64Value())
+	})
+
+	t.Run("fails to parse unknown reducer", func(t *testing.T) {
+		_, err := New(`reduce($A, "foo")`)
+		require.Error(t, err)
+	})
+
+	t.Run("fails to parse if reducer is not a string", func(t *testing.T) {
+		_, err := New(`reduce($A, 1)`)
+		require.Error(t, err)
+	})
+
+	t.Run("fails to parse empty arguments", func(t *testing.T) {
+		for _, expr := range []string{`reduce($A,, "max")`, `reduce(, $A, "max")`, `reduce($A, "max",)`, `reduce($A "max")`} {
+			_, err := New(expr)
+			require.ErrorContains(t, err, "unexpected", expr)
+		}
+	})
+}
@@ -113,6 +113,18 @@ var lexTests = []lexTest{
 		{itemVar, 0, "$A"},
 		tEOF,
 	}},
+	{"vector matching", "$A / on(k8s_pod, \"service name\") $B", []item{
+		{itemVar, 0, "$A"},
+		tDiv,
+		{itemFunc, 0, "on"},
+		{itemLeftParen, 0, "("},
+		{itemFunc, 0, "k8s_pod"},
+		{itemComma, 0, ","},
+		{itemString, 0, `"service name"`},
+		{itemRightParen, 0, ")"},
+		{itemVar, 0, "$B"},
+		tEOF,
+	}},
 	// errors
 	{"unclosed quote", "\"", []item{
 		{itemError, 0, "unterminated string"},
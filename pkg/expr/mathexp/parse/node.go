@@ -9,6 +9,7 @@ package parse
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // A Node is an element in the parse tree. The interface is trivial.
@@ -62,6 +63,8 @@ const (
 	NodeNumber
 	// NodeVar is variable: $A
 	NodeVar
+	// NodeAggregate is an aggregation over labels: sum by (host) ($A)
+	NodeAggregate
 )
 
 // String returns the string representation of the NodeType
@@ -77,6 +80,8 @@ func (t NodeType) String() string {
 		return "NodeString"
 	case NodeNumber:
 		return "NodeNumber"
+	case NodeAggregate:
+		return "NodeAggregate"
 	default:
 		return "NodeUnknown"
 	}
@@ -298,6 +303,25 @@ type BinaryNode struct {
 	Args     [2]Node
 	Operator item
 	OpStr    string
+	// Matching describes how items of the arguments are matched by their labels. If nil, the default union is used.
+	Matching *VectorMatching
+}
+
+// VectorMatching describes how items of two sets are matched by labels in a binary operation,
+// e.g. $A / on(service) $B or $A / ignoring(instance) $B
+type VectorMatching struct {
+	// On is true if items are matched only by the Labels. Otherwise, items are matched by all labels except the Labels.
+	On     bool
+	Labels []string
+}
+
+// String returns the string representation of the VectorMatching.
+func (m *VectorMatching) String() string {
+	keyword := "ignoring"
+	if m.On {
+		keyword = "on"
+	}
+	return fmt.Sprintf("%s(%s)", keyword, strings.Join(m.Labels, ", "))
 }
 
 func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
@@ -306,11 +330,17 @@ func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
 
 // String returns the string representation of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) String() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s %s %s", b.Args[0], b.Operator.val, b.Matching, b.Args[1])
+	}
 	return fmt.Sprintf("%s %s %s", b.Args[0], b.Operator.val, b.Args[1])
 }
 
 // StringAST returns the string representation of abstract syntax tree of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) StringAST() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s(%s, %s)", b.Operator.val, b.Matching, b.Args[0], b.Args[1])
+	}
 	return fmt.Sprintf("%s(%s, %s)", b.Operator.val, b.Args[0], b.Args[1])
 }
 
@@ -367,6 +397,68 @@ func (u *UnaryNode) Return() ReturnType {
 	return u.Arg.Return()
 }
 
+// AggregateNode holds an aggregation of items of a set by their labels, e.g. sum by (host) ($A) or avg without (instance) ($A).
+type AggregateNode struct {
+	NodeType
+	Pos
+	Op  string
+	Arg Node
+	// Grouping is the list of labels to group by, or to exclude from grouping if Without is true.
+	// If Grouping is empty and Without is false, all items are aggregated into one.
+	Grouping []string
+	Without  bool
+}
+
+func newAggregate(pos Pos, op string) *AggregateNode {
+	return &AggregateNode{NodeType: NodeAggregate, Pos: pos, Op: op}
+}
+
+// String returns the string representation of the AggregateNode so it fulfills the Node interface.
+func (a *AggregateNode) String() string {
+	if a.Without {
+		return fmt.Sprintf("%s without (%s) (%s)", a.Op, strings.Join(a.Grouping, ", "), a.Arg)
+	}
+	if len(a.Grouping) > 0 {
+		return fmt.Sprintf("%s by (%s) (%s)", a.Op, strings.Join(a.Grouping, ", "), a.Arg)
+	}
+	return fmt.Sprintf("%s(%s)", a.Op, a.Arg)
+}
+
+// StringAST returns the string representation of abstract syntax tree of the AggregateNode so it fulfills the Node interface.
+func (a *AggregateNode) StringAST() string {
+	if a.Without {
+		return fmt.Sprintf("%s without (%s) (%s)", a.Op, strings.Join(a.Grouping, ", "), a.Arg.StringAST())
+	}
+	if len(a.Grouping) > 0 {
+		return fmt.Sprintf("%s by (%s) (%s)", a.Op, strings.Join(a.Grouping, ", "), a.Arg.StringAST())
+	}
+	return fmt.Sprintf("%s(%s)", a.Op, a.Arg.StringAST())
+}
+
+// Check performs parse time checking on the AggregateNode so it fulfills the Node interface.
+func (a *AggregateNode) Check(t *Tree) error {
+	switch rt := a.Arg.Return(); rt {
+	case TypeNumberSet, TypeSeriesSet:
+		return a.Arg.Check(t)
+	default:
+		return fmt.Errorf(`parse: type error in %s, expected %v or %v, got %s`, a, TypeNumberSet, TypeSeriesSet, rt)
+	}
+}
+
+// Return returns the result type of the AggregateNode so it fulfills the Node interface.
+func (a *AggregateNode) Return() ReturnType {
+	return a.Arg.Return()
+}
+
+// IsAggregateOp returns true if the name is a name of an aggregation operator.
+func IsAggregateOp(name string) bool {
+	switch name {
+	case "sum", "avg", "min", "max", "count":
+		return true
+	}
+	return false
+}
+
 // Walk invokes f on n and sub-nodes of n.
 func Walk(n Node, f func(Node)) {
 	f(n)
@@ -382,6 +474,8 @@ func Walk(n Node, f func(Node)) {
 		// Ignore since these node types have no sub nodes.
 	case *UnaryNode:
 		Walk(n.Arg, f)
+	case *AggregateNode:
+		Walk(n.Arg, f)
 	default:
 		panic(fmt.Errorf("other type: %T", n))
 	}
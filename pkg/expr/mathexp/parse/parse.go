@@ -165,16 +165,22 @@ func (t *Tree) parse() {
 }
 
 /* Grammar:
-O -> A {"||" A}
-A -> C {"&&" C}
-C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") P}
-P -> M {( "+" | "-" ) M}
-M -> E {( "*" | "/" ) F}
-E -> F {( "**" ) F}
+O -> A {"||" [matching] A}
+A -> C {"&&" [matching] C}
+C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") [matching] P}
+P -> M {( "+" | "-" ) [matching] M}
+M -> E {( "*" | "/" ) [matching] F}
+E -> F {( "**" ) [matching] F}
 F -> v | "(" O ")" | "!" O | "-" O
-v -> number | func(..) | queryVar
+v -> number | func(..) | queryVar | aggregate
 Func -> name "(" param {"," param} ")"
 param -> number | "string" | queryVar
+matching -> ( "on" | "ignoring" ) labels
+aggregate -> aggop [grouping] "(" O ")" [grouping]
+aggop -> "sum" | "avg" | "min" | "max" | "count"
+grouping -> ( "by" | "without" ) labels
+labels -> "(" [label {"," label}] ")"
+label -> name | "string"
 */
 
 // expr:
@@ -185,7 +191,7 @@ func (t *Tree) O() Node {
 	for {
 		switch t.peek().typ {
 		case itemOr:
-			n = newBinary(t.next(), n, t.A())
+			n = t.binary(t.next(), n, t.A)
 		default:
 			return n
 		}
@@ -198,7 +204,7 @@ func (t *Tree) A() Node {
 	for {
 		switch t.peek().typ {
 		case itemAnd:
-			n = newBinary(t.next(), n, t.C())
+			n = t.binary(t.next(), n, t.C)
 		default:
 			return n
 		}
@@ -211,7 +217,7 @@ func (t *Tree) C() Node {
 	for {
 		switch t.peek().typ {
 		case itemEq, itemNotEq, itemGreater, itemGreaterEq, itemLess, itemLessEq:
-			n = newBinary(t.next(), n, t.P())
+			n = t.binary(t.next(), n, t.P)
 		default:
 			return n
 		}
@@ -224,7 +230,7 @@ func (t *Tree) P() Node {
 	for {
 		switch t.peek().typ {
 		case itemPlus, itemMinus:
-			n = newBinary(t.next(), n, t.M())
+			n = t.binary(t.next(), n, t.M)
 		default:
 			return n
 		}
@@ -237,7 +243,7 @@ func (t *Tree) M() Node {
 	for {
 		switch t.peek().typ {
 		case itemMult, itemDiv, itemMod:
-			n = newBinary(t.next(), n, t.E())
+			n = t.binary(t.next(), n, t.E)
 		default:
 			return n
 		}
@@ -250,13 +256,81 @@ func (t *Tree) E() Node {
 	for {
 		switch t.peek().typ {
 		case itemPow:
-			n = newBinary(t.next(), n, t.F())
+			n = t.binary(t.next(), n, t.F)
 		default:
 			return n
 		}
 	}
 }
 
+// binary creates a BinaryNode for the operator. It parses the optional vector matching clause that follows the operator
+// and then the right operand using the provided function.
+func (t *Tree) binary(operator item, left Node, right func() Node) Node {
+	var matching *VectorMatching
+	if token := t.peek(); token.typ == itemFunc && (token.val == "on" || token.val == "ignoring") {
+		t.next()
+		matching = &VectorMatching{
+			On:     token.val == "on",
+			Labels: t.labels(token.val),
+		}
+	}
+	n := newBinary(operator, left, right())
+	n.Matching = matching
+	return n
+}
+
+// labels parses a list of label names enclosed in parentheses, e.g. (host, "service name").
+func (t *Tree) labels(context string) []string {
+	t.expect(itemLeftParen, context)
+	labels := []string{}
+	for {
+		switch token := t.next(); token.typ {
+		case itemFunc:
+			labels = append(labels, token.val)
+		case itemString:
+			s, err := strconv.Unquote(token.val)
+			if err != nil {
+				t.errorf("Unquoting error: %s", err)
+			}
+			labels = append(labels, s)
+		case itemRightParen:
+			return labels
+		default:
+			t.unexpected(token, context)
+		}
+		switch token := t.next(); token.typ {
+		case itemComma:
+		case itemRightParen:
+			return labels
+		default:
+			t.unexpected(token, context)
+		}
+	}
+}
+
+// Aggregate parses an AggregateNode.
+func (t *Tree) Aggregate() *AggregateNode {
+	token := t.next()
+	a := newAggregate(token.pos, token.val)
+	grouping := func() bool {
+		if next := t.peek(); next.typ == itemFunc && (next.val == "by" || next.val == "without") {
+			t.next()
+			a.Without = next.val == "without"
+			a.Grouping = t.labels(next.val)
+			return true
+		}
+		return false
+	}
+	hasGrouping := grouping()
+	t.expect(itemLeftParen, token.val)
+	a.Arg = t.O()
+	t.expect(itemRightParen, token.val)
+	if !hasGrouping {
+		grouping()
+	}
+	return a
+}
+
 // F is v | "(" O ")" | "!" O | "-" O in the grammar.
 func (t *Tree) F() Node {
 	switch token := t.peek(); token.typ {
@@ -286,6 +360,9 @@ func (t *Tree) v() Node {
 		return n
 	case itemFunc:
 		t.backup()
+		if IsAggregateOp(token.val) {
+			return t.Aggregate()
+		}
 		return t.Func()
 	case itemVar:
 		t.backup()
@@ -314,22 +391,40 @@ func (t *Tree) Func() (f *FuncNode) {
 	}
 	f = newFunc(token.pos, token.val, funcv)
 	t.expect(itemLeftParen, "func")
+	// afterArg is true when the last token was an argument, which must be followed by a comma or the closing paren.
+	afterArg := false
 	for {
 		switch token = t.next(); token.typ {
 		default:
+			if afterArg {
+				t.unexpected(token, "func")
+			}
 			t.backup()
 			node := t.O()
 			f.append(node)
 			if len(f.Args) == 1 && f.F.VariantReturn {
 				f.F.Return = node.Return()
 			}
+			afterArg = true
 		case itemString:
+			if afterArg {
+				t.unexpected(token, "func")
+			}
 			s, err := strconv.Unquote(token.val)
 			if err != nil {
 				t.errorf("Unquoting error: %s", err)
 			}
 			f.append(newString(token.pos, token.val, s))
+			afterArg = true
+		case itemComma:
+			if !afterArg {
+				t.unexpected(token, "func")
+			}
+			afterArg = false
 		case itemRightParen:
+			if len(f.Args) > 0 && !afterArg {
+				t.unexpected(token, "func")
+			}
 			return
 		}
 	}
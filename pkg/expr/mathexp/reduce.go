@@ -3,6 +3,9 @@ package mathexp
 import (
 	"fmt"
 	"math"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -81,8 +84,117 @@ func Last(fv *Float64Field) *float64 {
 	return fv.GetValue(fv.Len() - 1)
 }
 
+func First(fv *Float64Field) *float64 {
+	var f float64
+	if fv.Len() == 0 {
+		f = math.NaN()
+		return &f
+	}
+	return fv.GetValue(0)
+}
+
+// CountNonNull returns the number of values that are not null.
+func CountNonNull(fv *Float64Field) *float64 {
+	var f float64
+	for i := 0; i < fv.Len(); i++ {
+		if fv.GetValue(i) != nil {
+			f++
+		}
+	}
+	return &f
+}
+
+// Range returns the difference between the maximum and the minimum value.
+func Range(fv *Float64Field) *float64 {
+	minimum := Min(fv)
+	maximum := Max(fv)
+	f := *maximum - *minimum
+	return &f
+}
+
+// Diff returns the difference between the last and the first value.
+func Diff(fv *Float64Field) *float64 {
+	first := First(fv)
+	last := Last(fv)
+	f := math.NaN()
+	if first != nil && last != nil {
+		f = *last - *first
+	}
+	return &f
+}
+
+func Variance(fv *Float64Field) *float64 {
+	values, ok := getNumbers(fv)
+	f := math.NaN()
+	if !ok || len(values) == 0 {
+		return &f
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	f = squares / float64(len(values))
+	return &f
+}
+
+func StdDev(fv *Float64Field) *float64 {
+	f := math.Sqrt(*Variance(fv))
+	return &f
+}
+
+func Median(fv *Float64Field) *float64 {
+	return Percentile(50)(fv)
+}
+
+// Percentile returns a reducer that calculates the p-th percentile (0 <= p <= 100) of the values
+// using linear interpolation between the closest ranks.
+func Percentile(p float64) ReducerFunc {
+	return func(fv *Float64Field) *float64 {
+		values, ok := getNumbers(fv)
+		f := math.NaN()
+		if !ok || len(values) == 0 {
+			return &f
+		}
+		sort.Float64s(values)
+		rank := p / 100 * float64(len(values)-1)
+		lower := int(math.Floor(rank))
+		upper := int(math.Ceil(rank))
+		f = values[lower] + (values[upper]-values[lower])*(rank-float64(lower))
+		return &f
+	}
+}
+
+// getNumbers returns the values of the field. It returns false if any of the values is null or NaN.
+func getNumbers(fv *Float64Field) ([]float64, bool) {
+	values := make([]float64, 0, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			return nil, false
+		}
+		values = append(values, *v)
+	}
+	return values, true
+}
+
+// percentileReducerPattern matches names of percentile reducers, e.g. p95 or p99.9
+var percentileReducerPattern = regexp.MustCompile(`^p(\d{1,2}(\.\d+)?|100)$`)
+
 func GetReduceFunc(rFunc string) (ReducerFunc, error) {
-	switch strings.ToLower(rFunc) {
+	rFunc = strings.ToLower(rFunc)
+	if m := percentileReducerPattern.FindStringSubmatch(rFunc); m != nil {
+		p, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("reduction %v not implemented", rFunc)
+		}
+		return Percentile(p), nil
+	}
+	switch rFunc {
 	case "sum":
 		return Sum, nil
 	case "mean":
@@ -95,14 +207,29 @@ func GetReduceFunc(rFunc string) (ReducerFunc, error) {
 		return Count, nil
 	case "last":
 		return Last, nil
+	case "first":
+		return First, nil
+	case "count_nonnull":
+		return CountNonNull, nil
+	case "median":
+		return Median, nil
+	case "stddev":
+		return StdDev, nil
+	case "variance":
+		return Variance, nil
+	case "range":
+		return Range, nil
+	case "diff":
+		return Diff, nil
 	default:
 		return nil, fmt.Errorf("reduction %v not implemented", rFunc)
 	}
 }
 
-// GetSupportedReduceFuncs returns collection of supported function names
+// GetSupportedReduceFuncs returns collection of supported function names.
+// Besides the listed percentiles, any percentile in the form pN (e.g. p75 or p99.9) is supported.
 func GetSupportedReduceFuncs() []string {
-	return []string{"sum", "mean", "min", "max", "count", "last"}
+	return []string{"sum", "mean", "min", "max", "count", "last", "first", "count_nonnull", "median", "stddev", "variance", "range", "diff", "p50", "p90", "p95", "p99"}
 }
 
 // Reduce turns the Series into a Number based on the given reduction function
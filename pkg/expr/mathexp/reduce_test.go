@@ -514,3 +514,74 @@ func TestSeriesReduceReplaceNN(t *testing.T) {
 		})
 	}
 }
+
+func TestSeriesReduceStatistics(t *testing.T) {
+	series := makeSeries("temp", nil,
+		tp{time.Unix(5, 0), float64Pointer(4)},
+		tp{time.Unix(10, 0), float64Pointer(1)},
+		tp{time.Unix(15, 0), float64Pointer(3)},
+		tp{time.Unix(20, 0), float64Pointer(2)},
+		tp{time.Unix(25, 0), float64Pointer(5)},
+	)
+
+	var tests = []struct {
+		red      string
+		expected float64
+	}{
+		{red: "first", expected: 4},
+		{red: "count_nonnull", expected: 5},
+		{red: "median", expected: 3},
+		{red: "p50", expected: 3},
+		{red: "p90", expected: 4.6},
+		{red: "p99.5", expected: 4.98},
+		{red: "p100", expected: 5},
+		{red: "variance", expected: 2},
+		{red: "stddev", expected: math.Sqrt(2)},
+		{red: "range", expected: 4},
+		{red: "diff", expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.red, func(t *testing.T) {
+			n, err := series.Reduce("", tt.red, nil)
+			require.NoError(t, err)
+			require.NotNil(t, n.GetFloat64Value())
+			require.InDelta(t, tt.expected, *n.GetFloat64Value(), 1e-9)
+		})
+	}
+
+	t.Run("should error on unknown percentile", func(t *testing.T) {
+		_, err := series.Reduce("", "p101", nil)
+		require.Error(t, err)
+	})
+
+	t.Run("should return NaN if series contains null", func(t *testing.T) {
+		s := seriesWithNil["A"].Values[0].(Series)
+		for _, red := range []string{"p95", "median", "stddev", "variance", "range", "diff"} {
+			n, err := s.Reduce("", red, nil)
+			require.NoError(t, err)
+			require.Truef(t, math.IsNaN(*n.GetFloat64Value()), "reducer %s", red)
+		}
+		n, err := s.Reduce("", "count_nonnull", nil)
+		require.NoError(t, err)
+		require.Equal(t, float64(1), *n.GetFloat64Value())
+	})
+
+	t.Run("should drop non-numbers when mapper is DropNonNumber", func(t *testing.T) {
+		s := seriesWithNil["A"].Values[0].(Series)
+		n, err := s.Reduce("", "p95", DropNonNumber{})
+		require.NoError(t, err)
+		require.Equal(t, float64(2), *n.GetFloat64Value())
+
+		n, err = seriesEmpty["A"].Values[0].(Series).Reduce("", "stddev", DropNonNumber{})
+		require.NoError(t, err)
+		require.Nil(t, n.GetFloat64Value())
+	})
+
+	t.Run("should replace non-numbers when mapper is ReplaceNonNumberWithValue", func(t *testing.T) {
+		s := seriesWithNil["A"].Values[0].(Series)
+		n, err := s.Reduce("", "range", ReplaceNonNumberWithValue{Value: 10})
+		require.NoError(t, err)
+		require.Equal(t, float64(8), *n.GetFloat64Value())
+	})
+}
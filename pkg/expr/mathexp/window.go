@@ -0,0 +1,292 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// WindowAlignTrailing makes the window end at the point being computed, i.e. (t-window, t].
+	WindowAlignTrailing = "trailing"
+	// WindowAlignCenter makes the window centered at the point being computed, i.e. [t-window/2, t+window/2].
+	WindowAlignCenter = "center"
+	// WindowAlignLeading makes the window start at the point being computed, i.e. [t, t+window).
+	WindowAlignLeading = "leading"
+)
+
+// windowPoint is a point of a series that has a numeric value.
+type windowPoint struct {
+	t time.Time
+	v float64
+}
+
+// WindowFunc calculates a value from the points that are in the window. Points are sorted by time.
+// It returns nil if the value cannot be calculated from the provided points.
+type WindowFunc = func(points []windowPoint) *float64
+
+func movingAvg(points []windowPoint) *float64 {
+	if len(points) == 0 {
+		return nil
+	}
+	var sum float64
+	for _, p := range points {
+		sum += p.v
+	}
+	f := sum / float64(len(points))
+	return &f
+}
+
+func movingSum(points []windowPoint) *float64 {
+	if len(points) == 0 {
+		return nil
+	}
+	var sum float64
+	for _, p := range points {
+		sum += p.v
+	}
+	return &sum
+}
+
+func movingMedian(points []windowPoint) *float64 {
+	if len(points) == 0 {
+		return nil
+	}
+	values := make([]float64, 0, len(points))
+	for _, p := range points {
+		values = append(values, p.v)
+	}
+	sort.Float64s(values)
+	var f float64
+	if mid := len(values) / 2; len(values)%2 == 0 {
+		f = (values[mid-1] + values[mid]) / 2
+	} else {
+		f = values[mid]
+	}
+	return &f
+}
+
+func movingMin(points []windowPoint) *float64 {
+	if len(points) == 0 {
+		return nil
+	}
+	f := points[0].v
+	for _, p := range points[1:] {
+		if p.v < f {
+			f = p.v
+		}
+	}
+	return &f
+}
+
+func movingMax(points []windowPoint) *float64 {
+	if len(points) == 0 {
+		return nil
+	}
+	f := points[0].v
+	for _, p := range points[1:] {
+		if p.v > f {
+			f = p.v
+		}
+	}
+	return &f
+}
+
+// delta is the difference between the last and the first value in the window.
+func delta(points []windowPoint) *float64 {
+	if len(points) < 2 {
+		return nil
+	}
+	f := points[len(points)-1].v - points[0].v
+	return &f
+}
+
+// increase is the difference between the last and the first value in the window where the values are treated as a counter,
+// i.e. if a value is less than the previous one, the counter is considered to be reset.
+func increase(points []windowPoint) *float64 {
+	if len(points) < 2 {
+		return nil
+	}
+	var f float64
+	for i := 1; i < len(points); i++ {
+		if points[i].v < points[i-1].v {
+			f += points[i].v
+			continue
+		}
+		f += points[i].v - points[i-1].v
+	}
+	return &f
+}
+
+// rate is the per-second increase of the counter in the window.
+func rate(points []windowPoint) *float64 {
+	inc := increase(points)
+	if inc == nil {
+		return nil
+	}
+	seconds := points[len(points)-1].t.Sub(points[0].t).Seconds()
+	if seconds == 0 {
+		return nil
+	}
+	f := *inc / seconds
+	return &f
+}
+
+// derivative is the per-second derivative of the values in the window calculated using simple linear regression.
+func derivative(points []windowPoint) *float64 {
+	if len(points) < 2 {
+		return nil
+	}
+	var sumX, sumY, sumXY, sumX2 float64
+	n := float64(len(points))
+	for _, p := range points {
+		x := p.t.Sub(points[0].t).Seconds()
+		sumX += x
+		sumY += p.v
+		sumXY += x * p.v
+		sumX2 += x * x
+	}
+	d := n*sumX2 - sumX*sumX
+	if d == 0 {
+		return nil
+	}
+	f := (n*sumXY - sumX*sumY) / d
+	return &f
+}
+
+// GetWindowFunc returns the function that calculates a value of a window by its name.
+// Function "cumulative_sum" is not a window function and therefore is not supported by this method.
+func GetWindowFunc(wFunc string) (WindowFunc, error) {
+	switch strings.ToLower(wFunc) {
+	case "moving_avg":
+		return movingAvg, nil
+	case "moving_sum":
+		return movingSum, nil
+	case "moving_median":
+		return movingMedian, nil
+	case "moving_min":
+		return movingMin, nil
+	case "moving_max":
+		return movingMax, nil
+	case "delta":
+		return delta, nil
+	case "increase":
+		return increase, nil
+	case "rate":
+		return rate, nil
+	case "derivative":
+		return derivative, nil
+	default:
+		return nil, fmt.Errorf("window function %v not implemented", wFunc)
+	}
+}
+
+// GetSupportedWindowFuncs returns collection of supported window function names
+func GetSupportedWindowFuncs() []string {
+	return []string{"moving_avg", "moving_sum", "moving_median", "moving_min", "moving_max", "delta", "increase", "rate", "derivative", "cumulative_sum"}
+}
+
+// ValidateWindow returns an error if the window function, size or alignment is not supported.
+func ValidateWindow(wFunc string, window time.Duration, alignment string) error {
+	switch alignment {
+	case WindowAlignTrailing, WindowAlignCenter, WindowAlignLeading:
+	default:
+		return fmt.Errorf("window alignment %v is not supported. Supported only: [%s,%s,%s]", alignment, WindowAlignTrailing, WindowAlignCenter, WindowAlignLeading)
+	}
+	if strings.ToLower(wFunc) == "cumulative_sum" {
+		return nil
+	}
+	if window <= 0 {
+		return fmt.Errorf("window must be greater than zero, got %v", window)
+	}
+	_, err := GetWindowFunc(wFunc)
+	return err
+}
+
+// Window creates a new Series where every point is the result of the window function applied to the points of
+// the original series that fall into the window of the given size aligned relative to the point.
+// Null and NaN values are ignored. If the function cannot be calculated for a window, the value of the point is null.
+// Function "cumulative_sum" ignores the window and calculates a running total from the beginning of the series.
+func (s Series) Window(refID, wFunc string, window time.Duration, alignment string) (Series, error) {
+	if err := ValidateWindow(wFunc, window, alignment); err != nil {
+		return s, fmt.Errorf("invalid expression '%s': %w", refID, err)
+	}
+
+	sorted := NewSeries(refID, s.GetLabels(), s.Len())
+	for i := 0; i < s.Len(); i++ {
+		sorted.SetPoint(i, s.GetTime(i), s.GetValue(i))
+	}
+	sorted.SortByTime(false)
+
+	points := make([]windowPoint, 0, sorted.Len())
+	for i := 0; i < sorted.Len(); i++ {
+		t, v := sorted.GetPoint(i)
+		if v == nil || math.IsNaN(*v) {
+			continue
+		}
+		points = append(points, windowPoint{t: t, v: *v})
+	}
+
+	result := NewSeries(refID, s.GetLabels(), sorted.Len())
+
+	if strings.ToLower(wFunc) == "cumulative_sum" {
+		var sum float64
+		for i := 0; i < sorted.Len(); i++ {
+			t, v := sorted.GetPoint(i)
+			if v != nil && !math.IsNaN(*v) {
+				sum += *v
+			}
+			f := sum
+			result.SetPoint(i, t, &f)
+		}
+		return result, nil
+	}
+
+	fn, err := GetWindowFunc(wFunc)
+	if err != nil {
+		return s, err
+	}
+
+	start, end := 0, 0
+	for i := 0; i < sorted.Len(); i++ {
+		t := sorted.GetTime(i)
+		from, to := windowBounds(t, window, alignment)
+		for start < len(points) && !windowContains(points[start].t, from, to, alignment) && points[start].t.Before(to) {
+			start++
+		}
+		if end < start {
+			end = start
+		}
+		for end < len(points) && windowContains(points[end].t, from, to, alignment) {
+			end++
+		}
+		result.SetPoint(i, t, fn(points[start:end]))
+	}
+	return result, nil
+}
+
+func windowBounds(t time.Time, window time.Duration, alignment string) (time.Time, time.Time) {
+	switch alignment {
+	case WindowAlignCenter:
+		return t.Add(-window / 2), t.Add(window / 2)
+	case WindowAlignLeading:
+		return t, t.Add(window)
+	default:
+		return t.Add(-window), t
+	}
+}
+
+// windowContains checks whether the time falls into the window. For trailing alignment the window is (from, to],
+// for leading alignment it is [from, to), and for the center alignment it is [from, to].
+func windowContains(t, from, to time.Time, alignment string) bool {
+	switch alignment {
+	case WindowAlignCenter:
+		return !t.Before(from) && !t.After(to)
+	case WindowAlignLeading:
+		return !t.Before(from) && t.Before(to)
+	default:
+		return t.After(from) && !t.After(to)
+	}
+}
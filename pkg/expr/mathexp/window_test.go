@@ -0,0 +1,130 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindowSeries(t *testing.T) {
+	input := makeSeries("", nil,
+		tp{time.Unix(0, 0), float64Pointer(1)},
+		tp{time.Unix(10, 0), float64Pointer(3)},
+		tp{time.Unix(20, 0), nil},
+		tp{time.Unix(30, 0), float64Pointer(8)},
+		tp{time.Unix(40, 0), float64Pointer(2)},
+	)
+
+	var tests = []struct {
+		name      string
+		function  string
+		window    time.Duration
+		alignment string
+		expected  []*float64
+	}{
+		{
+			name:      "moving_avg with trailing alignment",
+			function:  "moving_avg",
+			window:    20 * time.Second,
+			alignment: WindowAlignTrailing,
+			expected:  []*float64{float64Pointer(1), float64Pointer(2), float64Pointer(3), float64Pointer(8), float64Pointer(5)},
+		},
+		{
+			name:      "moving_max with leading alignment",
+			function:  "moving_max",
+			window:    20 * time.Second,
+			alignment: WindowAlignLeading,
+			expected:  []*float64{float64Pointer(3), float64Pointer(3), float64Pointer(8), float64Pointer(8), float64Pointer(2)},
+		},
+		{
+			name:      "moving_median with center alignment",
+			function:  "moving_median",
+			window:    20 * time.Second,
+			alignment: WindowAlignCenter,
+			expected:  []*float64{float64Pointer(2), float64Pointer(2), float64Pointer(5.5), float64Pointer(5), float64Pointer(5)},
+		},
+		{
+			name:      "delta returns null if there are less than two points in the window",
+			function:  "delta",
+			window:    20 * time.Second,
+			alignment: WindowAlignTrailing,
+			expected:  []*float64{nil, float64Pointer(2), nil, nil, float64Pointer(-6)},
+		},
+		{
+			name:      "increase handles counter resets",
+			function:  "increase",
+			window:    time.Minute,
+			alignment: WindowAlignTrailing,
+			expected:  []*float64{nil, float64Pointer(2), float64Pointer(2), float64Pointer(7), float64Pointer(9)},
+		},
+		{
+			name:      "rate is per-second increase",
+			function:  "rate",
+			window:    time.Minute,
+			alignment: WindowAlignTrailing,
+			expected:  []*float64{nil, float64Pointer(0.2), float64Pointer(0.2), float64Pointer(7.0 / 30), float64Pointer(9.0 / 40)},
+		},
+		{
+			name:      "cumulative_sum ignores window",
+			function:  "cumulative_sum",
+			alignment: WindowAlignTrailing,
+			expected:  []*float64{float64Pointer(1), float64Pointer(4), float64Pointer(4), float64Pointer(12), float64Pointer(14)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := input.Window("A", tt.function, tt.window, tt.alignment)
+			require.NoError(t, err)
+			require.Equal(t, input.Len(), result.Len())
+			for i, expected := range tt.expected {
+				require.Equal(t, input.GetTime(i), result.GetTime(i))
+				if expected == nil {
+					require.Nilf(t, result.GetValue(i), "point %d", i)
+					continue
+				}
+				require.NotNilf(t, result.GetValue(i), "point %d", i)
+				require.InDeltaf(t, *expected, *result.GetValue(i), 1e-9, "point %d", i)
+			}
+		})
+	}
+
+	t.Run("derivative is calculated using linear regression", func(t *testing.T) {
+		s := makeSeries("", nil,
+			tp{time.Unix(0, 0), float64Pointer(0)},
+			tp{time.Unix(10, 0), float64Pointer(5)},
+			tp{time.Unix(20, 0), float64Pointer(10)},
+		)
+		result, err := s.Window("A", "derivative", time.Minute, WindowAlignTrailing)
+		require.NoError(t, err)
+		require.Nil(t, result.GetValue(0))
+		require.InDelta(t, 0.5, *result.GetValue(2), 1e-9)
+	})
+
+	t.Run("sorts the input series by time", func(t *testing.T) {
+		s := makeSeries("", nil,
+			tp{time.Unix(10, 0), float64Pointer(2)},
+			tp{time.Unix(0, 0), float64Pointer(1)},
+		)
+		result, err := s.Window("A", "cumulative_sum", 0, WindowAlignTrailing)
+		require.NoError(t, err)
+		require.Equal(t, time.Unix(0, 0), result.GetTime(0))
+		require.Equal(t, float64(3), *result.GetValue(1))
+	})
+
+	t.Run("fails if function is not supported", func(t *testing.T) {
+		_, err := input.Window("A", "unknown", time.Minute, WindowAlignTrailing)
+		require.Error(t, err)
+	})
+
+	t.Run("fails if window is not positive", func(t *testing.T) {
+		_, err := input.Window("A", "moving_avg", 0, WindowAlignTrailing)
+		require.Error(t, err)
+	})
+
+	t.Run("fails if alignment is not supported", func(t *testing.T) {
+		_, err := input.Window("A", "moving_avg", time.Minute, "middle")
+		require.Error(t, err)
+	})
+}
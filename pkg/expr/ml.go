@@ -126,6 +126,7 @@ func (m *MLNode) Execute(ctx context.Context, now time.Time, _ mathexp.Vars, s *
 
 	// process the response the same way DSNode does. Use plugin ID as data source type. Semantically, they are the same.
 	responseType, result, err = convertDataFramesToResults(ctx, dataFrames, mlPluginID, s, logger)
+	recordResponse(ctx, m.refID, dataFrames, responseType)
 	return result, err
 }
 
@@ -51,6 +51,9 @@ func NodeTypeFromDatasourceUID(uid string) NodeType {
 	if uid == MLDatasourceUID {
 		return TypeMLNode
 	}
+	if uid == AlertStateDatasourceUID {
+		return TypeAlertStateNode
+	}
 	return TypeDatasourceNode
 }
 
@@ -135,6 +138,15 @@ func DataSourceModelFromNodeType(kind NodeType) (*datasources.DataSource, error)
 			JsonData:       simplejson.New(),
 			SecureJsonData: make(map[string][]byte),
 		}, nil
+	case TypeAlertStateNode:
+		return &datasources.DataSource{
+			ID:             alertStateDatasourceID,
+			UID:            AlertStateDatasourceUID,
+			Name:           AlertStateDatasourceUID,
+			Type:           AlertStateDatasourceUID,
+			JsonData:       simplejson.New(),
+			SecureJsonData: make(map[string][]byte),
+		}, nil
 	case TypeDatasourceNode:
 		return nil, errors.New("cannot create expression data source for data source kind")
 	default:
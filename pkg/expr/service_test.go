@@ -110,6 +110,103 @@ func TestService(t *testing.T) {
 	}
 }
 
+func TestServiceTrace(t *testing.T) {
+	dsDF := data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{time.Unix(1, 0)}),
+		data.NewField("value", data.Labels{"test": "label"}, []*float64{fp(2)}))
+
+	pCtxProvider := plugincontext.ProvideService(nil, &fakes.FakePluginStore{
+		PluginList: []plugins.PluginDTO{
+			{JSONData: plugins.JSONData{ID: "test"}},
+		},
+	}, &datafakes.FakeDataSourceService{}, nil)
+
+	s := Service{
+		cfg:          setting.NewCfg(),
+		dataService:  &mockEndpoint{Frames: []*data.Frame{dsDF}},
+		pCtxProvider: pCtxProvider,
+		features:     &featuremgmt.FeatureManager{},
+		tracer:       tracing.InitializeTracerForTest(),
+		metrics:      newMetrics(nil),
+	}
+
+	queries := []Query{
+		{
+			RefID: "A",
+			DataSource: &datasources.DataSource{
+				OrgID: 1,
+				UID:   "test",
+				Type:  "test",
+			},
+			JSON:      json.RawMessage(`{ "datasource": { "uid": "1" }, "intervalMs": 1000, "maxDataPoints": 1000 }`),
+			TimeRange: AbsoluteTimeRange{},
+		},
+		{
+			RefID:      "B",
+			DataSource: dataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "reduce", "expression": "A", "reducer": "last" }`),
+		},
+		{
+			RefID:      "C",
+			DataSource: dataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "1 + 1" }`),
+		},
+		{
+			// reducing a scalar fails
+			RefID:      "D",
+			DataSource: dataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "reduce", "expression": "C", "reducer": "last" }`),
+		},
+	}
+
+	pl, err := s.BuildPipeline(&Request{Queries: queries[:2], User: &user.SignedInUser{}})
+	require.NoError(t, err)
+
+	t.Run("records the execution of all nodes", func(t *testing.T) {
+		trace := &Trace{}
+		_, err := s.ExecutePipeline(WithTrace(context.Background(), trace), time.Now(), pl)
+		require.NoError(t, err)
+
+		require.Len(t, trace.Nodes, 2)
+		a, b := trace.Nodes[0], trace.Nodes[1]
+
+		require.Equal(t, "A", a.RefID)
+		require.Equal(t, TypeDatasourceNode, a.NodeType)
+		require.Equal(t, "test", a.DatasourceUID)
+		require.Equal(t, "test", a.DatasourceType)
+		require.Equal(t, data.Frames{dsDF}, a.Response)
+		require.Equal(t, "single frame series", a.Conversion)
+		require.Len(t, a.Results, 1)
+		require.NoError(t, a.Error)
+
+		require.Equal(t, "B", b.RefID)
+		require.Equal(t, TypeCMDNode, b.NodeType)
+		require.Equal(t, []string{"A"}, b.Inputs)
+		require.Len(t, b.Results, 1)
+		require.Equal(t, fp(2), b.Results[0].Fields[0].At(0))
+	})
+
+	t.Run("records the node that failed", func(t *testing.T) {
+		pl, err := s.BuildPipeline(&Request{Queries: queries, User: &user.SignedInUser{}})
+		require.NoError(t, err)
+
+		trace := &Trace{}
+		_, err = s.ExecutePipeline(WithTrace(context.Background(), trace), time.Now(), pl)
+		require.Error(t, err)
+
+		// the execution stops at the node that failed
+		failed := trace.Nodes[len(trace.Nodes)-1]
+		require.Equal(t, "D", failed.RefID)
+		require.Equal(t, err, failed.Error)
+		require.Empty(t, failed.Results)
+	})
+
+	t.Run("does not record anything without a trace", func(t *testing.T) {
+		_, err := s.ExecutePipeline(context.Background(), time.Now(), pl)
+		require.NoError(t, err)
+	})
+}
+
 func fp(f float64) *float64 {
 	return &f
 }
@@ -0,0 +1,97 @@
+package expr
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// Trace records the execution of every node of a data pipeline. A pipeline records its execution in the trace when
+// it is executed with a context that contains the trace, see WithTrace. A trace must not be shared by pipelines that
+// are executed concurrently.
+type Trace struct {
+	Nodes []*NodeTrace
+}
+
+// NodeTrace is the execution of a single node of a data pipeline.
+type NodeTrace struct {
+	RefID    string
+	NodeType NodeType
+	// Inputs are the RefIDs of the nodes whose results are used by an expression.
+	Inputs []string
+	// DatasourceUID and DatasourceType are set for data source queries.
+	DatasourceUID  string
+	DatasourceType string
+	// Response are the frames that the data source returned for the query, before they were converted to results.
+	Response data.Frames
+	// Conversion describes how the response was converted to results, for example "vector" or "no-data".
+	Conversion string
+	// Results are the results of the node.
+	Results  data.Frames
+	Start    time.Time
+	Duration time.Duration
+	Error    error
+}
+
+type traceKey struct{}
+
+// WithTrace returns a context with the trace that pipelines executed with the context record their execution in.
+func WithTrace(ctx context.Context, trace *Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, trace)
+}
+
+func traceFromContext(ctx context.Context) *Trace {
+	trace, _ := ctx.Value(traceKey{}).(*Trace)
+	return trace
+}
+
+// start adds the node to the trace. It returns nil if the trace is nil.
+func (t *Trace) start(node Node) *NodeTrace {
+	if t == nil {
+		return nil
+	}
+	nt := &NodeTrace{
+		RefID:    node.RefID(),
+		NodeType: node.NodeType(),
+		Start:    time.Now(),
+	}
+	switch n := node.(type) {
+	case *CMDNode:
+		nt.Inputs = n.Command.NeedsVars()
+	case *DSNode:
+		nt.DatasourceUID = n.datasource.UID
+		nt.DatasourceType = n.datasource.Type
+	}
+	t.Nodes = append(t.Nodes, nt)
+	return nt
+}
+
+func (nt *NodeTrace) finish(res mathexp.Results, err error) {
+	if nt == nil {
+		return
+	}
+	nt.Duration = time.Since(nt.Start)
+	nt.Error = err
+	if err == nil {
+		nt.Results = res.Values.AsDataFrames(nt.RefID)
+	}
+}
+
+// recordResponse adds the response of a data source and how it was converted to results to the trace of the node
+// that is executed with the context.
+func recordResponse(ctx context.Context, refID string, frames data.Frames, conversion string) {
+	trace := traceFromContext(ctx)
+	if trace == nil {
+		return
+	}
+	for i := len(trace.Nodes) - 1; i >= 0; i-- {
+		if trace.Nodes[i].RefID == refID {
+			trace.Nodes[i].Response = frames
+			trace.Nodes[i].Conversion = conversion
+			return
+		}
+	}
+}
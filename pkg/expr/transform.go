@@ -19,6 +19,8 @@ type Request struct {
 	OrgId   int64
 	Queries []Query
 	User    *user.SignedInUser
+	// AlertStates provides the current state of alert rules to alert state queries. Optional.
+	AlertStates AlertStateReader
 }
 
 // Query is like plugins.DataSubQuery, but with a a time range, and only the UID
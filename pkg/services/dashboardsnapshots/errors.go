@@ -5,3 +5,5 @@ import (
 )
 
 var ErrBaseNotFound = errutil.NewBase(errutil.StatusNotFound, "dashboardsnapshots.not-found", errutil.WithPublicMessage("Snapshot not found"))
+
+var ErrBaseBadRequest = errutil.NewBase(errutil.StatusBadRequest, "dashboardsnapshots.bad-request")
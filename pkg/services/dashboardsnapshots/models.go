@@ -79,6 +79,42 @@ type CreateDashboardSnapshotCommand struct {
 	DashboardEncrypted []byte `json:"-"`
 }
 
+// swagger:model
+type CaptureDashboardSnapshotCommand struct {
+	// UID of the dashboard to capture.
+	// required:true
+	DashboardUID string `json:"dashboardUid" binding:"Required"`
+	// Snapshot name. Defaults to the title of the dashboard.
+	// required:false
+	Name string `json:"name"`
+	// When the snapshot should expire in seconds. Default is never to expire.
+	// required:false
+	// default:0
+	Expires int64 `json:"expires"`
+	// Start of the time range of the snapshot, for example `now-6h` or a timestamp in epoch milliseconds. Defaults to the time range of the dashboard.
+	// required:false
+	From string `json:"from"`
+	// End of the time range of the snapshot, for example `now` or a timestamp in epoch milliseconds. Defaults to the time range of the dashboard.
+	// required:false
+	To string `json:"to"`
+	// Values of template variables by the name of the variable. Variables that are not set keep the value saved in the dashboard.
+	// required:false
+	Variables map[string][]string `json:"variables"`
+
+	Key       string `json:"-"`
+	DeleteKey string `json:"-"`
+
+	OrgID        int64              `json:"-"`
+	SignedInUser *user.SignedInUser `json:"-"`
+}
+
+// CaptureDashboardSnapshotResult is the snapshot captured from a dashboard.
+type CaptureDashboardSnapshotResult struct {
+	Snapshot *DashboardSnapshot
+	// Errors of the panels whose queries failed by panel ID. The snapshot shows no data for these panels.
+	PanelErrors map[int64]string
+}
+
 type DeleteDashboardSnapshotCommand struct {
 	DeleteKey string `json:"-"`
 }
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/components/templating"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+)
+
+const (
+	// defaultMaxDataPoints is used for panels that do not define the maximum number of data points. The frontend uses
+	// the width of the panel instead, which is not known on the server.
+	defaultMaxDataPoints = 1000
+
+	mixedDataSourceUID     = "-- Mixed --"
+	dashboardDataSourceUID = "-- Dashboard --"
+)
+
+// captureTimeRange is the absolute time range a snapshot is captured for.
+type captureTimeRange struct {
+	from time.Time
+	to   time.Time
+}
+
+// CaptureDashboardSnapshot runs the queries of all panels of the dashboard with the permissions of the user and stores
+// the results in a new snapshot, the same way the frontend creates snapshots. Panels whose queries fail are stored
+// without data and their errors are returned in the result.
+func (s *ServiceImpl) CaptureDashboardSnapshot(ctx context.Context, cmd *dashboardsnapshots.CaptureDashboardSnapshotCommand) (*dashboardsnapshots.CaptureDashboardSnapshotResult, error) {
+	dash, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: cmd.DashboardUID, OrgID: cmd.OrgID})
+	if err != nil {
+		return nil, err
+	}
+	model := dash.Data
+
+	tr, err := resolveTimeRange(model, cmd.From, cmd.To)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := templating.FromDashboard(model).Merge(cmd.Variables)
+	vars = vars.Merge(templating.Variables{
+		"__from": {strconv.FormatInt(tr.from.UnixMilli(), 10)},
+		"__to":   {strconv.FormatInt(tr.to.UnixMilli(), 10)},
+	})
+
+	panelErrors := make(map[int64]string)
+	s.capturePanels(ctx, cmd, model.Get("panels").MustArray(), tr, vars, panelErrors)
+	scrubDashboard(model, tr, cmd.Variables)
+
+	name := cmd.Name
+	if name == "" {
+		name = dash.Title
+	}
+	model.Set("title", name)
+	model.Set("snapshot", map[string]interface{}{
+		"timestamp":   time.Now().UTC().Format(time.RFC3339),
+		"originalUrl": fmt.Sprintf("/d/%s", dash.UID),
+	})
+
+	snapshot, err := s.CreateDashboardSnapshot(ctx, &dashboardsnapshots.CreateDashboardSnapshotCommand{
+		Dashboard: model,
+		Name:      name,
+		Expires:   cmd.Expires,
+		Key:       cmd.Key,
+		DeleteKey: cmd.DeleteKey,
+		OrgID:     cmd.OrgID,
+		UserID:    cmd.SignedInUser.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dashboardsnapshots.CaptureDashboardSnapshotResult{Snapshot: snapshot, PanelErrors: panelErrors}, nil
+}
+
+// resolveTimeRange returns the absolute time range of the snapshot. The time range of the dashboard is used if from
+// or to are not set. Relative times are resolved in the timezone of the dashboard.
+func resolveTimeRange(model *simplejson.Json, from, to string) (captureTimeRange, error) {
+	if from == "" {
+		from = model.GetPath("time", "from").MustString("now-6h")
+	}
+	if to == "" {
+		to = model.GetPath("time", "to").MustString("now")
+	}
+	location, err := time.LoadLocation(model.Get("timezone").MustString())
+	if err != nil {
+		location = time.UTC
+	}
+
+	timeRange := legacydata.NewDataTimeRange(from, to)
+	fromTime, err := timeRange.ParseFrom(legacydata.WithLocation(location))
+	if err != nil {
+		return captureTimeRange{}, dashboardsnapshots.ErrBaseBadRequest.Errorf("invalid from %q: %w", from, err)
+	}
+	toTime, err := timeRange.ParseTo(legacydata.WithLocation(location))
+	if err != nil {
+		return captureTimeRange{}, dashboardsnapshots.ErrBaseBadRequest.Errorf("invalid to %q: %w", to, err)
+	}
+	if !fromTime.Before(toTime) {
+		return captureTimeRange{}, dashboardsnapshots.ErrBaseBadRequest.Errorf("from %q must be before to %q", from, to)
+	}
+	return captureTimeRange{from: fromTime, to: toTime}, nil
+}
+
+// capturePanels stores the results of the queries of the panels in their snapshotData, including the panels in
+// collapsed rows.
+func (s *ServiceImpl) capturePanels(ctx context.Context, cmd *dashboardsnapshots.CaptureDashboardSnapshotCommand, panels []interface{}, tr captureTimeRange, vars templating.Variables, panelErrors map[int64]string) {
+	for _, panelObj := range panels {
+		panel := simplejson.NewFromAny(panelObj)
+		if panel.Get("type").MustString() == "row" {
+			s.capturePanels(ctx, cmd, panel.Get("panels").MustArray(), tr, vars, panelErrors)
+			continue
+		}
+		if len(panel.Get("targets").MustArray()) == 0 {
+			continue
+		}
+
+		frames, err := s.queryPanel(ctx, cmd, panel, tr, vars)
+		if err != nil {
+			s.log.Warn("Failed to query panel of snapshot", "dashboardUID", cmd.DashboardUID, "panelID", panel.Get("id").MustInt64(), "error", err)
+			panelErrors[panel.Get("id").MustInt64()] = err.Error()
+		}
+		panel.Set("snapshotData", frames)
+	}
+}
+
+// queryPanel runs the queries of the panel and returns the resulting data frames in their JSON representation. The
+// frames of successful queries are returned even if other queries of the panel fail.
+func (s *ServiceImpl) queryPanel(ctx context.Context, cmd *dashboardsnapshots.CaptureDashboardSnapshotCommand, panel *simplejson.Json, tr captureTimeRange, vars templating.Variables) ([]interface{}, error) {
+	frames := make([]interface{}, 0)
+	panelDataSource := panel.Get("datasource")
+	panelDataSourceUID := dataSourceUID(panelDataSource)
+	if panelDataSourceUID == dashboardDataSourceUID {
+		return frames, fmt.Errorf("panels that reuse the results of other panels are not supported")
+	}
+
+	maxDataPoints := panel.Get("maxDataPoints").MustInt64(defaultMaxDataPoints)
+	minInterval, err := intervalv2.GetIntervalFrom(vars.Interpolate(panel.Get("interval").MustString()), "", 0, time.Millisecond)
+	if err != nil {
+		return frames, err
+	}
+	interval := intervalv2.NewCalculator().Calculate(backend.TimeRange{From: tr.from, To: tr.to}, minInterval, maxDataPoints)
+	vars = vars.Merge(templating.Variables{
+		"__interval":    {interval.Text},
+		"__interval_ms": {strconv.FormatInt(interval.Milliseconds(), 10)},
+	})
+
+	targets := panel.Get("targets").MustArray()
+	hasExpression := false
+	for _, targetObj := range targets {
+		if expr.NodeTypeFromDatasourceUID(dataSourceUID(simplejson.NewFromAny(targetObj).Get("datasource"))) == expr.TypeCMDNode {
+			hasExpression = true
+		}
+	}
+
+	queries := make([]*simplejson.Json, 0, len(targets))
+	for _, targetObj := range targets {
+		// copy the query, the interpolation of the variables modifies it in place
+		b, err := simplejson.NewFromAny(targetObj).Encode()
+		if err != nil {
+			return frames, err
+		}
+		query, err := simplejson.NewJson(b)
+		if err != nil {
+			return frames, err
+		}
+		// hidden queries are still needed if expressions depend on them, the expressions remove them from the results
+		if !hasExpression && query.Get("hide").MustBool() {
+			continue
+		}
+		if _, ok := query.CheckGet("datasource"); !ok || dataSourceUID(query.Get("datasource")) == "" {
+			if panelDataSourceUID == "" || panelDataSourceUID == mixedDataSourceUID {
+				return frames, fmt.Errorf("query %s has no data source", query.Get("refId").MustString())
+			}
+			query.Set("datasource", panelDataSource.Interface())
+		}
+		vars.InterpolateJSON(query)
+		query.Set("intervalMs", interval.Milliseconds())
+		query.Set("maxDataPoints", maxDataPoints)
+		queries = append(queries, query)
+	}
+	if len(queries) == 0 {
+		return frames, nil
+	}
+
+	resp, err := s.queryService.QueryData(ctx, cmd.SignedInUser, false, dtos.MetricRequest{
+		From:    strconv.FormatInt(tr.from.UnixMilli(), 10),
+		To:      strconv.FormatInt(tr.to.UnixMilli(), 10),
+		Queries: queries,
+	})
+	if err != nil {
+		return frames, err
+	}
+
+	var queryErr error
+	// keep the order of the queries of the panel
+	for _, query := range queries {
+		refID := query.Get("refId").MustString()
+		res, ok := resp.Responses[refID]
+		if !ok {
+			continue
+		}
+		if res.Error != nil && queryErr == nil {
+			queryErr = fmt.Errorf("query %s failed: %w", refID, res.Error)
+		}
+		for _, frame := range res.Frames {
+			frame.RefID = refID
+			b, err := json.Marshal(frame)
+			if err != nil {
+				return frames, err
+			}
+			var frameJSON interface{}
+			if err := json.Unmarshal(b, &frameJSON); err != nil {
+				return frames, err
+			}
+			frames = append(frames, frameJSON)
+		}
+	}
+	return frames, queryErr
+}
+
+func dataSourceUID(datasource *simplejson.Json) string {
+	uid := datasource.Get("uid").MustString()
+	// before 8.3 the data source was referenced by its name
+	if uid == "" {
+		uid = datasource.MustString()
+	}
+	return uid
+}
+
+// scrubDashboard removes the queries, links and data sources from the dashboard model, so that the snapshot does not
+// expose more than the captured data. The variables keep the values the snapshot is captured for.
+func scrubDashboard(model *simplejson.Json, tr captureTimeRange, overrides templating.Variables) {
+	model.Set("time", map[string]interface{}{
+		"from": tr.from.UTC().Format(time.RFC3339Nano),
+		"to":   tr.to.UTC().Format(time.RFC3339Nano),
+		"raw": map[string]interface{}{
+			"from": tr.from.UTC().Format(time.RFC3339Nano),
+			"to":   tr.to.UTC().Format(time.RFC3339Nano),
+		},
+	})
+	model.Set("links", []interface{}{})
+	scrubPanels(model.Get("panels").MustArray())
+
+	annotations := make([]interface{}, 0)
+	for _, annotationObj := range model.GetPath("annotations", "list").MustArray() {
+		annotation := simplejson.NewFromAny(annotationObj)
+		if !annotation.Get("enable").MustBool() {
+			continue
+		}
+		annotations = append(annotations, map[string]interface{}{
+			"name":      annotation.Get("name").Interface(),
+			"enable":    true,
+			"iconColor": annotation.Get("iconColor").Interface(),
+			"type":      annotation.Get("type").Interface(),
+			"builtIn":   annotation.Get("builtIn").Interface(),
+			"hide":      annotation.Get("hide").Interface(),
+		})
+	}
+	model.SetPath([]string{"annotations", "list"}, annotations)
+
+	for _, variableObj := range model.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(variableObj)
+		if values, ok := overrides[variable.Get("name").MustString()]; ok {
+			var value interface{} = values
+			if len(values) == 1 {
+				value = values[0]
+			}
+			variable.Set("current", map[string]interface{}{"text": value, "value": value})
+		}
+		if _, ok := variable.CheckGet("query"); ok {
+			variable.Set("query", "")
+		}
+		current := variable.Get("current")
+		if len(current.MustMap()) > 0 {
+			variable.Set("options", []interface{}{current.Interface()})
+		} else {
+			variable.Set("options", []interface{}{})
+		}
+		if _, ok := variable.CheckGet("refresh"); ok {
+			// never refresh
+			variable.Set("refresh", 0)
+		}
+	}
+}
+
+func scrubPanels(panels []interface{}) {
+	for _, panelObj := range panels {
+		panel := simplejson.NewFromAny(panelObj)
+		if panel.Get("type").MustString() == "row" {
+			scrubPanels(panel.Get("panels").MustArray())
+		}
+		panel.Set("targets", []interface{}{})
+		panel.Set("links", []interface{}{})
+		panel.Set("datasource", nil)
+	}
+}
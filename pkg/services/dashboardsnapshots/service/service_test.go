@@ -2,16 +2,24 @@ package service
 
 import (
 	"context"
+	"errors"
 	"testing"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	dashsnapdb "github.com/grafana/grafana/pkg/services/dashboardsnapshots/database"
+	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/services/secrets/database"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -19,7 +27,7 @@ func TestDashboardSnapshotsService(t *testing.T) {
 	sqlStore := db.InitTestDB(t)
 	dsStore := dashsnapdb.ProvideStore(sqlStore, setting.NewCfg())
 	secretsService := secretsManager.SetupTestService(t, database.ProvideSecretsStore(sqlStore))
-	s := ProvideService(dsStore, secretsService)
+	s := ProvideService(dsStore, secretsService, dashboards.NewFakeDashboardService(t), query.NewFakeQueryService(t))
 
 	origSecret := setting.SecretKey
 	setting.SecretKey = "dashboard_snapshot_service_test"
@@ -68,3 +76,101 @@ func TestDashboardSnapshotsService(t *testing.T) {
 		require.Equal(t, rawDashboard, decrypted)
 	})
 }
+
+func TestCaptureDashboardSnapshot(t *testing.T) {
+	sqlStore := db.InitTestDB(t)
+	dsStore := dashsnapdb.ProvideStore(sqlStore, setting.NewCfg())
+	secretsService := secretsManager.SetupTestService(t, database.ProvideSecretsStore(sqlStore))
+
+	newDashboard := func(t *testing.T) *dashboards.Dashboard {
+		model, err := simplejson.NewJson([]byte(`{
+			"uid": "dash",
+			"title": "Dashboard",
+			"time": {"from": "now-1h", "to": "now"},
+			"templating": {"list": [
+				{"name": "job", "type": "custom", "query": "api,web", "current": {"text": "api", "value": "api"}}
+			]},
+			"panels": [
+				{"id": 1, "type": "timeseries", "datasource": {"uid": "prom"}, "targets": [
+					{"refId": "A", "expr": "up{job=\"$job\"}"},
+					{"refId": "B", "expr": "hidden", "hide": true}
+				]},
+				{"id": 2, "type": "row", "collapsed": true, "panels": [
+					{"id": 3, "type": "stat", "datasource": {"uid": "prom"}, "targets": [{"refId": "A", "expr": "failing"}]}
+				]}
+			]
+		}`))
+		require.NoError(t, err)
+		return &dashboards.Dashboard{UID: "dash", OrgID: 1, Title: "Dashboard", Data: model}
+	}
+
+	t.Run("should store the results of the queries in the snapshot", func(t *testing.T) {
+		ctx := context.Background()
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		dashboardService.On("GetDashboard", mock.Anything, &dashboards.GetDashboardQuery{UID: "dash", OrgID: 1}).Return(newDashboard(t), nil)
+		queryService := query.NewFakeQueryService(t)
+		queryService.On("QueryData", mock.Anything, mock.Anything, false, mock.Anything).Return(func(_ context.Context, _ *user.SignedInUser, _ bool, req dtos.MetricRequest) *backend.QueryDataResponse {
+			resp := backend.NewQueryDataResponse()
+			for _, q := range req.Queries {
+				refID := q.Get("refId").MustString()
+				if q.Get("expr").MustString() == "failing" {
+					resp.Responses[refID] = backend.DataResponse{Error: errors.New("query failed")}
+					continue
+				}
+				resp.Responses[refID] = backend.DataResponse{Frames: data.Frames{
+					data.NewFrame(q.Get("expr").MustString(), data.NewField("value", nil, []float64{1})),
+				}}
+			}
+			return resp
+		}, nil)
+		s := ProvideService(dsStore, secretsService, dashboardService, queryService)
+
+		result, err := s.CaptureDashboardSnapshot(ctx, &dashboardsnapshots.CaptureDashboardSnapshotCommand{
+			DashboardUID: "dash",
+			Variables:    map[string][]string{"job": {"web"}},
+			Key:          "capture",
+			DeleteKey:    "capture-delete",
+			OrgID:        1,
+			SignedInUser: &user.SignedInUser{UserID: 2, OrgID: 1},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "Dashboard", result.Snapshot.Name)
+		require.Equal(t, map[int64]string{3: "query A failed: query failed"}, result.PanelErrors)
+
+		snapshot, err := s.GetDashboardSnapshot(ctx, &dashboardsnapshots.GetDashboardSnapshotQuery{Key: "capture"})
+		require.NoError(t, err)
+		model := snapshot.Dashboard
+		require.Equal(t, "/d/dash", model.GetPath("snapshot", "originalUrl").MustString())
+
+		panel := model.Get("panels").GetIndex(0)
+		require.Empty(t, panel.Get("targets").MustArray())
+		require.Nil(t, panel.Get("datasource").Interface())
+		frames := panel.Get("snapshotData").MustArray()
+		require.Len(t, frames, 1)
+		frame := simplejson.NewFromAny(frames[0])
+		require.Equal(t, `up{job="web"}`, frame.GetPath("schema", "name").MustString())
+		require.Equal(t, "A", frame.GetPath("schema", "refId").MustString())
+
+		rowPanel := model.Get("panels").GetIndex(1).Get("panels").GetIndex(0)
+		require.Empty(t, rowPanel.Get("snapshotData").MustArray())
+
+		variable := model.GetPath("templating", "list").GetIndex(0)
+		require.Equal(t, "", variable.Get("query").MustString())
+		require.Equal(t, "web", variable.GetPath("current", "value").MustString())
+	})
+
+	t.Run("should fail if the time range is invalid", func(t *testing.T) {
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(newDashboard(t), nil)
+		s := ProvideService(dsStore, secretsService, dashboardService, query.NewFakeQueryService(t))
+
+		_, err := s.CaptureDashboardSnapshot(context.Background(), &dashboardsnapshots.CaptureDashboardSnapshotCommand{
+			DashboardUID: "dash",
+			From:         "now",
+			To:           "now-1h",
+			OrgID:        1,
+			SignedInUser: &user.SignedInUser{UserID: 2, OrgID: 1},
+		})
+		require.ErrorIs(t, err, dashboardsnapshots.ErrBaseBadRequest)
+	})
+}
@@ -13,6 +13,29 @@ type MockService struct {
 	mock.Mock
 }
 
+// CaptureDashboardSnapshot provides a mock function with given fields: _a0, _a1
+func (_m *MockService) CaptureDashboardSnapshot(_a0 context.Context, _a1 *CaptureDashboardSnapshotCommand) (*CaptureDashboardSnapshotResult, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *CaptureDashboardSnapshotResult
+	if rf, ok := ret.Get(0).(func(context.Context, *CaptureDashboardSnapshotCommand) *CaptureDashboardSnapshotResult); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*CaptureDashboardSnapshotResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *CaptureDashboardSnapshotCommand) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateDashboardSnapshot provides a mock function with given fields: _a0, _a1
 func (_m *MockService) CreateDashboardSnapshot(_a0 context.Context, _a1 *CreateDashboardSnapshotCommand) (*DashboardSnapshot, error) {
 	ret := _m.Called(_a0, _a1)
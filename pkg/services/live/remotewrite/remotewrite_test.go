@@ -77,6 +77,26 @@ func TestTsFromFramesMultipleFrames(t *testing.T) {
 	require.Equal(t, 4.0, ts[1].Samples[1].Value)
 }
 
+func TestTsFromFramesWithMetricName(t *testing.T) {
+	t1 := time.Now()
+	frame1 := data.NewFrame("A",
+		data.NewField("time", nil, []time.Time{t1}),
+		data.NewField("value", map[string]string{"host": "a"}, []float64{1.0}),
+	)
+	frame2 := data.NewFrame("A",
+		data.NewField("time", nil, []time.Time{t1}),
+		data.NewField("value", map[string]string{"host": "b"}, []float64{2.0}),
+	)
+	ts := TimeSeriesFromFramesWithMetricName("cpu:usage", frame1, frame2)
+	require.Len(t, ts, 2)
+	for i, expected := range []float64{1.0, 2.0} {
+		require.Len(t, ts[i].Samples, 1)
+		require.Equal(t, expected, ts[i].Samples[0].Value)
+		require.Equal(t, "__name__", ts[i].Labels[1].Name)
+		require.Equal(t, "cpu:usage", ts[i].Labels[1].Value)
+	}
+}
+
 func TestSerialize(t *testing.T) {
 	frame := data.NewFrame("test",
 		data.NewField("time", nil, []time.Time{time.Now(), time.Now().Add(time.Second)}),
@@ -5,6 +5,8 @@ import (
 	"net/url"
 	"time"
 
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -44,6 +46,12 @@ type Alertmanager interface {
 	DeleteSilence(silenceID string) error
 	GetSilence(silenceID string) (apimodels.GettableSilence, error)
 	ListSilences(filter []string) (apimodels.GettableSilences, error)
+	CreateSilences(silences []apimodels.PostableSilence) ([]string, error)
+	ExpireSilences(matchers amv2.Matchers) ([]string, error)
+	GetSilenceTemplates(ctx context.Context) ([]apimodels.SilenceTemplate, error)
+	SaveSilenceTemplate(ctx context.Context, template apimodels.SilenceTemplate) error
+	DeleteSilenceTemplate(ctx context.Context, name string) error
+	ApplySilenceTemplate(ctx context.Context, name string, createdBy string) (apimodels.GettableSilence, error)
 
 	// Alerts
 	GetAlerts(active, silenced, inhibited bool, filter []string, receiver string) (apimodels.GettableAlerts, error)
@@ -51,6 +59,7 @@ type Alertmanager interface {
 
 	// Receivers
 	GetReceivers(ctx context.Context) []apimodels.Receiver
+	GetReceiverNotifications(ctx context.Context, receiver string, limit int) ([]notifier.NotificationAttempt, error)
 	TestReceivers(ctx context.Context, c apimodels.TestReceiversConfigBodyParams) (*notifier.TestReceiversResult, error)
 	TestTemplate(ctx context.Context, c apimodels.TestTemplatesConfigBodyParams) (*notifier.TestTemplatesResults, error)
 }
@@ -103,13 +112,13 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 	api.RegisterAlertmanagerApiEndpoints(NewForkingAM(
 		api.DatasourceCache,
 		NewLotexAM(proxy, logger),
-		&AlertmanagerSrv{crypto: api.MultiOrgAlertmanager.Crypto, log: logger, ac: api.AccessControl, mam: api.MultiOrgAlertmanager},
+		&AlertmanagerSrv{crypto: api.MultiOrgAlertmanager.Crypto, log: logger, ac: api.AccessControl, mam: api.MultiOrgAlertmanager, stateManager: api.StateManager},
 	), m)
 	// Register endpoints for proxying to Prometheus-compatible backends.
 	api.RegisterPrometheusApiEndpoints(NewForkingProm(
 		api.DatasourceCache,
 		NewLotexProm(proxy, logger),
-		&PrometheusSrv{log: logger, manager: api.StateManager, store: api.RuleStore, ac: api.AccessControl},
+		&PrometheusSrv{log: logger, manager: api.StateManager, store: api.RuleStore, ac: api.AccessControl, baseInterval: api.Cfg.UnifiedAlerting.BaseInterval},
 	), m)
 	// Register endpoints for proxying to Cortex Ruler-compatible backends.
 	api.RegisterRulerApiEndpoints(NewForkingRuler(
@@ -117,6 +126,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		NewLotexRuler(proxy, logger),
 		&RulerSrv{
 			conditionValidator: api.EvaluatorFactory,
+			evaluator:          api.EvaluatorFactory,
 			QuotaService:       api.QuotaService,
 			store:              api.RuleStore,
 			provenanceStore:    api.ProvenanceStore,
@@ -137,6 +147,8 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			backtesting:     backtesting.NewEngine(api.AppUrl, api.EvaluatorFactory),
 			featureManager:  api.FeatureManager,
 			appUrl:          api.AppUrl,
+			amConfigStore:   api.AlertingStore,
+			ruleStore:       api.RuleStore,
 		}), m)
 	api.RegisterConfigurationApiEndpoints(NewConfiguration(
 		&ConfigSrv{
@@ -154,6 +166,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		templates:           api.Templates,
 		muteTimings:         api.MuteTimings,
 		alertRules:          api.AlertRules,
+		cfg:                 &api.Cfg.UnifiedAlerting,
 	}), m)
 
 	api.RegisterHistoryApiEndpoints(NewStateHistoryApi(&HistorySrv{
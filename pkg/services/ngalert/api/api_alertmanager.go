@@ -5,19 +5,26 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-openapi/strfmt"
 	alertingNotify "github.com/grafana/alerting/notify"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -28,10 +35,11 @@ const (
 )
 
 type AlertmanagerSrv struct {
-	log    log.Logger
-	ac     accesscontrol.AccessControl
-	mam    *notifier.MultiOrgAlertmanager
-	crypto notifier.Crypto
+	log          log.Logger
+	ac           accesscontrol.AccessControl
+	mam          *notifier.MultiOrgAlertmanager
+	crypto       notifier.Crypto
+	stateManager state.AlertInstanceManager
 }
 
 type UnknownReceiverError struct {
@@ -290,6 +298,245 @@ func (srv AlertmanagerSrv) RoutePostAlertingConfig(c *contextmodel.ReqContext, b
 	return ErrResp(http.StatusInternalServerError, err, "")
 }
 
+// RoutePreviewSilence returns the alerts in the Alertmanager and the alert rules with alert instances that a silence
+// with the given matchers and time range would mute. Alerts are returned if they are active at some point during the
+// silence. Alert instances only have a current state, so they are counted unless the silence has already ended.
+func (srv AlertmanagerSrv) RoutePreviewSilence(c *contextmodel.ReqContext, query apimodels.SilencePreviewQuery) response.Response {
+	if err := query.Validate(); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid silence")
+	}
+	matchers, err := toLabelsMatchers(query.Matchers)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid matchers")
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.OrgID)
+	if errResp != nil {
+		return errResp
+	}
+
+	alerts, err := am.GetAlerts(true, true, true, nil, "")
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alerts")
+	}
+	preview := apimodels.SilencePreview{
+		Alerts: apimodels.GettableAlerts{},
+		Rules:  []apimodels.SilencePreviewRule{},
+	}
+	startsAt, endsAt := time.Time(*query.StartsAt), time.Time(*query.EndsAt)
+	if !endsAt.After(time.Now()) {
+		return response.JSON(http.StatusOK, preview)
+	}
+	for _, alert := range alerts {
+		if matchesAll(matchers, alert.Labels) && overlaps(alert, startsAt, endsAt) {
+			preview.Alerts = append(preview.Alerts, alert)
+		}
+	}
+
+	rules := make(map[string]*apimodels.SilencePreviewRule)
+	for _, s := range srv.stateManager.GetAll(c.OrgID) {
+		if !matchesAll(matchers, s.Labels) {
+			continue
+		}
+		rule, ok := rules[s.AlertRuleUID]
+		if !ok {
+			rule = &apimodels.SilencePreviewRule{
+				UID:    s.AlertRuleUID,
+				Title:  s.Labels[model.AlertNameLabel],
+				Folder: s.Labels[ngmodels.FolderTitleLabel],
+			}
+			rules[s.AlertRuleUID] = rule
+		}
+		rule.Instances++
+		if s.State == eval.Alerting {
+			rule.Firing++
+		}
+	}
+	for _, rule := range rules {
+		preview.Rules = append(preview.Rules, *rule)
+	}
+	sort.Slice(preview.Rules, func(i, j int) bool {
+		if preview.Rules[i].Folder != preview.Rules[j].Folder {
+			return preview.Rules[i].Folder < preview.Rules[j].Folder
+		}
+		if preview.Rules[i].Title != preview.Rules[j].Title {
+			return preview.Rules[i].Title < preview.Rules[j].Title
+		}
+		return preview.Rules[i].UID < preview.Rules[j].UID
+	})
+
+	return response.JSON(http.StatusOK, preview)
+}
+
+// overlaps returns true if the alert is active at some point between startsAt and endsAt.
+func overlaps(alert *amv2.GettableAlert, startsAt, endsAt time.Time) bool {
+	if alert.StartsAt != nil && !time.Time(*alert.StartsAt).Before(endsAt) {
+		return false
+	}
+	if alert.EndsAt != nil && !time.Time(*alert.EndsAt).IsZero() && !time.Time(*alert.EndsAt).After(startsAt) {
+		return false
+	}
+	return true
+}
+
+func (srv AlertmanagerSrv) RouteCreateSilences(c *contextmodel.ReqContext, silences []apimodels.PostableSilence) response.Response {
+	if len(silences) == 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("no silences"), "")
+	}
+	action := accesscontrol.ActionAlertingInstanceCreate
+	for i, ps := range silences {
+		if err := ps.Validate(strfmt.Default); err != nil {
+			srv.log.Error("silence failed validation", "error", err)
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("silence %d: %w", i, err), "silence failed validation")
+		}
+		if ps.ID != "" {
+			action = accesscontrol.ActionAlertingInstanceUpdate
+		}
+	}
+	if !accesscontrol.HasAccess(srv.ac, c)(accesscontrol.EvalPermission(action)) {
+		errAction := "update"
+		if action == accesscontrol.ActionAlertingInstanceCreate {
+			errAction = "create"
+		}
+		return ErrResp(http.StatusUnauthorized, fmt.Errorf("user is not authorized to %s silences", errAction), "")
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.OrgID)
+	if errResp != nil {
+		return errResp
+	}
+
+	ids, err := am.CreateSilences(silences)
+	if err != nil {
+		if errors.Is(err, alertingNotify.ErrSilenceNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		if errors.Is(err, alertingNotify.ErrCreateSilenceBadPayload) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to create silences")
+	}
+	return response.JSON(http.StatusAccepted, apimodels.PostSilencesBulkOKBody{SilenceIDs: ids})
+}
+
+func (srv AlertmanagerSrv) RouteExpireSilences(c *contextmodel.ReqContext, query apimodels.ExpireSilencesQuery) response.Response {
+	if len(query.Matchers) == 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("at least one matcher is required"), "")
+	}
+	if err := query.Matchers.Validate(strfmt.Default); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid matchers")
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.OrgID)
+	if errResp != nil {
+		return errResp
+	}
+
+	ids, err := am.ExpireSilences(query.Matchers)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to expire silences")
+	}
+	return response.JSON(http.StatusOK, apimodels.PostSilencesBulkOKBody{SilenceIDs: ids})
+}
+
+func (srv AlertmanagerSrv) RouteGetSilenceTemplates(c *contextmodel.ReqContext) response.Response {
+	am, errResp := srv.AlertmanagerFor(c.OrgID)
+	if errResp != nil {
+		return errResp
+	}
+
+	templates, err := am.GetSilenceTemplates(c.Req.Context())
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, templates)
+}
+
+func (srv AlertmanagerSrv) RoutePostSilenceTemplate(c *contextmodel.ReqContext, template apimodels.SilenceTemplate) response.Response {
+	am, errResp := srv.AlertmanagerFor(c.OrgID)
+	if errResp != nil {
+		return errResp
+	}
+
+	if err := am.SaveSilenceTemplate(c.Req.Context(), template); err != nil {
+		if errors.Is(err, notifier.ErrSilenceTemplateInvalid) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to save silence template")
+	}
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "silence template saved"})
+}
+
+func (srv AlertmanagerSrv) RouteDeleteSilenceTemplate(c *contextmodel.ReqContext, name string) response.Response {
+	am, errResp := srv.AlertmanagerFor(c.OrgID)
+	if errResp != nil {
+		return errResp
+	}
+
+	if err := am.DeleteSilenceTemplate(c.Req.Context(), name); err != nil {
+		if errors.Is(err, notifier.ErrSilenceTemplateNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to delete silence template")
+	}
+	return response.JSON(http.StatusOK, util.DynMap{"message": "silence template deleted"})
+}
+
+func (srv AlertmanagerSrv) RouteApplySilenceTemplate(c *contextmodel.ReqContext, name string) response.Response {
+	am, errResp := srv.AlertmanagerFor(c.OrgID)
+	if errResp != nil {
+		return errResp
+	}
+
+	silence, err := am.ApplySilenceTemplate(c.Req.Context(), name, c.SignedInUser.Login)
+	if err != nil {
+		if errors.Is(err, notifier.ErrSilenceTemplateNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		if errors.Is(err, notifier.ErrSilenceTemplateInvalid) || errors.Is(err, alertingNotify.ErrCreateSilenceBadPayload) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to create silence from template")
+	}
+	return response.JSON(http.StatusAccepted, silence)
+}
+
+// toLabelsMatchers converts the matchers of a silence to label matchers.
+func toLabelsMatchers(matchers amv2.Matchers) (labels.Matchers, error) {
+	result := make(labels.Matchers, 0, len(matchers))
+	for _, m := range matchers {
+		if m.Name == nil || m.Value == nil {
+			return nil, errors.New("matchers must have a name and a value")
+		}
+		isEqual := m.IsEqual == nil || *m.IsEqual
+		isRegex := m.IsRegex != nil && *m.IsRegex
+		matchType := labels.MatchEqual
+		switch {
+		case isRegex && isEqual:
+			matchType = labels.MatchRegexp
+		case isRegex && !isEqual:
+			matchType = labels.MatchNotRegexp
+		case !isEqual:
+			matchType = labels.MatchNotEqual
+		}
+		matcher, err := labels.NewMatcher(matchType, *m.Name, *m.Value)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, matcher)
+	}
+	return result, nil
+}
+
+func matchesAll(matchers labels.Matchers, lbls map[string]string) bool {
+	for _, m := range matchers {
+		if !m.Matches(lbls[m.Name]) {
+			return false
+		}
+	}
+	return true
+}
+
 func (srv AlertmanagerSrv) RouteGetReceivers(c *contextmodel.ReqContext) response.Response {
 	am, errResp := srv.AlertmanagerFor(c.OrgID)
 	if errResp != nil {
@@ -300,6 +547,22 @@ func (srv AlertmanagerSrv) RouteGetReceivers(c *contextmodel.ReqContext) respons
 	return response.JSON(http.StatusOK, rcvs)
 }
 
+func (srv AlertmanagerSrv) RouteGetReceiverNotifications(c *contextmodel.ReqContext, receiver string) response.Response {
+	am, errResp := srv.AlertmanagerFor(c.OrgID)
+	if errResp != nil {
+		return errResp
+	}
+
+	attempts, err := am.GetReceiverNotifications(c.Req.Context(), receiver, c.QueryInt("limit"))
+	if err != nil {
+		if errors.Is(err, notifier.ErrReceiverNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, newNotificationAttempts(attempts))
+}
+
 func (srv AlertmanagerSrv) RoutePostTestReceivers(c *contextmodel.ReqContext, body apimodels.TestReceiversConfigBodyParams) response.Response {
 	if err := srv.crypto.ProcessSecureSettings(c.Req.Context(), c.OrgID, body.Receivers); err != nil {
 		var unknownReceiverError UnknownReceiverError
@@ -396,6 +659,31 @@ func newTestReceiversResult(r *notifier.TestReceiversResult) apimodels.TestRecei
 	return v
 }
 
+func newNotificationAttempts(attempts []notifier.NotificationAttempt) []apimodels.NotificationAttempt {
+	result := make([]apimodels.NotificationAttempt, 0, len(attempts))
+	for _, a := range attempts {
+		next := apimodels.NotificationAttempt{
+			Receiver:         a.Receiver,
+			Integration:      a.IntegrationName,
+			IntegrationUID:   a.IntegrationUID,
+			IntegrationIndex: a.IntegrationIndex,
+			GroupKey:         a.GroupKey,
+			FiringAlerts:     a.FiringAlerts,
+			ResolvedAlerts:   a.ResolvedAlerts,
+			Timestamp:        strfmt.DateTime(a.Timestamp),
+			Duration:         a.Duration.String(),
+			Retry:            a.Retry,
+			StatusCode:       a.StatusCode,
+			Payload:          a.Payload,
+		}
+		if a.Error != nil {
+			next.Error = a.Error.Error()
+		}
+		result = append(result, next)
+	}
+	return result
+}
+
 // statusForTestReceivers returns the appropriate status code for the response
 // for the results.
 //
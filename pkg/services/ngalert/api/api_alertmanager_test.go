@@ -20,10 +20,12 @@ import (
 	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/secrets/fakes"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
@@ -493,6 +495,144 @@ func TestRoutePostTestTemplates(t *testing.T) {
 	})
 }
 
+func TestRouteGetReceiverNotifications(t *testing.T) {
+	sut := createSut(t)
+
+	t.Run("assert 404 when no alertmanager found", func(tt *testing.T) {
+		rc := createRequestCtxInOrg(10)
+
+		response := sut.RouteGetReceiverNotifications(rc, "grafana-default-email")
+		require.Equal(tt, 404, response.Status())
+	})
+
+	t.Run("assert 404 when receiver does not exist", func(tt *testing.T) {
+		rc := createRequestCtxInOrg(1)
+
+		response := sut.RouteGetReceiverNotifications(rc, "unknown")
+		require.Equal(tt, 404, response.Status())
+	})
+
+	t.Run("assert 200 for an existing receiver", func(tt *testing.T) {
+		rc := createRequestCtxInOrg(1)
+
+		response := sut.RouteGetReceiverNotifications(rc, "grafana-default-email")
+		require.Equal(tt, 200, response.Status())
+		require.JSONEq(tt, "[]", string(response.Body()))
+	})
+}
+
+func TestRoutePreviewSilence(t *testing.T) {
+	sut := createSut(t)
+	fakeAIM := NewFakeAlertInstanceManager(t)
+	sut.stateManager = fakeAIM
+
+	fakeAIM.GenerateAlertInstances(1, "firing-rule", 2, func(s *state.State) *state.State {
+		s.State = eval.Alerting
+		s.Labels[ngmodels.FolderTitleLabel] = "folder"
+		return s
+	})
+	fakeAIM.GenerateAlertInstances(1, "normal-rule", 1, func(s *state.State) *state.State {
+		s.Labels[ngmodels.FolderTitleLabel] = "folder"
+		return s
+	})
+	fakeAIM.GenerateAlertInstances(1, "other-rule", 1, func(s *state.State) *state.State {
+		s.Labels["label"] = "other"
+		return s
+	})
+
+	now := strfmt.DateTime(time.Now())
+	later := strfmt.DateTime(time.Now().Add(time.Hour))
+	query := func(name, value string) apimodels.SilencePreviewQuery {
+		return apimodels.SilencePreviewQuery{
+			Matchers: amv2.Matchers{{Name: util.Pointer(name), Value: util.Pointer(value), IsRegex: util.Pointer(false)}},
+			StartsAt: &now,
+			EndsAt:   &later,
+		}
+	}
+
+	t.Run("assert 400 when time range is invalid", func(t *testing.T) {
+		q := query("label", "test")
+		q.EndsAt = &now
+		q.StartsAt = &later
+
+		response := sut.RoutePreviewSilence(createRequestCtxInOrg(1), q)
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+
+	t.Run("assert 404 when no alertmanager found", func(t *testing.T) {
+		response := sut.RoutePreviewSilence(createRequestCtxInOrg(10), query("label", "test"))
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("assert alert rules with matching instances are returned", func(t *testing.T) {
+		response := sut.RoutePreviewSilence(createRequestCtxInOrg(1), query("label", "test"))
+		require.Equal(t, http.StatusOK, response.Status())
+
+		var preview apimodels.SilencePreview
+		require.NoError(t, json.Unmarshal(response.Body(), &preview))
+		require.Empty(t, preview.Alerts)
+		require.Equal(t, []apimodels.SilencePreviewRule{
+			{UID: "firing-rule", Title: "test_title_0", Folder: "folder", Instances: 2, Firing: 2},
+			{UID: "normal-rule", Title: "test_title_0", Folder: "folder", Instances: 1, Firing: 0},
+		}, preview.Rules)
+	})
+
+	t.Run("assert nothing is returned when the silence has ended", func(t *testing.T) {
+		q := query("label", "test")
+		earlier := strfmt.DateTime(time.Now().Add(-2 * time.Hour))
+		ended := strfmt.DateTime(time.Now().Add(-time.Hour))
+		q.StartsAt, q.EndsAt = &earlier, &ended
+
+		response := sut.RoutePreviewSilence(createRequestCtxInOrg(1), q)
+		require.Equal(t, http.StatusOK, response.Status())
+		require.JSONEq(t, `{"alerts": [], "rules": []}`, string(response.Body()))
+	})
+}
+
+func TestOverlaps(t *testing.T) {
+	now := time.Now()
+	alert := func(startsAt, endsAt time.Time) *amv2.GettableAlert {
+		s, e := strfmt.DateTime(startsAt), strfmt.DateTime(endsAt)
+		return &amv2.GettableAlert{StartsAt: &s, EndsAt: &e}
+	}
+
+	require.True(t, overlaps(alert(now.Add(-time.Hour), now.Add(time.Minute)), now, now.Add(time.Hour)))
+	require.True(t, overlaps(alert(now.Add(30*time.Minute), now.Add(2*time.Hour)), now, now.Add(time.Hour)))
+	require.False(t, overlaps(alert(now.Add(-time.Hour), now.Add(-time.Minute)), now, now.Add(time.Hour)))
+	require.False(t, overlaps(alert(now.Add(-time.Hour), now.Add(time.Minute)), now.Add(time.Hour), now.Add(2*time.Hour)))
+}
+
+func TestRouteSilenceTemplates(t *testing.T) {
+	sut := createSut(t)
+
+	template := apimodels.SilenceTemplate{
+		Name:       "maintenance",
+		Matchers:   amv2.Matchers{{Name: util.Pointer("cluster"), Value: util.Pointer("prod"), IsRegex: util.Pointer(false)}},
+		MuteTiming: "weekends",
+	}
+
+	t.Run("assert 400 when mute timing does not exist", func(t *testing.T) {
+		response := sut.RoutePostSilenceTemplate(createRequestCtxInOrg(1), template)
+		require.Equal(t, http.StatusBadRequest, response.Status())
+
+		response = sut.RouteGetSilenceTemplates(createRequestCtxInOrg(1))
+		require.Equal(t, http.StatusOK, response.Status())
+		require.JSONEq(t, "[]", string(response.Body()))
+	})
+
+	t.Run("assert 404 when deleting a template that does not exist", func(t *testing.T) {
+		response := sut.RouteDeleteSilenceTemplate(createRequestCtxInOrg(1), "maintenance")
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("assert 404 when applying a template that does not exist", func(t *testing.T) {
+		rc := createRequestCtxInOrg(1)
+		rc.SignedInUser.Login = "admin"
+		response := sut.RouteApplySilenceTemplate(rc, "maintenance")
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+}
+
 func TestSilenceCreate(t *testing.T) {
 	makeSilence := func(comment string, createdBy string,
 		startsAt, endsAt strfmt.DateTime, matchers amv2.Matchers) amv2.Silence {
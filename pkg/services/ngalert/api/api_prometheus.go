@@ -30,6 +30,8 @@ type PrometheusSrv struct {
 	manager state.AlertInstanceManager
 	store   RuleStore
 	ac      accesscontrol.AccessControl
+	// baseInterval is the interval of the scheduler that is used to calculate the evaluation offsets of rules.
+	baseInterval time.Duration
 }
 
 const queryIncludeInternalLabels = "includeInternalLabels"
@@ -232,6 +234,10 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *contextmodel.ReqContext) respon
 		ngmodels.AlertRulesBy(ngmodels.AlertRulesByIndex).Sort(groupRules)
 	}
 
+	// the rules that query the state of rules in folders the user cannot see are not known here, the offsets of the
+	// rules they query can differ from the offsets at which the rules are evaluated
+	offsets := ngmodels.EvaluationOffsets(ruleList, srv.baseInterval)
+
 	rulesTotals := make(map[string]int64, len(groupedRules))
 	for groupKey, rules := range groupedRules {
 		folder := namespaceMap[groupKey.NamespaceUID]
@@ -242,7 +248,7 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *contextmodel.ReqContext) respon
 		if !authorizeAccessToRuleGroup(rules, hasAccess) {
 			continue
 		}
-		ruleGroup, totals := srv.toRuleGroup(groupKey, folder, rules, limitAlertsPerRule, withStatesFast, matchers, labelOptions)
+		ruleGroup, totals := srv.toRuleGroup(groupKey, folder, rules, offsets, limitAlertsPerRule, withStatesFast, matchers, labelOptions)
 		ruleGroup.Totals = totals
 		for k, v := range totals {
 			rulesTotals[k] += v
@@ -300,7 +306,7 @@ func matchersMatch(matchers []*labels.Matcher, labels map[string]string) bool {
 	return true
 }
 
-func (srv PrometheusSrv) toRuleGroup(groupKey ngmodels.AlertRuleGroupKey, folder *folder.Folder, rules []*ngmodels.AlertRule, limitAlerts int64, withStates map[eval.State]struct{}, matchers labels.Matchers, labelOptions []ngmodels.LabelOption) (*apimodels.RuleGroup, map[string]int64) {
+func (srv PrometheusSrv) toRuleGroup(groupKey ngmodels.AlertRuleGroupKey, folder *folder.Folder, rules []*ngmodels.AlertRule, offsets map[ngmodels.AlertRuleKey]time.Duration, limitAlerts int64, withStates map[eval.State]struct{}, matchers labels.Matchers, labelOptions []ngmodels.LabelOption) (*apimodels.RuleGroup, map[string]int64) {
 	newGroup := &apimodels.RuleGroup{
 		Name: groupKey.RuleGroup,
 		// file is what Prometheus uses for provisioning, we replace it with namespace which is the folder in Grafana.
@@ -326,6 +332,10 @@ func (srv PrometheusSrv) toRuleGroup(groupKey ngmodels.AlertRuleGroupKey, folder
 			Type:           apiv1.RuleTypeAlerting,
 			LastEvaluation: time.Time{},
 		}
+		if rule.Type() == ngmodels.RuleTypeRecording {
+			newRule.Type = apiv1.RuleTypeRecording
+		}
+		newRule.EvaluationOffset = offsets[rule.GetKey()].Seconds()
 
 		states := srv.manager.GetStatesForRuleUID(rule.OrgID, rule.UID)
 		totals := make(map[string]int64)
@@ -379,6 +389,9 @@ func (srv PrometheusSrv) toRuleGroup(groupKey ngmodels.AlertRuleGroupKey, folder
 			if alertState.Error != nil {
 				newRule.LastError = alertState.Error.Error()
 				newRule.Health = "error"
+			} else if alertState.StateReason == ngmodels.StateReasonInstanceLimitExceeded {
+				newRule.LastError = "the rule exceeds the limit of alert instances, excess alert instances are dropped"
+				newRule.Health = "error"
 			}
 
 			if len(withStates) > 0 {
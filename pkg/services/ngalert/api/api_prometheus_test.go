@@ -632,6 +632,39 @@ func TestRouteGetRuleStatuses(t *testing.T) {
 		require.Len(t, r3.Alerts, 1)
 	})
 
+	t.Run("should return evaluation offset of rules", func(t *testing.T) {
+		fakeStore, _, api := setupAPI(t)
+		api.baseInterval = 10 * time.Second
+		rules := ngmodels.GenerateAlertRules(10, ngmodels.AlertRuleGen(withOrgID(orgID), withGroup("Rule-Group-1"), ngmodels.WithInterval(time.Hour)))
+		fakeStore.PutRule(context.Background(), rules...)
+
+		r, err := http.NewRequest("GET", "/api/v1/rules", nil)
+		require.NoError(t, err)
+		c := &contextmodel.ReqContext{
+			Context: &web.Context{Req: r},
+			SignedInUser: &user.SignedInUser{
+				OrgID:       orgID,
+				Permissions: queryPermissions,
+			},
+		}
+		resp := api.RouteGetRuleStatuses(c)
+		require.Equal(t, http.StatusOK, resp.Status())
+		var res apimodels.RuleResponse
+		require.NoError(t, json.Unmarshal(resp.Body(), &res))
+
+		expected := make(map[string]float64, len(rules))
+		for _, rule := range rules {
+			expected[rule.Title] = rule.EvaluationOffset(api.baseInterval).Seconds()
+		}
+		actual := make(map[string]float64, len(rules))
+		for _, group := range res.Data.RuleGroups {
+			for _, rule := range group.Rules {
+				actual[rule.Name] = rule.EvaluationOffset
+			}
+		}
+		require.Equal(t, expected, actual)
+	})
+
 	t.Run("test time of first firing alert", func(t *testing.T) {
 		fakeStore, fakeAIM, api := setupAPI(t)
 		// Create rules in the same Rule Group to keep assertions simple
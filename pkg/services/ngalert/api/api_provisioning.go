@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 
@@ -12,9 +13,11 @@ import (
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	alerting_models "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -27,6 +30,7 @@ type ProvisioningSrv struct {
 	templates           TemplateService
 	muteTimings         MuteTimingService
 	alertRules          AlertRuleService
+	cfg                 *setting.UnifiedAlertingSettings
 }
 
 type ContactPointService interface {
@@ -63,6 +67,8 @@ type AlertRuleService interface {
 	DeleteAlertRule(ctx context.Context, orgID int64, ruleUID string, provenance alerting_models.Provenance) error
 	GetRuleGroup(ctx context.Context, orgID int64, folder, group string) (alerting_models.AlertRuleGroup, error)
 	ReplaceRuleGroup(ctx context.Context, orgID int64, group alerting_models.AlertRuleGroup, userID int64, provenance alerting_models.Provenance) error
+	ReplaceRuleGroups(ctx context.Context, orgID int64, groups []alerting_models.AlertRuleGroup, userID int64, provenance alerting_models.Provenance) error
+	CalculateRuleGroupChanges(ctx context.Context, orgID int64, group alerting_models.AlertRuleGroup, provenance alerting_models.Provenance) (*store.GroupDelta, error)
 	GetAlertRuleWithFolderTitle(ctx context.Context, orgID int64, ruleUID string) (provisioning.AlertRuleWithFolderTitle, error)
 	GetAlertRuleGroupWithFolderTitle(ctx context.Context, orgID int64, folder, group string) (alerting_models.AlertRuleGroupWithFolderTitle, error)
 	GetAlertGroupsWithFolderTitle(ctx context.Context, orgID int64) ([]alerting_models.AlertRuleGroupWithFolderTitle, error)
@@ -206,6 +212,14 @@ func (srv *ProvisioningSrv) RouteGetTemplates(c *contextmodel.ReqContext) respon
 	return response.JSON(http.StatusOK, result)
 }
 
+func (srv *ProvisioningSrv) RouteGetTemplatesExport(c *contextmodel.ReqContext) response.Response {
+	templates, err := srv.templates.GetTemplates(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return exportResponse(c, AlertingFileExportFromTemplates(c.OrgID, templates))
+}
+
 func (srv *ProvisioningSrv) RouteGetTemplate(c *contextmodel.ReqContext, name string) response.Response {
 	templates, err := srv.templates.GetTemplates(c.Req.Context(), c.OrgID)
 	if err != nil {
@@ -262,6 +276,14 @@ func (srv *ProvisioningSrv) RouteGetMuteTimings(c *contextmodel.ReqContext) resp
 	return response.JSON(http.StatusOK, timings)
 }
 
+func (srv *ProvisioningSrv) RouteGetMuteTimingsExport(c *contextmodel.ReqContext) response.Response {
+	timings, err := srv.muteTimings.GetMuteTimings(c.Req.Context(), c.OrgID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return exportResponse(c, AlertingFileExportFromMuteTimings(c.OrgID, timings))
+}
+
 func (srv *ProvisioningSrv) RoutePostMuteTiming(c *contextmodel.ReqContext, mt definitions.MuteTimeInterval) response.Response {
 	mt.Provenance = determineProvenance(c)
 	created, err := srv.muteTimings.CreateMuteTiming(c.Req.Context(), mt, c.OrgID)
@@ -469,6 +491,98 @@ func (srv *ProvisioningSrv) RoutePutAlertRuleGroup(c *contextmodel.ReqContext, a
 	return response.JSON(http.StatusOK, ag)
 }
 
+// RoutePostImportPrometheusRuleGroups converts the rule groups of the Prometheus rule file in the request body to
+// Grafana managed alert rules and replaces the groups with the same names in the folder. All groups are saved in a
+// single transaction. If the query parameter "dryRun" is true, the changes are returned but not saved.
+func (srv *ProvisioningSrv) RoutePostImportPrometheusRuleGroups(c *contextmodel.ReqContext, folderUID string) response.Response {
+	datasourceUID := c.Query("datasourceUid")
+	if datasourceUID == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("parameter 'datasourceUid' is required"), "")
+	}
+	dryRun := c.QueryBoolWithDefault("dryRun", false)
+
+	body, err := io.ReadAll(c.Req.Body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to read the rule file")
+	}
+	promGroups, err := prom.ParseRuleGroups(body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	groups, warnings, err := prom.ConvertRuleGroups(c.OrgID, folderUID, promGroups, prom.Config{
+		DatasourceUID:   datasourceUID,
+		DefaultInterval: srv.cfg.DefaultRuleEvaluationInterval,
+		RecordingRules:  srv.cfg.RecordingRules.Enabled,
+	})
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	result := definitions.PrometheusImportResult{
+		DryRun: dryRun,
+		Groups: make([]definitions.PrometheusImportGroupDelta, 0, len(groups)),
+	}
+	for _, group := range groups {
+		delta, err := srv.alertRules.CalculateRuleGroupChanges(c.Req.Context(), c.OrgID, group, alerting_models.ProvenanceConvertedPrometheus)
+		if err != nil {
+			return toImportErrorResponse(err)
+		}
+		groupDelta := toPrometheusImportGroupDelta(group, delta)
+		for _, w := range warnings {
+			if w.Group == group.Title {
+				groupDelta.Warnings = append(groupDelta.Warnings, w.String())
+			}
+		}
+		result.Groups = append(result.Groups, groupDelta)
+	}
+	if dryRun {
+		return response.JSON(http.StatusOK, result)
+	}
+
+	if err := srv.alertRules.ReplaceRuleGroups(c.Req.Context(), c.OrgID, groups, c.UserID, alerting_models.ProvenanceConvertedPrometheus); err != nil {
+		return toImportErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func toImportErrorResponse(err error) response.Response {
+	if errors.Is(err, alerting_models.ErrAlertRuleFailedValidation) {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	if errors.Is(err, store.ErrOptimisticLock) {
+		return ErrResp(http.StatusConflict, err, "")
+	}
+	if errors.Is(err, alerting_models.ErrQuotaReached) {
+		return ErrResp(http.StatusForbidden, err, "")
+	}
+	return ErrResp(http.StatusInternalServerError, err, "failed to import rule group")
+}
+
+func toPrometheusImportGroupDelta(group alerting_models.AlertRuleGroup, delta *store.GroupDelta) definitions.PrometheusImportGroupDelta {
+	result := definitions.PrometheusImportGroupDelta{
+		Title:     group.Title,
+		FolderUID: group.FolderUID,
+	}
+	for _, rule := range delta.New {
+		result.Added = append(result.Added, definitions.PrometheusImportRuleDelta{UID: rule.UID, Title: rule.Title})
+	}
+	for _, update := range delta.Update {
+		// the delta includes all rules of the group, also the ones that do not change
+		if len(update.Diff) == 0 {
+			continue
+		}
+		result.Updated = append(result.Updated, definitions.PrometheusImportRuleDelta{
+			UID:   update.New.UID,
+			Title: update.New.Title,
+			Diff:  toRuleVersionFieldDiffs(update.Diff),
+		})
+	}
+	for _, rule := range delta.Delete {
+		result.Deleted = append(result.Deleted, definitions.PrometheusImportRuleDelta{UID: rule.UID, Title: rule.Title})
+	}
+	return result
+}
+
 func determineProvenance(ctx *contextmodel.ReqContext) definitions.Provenance {
 	if _, disabled := ctx.Req.Header[disableProvenanceHeaderName]; disabled {
 		return definitions.Provenance(alerting_models.ProvenanceNone)
@@ -488,8 +602,12 @@ func extractExportRequest(c *contextmodel.ReqContext) definitions.ExportQueryPar
 		format = "json"
 	}
 
+	if strings.Contains(acceptHeader, "hcl") {
+		format = "hcl"
+	}
+
 	queryFormat := c.Query("format")
-	if queryFormat == "yaml" || queryFormat == "json" {
+	if queryFormat == "yaml" || queryFormat == "json" || queryFormat == "hcl" {
 		format = queryFormat
 	}
 
@@ -503,6 +621,9 @@ func extractExportRequest(c *contextmodel.ReqContext) definitions.ExportQueryPar
 
 func exportResponse(c *contextmodel.ReqContext, body definitions.AlertingFileExport) response.Response {
 	params := extractExportRequest(c)
+	if params.Format == "hcl" {
+		return exportHcl(params.Download, body)
+	}
 	if params.Download {
 		r := response.JSONDownload
 		if params.Format == "yaml" {
@@ -517,3 +638,18 @@ func exportResponse(c *contextmodel.ReqContext, body definitions.AlertingFileExp
 	}
 	return r(http.StatusOK, body)
 }
+
+// exportHcl returns the resources of the export as Terraform resources of the Grafana provider.
+func exportHcl(download bool, body definitions.AlertingFileExport) response.Response {
+	b, err := HCLFromAlertingFileExport(body)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to convert to HCL")
+	}
+	resp := response.Respond(http.StatusOK, b)
+	if download {
+		return resp.
+			SetHeader("Content-Type", "application/terraform+hcl").
+			SetHeader("Content-Disposition", `attachment;filename="export.tf"`)
+	}
+	return resp.SetHeader("Content-Type", "text/hcl")
+}
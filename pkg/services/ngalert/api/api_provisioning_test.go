@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -386,6 +388,101 @@ func TestProvisioningApi(t *testing.T) {
 		})
 	})
 
+	t.Run("prometheus rule file import", func(t *testing.T) {
+		ruleFile := `
+groups:
+  - name: my-cool-group
+    interval: 1m
+    rules:
+      - alert: HighLatency
+        expr: job:request_latency_seconds:mean5m{job="api"} > 0.5
+        for: 5m
+`
+
+		t.Run("missing data source, POST returns 400", func(t *testing.T) {
+			sut := createProvisioningSrvSut(t)
+			rc := createTestRequestCtx()
+			rc.Req.Body = io.NopCloser(strings.NewReader(ruleFile))
+
+			response := sut.RoutePostImportPrometheusRuleGroups(&rc, "folder-uid")
+
+			require.Equal(t, 400, response.Status())
+		})
+
+		t.Run("invalid rule file, POST returns 400", func(t *testing.T) {
+			sut := createProvisioningSrvSut(t)
+			rc := createTestRequestCtx()
+			rc.Req.Form.Set("datasourceUid", "prometheus")
+			rc.Req.Body = io.NopCloser(strings.NewReader("groups: [{name: test, rules: [{alert: test, expr: 'up =='}]}]"))
+
+			response := sut.RoutePostImportPrometheusRuleGroups(&rc, "folder-uid")
+
+			require.Equal(t, 400, response.Status())
+		})
+
+		t.Run("dry run, POST returns changes and does not save them", func(t *testing.T) {
+			sut := createProvisioningSrvSut(t)
+			rc := createTestRequestCtx()
+			rc.Req.Form.Set("datasourceUid", "prometheus")
+			rc.Req.Form.Set("dryRun", "true")
+			rc.Req.Body = io.NopCloser(strings.NewReader(ruleFile))
+
+			response := sut.RoutePostImportPrometheusRuleGroups(&rc, "folder-uid")
+
+			require.Equal(t, 200, response.Status())
+			result := definitions.PrometheusImportResult{}
+			require.NoError(t, json.Unmarshal(response.Body(), &result))
+			require.True(t, result.DryRun)
+			require.Len(t, result.Groups, 1)
+			require.Len(t, result.Groups[0].Added, 1)
+			require.Equal(t, "HighLatency", result.Groups[0].Added[0].Title)
+
+			response = sut.RouteGetAlertRuleGroup(&rc, "folder-uid", "my-cool-group")
+			require.Equal(t, 404, response.Status())
+		})
+
+		t.Run("valid rule file, POST saves rule groups", func(t *testing.T) {
+			sut := createProvisioningSrvSut(t)
+			rc := createTestRequestCtx()
+			rc.Req.Form.Set("datasourceUid", "prometheus")
+			rc.Req.Body = io.NopCloser(strings.NewReader(ruleFile))
+
+			response := sut.RoutePostImportPrometheusRuleGroups(&rc, "folder-uid")
+			require.Equal(t, 200, response.Status())
+			result := definitions.PrometheusImportResult{}
+			require.NoError(t, json.Unmarshal(response.Body(), &result))
+			require.Len(t, result.Groups, 1)
+			require.Len(t, result.Groups[0].Added, 1)
+
+			response = sut.RouteGetAlertRuleGroup(&rc, "folder-uid", "my-cool-group")
+			require.Equal(t, 200, response.Status())
+			group := definitions.AlertRuleGroup{}
+			require.NoError(t, json.Unmarshal(response.Body(), &group))
+			require.Len(t, group.Rules, 1)
+			require.Equal(t, result.Groups[0].Added[0].UID, group.Rules[0].UID)
+		})
+
+		t.Run("rule file imported again, POST keeps rule UIDs", func(t *testing.T) {
+			sut := createProvisioningSrvSut(t)
+			rc := createTestRequestCtx()
+			rc.Req.Form.Set("datasourceUid", "prometheus")
+			rc.Req.Body = io.NopCloser(strings.NewReader(ruleFile))
+			response := sut.RoutePostImportPrometheusRuleGroups(&rc, "folder-uid")
+			require.Equal(t, 200, response.Status())
+
+			rc.Req.Body = io.NopCloser(strings.NewReader(ruleFile))
+			response = sut.RoutePostImportPrometheusRuleGroups(&rc, "folder-uid")
+
+			require.Equal(t, 200, response.Status())
+			result := definitions.PrometheusImportResult{}
+			require.NoError(t, json.Unmarshal(response.Body(), &result))
+			require.Len(t, result.Groups, 1)
+			require.Empty(t, result.Groups[0].Added)
+			require.Empty(t, result.Groups[0].Updated)
+			require.Empty(t, result.Groups[0].Deleted)
+		})
+	})
+
 	t.Run("exports", func(t *testing.T) {
 		t.Run("alert rule group", func(t *testing.T) {
 			t.Run("are present, GET returns 200", func(t *testing.T) {
@@ -781,6 +878,21 @@ func TestProvisioningApi(t *testing.T) {
 				require.Equal(t, "", rc.Context.Resp.Header().Get("Content-Disposition"))
 			})
 
+			t.Run("query format contains hcl, GET returns text hcl", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+				insertRule(t, sut, createTestAlertRuleWithFolderAndGroup("rule1", 1, "folder-uid", "groupa"))
+
+				rc.Context.Req.Form.Set("format", "hcl")
+				response := sut.RouteGetAlertRulesExport(&rc)
+				response.WriteTo(&rc)
+
+				require.Equal(t, 200, response.Status())
+				require.Equal(t, "text/hcl", rc.Context.Resp.Header().Get("Content-Type"))
+				require.Contains(t, string(response.Body()), "resource \"grafana_rule_group\" \"folder_title_groupa\" {")
+				require.Contains(t, string(response.Body()), "folder_uid       = \"folder-uid\"")
+			})
+
 			t.Run("json body content is as expected", func(t *testing.T) {
 				sut := createProvisioningSrvSut(t)
 				rc := createTestRequestCtx()
@@ -923,6 +1035,61 @@ func TestProvisioningApi(t *testing.T) {
 				require.Equal(t, expectedResponse, string(response.Body()))
 			})
 		})
+
+		t.Run("mute timings", func(t *testing.T) {
+			t.Run("yaml body content is as expected", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+
+				rc.Context.Req.Header.Add("Accept", "application/yaml")
+				response := sut.RouteGetMuteTimingsExport(&rc)
+
+				expectedResponse := "apiVersion: 1\nmuteTimes:\n    - orgId: 1\n      name: interval\n      time_intervals: []\n"
+				require.Equal(t, 200, response.Status())
+				require.Equal(t, expectedResponse, string(response.Body()))
+			})
+
+			t.Run("query format contains hcl, GET returns terraform resources", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+
+				rc.Context.Req.Form.Set("format", "hcl")
+				response := sut.RouteGetMuteTimingsExport(&rc)
+				response.WriteTo(&rc)
+
+				expectedResponse := "resource \"grafana_mute_timing\" \"interval\" {\n  name = \"interval\"\n}\n"
+				require.Equal(t, 200, response.Status())
+				require.Equal(t, "text/hcl", rc.Context.Resp.Header().Get("Content-Type"))
+				require.Equal(t, expectedResponse, string(response.Body()))
+			})
+		})
+
+		t.Run("templates", func(t *testing.T) {
+			t.Run("yaml body content is as expected", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+
+				rc.Context.Req.Header.Add("Accept", "application/yaml")
+				response := sut.RouteGetTemplatesExport(&rc)
+
+				expectedResponse := "apiVersion: 1\ntemplates:\n    - orgId: 1\n      name: a\n      template: template\n"
+				require.Equal(t, 200, response.Status())
+				require.Equal(t, expectedResponse, string(response.Body()))
+			})
+
+			t.Run("query param download=true and format hcl, GET returns terraform file", func(t *testing.T) {
+				sut := createProvisioningSrvSut(t)
+				rc := createTestRequestCtx()
+
+				rc.Context.Req.Form.Set("format", "hcl")
+				rc.Context.Req.Form.Set("download", "true")
+				response := sut.RouteGetTemplatesExport(&rc)
+				response.WriteTo(&rc)
+
+				require.Equal(t, 200, response.Status())
+				require.Equal(t, "attachment;filename=\"export.tf\"", rc.Context.Resp.Header().Get("Content-Disposition"))
+			})
+		})
 	})
 }
 
@@ -1241,6 +1408,10 @@ func createProvisioningSrvSutFromEnv(t *testing.T, env *testEnvironment) Provisi
 		templates:           provisioning.NewTemplateService(env.configs, env.prov, env.xact, env.log),
 		muteTimings:         provisioning.NewMuteTimingService(env.configs, env.prov, env.xact, env.log),
 		alertRules:          provisioning.NewAlertRuleService(env.store, env.prov, env.dashboardService, env.quotas, env.xact, 60, 10, env.log),
+		cfg: &setting.UnifiedAlertingSettings{
+			BaseInterval:                  10 * time.Second,
+			DefaultRuleEvaluationInterval: time.Minute,
+		},
 	}
 }
 
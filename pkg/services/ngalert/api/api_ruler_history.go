@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 	"time"
@@ -31,28 +32,43 @@ func (srv *HistorySrv) RouteQueryStateHistory(c *contextmodel.ReqContext) respon
 	ruleUID := c.Query("ruleUID")
 	dashUID := c.Query("dashboardUID")
 	panelID := c.QueryInt64("panelID")
+	folderUID := c.Query("folderUID")
+	ruleGroup := c.Query("ruleGroup")
+	page := c.QueryInt("page")
 
-	labels := make(map[string]string)
-	for k, v := range c.Req.URL.Query() {
-		if strings.HasPrefix(k, labelQueryPrefix) {
-			labels[k[len(labelQueryPrefix):]] = v[0]
-		}
-	}
+	labels := labelsFromQuery(c)
 
 	query := models.HistoryQuery{
 		RuleUID:      ruleUID,
 		OrgID:        c.OrgID,
 		DashboardUID: dashUID,
 		PanelID:      panelID,
+		NamespaceUID: folderUID,
+		RuleGroup:    ruleGroup,
 		SignedInUser: c.SignedInUser,
 		From:         time.Unix(from, 0),
 		To:           time.Unix(to, 0),
 		Limit:        limit,
+		Page:         page,
 		Labels:       labels,
 	}
 	frame, err := srv.hist.Query(c.Req.Context(), query)
 	if err != nil {
+		if errors.Is(err, models.ErrUnsupportedHistoryQuery) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
 		return ErrResp(http.StatusInternalServerError, err, "")
 	}
 	return response.JSON(http.StatusOK, frame)
 }
+
+// labelsFromQuery returns the labels to filter state history by, which are passed as query parameters with the prefix "labels_".
+func labelsFromQuery(c *contextmodel.ReqContext) map[string]string {
+	labels := make(map[string]string)
+	for k, v := range c.Req.URL.Query() {
+		if strings.HasPrefix(k, labelQueryPrefix) {
+			labels[k[len(labelQueryPrefix):]] = v[0]
+		}
+	}
+	return labels
+}
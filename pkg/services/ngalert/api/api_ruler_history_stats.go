@@ -0,0 +1,361 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	defaultStateHistoryStatsWindow = 7 * 24 * time.Hour
+	defaultStateHistoryStatsTopN   = 10
+	// stateHistoryStatsPageSize is the number of entries queried from the backend at once, the maximum of the Loki backend.
+	stateHistoryStatsPageSize = 5000
+)
+
+// RouteQueryStateHistoryStats calculates statistics of the state history of alert rules and their instances over a window.
+func (srv *HistorySrv) RouteQueryStateHistoryStats(c *contextmodel.ReqContext) response.Response {
+	to := time.Now()
+	if t := c.QueryInt64("to"); t > 0 {
+		to = time.Unix(t, 0)
+	}
+	from := to.Add(-defaultStateHistoryStatsWindow)
+	if f := c.QueryInt64("from"); f > 0 {
+		from = time.Unix(f, 0)
+	}
+	if !from.Before(to) {
+		return ErrResp(http.StatusBadRequest, errors.New("parameter 'from' must be before 'to'"), "")
+	}
+	topN := c.QueryInt("topN")
+	if topN < 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("parameter 'topN' must not be negative"), "")
+	}
+	if topN == 0 {
+		topN = defaultStateHistoryStatsTopN
+	}
+
+	query := models.HistoryQuery{
+		RuleUID:      c.Query("ruleUID"),
+		OrgID:        c.OrgID,
+		NamespaceUID: c.Query("folderUID"),
+		RuleGroup:    c.Query("ruleGroup"),
+		SignedInUser: c.SignedInUser,
+		From:         from,
+		To:           to,
+		Labels:       labelsFromQuery(c),
+	}
+	transitions, err := queryAllTransitions(c.Req.Context(), srv.hist, query, stateHistoryStatsPageSize)
+	if err != nil {
+		if errors.Is(err, models.ErrUnsupportedHistoryQuery) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to read state history")
+	}
+	return response.JSON(http.StatusOK, calculateStateHistoryStats(transitions, from, to, topN))
+}
+
+// queryAllTransitions reads all transitions of the query from the backend. The backends return the latest entries up to
+// the limit, therefore the window is queried page by page, ending each page at the oldest entry of the previous one.
+// The end of the window is inclusive in some backends and exclusive in others, so the pages overlap slightly and
+// the transitions that were already read are skipped.
+func queryAllTransitions(ctx context.Context, hist Historian, query models.HistoryQuery, pageSize int) ([]historyTransition, error) {
+	type transitionKey struct {
+		time              int64
+		ruleUID           string
+		fingerprint       data.Fingerprint
+		previous, current string
+	}
+	seen := map[transitionKey]struct{}{}
+
+	query.Limit = pageSize
+	var result []historyTransition
+	for {
+		frame, err := hist.Query(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		transitions, err := historyTransitionsFromFrame(frame)
+		if err != nil {
+			return nil, err
+		}
+		oldest, added := query.To, 0
+		for _, tr := range transitions {
+			key := transitionKey{tr.time.UnixNano(), tr.ruleUID, tr.labels.Fingerprint(), tr.previous, tr.current}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			result = append(result, tr)
+			added++
+			if tr.time.Before(oldest) {
+				oldest = tr.time
+			}
+		}
+		if len(transitions) < pageSize || added == 0 {
+			return result, nil
+		}
+		query.To = oldest.Add(time.Nanosecond)
+	}
+}
+
+// historyTransition is a single state change of an alert instance.
+type historyTransition struct {
+	time     time.Time
+	ruleUID  string
+	labels   data.Labels
+	previous string
+	current  string
+}
+
+// historyTransitionsFromFrame reads the state transitions from the frame returned by a state history backend.
+// Both the log-like frame of the Loki and SQL backends, and the frame of the annotation backend are supported.
+func historyTransitionsFromFrame(frame *data.Frame) ([]historyTransition, error) {
+	if frame == nil {
+		return nil, nil
+	}
+	times, _ := frame.FieldByName("time")
+	if times == nil {
+		return nil, nil
+	}
+	if lines, _ := frame.FieldByName("line"); lines != nil {
+		return historyTransitionsFromLines(times, lines)
+	}
+	prev, _ := frame.FieldByName("prev")
+	next, _ := frame.FieldByName("next")
+	text, _ := frame.FieldByName("text")
+	if prev == nil || next == nil || text == nil {
+		return nil, errors.New("unknown format of state history")
+	}
+	return historyTransitionsFromAnnotations(times, text, prev, next)
+}
+
+func historyTransitionsFromLines(times, lines *data.Field) ([]historyTransition, error) {
+	result := make([]historyTransition, 0, times.Len())
+	for i := 0; i < times.Len(); i++ {
+		t, ok := times.At(i).(time.Time)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type of time %T", times.At(i))
+		}
+		raw, ok := lines.At(i).(json.RawMessage)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type of line %T", lines.At(i))
+		}
+		var line struct {
+			Previous string            `json:"previous"`
+			Current  string            `json:"current"`
+			RuleUID  string            `json:"ruleUID"`
+			Labels   map[string]string `json:"labels"`
+		}
+		if err := json.Unmarshal(raw, &line); err != nil {
+			return nil, fmt.Errorf("failed to parse line: %w", err)
+		}
+		result = append(result, historyTransition{
+			time:     t,
+			ruleUID:  line.RuleUID,
+			labels:   line.Labels,
+			previous: baseState(line.Previous),
+			current:  baseState(line.Current),
+		})
+	}
+	return result, nil
+}
+
+// historyTransitionsFromAnnotations reads transitions of the annotation backend. Annotations do not store labels of the alert instance
+// in a structured way, therefore they are parsed from the text of the annotation, which has format "<title> {<labels>} - <values>".
+func historyTransitionsFromAnnotations(times, text, prev, next *data.Field) ([]historyTransition, error) {
+	ruleUID := times.Labels["ruleUID"]
+	result := make([]historyTransition, 0, times.Len())
+	for i := 0; i < times.Len(); i++ {
+		t, ok := times.At(i).(time.Time)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type of time %T", times.At(i))
+		}
+		var labels data.Labels
+		if txt, ok := text.At(i).(string); ok {
+			start, end := strings.Index(txt, "{"), strings.LastIndex(txt, "} - ")
+			if start >= 0 && end > start {
+				// the text is not guaranteed to be parseable, in which case labels are ignored
+				labels, _ = data.LabelsFromString(txt[start+1 : end])
+			}
+		}
+		p, _ := prev.At(i).(string)
+		n, _ := next.At(i).(string)
+		result = append(result, historyTransition{
+			time:     t,
+			ruleUID:  ruleUID,
+			labels:   labels,
+			previous: baseState(p),
+			current:  baseState(n),
+		})
+	}
+	return result, nil
+}
+
+// baseState removes the reason from a formatted state, e.g. "Normal (MissingSeries)" becomes "Normal".
+func baseState(formatted string) string {
+	s, _, _ := strings.Cut(formatted, " (")
+	return s
+}
+
+// instanceStats accumulates statistics of the transitions of a single alert instance.
+type instanceStats struct {
+	ruleUID    string
+	labels     data.Labels
+	summary    apimodels.StateHistoryStatsSummary
+	state      string
+	lastChange time.Time
+	// firingSince is the time the instance started firing. Zero if the instance is not firing or it started firing before the window.
+	firingSince time.Time
+	// resolved is the number of resolutions for which the time to resolve is known.
+	resolved    int
+	resolveTime time.Duration
+}
+
+func newInstanceStats(ruleUID string, labels data.Labels, from time.Time) *instanceStats {
+	if labels == nil {
+		labels = data.Labels{}
+	}
+	return &instanceStats{
+		ruleUID:    ruleUID,
+		labels:     labels,
+		summary:    apimodels.StateHistoryStatsSummary{TimeInState: map[string]float64{}},
+		lastChange: from,
+	}
+}
+
+func (s *instanceStats) add(tr historyTransition) {
+	alerting, normal := eval.Alerting.String(), eval.Normal.String()
+	if tr.time.After(s.lastChange) {
+		s.summary.TimeInState[tr.previous] += tr.time.Sub(s.lastChange).Seconds()
+	}
+	s.summary.Transitions++
+	s.state = tr.current
+	s.lastChange = tr.time
+
+	if tr.current == alerting && (tr.previous == normal || tr.previous == eval.Pending.String()) {
+		s.summary.FlapCount++
+		s.firingSince = tr.time
+		return
+	}
+	if tr.previous == alerting && tr.current != alerting {
+		if tr.current == normal {
+			s.summary.Resolutions++
+			if !s.firingSince.IsZero() {
+				s.resolved++
+				s.resolveTime += tr.time.Sub(s.firingSince)
+			}
+		}
+		s.firingSince = time.Time{}
+	}
+}
+
+// finish accounts the time from the last transition until the end of the window.
+func (s *instanceStats) finish(to time.Time) {
+	if to.After(s.lastChange) {
+		s.summary.TimeInState[s.state] += to.Sub(s.lastChange).Seconds()
+	}
+	if s.resolved > 0 {
+		s.summary.MeanTimeToResolve = s.resolveTime.Seconds() / float64(s.resolved)
+	}
+}
+
+func (s *instanceStats) toInstanceStateHistoryStats() apimodels.InstanceStateHistoryStats {
+	return apimodels.InstanceStateHistoryStats{
+		RuleUID:                  s.ruleUID,
+		Labels:                   s.labels,
+		StateHistoryStatsSummary: s.summary,
+	}
+}
+
+// calculateStateHistoryStats calculates statistics of the transitions within the window [from, to].
+// The state of an instance before its first transition in the window is assumed to be the previous state of that transition,
+// and the state after the last transition is assumed to last until the end of the window.
+func calculateStateHistoryStats(transitions []historyTransition, from, to time.Time, topN int) apimodels.StateHistoryStats {
+	sort.SliceStable(transitions, func(i, j int) bool {
+		return transitions[i].time.Before(transitions[j].time)
+	})
+
+	instances := map[string]*instanceStats{}
+	var order []string
+	for _, tr := range transitions {
+		key := tr.ruleUID + "/" + tr.labels.Fingerprint().String()
+		inst, ok := instances[key]
+		if !ok {
+			inst = newInstanceStats(tr.ruleUID, tr.labels, from)
+			instances[key] = inst
+			order = append(order, key)
+		}
+		inst.add(tr)
+	}
+
+	result := apimodels.StateHistoryStats{
+		From:              from,
+		To:                to,
+		Rules:             []apimodels.RuleStateHistoryStats{},
+		NoisiestInstances: []apimodels.InstanceStateHistoryStats{},
+	}
+	type ruleStats struct {
+		stats       apimodels.RuleStateHistoryStats
+		resolved    int
+		resolveTime time.Duration
+	}
+	rules := map[string]*ruleStats{}
+	var ruleOrder []string
+	for _, key := range order {
+		inst := instances[key]
+		inst.finish(to)
+
+		rule, ok := rules[inst.ruleUID]
+		if !ok {
+			rule = &ruleStats{stats: apimodels.RuleStateHistoryStats{
+				RuleUID:                  inst.ruleUID,
+				StateHistoryStatsSummary: apimodels.StateHistoryStatsSummary{TimeInState: map[string]float64{}},
+			}}
+			rules[inst.ruleUID] = rule
+			ruleOrder = append(ruleOrder, inst.ruleUID)
+		}
+		for state, seconds := range inst.summary.TimeInState {
+			rule.stats.TimeInState[state] += seconds
+		}
+		rule.stats.Transitions += inst.summary.Transitions
+		rule.stats.FlapCount += inst.summary.FlapCount
+		rule.stats.Resolutions += inst.summary.Resolutions
+		rule.resolved += inst.resolved
+		rule.resolveTime += inst.resolveTime
+		rule.stats.Instances = append(rule.stats.Instances, inst.toInstanceStateHistoryStats())
+		result.NoisiestInstances = append(result.NoisiestInstances, inst.toInstanceStateHistoryStats())
+	}
+
+	for _, uid := range ruleOrder {
+		rule := rules[uid]
+		if rule.resolved > 0 {
+			rule.stats.MeanTimeToResolve = rule.resolveTime.Seconds() / float64(rule.resolved)
+		}
+		result.Rules = append(result.Rules, rule.stats)
+	}
+
+	sort.SliceStable(result.NoisiestInstances, func(i, j int) bool {
+		a, b := result.NoisiestInstances[i], result.NoisiestInstances[j]
+		if a.FlapCount != b.FlapCount {
+			return a.FlapCount > b.FlapCount
+		}
+		return a.Transitions > b.Transitions
+	})
+	if len(result.NoisiestInstances) > topN {
+		result.NoisiestInstances = result.NoisiestInstances[:topN]
+	}
+	return result
+}
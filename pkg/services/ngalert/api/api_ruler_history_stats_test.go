@@ -0,0 +1,235 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestCalculateStateHistoryStats(t *testing.T) {
+	from := time.Unix(0, 0)
+	to := from.Add(100 * time.Second)
+	at := func(sec int) time.Time {
+		return from.Add(time.Duration(sec) * time.Second)
+	}
+	flappy := data.Labels{"instance": "flappy"}
+	stable := data.Labels{"instance": "stable"}
+
+	transitions := []historyTransition{
+		// out of order to make sure transitions are sorted by time
+		{time: at(30), ruleUID: "rule-1", labels: flappy, previous: "Alerting", current: "Normal"},
+		{time: at(10), ruleUID: "rule-1", labels: flappy, previous: "Normal", current: "Alerting"},
+		{time: at(50), ruleUID: "rule-1", labels: flappy, previous: "Normal", current: "Pending"},
+		{time: at(60), ruleUID: "rule-1", labels: flappy, previous: "Pending", current: "Alerting"},
+		{time: at(90), ruleUID: "rule-1", labels: flappy, previous: "Alerting", current: "Normal"},
+		// the instance was firing before the window, so the time to resolve is unknown
+		{time: at(40), ruleUID: "rule-1", labels: stable, previous: "Alerting", current: "Normal"},
+		{time: at(20), ruleUID: "rule-2", labels: nil, previous: "Normal", current: "Alerting"},
+	}
+
+	stats := calculateStateHistoryStats(transitions, from, to, 2)
+
+	require.Equal(t, from, stats.From)
+	require.Equal(t, to, stats.To)
+	require.Len(t, stats.Rules, 2)
+
+	rule1 := stats.Rules[0]
+	require.Equal(t, "rule-1", rule1.RuleUID)
+	require.Len(t, rule1.Instances, 2)
+	require.Equal(t, 6, rule1.Transitions)
+	require.Equal(t, 2, rule1.FlapCount)
+	require.Equal(t, 3, rule1.Resolutions)
+	require.Equal(t, float64(25), rule1.MeanTimeToResolve)
+	require.Equal(t, map[string]float64{"Normal": 10 + 20 + 10 + 60, "Pending": 10, "Alerting": 20 + 30 + 40}, rule1.TimeInState)
+
+	flappyStats := rule1.Instances[0]
+	require.Equal(t, map[string]string(flappy), flappyStats.Labels)
+	require.Equal(t, 5, flappyStats.Transitions)
+	require.Equal(t, 2, flappyStats.FlapCount)
+	require.Equal(t, 2, flappyStats.Resolutions)
+	require.Equal(t, float64(25), flappyStats.MeanTimeToResolve)
+	require.Equal(t, map[string]float64{"Normal": 10 + 20 + 10, "Pending": 10, "Alerting": 20 + 30}, flappyStats.TimeInState)
+
+	stableStats := rule1.Instances[1]
+	require.Equal(t, 1, stableStats.Resolutions)
+	require.Zero(t, stableStats.MeanTimeToResolve)
+	require.Equal(t, map[string]float64{"Alerting": 40, "Normal": 60}, stableStats.TimeInState)
+
+	rule2 := stats.Rules[1]
+	require.Equal(t, "rule-2", rule2.RuleUID)
+	require.Equal(t, 1, rule2.FlapCount)
+	require.Equal(t, map[string]float64{"Normal": 20, "Alerting": 80}, rule2.TimeInState)
+	require.Equal(t, map[string]string{}, rule2.Instances[0].Labels)
+
+	require.Len(t, stats.NoisiestInstances, 2)
+	require.Equal(t, map[string]string(flappy), stats.NoisiestInstances[0].Labels)
+	require.Equal(t, "rule-2", stats.NoisiestInstances[1].RuleUID)
+}
+
+func TestHistoryTransitionsFromFrame(t *testing.T) {
+	t.Run("reads frame of log-like backends", func(t *testing.T) {
+		line, err := json.Marshal(map[string]interface{}{
+			"previous": "Normal (MissingSeries)",
+			"current":  "Alerting",
+			"ruleUID":  "rule-uid",
+			"labels":   map[string]string{"a": "b"},
+		})
+		require.NoError(t, err)
+		frame := data.NewFrame("states",
+			data.NewField("time", nil, []time.Time{time.Unix(10, 0)}),
+			data.NewField("line", nil, []json.RawMessage{line}),
+			data.NewField("labels", nil, []json.RawMessage{json.RawMessage(`{}`)}),
+		)
+
+		transitions, err := historyTransitionsFromFrame(frame)
+
+		require.NoError(t, err)
+		require.Equal(t, []historyTransition{{
+			time:     time.Unix(10, 0),
+			ruleUID:  "rule-uid",
+			labels:   data.Labels{"a": "b"},
+			previous: "Normal",
+			current:  "Alerting",
+		}}, transitions)
+	})
+
+	t.Run("reads frame of annotation backend", func(t *testing.T) {
+		lbls := data.Labels{"from": "state-history", "ruleUID": "rule-uid"}
+		frame := data.NewFrame("states",
+			data.NewField("time", lbls, []time.Time{time.Unix(10, 0)}),
+			data.NewField("text", lbls, []string{"my rule {a=b, c=d} - A=1.000000"}),
+			data.NewField("prev", lbls, []string{"Pending"}),
+			data.NewField("next", lbls, []string{"Alerting"}),
+			data.NewField("data", lbls, []string{"{}"}),
+		)
+
+		transitions, err := historyTransitionsFromFrame(frame)
+
+		require.NoError(t, err)
+		require.Equal(t, []historyTransition{{
+			time:     time.Unix(10, 0),
+			ruleUID:  "rule-uid",
+			labels:   data.Labels{"a": "b", "c": "d"},
+			previous: "Pending",
+			current:  "Alerting",
+		}}, transitions)
+	})
+
+	t.Run("fails if the format is unknown", func(t *testing.T) {
+		frame := data.NewFrame("states", data.NewField("time", nil, []time.Time{time.Unix(10, 0)}))
+
+		_, err := historyTransitionsFromFrame(frame)
+
+		require.Error(t, err)
+	})
+}
+
+func TestRouteQueryStateHistoryStats(t *testing.T) {
+	t.Run("queries backend with filters and window", func(t *testing.T) {
+		hist := &fakeHistorian{frame: data.NewFrame("states")}
+		srv := &HistorySrv{hist: hist}
+		req := createRequestContext(1, nil)
+		req.Req.URL.RawQuery = "from=100&to=200&ruleUID=rule-uid&folderUID=folder&ruleGroup=group&labels_team=a"
+
+		response := srv.RouteQueryStateHistoryStats(req)
+
+		require.Equal(t, http.StatusOK, response.Status())
+		require.Equal(t, "rule-uid", hist.query.RuleUID)
+		require.Equal(t, "folder", hist.query.NamespaceUID)
+		require.Equal(t, "group", hist.query.RuleGroup)
+		require.Equal(t, map[string]string{"team": "a"}, hist.query.Labels)
+		require.Equal(t, time.Unix(100, 0), hist.query.From)
+		require.Equal(t, time.Unix(200, 0), hist.query.To)
+
+		result := apimodels.StateHistoryStats{}
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Empty(t, result.Rules)
+	})
+
+	t.Run("returns 400 if the backend does not support the query", func(t *testing.T) {
+		srv := &HistorySrv{hist: &fakeHistorian{err: fmt.Errorf("%w: ruleUID is required", models.ErrUnsupportedHistoryQuery)}}
+		req := createRequestContext(1, nil)
+
+		response := srv.RouteQueryStateHistoryStats(req)
+
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+
+	t.Run("returns 400 if window is invalid", func(t *testing.T) {
+		srv := &HistorySrv{hist: &fakeHistorian{}}
+		req := createRequestContext(1, nil)
+		req.Req.URL.RawQuery = "from=200&to=100"
+
+		response := srv.RouteQueryStateHistoryStats(req)
+
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+}
+
+func TestQueryAllTransitions(t *testing.T) {
+	line := func(current string) json.RawMessage {
+		return json.RawMessage(fmt.Sprintf(`{"previous": "Normal", "current": %q, "ruleUID": "rule-uid", "labels": {}}`, current))
+	}
+	page := func(seconds []int64, states []string) *data.Frame {
+		times := make([]time.Time, 0, len(seconds))
+		lines := make([]json.RawMessage, 0, len(seconds))
+		for i := range seconds {
+			times = append(times, time.Unix(seconds[i], 0))
+			lines = append(lines, line(states[i]))
+		}
+		return data.NewFrame("states", data.NewField("time", nil, times), data.NewField("line", nil, lines))
+	}
+	hist := &fakeHistorian{pages: []*data.Frame{
+		page([]int64{30, 40}, []string{"Alerting", "Pending"}),
+		// the second page overlaps the first one at the end of the window
+		page([]int64{20, 30}, []string{"Pending", "Alerting"}),
+		page([]int64{10}, []string{"Alerting"}),
+	}}
+	query := models.HistoryQuery{From: time.Unix(0, 0), To: time.Unix(50, 0)}
+
+	transitions, err := queryAllTransitions(context.Background(), hist, query, 2)
+
+	require.NoError(t, err)
+	require.Len(t, transitions, 4)
+	require.Len(t, hist.queries, 3)
+	for _, q := range hist.queries {
+		require.Equal(t, 2, q.Limit)
+		require.Equal(t, time.Unix(0, 0), q.From)
+	}
+	require.Equal(t, time.Unix(50, 0), hist.queries[0].To)
+	require.Equal(t, time.Unix(30, 1), hist.queries[1].To)
+	require.Equal(t, time.Unix(20, 1), hist.queries[2].To)
+}
+
+type fakeHistorian struct {
+	query   models.HistoryQuery
+	queries []models.HistoryQuery
+	frame   *data.Frame
+	// pages are returned by subsequent queries instead of frame.
+	pages []*data.Frame
+	err   error
+}
+
+func (f *fakeHistorian) Query(_ context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	f.query = query
+	f.queries = append(f.queries, query)
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.pages != nil {
+		if len(f.queries) > len(f.pages) {
+			return data.NewFrame("states"), nil
+		}
+		return f.pages[len(f.queries)-1], nil
+	}
+	return f.frame, nil
+}
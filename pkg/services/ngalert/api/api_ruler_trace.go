@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// RouteGetRuleEvaluationTrace evaluates the alert rule at the time specified by query parameter "time" and returns
+// the trace of every query and expression of the rule together with the evaluation results. The evaluation does not
+// change the state of the rule. If the evaluation fails, the response contains the error and the trace up to the failure.
+func (srv RulerSrv) RouteGetRuleEvaluationTrace(c *contextmodel.ReqContext, ruleUID string) response.Response {
+	at, err := parseEvaluationTime(c.Query("time"))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	rule, _, resp := srv.getAuthorizedRuleByUID(c, ruleUID)
+	if resp != nil {
+		return resp
+	}
+	if !authorizeDatasourceAccessForRule(rule, accesscontrol.HasAccess(srv.ac, c)) {
+		return errorToResponse(fmt.Errorf("%w to query one or many data sources used by the rule", ErrAuthorization))
+	}
+	if err := authorizeAlertStateAccess(c.Req.Context(), srv.store, c.OrgID, rule.Data, nil, accesscontrol.HasAccess(srv.ac, c)); err != nil {
+		return errorToResponse(err)
+	}
+
+	evaluator, err := srv.evaluator.Create(eval.NewContext(c.Req.Context(), c.SignedInUser), rule.GetEvalCondition())
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "Failed to build evaluator for queries and expressions")
+	}
+
+	trace := &expr.Trace{}
+	ctx := expr.WithTrace(c.Req.Context(), trace)
+	start := time.Now()
+	results, err := evaluateForTrace(ctx, rule, evaluator, at)
+	duration := time.Since(start)
+
+	result := apimodels.RuleEvaluationTrace{
+		RuleUID:     rule.UID,
+		Condition:   rule.Condition,
+		EvaluatedAt: at,
+		Duration:    duration.Seconds(),
+		TraceID:     tracing.TraceIDFromContext(ctx, false),
+		Nodes:       toEvaluationNodeTraces(trace),
+		Results:     toEvaluationResultTraces(results),
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// evaluateForTrace evaluates the rule the same way the scheduler does. Recording rules do not produce results.
+func evaluateForTrace(ctx context.Context, rule *ngmodels.AlertRule, evaluator eval.ConditionEvaluator, at time.Time) (eval.Results, error) {
+	if rule.Type() != ngmodels.RuleTypeRecording {
+		return evaluator.Evaluate(ctx, at)
+	}
+	resp, err := evaluator.EvaluateRaw(ctx, at)
+	if err != nil {
+		return nil, err
+	}
+	result, ok := resp.Responses[rule.Record.From]
+	if !ok {
+		return nil, fmt.Errorf("no result for query %s", rule.Record.From)
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to evaluate query %s: %w", rule.Record.From, result.Error)
+	}
+	return nil, nil
+}
+
+// parseEvaluationTime parses the time as Unix timestamp in seconds or in RFC3339 format. Returns the current time if
+// the value is empty.
+func parseEvaluationTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Now(), nil
+	}
+	if sec, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(sec, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errors.New("parameter 'time' must be a Unix timestamp in seconds or in RFC3339 format")
+	}
+	return t, nil
+}
+
+func toEvaluationNodeTraces(trace *expr.Trace) []apimodels.EvaluationNodeTrace {
+	result := make([]apimodels.EvaluationNodeTrace, 0, len(trace.Nodes))
+	for _, n := range trace.Nodes {
+		node := apimodels.EvaluationNodeTrace{
+			RefID:          n.RefID,
+			Type:           n.NodeType.String(),
+			Inputs:         n.Inputs,
+			DatasourceUID:  n.DatasourceUID,
+			DatasourceType: n.DatasourceType,
+			Response:       n.Response,
+			Conversion:     n.Conversion,
+			Results:        n.Results,
+			StartedAt:      n.Start,
+			Duration:       n.Duration.Seconds(),
+		}
+		if n.Error != nil {
+			node.Error = n.Error.Error()
+		}
+		result = append(result, node)
+	}
+	return result
+}
+
+func toEvaluationResultTraces(results eval.Results) []apimodels.EvaluationResultTrace {
+	if len(results) == 0 {
+		return nil
+	}
+	traces := make([]apimodels.EvaluationResultTrace, 0, len(results))
+	for _, r := range results {
+		t := apimodels.EvaluationResultTrace{
+			Labels:           r.Instance,
+			State:            r.State.String(),
+			EvaluationString: r.EvaluationString,
+		}
+		if r.Error != nil {
+			t.Error = r.Error.Error()
+		}
+		if len(r.Values) > 0 {
+			t.Values = make(map[string]*float64, len(r.Values))
+			for refID, v := range r.Values {
+				t.Values[refID] = v.Value
+			}
+		}
+		traces = append(traces, t)
+	}
+	return traces
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestParseEvaluationTime(t *testing.T) {
+	at, err := parseEvaluationTime("1700000000")
+	require.NoError(t, err)
+	require.Equal(t, time.Unix(1700000000, 0), at)
+
+	at, err = parseEvaluationTime("2023-11-14T22:13:20Z")
+	require.NoError(t, err)
+	require.True(t, time.Unix(1700000000, 0).Equal(at))
+
+	at, err = parseEvaluationTime("")
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now(), at, time.Minute)
+
+	_, err = parseEvaluationTime("yesterday")
+	require.Error(t, err)
+}
+
+func TestToEvaluationTraces(t *testing.T) {
+	t.Run("converts nodes in the order they were executed", func(t *testing.T) {
+		trace := &expr.Trace{Nodes: []*expr.NodeTrace{
+			{RefID: "A", NodeType: expr.TypeDatasourceNode, DatasourceUID: "ds", Conversion: "vector", Duration: time.Second},
+			{RefID: "B", NodeType: expr.TypeCMDNode, Inputs: []string{"A"}, Error: errors.New("failed")},
+		}}
+		nodes := toEvaluationNodeTraces(trace)
+		require.Len(t, nodes, 2)
+		require.Equal(t, "A", nodes[0].RefID)
+		require.Equal(t, "ds", nodes[0].DatasourceUID)
+		require.Equal(t, "vector", nodes[0].Conversion)
+		require.Equal(t, float64(1), nodes[0].Duration)
+		require.Empty(t, nodes[0].Error)
+		require.Equal(t, []string{"A"}, nodes[1].Inputs)
+		require.Equal(t, "failed", nodes[1].Error)
+	})
+
+	t.Run("converts results with their values", func(t *testing.T) {
+		results := eval.Results{
+			{
+				Instance: data.Labels{"host": "a"},
+				State:    eval.Alerting,
+				Values:   map[string]eval.NumberValueCapture{"B": {Var: "B", Value: util.Pointer(3.0)}},
+			},
+			{State: eval.Error, Error: errors.New("failed")},
+		}
+		traces := toEvaluationResultTraces(results)
+		require.Len(t, traces, 2)
+		require.Equal(t, map[string]string{"host": "a"}, traces[0].Labels)
+		require.Equal(t, "Alerting", traces[0].State)
+		require.Equal(t, 3.0, *traces[0].Values["B"])
+		require.Equal(t, "Error", traces[1].State)
+		require.Equal(t, "failed", traces[1].Error)
+		require.Nil(t, toEvaluationResultTraces(nil))
+	})
+}
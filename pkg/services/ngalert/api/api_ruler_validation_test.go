@@ -223,6 +223,16 @@ func TestValidateRuleGroup(t *testing.T) {
 			require.True(t, alert.HasPause)
 		}
 	})
+
+	t.Run("should apply evaluation offset setting of the group to all rules", func(t *testing.T) {
+		g := validGroup(cfg, rules...)
+		g.DisableEvaluationOffset = true
+		alerts, err := validateRuleGroup(&g, orgId, folder, cfg)
+		require.NoError(t, err)
+		for _, alert := range alerts {
+			require.True(t, alert.DisableEvaluationOffset)
+		}
+	})
 }
 
 func TestValidateRuleGroupFailures(t *testing.T) {
@@ -566,6 +576,83 @@ func TestValidateRuleNodeFailures_NoUID(t *testing.T) {
 	}
 }
 
+func TestValidateRuleNode_Record(t *testing.T) {
+	orgId := rand.Int63()
+	folder := randFolder()
+	cfg := config(t)
+	cfg.RecordingRules.Enabled = true
+
+	recordingRule := func() *apimodels.PostableExtendedRuleNode {
+		r := validRule()
+		r.GrafanaManagedAlert.UID = ""
+		r.GrafanaManagedAlert.Condition = ""
+		r.GrafanaManagedAlert.Record = &apimodels.Record{
+			Metric: "test_metric",
+			From:   "A",
+		}
+		return &r
+	}
+
+	t.Run("should use the recorded query as condition", func(t *testing.T) {
+		rule, err := validateRuleNode(recordingRule(), "", cfg.BaseInterval, orgId, folder, cfg)
+		require.NoError(t, err)
+		require.Equal(t, models.Record{Metric: "test_metric", From: "A"}, rule.Record)
+		require.Equal(t, "A", rule.Condition)
+		require.Equal(t, models.RuleTypeRecording, rule.Type())
+	})
+
+	testCases := []struct {
+		name string
+		rule func() *apimodels.PostableExtendedRuleNode
+		cfg  func() *setting.UnifiedAlertingSettings
+	}{
+		{
+			name: "fail if recording rules are disabled",
+			rule: recordingRule,
+			cfg: func() *setting.UnifiedAlertingSettings {
+				c := *cfg
+				c.RecordingRules.Enabled = false
+				return &c
+			},
+		},
+		{
+			name: "fail if metric name is not valid",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := recordingRule()
+				r.GrafanaManagedAlert.Record.Metric = "test metric"
+				return r
+			},
+		},
+		{
+			name: "fail if recorded query does not exist",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := recordingRule()
+				r.GrafanaManagedAlert.Record.From = "B"
+				return r
+			},
+		},
+		{
+			name: "fail if condition is not the recorded query",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := recordingRule()
+				r.GrafanaManagedAlert.Condition = "B"
+				return r
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := cfg
+			if testCase.cfg != nil {
+				c = testCase.cfg()
+			}
+			_, err := validateRuleNode(testCase.rule(), "", c.BaseInterval, orgId, folder, c)
+			require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		})
+	}
+}
+
 func TestValidateRuleNode_UID(t *testing.T) {
 	orgId := rand.Int63()
 	folder := randFolder()
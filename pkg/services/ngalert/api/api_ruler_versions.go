@@ -0,0 +1,241 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util/cmputil"
+)
+
+// RouteGetRuleVersions returns versions of the alert rule sorted from the latest to the oldest.
+// The versions can be paged using query parameters "limit" and "page". Versions that use data sources
+// the user does not have access to are omitted, so a page can contain fewer versions than the limit.
+func (srv RulerSrv) RouteGetRuleVersions(c *contextmodel.ReqContext, ruleUID string) response.Response {
+	limit := c.QueryInt("limit")
+	page := c.QueryInt("page")
+	if limit < 0 || page < 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("parameters 'limit' and 'page' must not be negative"), "")
+	}
+
+	rule, _, resp := srv.getAuthorizedRuleByUID(c, ruleUID)
+	if resp != nil {
+		return resp
+	}
+
+	versions, err := srv.store.GetAlertRuleVersions(c.Req.Context(), &ngmodels.GetAlertRuleVersionsQuery{
+		OrgID:   c.SignedInUser.OrgID,
+		RuleUID: rule.UID,
+		Limit:   limit,
+		Page:    page,
+	})
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get versions of the alert rule")
+	}
+
+	hasAccess := accesscontrol.HasAccess(srv.ac, c)
+	result := make(apimodels.GettableRuleVersions, 0, len(versions))
+	for _, v := range versions {
+		if !authorizeDatasourceAccessForRuleVersion(v, hasAccess) {
+			continue
+		}
+		result = append(result, toGettableRuleVersion(v))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// RouteGetRuleVersionsDiff returns the field-level difference between two versions of the alert rule.
+// The versions are specified by query parameters "from" and "to". If "to" is omitted, the current version of the rule is used.
+func (srv RulerSrv) RouteGetRuleVersionsDiff(c *contextmodel.ReqContext, ruleUID string) response.Response {
+	from := c.QueryInt64("from")
+	if from <= 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("parameter 'from' must be a positive version number"), "")
+	}
+	to := c.QueryInt64("to")
+	if to < 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("parameter 'to' must be a positive version number"), "")
+	}
+
+	rule, _, resp := srv.getAuthorizedRuleByUID(c, ruleUID)
+	if resp != nil {
+		return resp
+	}
+	if to == 0 {
+		to = rule.Version
+	}
+
+	fromVersion, resp := srv.getAuthorizedRuleVersion(c, rule, from)
+	if resp != nil {
+		return resp
+	}
+	toVersion, resp := srv.getAuthorizedRuleVersion(c, rule, to)
+	if resp != nil {
+		return resp
+	}
+
+	return response.JSON(http.StatusOK, apimodels.RuleVersionDiff{
+		From: from,
+		To:   to,
+		Diff: toRuleVersionFieldDiffs(fromVersion.Diff(toVersion)),
+	})
+}
+
+// RoutePostRestoreRuleVersion restores the definition of the alert rule from the specified version.
+// The restored definition is saved as a new version of the rule that refers to the restored version.
+// The rule stays in the current folder and group. Provisioned rules cannot be restored.
+func (srv RulerSrv) RoutePostRestoreRuleVersion(c *contextmodel.ReqContext, ruleUID string, rawVersion string) response.Response {
+	version, err := strconv.ParseInt(rawVersion, 10, 64)
+	if err != nil || version <= 0 {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("invalid version '%s'", rawVersion), "")
+	}
+
+	rule, group, resp := srv.getAuthorizedRuleByUID(c, ruleUID)
+	if resp != nil {
+		return resp
+	}
+	ruleVersion, resp := srv.getAuthorizedRuleVersion(c, rule, version)
+	if resp != nil {
+		return resp
+	}
+
+	restored := ruleVersion.Restore(rule)
+	rules := make([]*ngmodels.AlertRuleWithOptionals, 0, len(group))
+	for _, r := range group {
+		if r.UID == rule.UID {
+			r = &restored
+		}
+		rules = append(rules, &ngmodels.AlertRuleWithOptionals{AlertRule: *r, HasPause: true})
+	}
+
+	changes, err := srv.applyRuleGroupChanges(c, rule.GetGroupKey(), rules, map[string]int64{rule.UID: version})
+	if err != nil {
+		return toRuleGroupUpdateErrorResponse(err)
+	}
+
+	if changes.IsEmpty() {
+		return response.JSON(http.StatusAccepted, apimodels.RestoreRuleVersionResponse{
+			Message: "no changes detected, the alert rule already matches the version",
+			Version: rule.Version,
+		})
+	}
+	return response.JSON(http.StatusAccepted, apimodels.RestoreRuleVersionResponse{
+		Message: "alert rule restored successfully",
+		Version: rule.Version + 1,
+	})
+}
+
+// getAuthorizedRuleByUID returns the alert rule and all rules of its group if the user can access the folder of the rule
+// and all data sources used by the rules of the group. Otherwise, returns an error response.
+func (srv RulerSrv) getAuthorizedRuleByUID(c *contextmodel.ReqContext, ruleUID string) (*ngmodels.AlertRule, ngmodels.RulesGroup, response.Response) {
+	group, err := srv.store.GetAlertRulesGroupByRuleUID(c.Req.Context(), &ngmodels.GetAlertRulesGroupByRuleUIDQuery{
+		UID:   ruleUID,
+		OrgID: c.SignedInUser.OrgID,
+	})
+	if err != nil {
+		return nil, nil, ErrResp(http.StatusInternalServerError, err, "failed to get alert rule")
+	}
+	var rule *ngmodels.AlertRule
+	for _, r := range group {
+		if r.UID == ruleUID {
+			rule = r
+			break
+		}
+	}
+	if rule == nil {
+		return nil, nil, ErrResp(http.StatusNotFound, ngmodels.ErrAlertRuleNotFound, "")
+	}
+
+	if _, err := srv.store.GetNamespaceByUID(c.Req.Context(), rule.NamespaceUID, c.SignedInUser.OrgID, c.SignedInUser); err != nil {
+		return nil, nil, toNamespaceErrorResponse(err)
+	}
+
+	if !authorizeAccessToRuleGroup(group, accesscontrol.HasAccess(srv.ac, c)) {
+		return nil, nil, ErrResp(http.StatusUnauthorized, fmt.Errorf("%w to access the rule because it does not have access to one or many data sources one or many rules in the group use", ErrAuthorization), "")
+	}
+	return rule, group, nil
+}
+
+// getAuthorizedRuleVersion returns the version of the alert rule if the user can access all data sources the version uses.
+// Otherwise, returns an error response.
+func (srv RulerSrv) getAuthorizedRuleVersion(c *contextmodel.ReqContext, rule *ngmodels.AlertRule, version int64) (*ngmodels.AlertRuleVersion, response.Response) {
+	result, err := srv.store.GetAlertRuleVersion(c.Req.Context(), &ngmodels.GetAlertRuleVersionQuery{
+		OrgID:   rule.OrgID,
+		RuleUID: rule.UID,
+		Version: version,
+	})
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleVersionNotFound) {
+			return nil, ErrResp(http.StatusNotFound, err, "version %d", version)
+		}
+		return nil, ErrResp(http.StatusInternalServerError, err, "failed to get version of the alert rule")
+	}
+	if !authorizeDatasourceAccessForRuleVersion(result, accesscontrol.HasAccess(srv.ac, c)) {
+		return nil, ErrResp(http.StatusUnauthorized, fmt.Errorf("%w to access version %d of the rule because it does not have access to one or many data sources the version uses", ErrAuthorization, version), "")
+	}
+	return result, nil
+}
+
+// authorizeDatasourceAccessForRuleVersion checks that user has access to all data sources declared by the version of a rule
+func authorizeDatasourceAccessForRuleVersion(version *ngmodels.AlertRuleVersion, evaluator func(evaluator accesscontrol.Evaluator) bool) bool {
+	rule := version.ToAlertRule()
+	return authorizeDatasourceAccessForRule(&rule, evaluator)
+}
+
+func toGettableRuleVersion(v *ngmodels.AlertRuleVersion) apimodels.GettableRuleVersion {
+	return apimodels.GettableRuleVersion{
+		UID:             v.RuleUID,
+		Version:         v.Version,
+		ParentVersion:   v.ParentVersion,
+		RestoredFrom:    v.RestoredFrom,
+		Created:         v.Created,
+		Title:           v.Title,
+		Condition:       v.Condition,
+		Data:            ApiAlertQueriesFromAlertQueries(v.Data),
+		IntervalSeconds: v.IntervalSeconds,
+		NamespaceUID:    v.RuleNamespaceUID,
+		RuleGroup:       v.RuleGroup,
+		NoDataState:     apimodels.NoDataState(v.NoDataState),
+		ExecErrState:    apimodels.ExecutionErrorState(v.ExecErrState),
+		For:             model.Duration(v.For),
+		Annotations:     v.Annotations,
+		Labels:          v.Labels,
+		IsPaused:        v.IsPaused,
+		Record:          ApiRecordFromModelRecord(v.Record),
+	}
+}
+
+func toRuleVersionFieldDiffs(report cmputil.DiffReport) []apimodels.RuleVersionFieldDiff {
+	result := make([]apimodels.RuleVersionFieldDiff, 0, len(report))
+	for _, d := range report {
+		result = append(result, apimodels.RuleVersionFieldDiff{
+			Field: d.Path,
+			From:  diffValue(d.Left),
+			To:    diffValue(d.Right),
+		})
+	}
+	return result
+}
+
+// diffValue converts the value of a diff to a value that can be serialized to JSON.
+// Returns nil if the value does not exist, e.g. if it is a label that was added or removed.
+func diffValue(v reflect.Value) interface{} {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	switch value := v.Interface().(type) {
+	case time.Duration:
+		return model.Duration(value).String()
+	default:
+		return value
+	}
+}
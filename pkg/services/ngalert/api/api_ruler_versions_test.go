@@ -0,0 +1,316 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestRouteRuleVersions(t *testing.T) {
+	orgID := rand.Int63()
+	folder := randFolder()
+	groupKey := models.GenerateGroupKey(orgID)
+	groupKey.NamespaceUID = folder.UID
+
+	setup := func(t *testing.T) (*fakes.RuleStore, *models.AlertRule, []*models.AlertRuleVersion) {
+		ruleStore := fakes.NewRuleStore(t)
+		ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+		rules := models.GenerateAlertRules(rand.Intn(3)+2, models.AlertRuleGen(withGroupKey(groupKey), models.WithUniqueGroupIndex(), withDashboardAnnotations))
+		ruleStore.PutRule(context.Background(), rules...)
+
+		rule := rules[0]
+		rule.Version = 3
+		rule.Labels = models.GenerateAlertLabels(rand.Intn(3)+1, "lbl-")
+		rule.For = time.Duration(rule.Version) * time.Minute
+		versions := make([]*models.AlertRuleVersion, 0, rule.Version)
+		for i := int64(1); i <= rule.Version; i++ {
+			v := ruleToVersion(rule)
+			v.Version = i
+			v.ParentVersion = i - 1
+			v.Created = rule.Updated.Add(time.Duration(i-rule.Version) * time.Hour)
+			if i < rule.Version {
+				v.Title = util.GenerateShortUID()
+				v.Labels = map[string]string{"version": util.GenerateShortUID()}
+				v.For = time.Duration(i) * time.Minute
+			}
+			versions = append(versions, v)
+		}
+		ruleStore.PutRuleVersion(versions...)
+		return ruleStore, rule, versions
+	}
+
+	permissions := func(rules []*models.AlertRule, actions ...string) map[int64]map[string][]string {
+		perms := createPermissionsForRules(rules, orgID)
+		for _, action := range actions {
+			perms[orgID][action] = append(perms[orgID][action], dashboards.ScopeFoldersProvider.GetResourceScopeUID(folder.UID))
+		}
+		return perms
+	}
+
+	t.Run("RouteGetRuleVersions", func(t *testing.T) {
+		t.Run("should return versions latest first", func(t *testing.T) {
+			ruleStore, rule, _ := setup(t)
+			req := createRequestContextWithPerms(orgID, permissions(ruleStore.Rules[orgID]), nil)
+
+			response := createService(ruleStore).RouteGetRuleVersions(req, rule.UID)
+
+			require.Equal(t, http.StatusOK, response.Status())
+			result := apimodels.GettableRuleVersions{}
+			require.NoError(t, json.Unmarshal(response.Body(), &result))
+			require.Len(t, result, 3)
+			for i, v := range result {
+				require.Equal(t, int64(3-i), v.Version)
+				require.Equal(t, rule.UID, v.UID)
+			}
+		})
+
+		t.Run("should page versions", func(t *testing.T) {
+			ruleStore, rule, _ := setup(t)
+			req := createRequestContextWithPerms(orgID, permissions(ruleStore.Rules[orgID]), nil)
+			req.Req.URL.RawQuery = "limit=2&page=2"
+
+			response := createService(ruleStore).RouteGetRuleVersions(req, rule.UID)
+
+			require.Equal(t, http.StatusOK, response.Status())
+			result := apimodels.GettableRuleVersions{}
+			require.NoError(t, json.Unmarshal(response.Body(), &result))
+			require.Len(t, result, 1)
+			require.Equal(t, int64(1), result[0].Version)
+		})
+
+		t.Run("should omit versions that use data sources the user does not have access to", func(t *testing.T) {
+			ruleStore, rule, versions := setup(t)
+			versions[1].Data = []models.AlertQuery{models.GenerateAlertQuery()}
+			req := createRequestContextWithPerms(orgID, permissions(ruleStore.Rules[orgID]), nil)
+
+			response := createService(ruleStore).RouteGetRuleVersions(req, rule.UID)
+
+			require.Equal(t, http.StatusOK, response.Status())
+			result := apimodels.GettableRuleVersions{}
+			require.NoError(t, json.Unmarshal(response.Body(), &result))
+			require.Len(t, result, 2)
+			require.Equal(t, int64(3), result[0].Version)
+			require.Equal(t, int64(1), result[1].Version)
+		})
+
+		t.Run("should return 404 if rule does not exist", func(t *testing.T) {
+			ruleStore, _, _ := setup(t)
+			req := createRequestContextWithPerms(orgID, permissions(ruleStore.Rules[orgID]), nil)
+
+			response := createService(ruleStore).RouteGetRuleVersions(req, util.GenerateShortUID())
+
+			require.Equal(t, http.StatusNotFound, response.Status())
+		})
+
+		t.Run("should return 401 if user does not have access to data sources of the group", func(t *testing.T) {
+			ruleStore, rule, _ := setup(t)
+			req := createRequestContextWithPerms(orgID, permissions(ruleStore.Rules[orgID][1:]), nil)
+
+			response := createService(ruleStore).RouteGetRuleVersions(req, rule.UID)
+
+			require.Equal(t, http.StatusUnauthorized, response.Status())
+		})
+	})
+
+	t.Run("RouteGetRuleVersionsDiff", func(t *testing.T) {
+		t.Run("should compare version with the current one", func(t *testing.T) {
+			ruleStore, rule, versions := setup(t)
+			req := createRequestContextWithPerms(orgID, permissions(ruleStore.Rules[orgID]), nil)
+			req.Req.URL.RawQuery = "from=1"
+
+			response := createService(ruleStore).RouteGetRuleVersionsDiff(req, rule.UID)
+
+			require.Equal(t, http.StatusOK, response.Status())
+			result := apimodels.RuleVersionDiff{}
+			require.NoError(t, json.Unmarshal(response.Body(), &result))
+			require.Equal(t, int64(1), result.From)
+			require.Equal(t, int64(3), result.To)
+
+			fields := make(map[string]apimodels.RuleVersionFieldDiff, len(result.Diff))
+			for _, d := range result.Diff {
+				fields[d.Field] = d
+			}
+			require.Contains(t, fields, "Title")
+			require.Equal(t, versions[0].Title, fields["Title"].From)
+			require.Equal(t, rule.Title, fields["Title"].To)
+			require.Contains(t, fields, "For")
+			require.Equal(t, "1m", fields["For"].From)
+			require.Contains(t, fields, "Labels[version]")
+			require.Nil(t, fields["Labels[version]"].To)
+		})
+
+		t.Run("should return empty diff for the same versions", func(t *testing.T) {
+			ruleStore, rule, _ := setup(t)
+			req := createRequestContextWithPerms(orgID, permissions(ruleStore.Rules[orgID]), nil)
+			req.Req.URL.RawQuery = "from=2&to=2"
+
+			response := createService(ruleStore).RouteGetRuleVersionsDiff(req, rule.UID)
+
+			require.Equal(t, http.StatusOK, response.Status())
+			result := apimodels.RuleVersionDiff{}
+			require.NoError(t, json.Unmarshal(response.Body(), &result))
+			require.Empty(t, result.Diff)
+		})
+
+		t.Run("should return 400 if from is not specified", func(t *testing.T) {
+			ruleStore, rule, _ := setup(t)
+			req := createRequestContextWithPerms(orgID, permissions(ruleStore.Rules[orgID]), nil)
+
+			response := createService(ruleStore).RouteGetRuleVersionsDiff(req, rule.UID)
+
+			require.Equal(t, http.StatusBadRequest, response.Status())
+		})
+
+		t.Run("should return 404 if version does not exist", func(t *testing.T) {
+			ruleStore, rule, _ := setup(t)
+			req := createRequestContextWithPerms(orgID, permissions(ruleStore.Rules[orgID]), nil)
+			req.Req.URL.RawQuery = "from=10"
+
+			response := createService(ruleStore).RouteGetRuleVersionsDiff(req, rule.UID)
+
+			require.Equal(t, http.StatusNotFound, response.Status())
+		})
+	})
+
+	t.Run("RoutePostRestoreRuleVersion", func(t *testing.T) {
+		t.Run("should update rule with definition of the version", func(t *testing.T) {
+			ruleStore, rule, versions := setup(t)
+			req := createRequestContextWithPerms(orgID, permissions(ruleStore.Rules[orgID], ac.ActionAlertingRuleUpdate), nil)
+			svc := createService(ruleStore)
+			svc.conditionValidator = &recordingConditionValidator{}
+
+			response := svc.RoutePostRestoreRuleVersion(req, rule.UID, "1")
+
+			require.Equal(t, http.StatusAccepted, response.Status())
+			result := apimodels.RestoreRuleVersionResponse{}
+			require.NoError(t, json.Unmarshal(response.Body(), &result))
+			require.Equal(t, rule.Version+1, result.Version)
+
+			updates := ruleStore.GetRecordedCommands(func(cmd interface{}) (interface{}, bool) {
+				a, ok := cmd.([]models.UpdateRule)
+				return a, ok
+			})
+			require.Len(t, updates, 1)
+			var restored *models.UpdateRule
+			for _, u := range updates[0].([]models.UpdateRule) {
+				if u.New.UID == rule.UID {
+					u := u
+					restored = &u
+				}
+			}
+			require.NotNil(t, restored)
+			require.Equal(t, int64(1), restored.RestoredFrom)
+			require.Equal(t, versions[0].Title, restored.New.Title)
+			require.Equal(t, versions[0].Labels, restored.New.Labels)
+			require.Equal(t, versions[0].For, restored.New.For)
+			require.Equal(t, rule.RuleGroup, restored.New.RuleGroup)
+			require.Equal(t, rule.NamespaceUID, restored.New.NamespaceUID)
+		})
+
+		t.Run("should do nothing if rule already matches the version", func(t *testing.T) {
+			ruleStore, rule, _ := setup(t)
+			req := createRequestContextWithPerms(orgID, permissions(ruleStore.Rules[orgID], ac.ActionAlertingRuleUpdate), nil)
+			svc := createService(ruleStore)
+			svc.conditionValidator = &recordingConditionValidator{}
+
+			response := svc.RoutePostRestoreRuleVersion(req, rule.UID, "3")
+
+			require.Equal(t, http.StatusAccepted, response.Status())
+			updates := ruleStore.GetRecordedCommands(func(cmd interface{}) (interface{}, bool) {
+				a, ok := cmd.([]models.UpdateRule)
+				return a, ok
+			})
+			require.Empty(t, updates)
+		})
+
+		t.Run("should return 400 if the group is provisioned", func(t *testing.T) {
+			ruleStore, rule, _ := setup(t)
+			req := createRequestContextWithPerms(orgID, permissions(ruleStore.Rules[orgID], ac.ActionAlertingRuleUpdate), nil)
+			provenanceStore := provisioning.NewFakeProvisioningStore()
+			require.NoError(t, provenanceStore.SetProvenance(context.Background(), ruleStore.Rules[orgID][1], orgID, models.ProvenanceAPI))
+			svc := createServiceWithProvenanceStore(ruleStore, provenanceStore)
+			svc.conditionValidator = &recordingConditionValidator{}
+
+			response := svc.RoutePostRestoreRuleVersion(req, rule.UID, "1")
+
+			require.Equal(t, http.StatusBadRequest, response.Status())
+			updates := ruleStore.GetRecordedCommands(func(cmd interface{}) (interface{}, bool) {
+				a, ok := cmd.([]models.UpdateRule)
+				return a, ok
+			})
+			require.Empty(t, updates)
+		})
+
+		t.Run("should return 401 if user is not authorized to update rules in the folder", func(t *testing.T) {
+			ruleStore, rule, _ := setup(t)
+			req := createRequestContextWithPerms(orgID, permissions(ruleStore.Rules[orgID]), nil)
+			svc := createService(ruleStore)
+			svc.conditionValidator = &recordingConditionValidator{}
+
+			response := svc.RoutePostRestoreRuleVersion(req, rule.UID, "1")
+
+			require.Equal(t, http.StatusUnauthorized, response.Status())
+		})
+
+		t.Run("should return 400 if version is invalid", func(t *testing.T) {
+			ruleStore, rule, _ := setup(t)
+			req := createRequestContextWithPerms(orgID, permissions(ruleStore.Rules[orgID], ac.ActionAlertingRuleUpdate), nil)
+
+			response := createService(ruleStore).RoutePostRestoreRuleVersion(req, rule.UID, "latest")
+
+			require.Equal(t, http.StatusBadRequest, response.Status())
+		})
+	})
+}
+
+// withDashboardAnnotations sets the annotations of the dashboard and panel of the rule, as the versions restore them from the annotations.
+func withDashboardAnnotations(rule *models.AlertRule) {
+	if rule.DashboardUID == nil || rule.PanelID == nil {
+		rule.DashboardUID = nil
+		rule.PanelID = nil
+		return
+	}
+	if rule.Annotations == nil {
+		rule.Annotations = map[string]string{}
+	}
+	rule.Annotations[models.DashboardUIDAnnotation] = *rule.DashboardUID
+	rule.Annotations[models.PanelIDAnnotation] = strconv.FormatInt(*rule.PanelID, 10)
+}
+
+func ruleToVersion(rule *models.AlertRule) *models.AlertRuleVersion {
+	return &models.AlertRuleVersion{
+		RuleOrgID:               rule.OrgID,
+		RuleUID:                 rule.UID,
+		RuleNamespaceUID:        rule.NamespaceUID,
+		RuleGroup:               rule.RuleGroup,
+		RuleGroupIndex:          rule.RuleGroupIndex,
+		Version:                 rule.Version,
+		Created:                 rule.Updated,
+		Title:                   rule.Title,
+		Condition:               rule.Condition,
+		Data:                    rule.Data,
+		IntervalSeconds:         rule.IntervalSeconds,
+		NoDataState:             rule.NoDataState,
+		ExecErrState:            rule.ExecErrState,
+		For:                     rule.For,
+		Annotations:             rule.Annotations,
+		Labels:                  rule.Labels,
+		IsPaused:                rule.IsPaused,
+		Record:                  rule.Record,
+		DisableEvaluationOffset: rule.DisableEvaluationOffset,
+	}
+}
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -24,7 +26,9 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -39,6 +43,8 @@ type TestingApiSrv struct {
 	backtesting     *backtesting.Engine
 	featureManager  featuremgmt.FeatureToggles
 	appUrl          *url.URL
+	amConfigStore   AlertingStore
+	ruleStore       RuleStore
 }
 
 // RouteTestGrafanaRuleConfig returns a list of potential alerts for a given rule configuration. This is intended to be
@@ -66,6 +72,9 @@ func (srv TestingApiSrv) RouteTestGrafanaRuleConfig(c *contextmodel.ReqContext,
 	}) {
 		return errorToResponse(fmt.Errorf("%w to query one or many data sources used by the rule", ErrAuthorization))
 	}
+	if err := authorizeAlertStateAccess(c.Req.Context(), srv.ruleStore, c.OrgID, rule.Data, nil, accesscontrol.HasAccess(srv.accessControl, c)); err != nil {
+		return errorToResponse(err)
+	}
 
 	evaluator, err := srv.evaluator.Create(eval.NewContext(c.Req.Context(), c.SignedInUser), rule.GetEvalCondition())
 	if err != nil {
@@ -150,6 +159,9 @@ func (srv TestingApiSrv) RouteEvalQueries(c *contextmodel.ReqContext, cmd apimod
 	}) {
 		return ErrResp(http.StatusUnauthorized, fmt.Errorf("%w to query one or many data sources used by the rule", ErrAuthorization), "")
 	}
+	if err := authorizeAlertStateAccess(c.Req.Context(), srv.ruleStore, c.OrgID, queries, nil, accesscontrol.HasAccess(srv.accessControl, c)); err != nil {
+		return errorToResponse(err)
+	}
 
 	cond := ngmodels.Condition{
 		Condition: "",
@@ -208,6 +220,9 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 	}) {
 		return errorToResponse(fmt.Errorf("%w to query one or many data sources used by the rule", ErrAuthorization))
 	}
+	if err := authorizeAlertStateAccess(c.Req.Context(), srv.ruleStore, c.OrgID, queries, nil, accesscontrol.HasAccess(srv.accessControl, c)); err != nil {
+		return errorToResponse(err)
+	}
 
 	rule := &ngmodels.AlertRule{
 		// ID:             0,
@@ -232,6 +247,21 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 		Labels:          cmd.Labels,
 	}
 
+	if cmd.Notifications {
+		if cmd.Stream {
+			return ErrResp(400, nil, "Streaming cannot be combined with simulation of notifications")
+		}
+		return srv.backtestNotifications(c, rule, cmd.From, cmd.To)
+	}
+
+	if cmd.Stream {
+		return backtestStreamResponse{
+			run: func(ctx context.Context, callback func(apimodels.BacktestEvaluation) error) error {
+				return srv.backtesting.TestStream(ctx, c.SignedInUser, rule, cmd.From, cmd.To, callback)
+			},
+		}
+	}
+
 	result, err := srv.backtesting.Test(c.Req.Context(), c.SignedInUser, rule, cmd.From, cmd.To)
 	if err != nil {
 		if errors.Is(err, backtesting.ErrInvalidInputData) {
@@ -246,3 +276,81 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 	}
 	return response.JSON(http.StatusOK, body)
 }
+
+// backtestNotifications runs backtesting of the rule and simulates notifications using the current Alertmanager configuration of the organization.
+func (srv TestingApiSrv) backtestNotifications(c *contextmodel.ReqContext, rule *ngmodels.AlertRule, from, to time.Time) response.Response {
+	amConfig, err := srv.amConfigStore.GetLatestAlertmanagerConfiguration(c.Req.Context(), &ngmodels.GetLatestAlertmanagerConfigurationQuery{OrgID: c.OrgID})
+	if err != nil {
+		if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "Failed to get the Alertmanager configuration")
+	}
+	cfg, err := notifier.Load([]byte(amConfig.AlertmanagerConfiguration))
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "Failed to parse the Alertmanager configuration")
+	}
+
+	result, notifications, err := srv.backtesting.TestNotifications(c.Req.Context(), c.SignedInUser, rule, from, to, cfg.AlertmanagerConfig)
+	if err != nil {
+		if errors.Is(err, backtesting.ErrInvalidInputData) {
+			return ErrResp(400, err, "Failed to evaluate")
+		}
+		return ErrResp(500, err, "Failed to evaluate")
+	}
+
+	return response.JSON(http.StatusOK, data.Frames{result, notifications})
+}
+
+// backtestStreamResponse writes the results of backtesting as newline-delimited JSON, one line per evaluation.
+// Every line is flushed to the client as soon as it is available. If the testing fails before the first evaluation,
+// the response is a regular error. Otherwise, the error is written as the last line of the stream.
+type backtestStreamResponse struct {
+	run func(ctx context.Context, callback func(apimodels.BacktestEvaluation) error) error
+}
+
+// Status gets the response's status.
+// Required to implement response.Response.
+func (r backtestStreamResponse) Status() int {
+	return http.StatusOK
+}
+
+// Body gets the response's body.
+// Required to implement response.Response.
+func (r backtestStreamResponse) Body() []byte {
+	return nil
+}
+
+// WriteTo runs the backtesting and streams the results to the provided context.
+// Required to implement response.Response.
+func (r backtestStreamResponse) WriteTo(ctx *contextmodel.ReqContext) {
+	started := false
+	enc := json.NewEncoder(ctx.Resp)
+	err := r.run(ctx.Req.Context(), func(evaluation apimodels.BacktestEvaluation) error {
+		if !started {
+			ctx.Resp.Header().Set("Content-Type", "application/x-ndjson")
+			ctx.Resp.WriteHeader(http.StatusOK)
+			started = true
+		}
+		if err := enc.Encode(evaluation); err != nil {
+			return err
+		}
+		ctx.Resp.Flush()
+		return nil
+	})
+	if err == nil {
+		return
+	}
+	if !started {
+		status := http.StatusInternalServerError
+		if errors.Is(err, backtesting.ErrInvalidInputData) {
+			status = http.StatusBadRequest
+		}
+		ErrResp(status, err, "Failed to evaluate").WriteTo(ctx)
+		return
+	}
+	ctx.Logger.Error("Backtesting failed while streaming results", "error", err)
+	if err := enc.Encode(map[string]string{"error": err.Error()}); err != nil {
+		ctx.Logger.Error("Error writing to response", "err", err)
+	}
+}
@@ -1,8 +1,14 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,12 +16,14 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	acMock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	fakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -279,3 +287,82 @@ func createTestingApiSrv(t *testing.T, ds *fakes.FakeCacheService, ac *acMock.Mo
 		cfg:             config(t),
 	}
 }
+
+func TestBacktestStreamResponse(t *testing.T) {
+	createContext := func() (*contextmodel.ReqContext, *httptest.ResponseRecorder) {
+		recorder := httptest.NewRecorder()
+		req := &http.Request{URL: &url.URL{}, Header: http.Header{}}
+		return &contextmodel.ReqContext{
+			Context: &web.Context{
+				Req:  req.WithContext(context.Background()),
+				Resp: web.NewResponseWriter(http.MethodPost, recorder),
+			},
+			SignedInUser: &user.SignedInUser{},
+			Logger:       log.NewNopLogger(),
+		}, recorder
+	}
+	one := 1.0
+	evaluations := []definitions.BacktestEvaluation{
+		{
+			Time:      time.Unix(0, 0).UTC(),
+			Instances: []definitions.BacktestInstance{{Labels: map[string]string{"a": "b"}, State: "Alerting", Values: map[string]*float64{"A": &one}}},
+		},
+		{
+			Time:      time.Unix(60, 0).UTC(),
+			Instances: []definitions.BacktestInstance{{Labels: map[string]string{"a": "b"}, State: "Normal"}},
+		},
+	}
+
+	t.Run("should write a line per evaluation", func(t *testing.T) {
+		ctx, recorder := createContext()
+		backtestStreamResponse{
+			run: func(_ context.Context, callback func(definitions.BacktestEvaluation) error) error {
+				for _, e := range evaluations {
+					if err := callback(e); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		}.WriteTo(ctx)
+
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Equal(t, "application/x-ndjson", recorder.Header().Get("Content-Type"))
+		lines := strings.Split(strings.TrimSpace(recorder.Body.String()), "\n")
+		require.Len(t, lines, len(evaluations))
+		for i, line := range lines {
+			var actual definitions.BacktestEvaluation
+			require.NoError(t, json.Unmarshal([]byte(line), &actual))
+			require.Equal(t, evaluations[i], actual)
+		}
+	})
+
+	t.Run("should respond with error if testing fails before first evaluation", func(t *testing.T) {
+		ctx, recorder := createContext()
+		backtestStreamResponse{
+			run: func(_ context.Context, _ func(definitions.BacktestEvaluation) error) error {
+				return fmt.Errorf("%w: test", backtesting.ErrInvalidInputData)
+			},
+		}.WriteTo(ctx)
+
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+		require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	})
+
+	t.Run("should write error as the last line if testing fails during streaming", func(t *testing.T) {
+		ctx, recorder := createContext()
+		backtestStreamResponse{
+			run: func(_ context.Context, callback func(definitions.BacktestEvaluation) error) error {
+				if err := callback(evaluations[0]); err != nil {
+					return err
+				}
+				return errors.New("test-error")
+			},
+		}.WriteTo(ctx)
+
+		require.Equal(t, http.StatusOK, recorder.Code)
+		lines := strings.Split(strings.TrimSpace(recorder.Body.String()), "\n")
+		require.Len(t, lines, 2)
+		require.JSONEq(t, `{"error": "test-error"}`, lines[1])
+	})
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -46,8 +47,20 @@ func (api *API) authorize(method, path string) web.Handler {
 			ac.EvalPermission(ac.ActionAlertingRuleCreate, scope),
 			ac.EvalPermission(ac.ActionAlertingRuleDelete, scope),
 		)
+	case http.MethodGet + "/api/ruler/grafana/api/v1/rule/{RuleUID}/versions",
+		http.MethodGet + "/api/ruler/grafana/api/v1/rule/{RuleUID}/versions/diff":
+		// access to the folder of the rule is checked by the handler
+		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
+	case http.MethodGet + "/api/ruler/grafana/api/v1/rule/{RuleUID}/trace":
+		// access to the folder of the rule and to the data sources it queries is checked by the handler
+		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
+	case http.MethodPost + "/api/ruler/grafana/api/v1/rule/{RuleUID}/versions/{Version}/restore":
+		// more granular permissions are enforced by the handler via "authorizeRuleChanges"
+		eval = ac.EvalPermission(ac.ActionAlertingRuleUpdate)
+
 	// Grafana rule state history paths
-	case http.MethodGet + "/api/v1/rules/history":
+	case http.MethodGet + "/api/v1/rules/history",
+		http.MethodGet + "/api/v1/rules/history/stats":
 		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
 
 	// Grafana, Prometheus-compatible Paths
@@ -100,6 +113,21 @@ func (api *API) authorize(method, path string) web.Handler {
 	case http.MethodPost + "/api/alertmanager/grafana/api/v2/silences":
 		// additional authorization is done in the request handler
 		eval = ac.EvalAny(ac.EvalPermission(ac.ActionAlertingInstanceCreate), ac.EvalPermission(ac.ActionAlertingInstanceUpdate))
+	case http.MethodPost + "/api/alertmanager/grafana/api/v2/silences/preview":
+		eval = ac.EvalPermission(ac.ActionAlertingInstanceRead)
+	case http.MethodPost + "/api/alertmanager/grafana/api/v2/silences/bulk":
+		// additional authorization is done in the request handler
+		eval = ac.EvalAny(ac.EvalPermission(ac.ActionAlertingInstanceCreate), ac.EvalPermission(ac.ActionAlertingInstanceUpdate))
+	case http.MethodPost + "/api/alertmanager/grafana/api/v2/silences/expire":
+		eval = ac.EvalPermission(ac.ActionAlertingInstanceUpdate) // expiring silences is the same as deleting them
+	case http.MethodGet + "/api/alertmanager/grafana/api/v2/silences/templates":
+		eval = ac.EvalPermission(ac.ActionAlertingInstanceRead)
+	case http.MethodPost + "/api/alertmanager/grafana/api/v2/silences/templates":
+		eval = ac.EvalPermission(ac.ActionAlertingInstanceUpdate)
+	case http.MethodDelete + "/api/alertmanager/grafana/api/v2/silences/templates/{Name}":
+		eval = ac.EvalPermission(ac.ActionAlertingInstanceUpdate)
+	case http.MethodPost + "/api/alertmanager/grafana/api/v2/silences/templates/{Name}/apply":
+		eval = ac.EvalPermission(ac.ActionAlertingInstanceCreate)
 
 	// Alert Instances. Grafana Paths
 	case http.MethodGet + "/api/alertmanager/grafana/api/v2/alerts/groups":
@@ -151,6 +179,8 @@ func (api *API) authorize(method, path string) web.Handler {
 		eval = ac.EvalAny(ac.EvalPermission(ac.ActionAlertingNotificationsWrite))
 	case http.MethodGet + "/api/alertmanager/grafana/config/api/v1/receivers":
 		eval = ac.EvalPermission(ac.ActionAlertingNotificationsRead)
+	case http.MethodGet + "/api/alertmanager/grafana/config/api/v1/receivers/{Receiver}/notifications":
+		eval = ac.EvalPermission(ac.ActionAlertingNotificationsRead)
 	case http.MethodPost + "/api/alertmanager/grafana/config/api/v1/receivers/test":
 		eval = ac.EvalPermission(ac.ActionAlertingNotificationsWrite)
 	case http.MethodPost + "/api/alertmanager/grafana/config/api/v1/templates/test":
@@ -190,8 +220,10 @@ func (api *API) authorize(method, path string) web.Handler {
 		http.MethodGet + "/api/v1/provisioning/contact-points/export",
 		http.MethodGet + "/api/v1/provisioning/templates",
 		http.MethodGet + "/api/v1/provisioning/templates/{name}",
+		http.MethodGet + "/api/v1/provisioning/templates/export",
 		http.MethodGet + "/api/v1/provisioning/mute-timings",
 		http.MethodGet + "/api/v1/provisioning/mute-timings/{name}",
+		http.MethodGet + "/api/v1/provisioning/mute-timings/export",
 		http.MethodGet + "/api/v1/provisioning/alert-rules",
 		http.MethodGet + "/api/v1/provisioning/alert-rules/{UID}",
 		http.MethodGet + "/api/v1/provisioning/alert-rules/export",
@@ -213,7 +245,8 @@ func (api *API) authorize(method, path string) web.Handler {
 		http.MethodPost + "/api/v1/provisioning/alert-rules",
 		http.MethodPut + "/api/v1/provisioning/alert-rules/{UID}",
 		http.MethodDelete + "/api/v1/provisioning/alert-rules/{UID}",
-		http.MethodPut + "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}":
+		http.MethodPut + "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}",
+		http.MethodPost + "/api/v1/provisioning/folder/{FolderUID}/import/prometheus":
 		eval = ac.EvalPermission(ac.ActionAlertingProvisioningWrite) // organization scope
 	}
 
@@ -232,6 +265,10 @@ func authorizeDatasourceAccessForRule(rule *ngmodels.AlertRule, evaluator func(e
 			OldDatasourceUID {
 			continue
 		}
+		// access to the state of other rules is checked by authorizeAlertStateAccess
+		if query.DatasourceUID == expr.AlertStateDatasourceUID {
+			continue
+		}
 		if !evaluator(ac.EvalPermission(datasources.ActionQuery, datasources.ScopeProvider.GetResourceScopeUID(query.DatasourceUID))) {
 			return false
 		}
@@ -239,6 +276,71 @@ func authorizeDatasourceAccessForRule(rule *ngmodels.AlertRule, evaluator func(e
 	return true
 }
 
+// authorizeAlertStateAccess checks that user can read all alert rules whose state is queried by the queries.
+// The user can read a rule if it can read alert rules in the folder of the rule and query all data sources the rule uses.
+// Queries that select rules by labels can match rules in any folder, and therefore require access to rules in all folders.
+// The pending rules are looked up before the store, so that rules of a group that is being saved can depend on each other.
+func authorizeAlertStateAccess(ctx context.Context, rules RuleStore, orgID int64, queries []ngmodels.AlertQuery, pending []*ngmodels.AlertRule, evaluator func(evaluator ac.Evaluator) bool) error {
+	var uids []string
+	for _, q := range queries {
+		if q.DatasourceUID != expr.AlertStateDatasourceUID {
+			continue
+		}
+		query, err := expr.UnmarshalAlertStateQuery(q.Model)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleFailedValidation, err)
+		}
+		if len(query.RuleUIDs) == 0 {
+			if !evaluator(ac.EvalPermission(ac.ActionAlertingRuleRead, dashboards.ScopeFoldersAll)) {
+				return fmt.Errorf("%w to query the state of alert rules selected by labels because it cannot read alert rules in all folders", ErrAuthorization)
+			}
+			continue
+		}
+		uids = append(uids, query.RuleUIDs...)
+	}
+	if len(uids) == 0 {
+		return nil
+	}
+
+	byUID := make(map[string]*ngmodels.AlertRule, len(uids))
+	stored, err := rules.ListAlertRules(ctx, &ngmodels.ListAlertRulesQuery{OrgID: orgID, RuleUIDs: uids})
+	if err != nil {
+		return err
+	}
+	for _, rule := range stored {
+		byUID[rule.UID] = rule
+	}
+	for _, rule := range pending {
+		byUID[rule.UID] = rule
+	}
+
+	for _, uid := range uids {
+		rule, ok := byUID[uid]
+		// rules that do not exist are not distinguished from the ones user cannot access
+		if !ok ||
+			!evaluator(ac.EvalPermission(ac.ActionAlertingRuleRead, dashboards.ScopeFoldersProvider.GetResourceScopeUID(rule.NamespaceUID))) ||
+			!authorizeDatasourceAccessForRule(rule, evaluator) {
+			return fmt.Errorf("%w to query the state of alert rule %s", ErrAuthorization, uid)
+		}
+	}
+	return nil
+}
+
+// authorizeAlertStateAccessForChanges checks authorizeAlertStateAccess for every new and updated rule of the group.
+func authorizeAlertStateAccessForChanges(ctx context.Context, rules RuleStore, change *store.GroupDelta, evaluator func(evaluator ac.Evaluator) bool) error {
+	pending := make([]*ngmodels.AlertRule, 0, len(change.New)+len(change.Update))
+	pending = append(pending, change.New...)
+	for _, upd := range change.Update {
+		pending = append(pending, upd.New)
+	}
+	for _, rule := range pending {
+		if err := authorizeAlertStateAccess(ctx, rules, change.GroupKey.OrgID, rule.Data, pending, evaluator); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // authorizeAccessToRuleGroup checks all rules against authorizeDatasourceAccessForRule and exits on the first negative result
 func authorizeAccessToRuleGroup(rules []*ngmodels.AlertRule, evaluator func(evaluator ac.Evaluator) bool) bool {
 	for _, rule := range rules {
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"math"
 	"math/rand"
 	"net/http"
@@ -18,6 +19,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -49,7 +51,7 @@ func TestAuthorize(t *testing.T) {
 		}
 		paths[p] = methods
 	}
-	require.Len(t, paths, 50)
+	require.Len(t, paths, 65)
 
 	ac := acmock.New()
 	api := &API{AccessControl: ac}
@@ -494,3 +496,55 @@ func Test_authorizeAccessToRuleGroup(t *testing.T) {
 		require.False(t, result)
 	})
 }
+
+func Test_authorizeAlertStateAccess(t *testing.T) {
+	orgID := int64(1)
+	visible := models.AlertRuleGen(models.WithOrgID(orgID))()
+	hidden := models.AlertRuleGen(models.WithOrgID(orgID))()
+	hidden.NamespaceUID = visible.NamespaceUID + "-hidden"
+	ruleStore := fakes.NewRuleStore(t)
+	ruleStore.PutRule(context.Background(), visible, hidden)
+
+	permissions := map[string][]string{
+		ac.ActionAlertingRuleRead: {dashboards.ScopeFoldersProvider.GetResourceScopeUID(visible.NamespaceUID)},
+		datasources.ActionQuery:   {datasources.ScopeAll},
+	}
+	evaluator := func(evaluator ac.Evaluator) bool {
+		return evaluator.Evaluate(permissions)
+	}
+	stateQuery := func(model string) []models.AlertQuery {
+		return []models.AlertQuery{{RefID: "A", DatasourceUID: expr.AlertStateDatasourceUID, Model: []byte(model)}}
+	}
+
+	t.Run("should allow queries of rules in readable folders", func(t *testing.T) {
+		err := authorizeAlertStateAccess(context.Background(), ruleStore, orgID, stateQuery(`{"ruleUIDs":["`+visible.UID+`"]}`), nil, evaluator)
+		require.NoError(t, err)
+	})
+
+	t.Run("should deny queries of rules in folders user cannot read", func(t *testing.T) {
+		err := authorizeAlertStateAccess(context.Background(), ruleStore, orgID, stateQuery(`{"ruleUIDs":["`+visible.UID+`","`+hidden.UID+`"]}`), nil, evaluator)
+		require.ErrorIs(t, err, ErrAuthorization)
+	})
+
+	t.Run("should deny queries of rules that do not exist", func(t *testing.T) {
+		err := authorizeAlertStateAccess(context.Background(), ruleStore, orgID, stateQuery(`{"ruleUIDs":["unknown"]}`), nil, evaluator)
+		require.ErrorIs(t, err, ErrAuthorization)
+	})
+
+	t.Run("should allow queries of pending rules", func(t *testing.T) {
+		pending := models.AlertRuleGen(models.WithOrgID(orgID))()
+		pending.NamespaceUID = visible.NamespaceUID
+		err := authorizeAlertStateAccess(context.Background(), ruleStore, orgID, stateQuery(`{"ruleUIDs":["`+pending.UID+`"]}`), []*models.AlertRule{pending}, evaluator)
+		require.NoError(t, err)
+	})
+
+	t.Run("should require access to all folders to select rules by labels", func(t *testing.T) {
+		query := stateQuery(`{"labels":{"team":"a"}}`)
+		err := authorizeAlertStateAccess(context.Background(), ruleStore, orgID, query, nil, evaluator)
+		require.ErrorIs(t, err, ErrAuthorization)
+
+		permissions[ac.ActionAlertingRuleRead] = []string{dashboards.ScopeFoldersAll}
+		err = authorizeAlertStateAccess(context.Background(), ruleStore, orgID, query, nil, evaluator)
+		require.NoError(t, err)
+	})
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"sort"
 	"time"
 
 	"github.com/prometheus/common/model"
@@ -98,6 +99,28 @@ func ApiAlertQueriesFromAlertQueries(queries []models.AlertQuery) []definitions.
 	return result
 }
 
+// ApiRecordFromModelRecord converts models.Record to definitions.Record. Returns nil if the record is empty, i.e. the rule is not a recording rule.
+func ApiRecordFromModelRecord(r models.Record) *definitions.Record {
+	if r.IsEmpty() {
+		return nil
+	}
+	return &definitions.Record{
+		Metric: r.Metric,
+		From:   r.From,
+	}
+}
+
+// ModelRecordFromApiRecord converts definitions.Record to models.Record. Returns an empty record if r is nil.
+func ModelRecordFromApiRecord(r *definitions.Record) models.Record {
+	if r == nil {
+		return models.Record{}
+	}
+	return models.Record{
+		Metric: r.Metric,
+		From:   r.From,
+	}
+}
+
 func AlertRuleGroupFromApiAlertRuleGroup(a definitions.AlertRuleGroup) (models.AlertRuleGroup, error) {
 	ruleGroup := models.AlertRuleGroup{
 		Title:     a.Title,
@@ -151,11 +174,12 @@ func AlertRuleGroupExportFromAlertRuleGroupWithFolderTitle(d models.AlertRuleGro
 		rules = append(rules, alert)
 	}
 	return definitions.AlertRuleGroupExport{
-		OrgID:    d.OrgID,
-		Name:     d.Title,
-		Folder:   d.FolderTitle,
-		Interval: model.Duration(time.Duration(d.Interval) * time.Second),
-		Rules:    rules,
+		OrgID:     d.OrgID,
+		Name:      d.Title,
+		Folder:    d.FolderTitle,
+		FolderUID: d.FolderUID,
+		Interval:  model.Duration(time.Duration(d.Interval) * time.Second),
+		Rules:     rules,
 	}, nil
 }
 
@@ -298,3 +322,34 @@ func RouteExportFromRoute(route *definitions.Route) *definitions.RouteExport {
 
 	return &export
 }
+
+// AlertingFileExportFromMuteTimings creates a definitions.AlertingFileExport DTO from []definitions.MuteTimeInterval.
+func AlertingFileExportFromMuteTimings(orgID int64, muteTimings []definitions.MuteTimeInterval) definitions.AlertingFileExport {
+	f := definitions.AlertingFileExport{APIVersion: 1}
+	for _, mt := range muteTimings {
+		f.MuteTimings = append(f.MuteTimings, definitions.MuteTimeIntervalExport{
+			OrgID:            orgID,
+			MuteTimeInterval: mt.MuteTimeInterval,
+		})
+	}
+	return f
+}
+
+// AlertingFileExportFromTemplates creates a definitions.AlertingFileExport DTO from templates mapped by their names.
+// The templates are sorted by name.
+func AlertingFileExportFromTemplates(orgID int64, templates map[string]string) definitions.AlertingFileExport {
+	f := definitions.AlertingFileExport{APIVersion: 1}
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f.Templates = append(f.Templates, definitions.NotificationTemplateExport{
+			OrgID:    orgID,
+			Name:     name,
+			Template: templates[name],
+		})
+	}
+	return f
+}
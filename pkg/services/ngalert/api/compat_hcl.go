@@ -0,0 +1,536 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// terraformIntegration describes the block of an integration in the grafana_contact_point resource of the Grafana
+// Terraform provider.
+type terraformIntegration struct {
+	// block is the name of the block.
+	block string
+	// arguments maps the settings of the integration to the arguments of the block.
+	arguments map[string]string
+}
+
+// terraformIntegrations maps the types of integrations to their blocks in the grafana_contact_point resource.
+// It follows the schema of the provider and must be updated together with it. Settings that are not listed are
+// exported in the settings argument of the block.
+var terraformIntegrations = map[string]terraformIntegration{
+	"dingding": {block: "dingding", arguments: map[string]string{
+		"url":     "url",
+		"msgType": "message_type",
+		"title":   "title",
+		"message": "message",
+	}},
+	"discord": {block: "discord", arguments: map[string]string{
+		"url":                  "url",
+		"title":                "title",
+		"message":              "message",
+		"avatar_url":           "avatar_url",
+		"use_discord_username": "use_discord_username",
+	}},
+	"email": {block: "email", arguments: map[string]string{
+		"addresses":   "addresses",
+		"singleEmail": "single_email",
+		"message":     "message",
+		"subject":     "subject",
+	}},
+	"googlechat": {block: "googlechat", arguments: map[string]string{
+		"url":     "url",
+		"title":   "title",
+		"message": "message",
+	}},
+	"kafka": {block: "kafka", arguments: map[string]string{
+		"kafkaRestProxy": "rest_proxy_url",
+		"kafkaTopic":     "topic",
+		"username":       "username",
+		"password":       "password",
+		"apiVersion":     "api_version",
+		"kafkaClusterId": "cluster_id",
+		"description":    "description",
+		"details":        "details",
+	}},
+	"LINE": {block: "line", arguments: map[string]string{
+		"token":       "token",
+		"title":       "title",
+		"description": "description",
+	}},
+	"opsgenie": {block: "opsgenie", arguments: map[string]string{
+		"apiUrl":           "url",
+		"apiKey":           "api_key",
+		"message":          "message",
+		"description":      "description",
+		"autoClose":        "auto_close",
+		"overridePriority": "override_priority",
+		"sendTagsAs":       "send_tags_as",
+	}},
+	"pagerduty": {block: "pagerduty", arguments: map[string]string{
+		"integrationKey": "integration_key",
+		"severity":       "severity",
+		"class":          "class",
+		"component":      "component",
+		"group":          "group",
+		"summary":        "summary",
+		"source":         "source",
+		"client":         "client",
+		"client_url":     "client_url",
+		"details":        "details",
+	}},
+	"prometheus-alertmanager": {block: "alertmanager", arguments: map[string]string{
+		"url":               "url",
+		"basicAuthUser":     "basic_auth_user",
+		"basicAuthPassword": "basic_auth_password",
+	}},
+	"pushover": {block: "pushover", arguments: map[string]string{
+		"userKey":    "user_key",
+		"apiToken":   "api_token",
+		"priority":   "priority",
+		"okPriority": "ok_priority",
+		"retry":      "retry",
+		"expire":     "expire",
+		"device":     "device",
+		"sound":      "sound",
+		"okSound":    "ok_sound",
+		"title":      "title",
+		"message":    "message",
+	}},
+	"sensugo": {block: "sensugo", arguments: map[string]string{
+		"url":       "url",
+		"apikey":    "api_key",
+		"entity":    "entity",
+		"check":     "check",
+		"namespace": "namespace",
+		"handler":   "handler",
+		"message":   "message",
+	}},
+	"slack": {block: "slack", arguments: map[string]string{
+		"endpointUrl":    "endpoint_url",
+		"url":            "url",
+		"token":          "token",
+		"recipient":      "recipient",
+		"text":           "text",
+		"title":          "title",
+		"username":       "username",
+		"icon_emoji":     "icon_emoji",
+		"icon_url":       "icon_url",
+		"mentionChannel": "mention_channel",
+		"mentionUsers":   "mention_users",
+		"mentionGroups":  "mention_groups",
+	}},
+	"teams": {block: "teams", arguments: map[string]string{
+		"url":          "url",
+		"title":        "title",
+		"sectiontitle": "section_title",
+		"message":      "message",
+	}},
+	"telegram": {block: "telegram", arguments: map[string]string{
+		"bottoken":             "token",
+		"chatid":               "chat_id",
+		"message":              "message",
+		"parse_mode":           "parse_mode",
+		"disable_notification": "disable_notifications",
+	}},
+	"threema": {block: "threema", arguments: map[string]string{
+		"gateway_id":   "gateway_id",
+		"recipient_id": "recipient_id",
+		"api_secret":   "api_secret",
+		"title":        "title",
+		"description":  "description",
+	}},
+	"victorops": {block: "victorops", arguments: map[string]string{
+		"url":         "url",
+		"messageType": "message_type",
+		"title":       "title",
+		"description": "description",
+	}},
+	"webex": {block: "webex", arguments: map[string]string{
+		"bot_token": "token",
+		"api_url":   "api_url",
+		"room_id":   "room_id",
+		"message":   "message",
+	}},
+	"webhook": {block: "webhook", arguments: map[string]string{
+		"url":                       "url",
+		"httpMethod":                "http_method",
+		"username":                  "basic_auth_user",
+		"password":                  "basic_auth_password",
+		"authorization_scheme":      "authorization_scheme",
+		"authorization_credentials": "authorization_credentials",
+		"maxAlerts":                 "max_alerts",
+		"title":                     "title",
+		"message":                   "message",
+	}},
+	"wecom": {block: "wecom", arguments: map[string]string{
+		"url":      "url",
+		"secret":   "secret",
+		"corp_id":  "corp_id",
+		"agent_id": "agent_id",
+		"msgtype":  "msg_type",
+		"message":  "message",
+		"title":    "title",
+		"touser":   "to_user",
+	}},
+}
+
+// HCLFromAlertingFileExport converts the alerting resources of the export to resources of the Grafana Terraform
+// provider. Secure settings that are redacted in the export are replaced with references to sensitive variables.
+func HCLFromAlertingFileExport(export definitions.AlertingFileExport) ([]byte, error) {
+	e := &hclExporter{file: hclwrite.NewEmptyFile(), names: make(map[string]struct{})}
+	for _, group := range export.Groups {
+		if err := e.addRuleGroup(group); err != nil {
+			return nil, fmt.Errorf("failed to convert rule group '%s': %w", group.Name, err)
+		}
+	}
+	for _, cp := range export.ContactPoints {
+		if err := e.addContactPoint(cp); err != nil {
+			return nil, fmt.Errorf("failed to convert contact point '%s': %w", cp.Name, err)
+		}
+	}
+	for _, policy := range export.Policies {
+		if policy.Policy == nil {
+			continue
+		}
+		e.addNotificationPolicy(policy.Policy)
+	}
+	for _, mt := range export.MuteTimings {
+		e.addMuteTiming(mt)
+	}
+	for _, tmpl := range export.Templates {
+		_, body := e.appendBlock("resource", "grafana_message_template", tmpl.Name)
+		body.SetAttributeValue("name", cty.StringVal(tmpl.Name))
+		body.SetAttributeValue("template", cty.StringVal(tmpl.Template))
+	}
+	return hclwrite.Format(e.file.Bytes()), nil
+}
+
+type hclExporter struct {
+	file *hclwrite.File
+	// names contains the names of the blocks that were added, prefixed with their type.
+	names map[string]struct{}
+}
+
+// appendBlock appends a top level block, such as a resource or a variable, to the file. The last label of the block
+// is converted to an identifier that is not used by other blocks with the same preceding labels, and returned.
+func (e *hclExporter) appendBlock(blockType string, labels ...string) (string, *hclwrite.Body) {
+	body := e.file.Body()
+	if len(body.Blocks()) > 0 {
+		body.AppendNewline()
+	}
+	name := e.uniqueName(blockType, labels...)
+	labels[len(labels)-1] = name
+	return name, body.AppendNewBlock(blockType, labels).Body()
+}
+
+func (e *hclExporter) uniqueName(blockType string, labels ...string) string {
+	prefix := strings.Join(append([]string{blockType}, labels[:len(labels)-1]...), ".") + "."
+	id := hclIdentifier(labels[len(labels)-1])
+	result := id
+	for i := 2; ; i++ {
+		if _, ok := e.names[prefix+result]; !ok {
+			break
+		}
+		result = fmt.Sprintf("%s_%d", id, i)
+	}
+	e.names[prefix+result] = struct{}{}
+	return result
+}
+
+// addVariable adds a sensitive variable for a secure setting and returns a reference to it.
+func (e *hclExporter) addVariable(name string) hclwrite.Tokens {
+	name, body := e.appendBlock("variable", name)
+	body.SetAttributeTraversal("type", hcl.Traversal{hcl.TraverseRoot{Name: "string"}})
+	body.SetAttributeValue("sensitive", cty.True)
+	return hclwrite.TokensForTraversal(hcl.Traversal{hcl.TraverseRoot{Name: "var"}, hcl.TraverseAttr{Name: name}})
+}
+
+func (e *hclExporter) addRuleGroup(group definitions.AlertRuleGroupExport) error {
+	_, body := e.appendBlock("resource", "grafana_rule_group", group.Folder+"_"+group.Name)
+	body.SetAttributeValue("name", cty.StringVal(group.Name))
+	body.SetAttributeValue("folder_uid", cty.StringVal(group.FolderUID))
+	body.SetAttributeValue("interval_seconds", cty.NumberIntVal(int64(time.Duration(group.Interval).Seconds())))
+	for _, rule := range group.Rules {
+		ruleBody := body.AppendNewBlock("rule", nil).Body()
+		ruleBody.SetAttributeValue("name", cty.StringVal(rule.Title))
+		ruleBody.SetAttributeValue("condition", cty.StringVal(rule.Condition))
+		ruleBody.SetAttributeValue("no_data_state", cty.StringVal(string(rule.NoDataState)))
+		ruleBody.SetAttributeValue("exec_err_state", cty.StringVal(string(rule.ExecErrState)))
+		ruleBody.SetAttributeValue("for", cty.StringVal(rule.For.String()))
+		ruleBody.SetAttributeValue("is_paused", cty.BoolVal(rule.IsPaused))
+		setStringMap(ruleBody, "annotations", rule.Annotations)
+		setStringMap(ruleBody, "labels", rule.Labels)
+		for _, query := range rule.Data {
+			mdl, err := ctyValueFromJSON(query.Model)
+			if err != nil {
+				return err
+			}
+			queryBody := ruleBody.AppendNewBlock("data", nil).Body()
+			queryBody.SetAttributeValue("ref_id", cty.StringVal(query.RefID))
+			setString(queryBody, "query_type", query.QueryType)
+			queryBody.SetAttributeValue("datasource_uid", cty.StringVal(query.DatasourceUID))
+			queryBody.SetAttributeRaw("model", hclwrite.TokensForFunctionCall("jsonencode", hclwrite.TokensForValue(mdl)))
+			timeRange := queryBody.AppendNewBlock("relative_time_range", nil).Body()
+			timeRange.SetAttributeValue("from", cty.NumberIntVal(int64(time.Duration(query.RelativeTimeRange.From).Seconds())))
+			timeRange.SetAttributeValue("to", cty.NumberIntVal(int64(time.Duration(query.RelativeTimeRange.To).Seconds())))
+		}
+	}
+	return nil
+}
+
+func (e *hclExporter) addContactPoint(cp definitions.ContactPointExport) error {
+	resourceName, body := e.appendBlock("resource", "grafana_contact_point", cp.Name)
+	body.SetAttributeValue("name", cty.StringVal(cp.Name))
+	for _, receiver := range cp.Receivers {
+		integration, ok := terraformIntegrations[receiver.Type]
+		if !ok {
+			return fmt.Errorf("integration '%s' is not supported by the Terraform provider", receiver.Type)
+		}
+		settings := make(map[string]interface{})
+		if len(receiver.Settings) > 0 {
+			if err := json.Unmarshal(receiver.Settings, &settings); err != nil {
+				return err
+			}
+		}
+		arguments := make(map[string]hclwrite.Tokens, len(settings))
+		var extra []hclwrite.ObjectAttrTokens
+		for _, key := range sortedKeys(settings) {
+			argument, known := integration.arguments[key]
+			var tokens hclwrite.Tokens
+			switch value := settings[key].(type) {
+			case string:
+				if value == definitions.RedactedValue {
+					name := argument
+					if !known {
+						name = key
+					}
+					tokens = e.addVariable(resourceName + "_" + integration.block + "_" + name)
+					break
+				}
+				if key == "addresses" && receiver.Type == "email" {
+					tokens = hclwrite.TokensForValue(stringList(strings.FieldsFunc(value, func(r rune) bool {
+						return r == ';' || r == ',' || r == '\n'
+					})))
+					break
+				}
+				tokens = hclwrite.TokensForValue(cty.StringVal(value))
+			default:
+				v, err := ctyValueFromJSON(value)
+				if err != nil {
+					return err
+				}
+				tokens = hclwrite.TokensForValue(v)
+				if !known {
+					// the settings argument is a map of strings
+					tokens = hclwrite.TokensForFunctionCall("jsonencode", tokens)
+				}
+			}
+			if known {
+				arguments[argument] = tokens
+			} else {
+				extra = append(extra, hclwrite.ObjectAttrTokens{
+					Name:  hclwrite.TokensForValue(cty.StringVal(key)),
+					Value: tokens,
+				})
+			}
+		}
+		integrationBody := body.AppendNewBlock(integration.block, nil).Body()
+		for _, argument := range sortedKeys(arguments) {
+			integrationBody.SetAttributeRaw(argument, arguments[argument])
+		}
+		if receiver.DisableResolveMessage {
+			integrationBody.SetAttributeValue("disable_resolve_message", cty.True)
+		}
+		if len(extra) > 0 {
+			integrationBody.SetAttributeRaw("settings", hclwrite.TokensForObject(extra))
+		}
+	}
+	return nil
+}
+
+func (e *hclExporter) addNotificationPolicy(route *definitions.RouteExport) {
+	_, body := e.appendBlock("resource", "grafana_notification_policy", "notification_policy")
+	body.SetAttributeValue("contact_point", cty.StringVal(route.Receiver))
+	// group_by is required in the root policy
+	body.SetAttributeValue("group_by", stringList(route.GroupByStr))
+	setString(body, "group_wait", durationString(route.GroupWait))
+	setString(body, "group_interval", durationString(route.GroupInterval))
+	setString(body, "repeat_interval", durationString(route.RepeatInterval))
+	for _, r := range route.Routes {
+		addPolicy(body, r)
+	}
+}
+
+func addPolicy(parent *hclwrite.Body, route *definitions.RouteExport) {
+	body := parent.AppendNewBlock("policy", nil).Body()
+	setString(body, "contact_point", route.Receiver)
+	setStringList(body, "group_by", route.GroupByStr)
+	if route.Continue {
+		body.SetAttributeValue("continue", cty.True)
+	}
+	setStringList(body, "mute_timings", route.MuteTimeIntervals)
+	setString(body, "group_wait", durationString(route.GroupWait))
+	setString(body, "group_interval", durationString(route.GroupInterval))
+	setString(body, "repeat_interval", durationString(route.RepeatInterval))
+	addMatcher := func(label string, match labels.MatchType, value string) {
+		matcher := body.AppendNewBlock("matcher", nil).Body()
+		matcher.SetAttributeValue("label", cty.StringVal(label))
+		matcher.SetAttributeValue("match", cty.StringVal(match.String()))
+		matcher.SetAttributeValue("value", cty.StringVal(value))
+	}
+	for _, m := range route.ObjectMatchers {
+		addMatcher(m.Name, m.Type, m.Value)
+	}
+	for _, m := range route.Matchers {
+		addMatcher(m.Name, m.Type, m.Value)
+	}
+	for _, label := range sortedKeys(route.Match) {
+		addMatcher(label, labels.MatchEqual, route.Match[label])
+	}
+	for _, label := range sortedKeys(route.MatchRE) {
+		addMatcher(label, labels.MatchRegexp, route.MatchRE[label].String())
+	}
+	for _, r := range route.Routes {
+		addPolicy(body, r)
+	}
+}
+
+func (e *hclExporter) addMuteTiming(mt definitions.MuteTimeIntervalExport) {
+	_, body := e.appendBlock("resource", "grafana_mute_timing", mt.Name)
+	body.SetAttributeValue("name", cty.StringVal(mt.Name))
+	for _, ti := range mt.TimeIntervals {
+		interval := body.AppendNewBlock("intervals", nil).Body()
+		for _, tr := range ti.Times {
+			times := interval.AppendNewBlock("times", nil).Body()
+			times.SetAttributeValue("start", cty.StringVal(minutesToTime(tr.StartMinute)))
+			times.SetAttributeValue("end", cty.StringVal(minutesToTime(tr.EndMinute)))
+		}
+		var weekdays, daysOfMonth, months, years []string
+		for _, r := range ti.Weekdays {
+			weekdays = append(weekdays, rangeString(r))
+		}
+		for _, r := range ti.DaysOfMonth {
+			daysOfMonth = append(daysOfMonth, rangeString(r.InclusiveRange))
+		}
+		for _, r := range ti.Months {
+			months = append(months, rangeString(r.InclusiveRange))
+		}
+		for _, r := range ti.Years {
+			years = append(years, rangeString(r.InclusiveRange))
+		}
+		setStringList(interval, "weekdays", weekdays)
+		setStringList(interval, "days_of_month", daysOfMonth)
+		setStringList(interval, "months", months)
+		setStringList(interval, "years", years)
+		if ti.Location != nil {
+			interval.SetAttributeValue("location", cty.StringVal(ti.Location.String()))
+		}
+	}
+}
+
+// setString sets the attribute unless the value is empty.
+func setString(body *hclwrite.Body, name, value string) {
+	if value != "" {
+		body.SetAttributeValue(name, cty.StringVal(value))
+	}
+}
+
+// setStringList sets the attribute unless the list is empty.
+func setStringList(body *hclwrite.Body, name string, values []string) {
+	if len(values) > 0 {
+		body.SetAttributeValue(name, stringList(values))
+	}
+}
+
+// setStringMap sets the attribute unless the map is empty.
+func setStringMap(body *hclwrite.Body, name string, m map[string]string) {
+	if len(m) == 0 {
+		return
+	}
+	values := make(map[string]cty.Value, len(m))
+	for k, v := range m {
+		values[k] = cty.StringVal(v)
+	}
+	body.SetAttributeValue(name, cty.MapVal(values))
+}
+
+func stringList(values []string) cty.Value {
+	if len(values) == 0 {
+		return cty.ListValEmpty(cty.String)
+	}
+	result := make([]cty.Value, 0, len(values))
+	for _, v := range values {
+		result = append(result, cty.StringVal(v))
+	}
+	return cty.ListVal(result)
+}
+
+// ctyValueFromJSON converts a value that can be encoded as JSON, such as the model of a query, to a cty value.
+func ctyValueFromJSON(v interface{}) (cty.Value, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	t, err := ctyjson.ImpliedType(b)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return ctyjson.Unmarshal(b, t)
+}
+
+// hclIdentifier converts s to a valid identifier that can be used as the name of a resource.
+// Characters that are not allowed are replaced with underscores.
+func hclIdentifier(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	result := b.String()
+	if result == "" || !(unicode.IsLetter(rune(result[0])) || result[0] == '_') {
+		result = "_" + result
+	}
+	return result
+}
+
+func rangeString(r interface{ MarshalText() ([]byte, error) }) string {
+	// MarshalText of time interval ranges never returns an error
+	b, _ := r.MarshalText()
+	return string(b)
+}
+
+func minutesToTime(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+func durationString(d *model.Duration) string {
+	if d == nil {
+		return ""
+	}
+	return d.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	amConfig "github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestHCLFromAlertingFileExport(t *testing.T) {
+	groupWait := model.Duration(30 * time.Second)
+	repeatInterval := model.Duration(4 * time.Hour)
+	matcher, err := labels.NewMatcher(labels.MatchNotEqual, "team", "ops")
+	require.NoError(t, err)
+	location, err := time.LoadLocation("UTC")
+	require.NoError(t, err)
+
+	export := definitions.AlertingFileExport{
+		APIVersion: 1,
+		Groups: []definitions.AlertRuleGroupExport{{
+			OrgID:     1,
+			Name:      "my group",
+			Folder:    "My Folder",
+			FolderUID: "folder-uid",
+			Interval:  model.Duration(time.Minute),
+			Rules: []definitions.AlertRuleExport{{
+				UID:       "rule-uid",
+				Title:     "High CPU",
+				Condition: "B",
+				Data: []definitions.AlertQueryExport{{
+					RefID:             "A",
+					RelativeTimeRange: definitions.RelativeTimeRange{From: definitions.Duration(10 * time.Minute)},
+					DatasourceUID:     "prometheus",
+					Model:             map[string]interface{}{"expr": "cpu > 0.9", "refId": "A"},
+				}},
+				NoDataState:  definitions.OK,
+				ExecErrState: definitions.ErrorErrState,
+				For:          model.Duration(5 * time.Minute),
+				Labels:       map[string]string{"severity": "critical"},
+			}},
+		}},
+		ContactPoints: []definitions.ContactPointExport{{
+			OrgID: 1,
+			Name:  "ops team",
+			Receivers: []definitions.ReceiverExport{
+				{
+					Type:     "email",
+					Settings: definitions.RawMessage(`{"addresses":"a@example.com;b@example.com","singleEmail":true}`),
+				},
+				{
+					Type:                  "prometheus-alertmanager",
+					Settings:              definitions.RawMessage(`{"basicAuthUser":"user","url":"http://localhost:9093"}`),
+					DisableResolveMessage: true,
+				},
+				{
+					Type:     "webhook",
+					Settings: definitions.RawMessage(`{"httpMethod":"POST","password":"[REDACTED]","url":"http://localhost","username":"user"}`),
+				},
+				{
+					Type:     "LINE",
+					Settings: definitions.RawMessage(`{"token":"[REDACTED]","custom":{"a":1}}`),
+				},
+			},
+		}},
+		Policies: []definitions.NotificationPolicyExport{{
+			OrgID: 1,
+			Policy: &definitions.RouteExport{
+				Receiver:       "ops team",
+				GroupByStr:     []string{"alertname"},
+				GroupWait:      &groupWait,
+				RepeatInterval: &repeatInterval,
+				Routes: []*definitions.RouteExport{{
+					Receiver:          "ops team",
+					ObjectMatchers:    definitions.ObjectMatchers{matcher},
+					Match:             map[string]string{"severity": "critical"},
+					MuteTimeIntervals: []string{"weekends"},
+					Continue:          true,
+				}},
+			},
+		}},
+		MuteTimings: []definitions.MuteTimeIntervalExport{{
+			OrgID: 1,
+			MuteTimeInterval: amConfig.MuteTimeInterval{
+				Name: "weekends",
+				TimeIntervals: []timeinterval.TimeInterval{{
+					Times:    []timeinterval.TimeRange{{StartMinute: 60, EndMinute: 1439}},
+					Weekdays: []timeinterval.WeekdayRange{{InclusiveRange: timeinterval.InclusiveRange{Begin: 6, End: 6}}, {InclusiveRange: timeinterval.InclusiveRange{Begin: 0, End: 0}}},
+					Months:   []timeinterval.MonthRange{{InclusiveRange: timeinterval.InclusiveRange{Begin: 1, End: 3}}},
+					Location: &timeinterval.Location{Location: location},
+				}},
+			},
+		}},
+		Templates: []definitions.NotificationTemplateExport{{
+			OrgID:    1,
+			Name:     "my template",
+			Template: `{{ define "my template" }}${ .Status }{{ end }}`,
+		}},
+	}
+
+	result, err := HCLFromAlertingFileExport(export)
+	require.NoError(t, err)
+
+	expected := `resource "grafana_rule_group" "my_folder_my_group" {
+  name             = "my group"
+  folder_uid       = "folder-uid"
+  interval_seconds = 60
+  rule {
+    name           = "High CPU"
+    condition      = "B"
+    no_data_state  = "OK"
+    exec_err_state = "Error"
+    for            = "5m"
+    is_paused      = false
+    labels = {
+      severity = "critical"
+    }
+    data {
+      ref_id         = "A"
+      datasource_uid = "prometheus"
+      model = jsonencode({
+        expr  = "cpu > 0.9"
+        refId = "A"
+      })
+      relative_time_range {
+        from = 600
+        to   = 0
+      }
+    }
+  }
+}
+
+resource "grafana_contact_point" "ops_team" {
+  name = "ops team"
+  email {
+    addresses    = ["a@example.com", "b@example.com"]
+    single_email = true
+  }
+  alertmanager {
+    basic_auth_user         = "user"
+    url                     = "http://localhost:9093"
+    disable_resolve_message = true
+  }
+  webhook {
+    basic_auth_password = var.ops_team_webhook_basic_auth_password
+    basic_auth_user     = "user"
+    http_method         = "POST"
+    url                 = "http://localhost"
+  }
+  line {
+    token = var.ops_team_line_token
+    settings = {
+      "custom" = jsonencode({
+        a = 1
+      })
+    }
+  }
+}
+
+variable "ops_team_webhook_basic_auth_password" {
+  type      = string
+  sensitive = true
+}
+
+variable "ops_team_line_token" {
+  type      = string
+  sensitive = true
+}
+
+resource "grafana_notification_policy" "notification_policy" {
+  contact_point   = "ops team"
+  group_by        = ["alertname"]
+  group_wait      = "30s"
+  repeat_interval = "4h"
+  policy {
+    contact_point = "ops team"
+    continue      = true
+    mute_timings  = ["weekends"]
+    matcher {
+      label = "team"
+      match = "!="
+      value = "ops"
+    }
+    matcher {
+      label = "severity"
+      match = "="
+      value = "critical"
+    }
+  }
+}
+
+resource "grafana_mute_timing" "weekends" {
+  name = "weekends"
+  intervals {
+    times {
+      start = "01:00"
+      end   = "23:59"
+    }
+    weekdays = ["saturday", "sunday"]
+    months   = ["1:3"]
+    location = "UTC"
+  }
+}
+
+resource "grafana_message_template" "my_template" {
+  name     = "my template"
+  template = "{{ define \"my template\" }}$${ .Status }{{ end }}"
+}
+`
+	require.Equal(t, expected, string(result))
+}
+
+func TestHCLFromAlertingFileExport_UniqueNames(t *testing.T) {
+	export := definitions.AlertingFileExport{
+		Templates: []definitions.NotificationTemplateExport{
+			{Name: "my template"},
+			{Name: "my_template"},
+		},
+	}
+
+	result, err := HCLFromAlertingFileExport(export)
+	require.NoError(t, err)
+	require.Contains(t, string(result), `resource "grafana_message_template" "my_template" {`)
+	require.Contains(t, string(result), `resource "grafana_message_template" "my_template_2" {`)
+}
+
+func TestHCLFromAlertingFileExport_UnsupportedIntegration(t *testing.T) {
+	export := definitions.AlertingFileExport{
+		ContactPoints: []definitions.ContactPointExport{{
+			Name:      "my contact point",
+			Receivers: []definitions.ReceiverExport{{Type: "unknown"}},
+		}},
+	}
+
+	_, err := HCLFromAlertingFileExport(export)
+	require.ErrorContains(t, err, "integration 'unknown' is not supported")
+}
+
+func TestHCLIdentifier(t *testing.T) {
+	testCases := map[string]string{
+		"my_group":     "my_group",
+		"My Group":     "my_group",
+		"group-1":      "group-1",
+		"1st group":    "_1st_group",
+		"":             "_",
+		"grüppe/alert": "gr_ppe_alert",
+	}
+	for in, expected := range testCases {
+		require.Equal(t, expected, hclIdentifier(in), in)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
 var (
@@ -28,7 +29,7 @@ func errorToResponse(err error) response.Response {
 	if errors.Is(err, datasources.ErrDataSourceNotFound) {
 		return ErrResp(404, err, "")
 	}
-	if errors.Is(err, errUnexpectedDatasourceType) {
+	if errors.Is(err, errUnexpectedDatasourceType) || errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) {
 		return ErrResp(400, err, "")
 	}
 	if errors.Is(err, ErrAuthorization) {
@@ -186,6 +186,10 @@ func (f *AlertmanagerApiHandler) handleRouteGetGrafanaReceivers(ctx *contextmode
 	return f.GrafanaSvc.RouteGetReceivers(ctx)
 }
 
+func (f *AlertmanagerApiHandler) handleRouteGetGrafanaReceiverNotifications(ctx *contextmodel.ReqContext, receiver string) response.Response {
+	return f.GrafanaSvc.RouteGetReceiverNotifications(ctx, receiver)
+}
+
 func (f *AlertmanagerApiHandler) handleRoutePostTestGrafanaReceivers(ctx *contextmodel.ReqContext, conf apimodels.TestReceiversConfigBodyParams) response.Response {
 	return f.GrafanaSvc.RoutePostTestReceivers(ctx, conf)
 }
@@ -193,3 +197,31 @@ func (f *AlertmanagerApiHandler) handleRoutePostTestGrafanaReceivers(ctx *contex
 func (f *AlertmanagerApiHandler) handleRoutePostTestGrafanaTemplates(ctx *contextmodel.ReqContext, conf apimodels.TestTemplatesConfigBodyParams) response.Response {
 	return f.GrafanaSvc.RoutePostTestTemplates(ctx, conf)
 }
+
+func (f *AlertmanagerApiHandler) handleRoutePostGrafanaSilencesPreview(ctx *contextmodel.ReqContext, query apimodels.SilencePreviewQuery) response.Response {
+	return f.GrafanaSvc.RoutePreviewSilence(ctx, query)
+}
+
+func (f *AlertmanagerApiHandler) handleRoutePostGrafanaSilencesBulk(ctx *contextmodel.ReqContext, silences []apimodels.PostableSilence) response.Response {
+	return f.GrafanaSvc.RouteCreateSilences(ctx, silences)
+}
+
+func (f *AlertmanagerApiHandler) handleRoutePostGrafanaSilencesExpire(ctx *contextmodel.ReqContext, query apimodels.ExpireSilencesQuery) response.Response {
+	return f.GrafanaSvc.RouteExpireSilences(ctx, query)
+}
+
+func (f *AlertmanagerApiHandler) handleRouteGetGrafanaSilenceTemplates(ctx *contextmodel.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteGetSilenceTemplates(ctx)
+}
+
+func (f *AlertmanagerApiHandler) handleRoutePostGrafanaSilenceTemplate(ctx *contextmodel.ReqContext, template apimodels.SilenceTemplate) response.Response {
+	return f.GrafanaSvc.RoutePostSilenceTemplate(ctx, template)
+}
+
+func (f *AlertmanagerApiHandler) handleRouteDeleteGrafanaSilenceTemplate(ctx *contextmodel.ReqContext, name string) response.Response {
+	return f.GrafanaSvc.RouteDeleteSilenceTemplate(ctx, name)
+}
+
+func (f *AlertmanagerApiHandler) handleRoutePostGrafanaSilenceTemplateApply(ctx *contextmodel.ReqContext, name string) response.Response {
+	return f.GrafanaSvc.RouteApplySilenceTemplate(ctx, name)
+}
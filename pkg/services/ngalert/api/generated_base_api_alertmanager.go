@@ -24,6 +24,7 @@ type AlertmanagerApi interface {
 	RouteDeleteAlertingConfig(*contextmodel.ReqContext) response.Response
 	RouteDeleteGrafanaAlertingConfig(*contextmodel.ReqContext) response.Response
 	RouteDeleteGrafanaSilence(*contextmodel.ReqContext) response.Response
+	RouteDeleteGrafanaSilenceTemplate(*contextmodel.ReqContext) response.Response
 	RouteDeleteSilence(*contextmodel.ReqContext) response.Response
 	RouteGetAMAlertGroups(*contextmodel.ReqContext) response.Response
 	RouteGetAMAlerts(*contextmodel.ReqContext) response.Response
@@ -34,8 +35,10 @@ type AlertmanagerApi interface {
 	RouteGetGrafanaAMStatus(*contextmodel.ReqContext) response.Response
 	RouteGetGrafanaAlertingConfig(*contextmodel.ReqContext) response.Response
 	RouteGetGrafanaAlertingConfigHistory(*contextmodel.ReqContext) response.Response
+	RouteGetGrafanaReceiverNotifications(*contextmodel.ReqContext) response.Response
 	RouteGetGrafanaReceivers(*contextmodel.ReqContext) response.Response
 	RouteGetGrafanaSilence(*contextmodel.ReqContext) response.Response
+	RouteGetGrafanaSilenceTemplates(*contextmodel.ReqContext) response.Response
 	RouteGetGrafanaSilences(*contextmodel.ReqContext) response.Response
 	RouteGetSilence(*contextmodel.ReqContext) response.Response
 	RouteGetSilences(*contextmodel.ReqContext) response.Response
@@ -43,6 +46,11 @@ type AlertmanagerApi interface {
 	RoutePostAlertingConfig(*contextmodel.ReqContext) response.Response
 	RoutePostGrafanaAlertingConfig(*contextmodel.ReqContext) response.Response
 	RoutePostGrafanaAlertingConfigHistoryActivate(*contextmodel.ReqContext) response.Response
+	RoutePostGrafanaSilenceTemplate(*contextmodel.ReqContext) response.Response
+	RoutePostGrafanaSilenceTemplateApply(*contextmodel.ReqContext) response.Response
+	RoutePostGrafanaSilencesBulk(*contextmodel.ReqContext) response.Response
+	RoutePostGrafanaSilencesExpire(*contextmodel.ReqContext) response.Response
+	RoutePostGrafanaSilencesPreview(*contextmodel.ReqContext) response.Response
 	RoutePostTestGrafanaReceivers(*contextmodel.ReqContext) response.Response
 	RoutePostTestGrafanaTemplates(*contextmodel.ReqContext) response.Response
 }
@@ -78,6 +86,11 @@ func (f *AlertmanagerApiHandler) RouteDeleteGrafanaSilence(ctx *contextmodel.Req
 	silenceIdParam := web.Params(ctx.Req)[":SilenceId"]
 	return f.handleRouteDeleteGrafanaSilence(ctx, silenceIdParam)
 }
+func (f *AlertmanagerApiHandler) RouteDeleteGrafanaSilenceTemplate(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	nameParam := web.Params(ctx.Req)[":Name"]
+	return f.handleRouteDeleteGrafanaSilenceTemplate(ctx, nameParam)
+}
 func (f *AlertmanagerApiHandler) RouteDeleteSilence(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	silenceIdParam := web.Params(ctx.Req)[":SilenceId"]
@@ -119,6 +132,11 @@ func (f *AlertmanagerApiHandler) RouteGetGrafanaAlertingConfig(ctx *contextmodel
 func (f *AlertmanagerApiHandler) RouteGetGrafanaAlertingConfigHistory(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetGrafanaAlertingConfigHistory(ctx)
 }
+func (f *AlertmanagerApiHandler) RouteGetGrafanaReceiverNotifications(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	receiverParam := web.Params(ctx.Req)[":Receiver"]
+	return f.handleRouteGetGrafanaReceiverNotifications(ctx, receiverParam)
+}
 func (f *AlertmanagerApiHandler) RouteGetGrafanaReceivers(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetGrafanaReceivers(ctx)
 }
@@ -127,6 +145,9 @@ func (f *AlertmanagerApiHandler) RouteGetGrafanaSilence(ctx *contextmodel.ReqCon
 	silenceIdParam := web.Params(ctx.Req)[":SilenceId"]
 	return f.handleRouteGetGrafanaSilence(ctx, silenceIdParam)
 }
+func (f *AlertmanagerApiHandler) RouteGetGrafanaSilenceTemplates(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetGrafanaSilenceTemplates(ctx)
+}
 func (f *AlertmanagerApiHandler) RouteGetGrafanaSilences(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetGrafanaSilences(ctx)
 }
@@ -174,6 +195,43 @@ func (f *AlertmanagerApiHandler) RoutePostGrafanaAlertingConfigHistoryActivate(c
 	idParam := web.Params(ctx.Req)[":id"]
 	return f.handleRoutePostGrafanaAlertingConfigHistoryActivate(ctx, idParam)
 }
+func (f *AlertmanagerApiHandler) RoutePostGrafanaSilenceTemplate(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.SilenceTemplate{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePostGrafanaSilenceTemplate(ctx, conf)
+}
+func (f *AlertmanagerApiHandler) RoutePostGrafanaSilenceTemplateApply(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	nameParam := web.Params(ctx.Req)[":Name"]
+	return f.handleRoutePostGrafanaSilenceTemplateApply(ctx, nameParam)
+}
+func (f *AlertmanagerApiHandler) RoutePostGrafanaSilencesBulk(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := []apimodels.PostableSilence{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePostGrafanaSilencesBulk(ctx, conf)
+}
+func (f *AlertmanagerApiHandler) RoutePostGrafanaSilencesExpire(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.ExpireSilencesQuery{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePostGrafanaSilencesExpire(ctx, conf)
+}
+func (f *AlertmanagerApiHandler) RoutePostGrafanaSilencesPreview(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.SilencePreviewQuery{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePostGrafanaSilencesPreview(ctx, conf)
+}
 func (f *AlertmanagerApiHandler) RoutePostTestGrafanaReceivers(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Request Body
 	conf := apimodels.TestReceiversConfigBodyParams{}
@@ -193,6 +251,76 @@ func (f *AlertmanagerApiHandler) RoutePostTestGrafanaTemplates(ctx *contextmodel
 
 func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApi, m *metrics.API) {
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/silences/preview"),
+			api.authorize(http.MethodPost, "/api/alertmanager/grafana/api/v2/silences/preview"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/api/v2/silences/preview",
+				api.Hooks.Wrap(srv.RoutePostGrafanaSilencesPreview),
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/silences/bulk"),
+			api.authorize(http.MethodPost, "/api/alertmanager/grafana/api/v2/silences/bulk"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/api/v2/silences/bulk",
+				api.Hooks.Wrap(srv.RoutePostGrafanaSilencesBulk),
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/silences/expire"),
+			api.authorize(http.MethodPost, "/api/alertmanager/grafana/api/v2/silences/expire"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/api/v2/silences/expire",
+				api.Hooks.Wrap(srv.RoutePostGrafanaSilencesExpire),
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/silences/templates"),
+			api.authorize(http.MethodGet, "/api/alertmanager/grafana/api/v2/silences/templates"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/grafana/api/v2/silences/templates",
+				api.Hooks.Wrap(srv.RouteGetGrafanaSilenceTemplates),
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/silences/templates"),
+			api.authorize(http.MethodPost, "/api/alertmanager/grafana/api/v2/silences/templates"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/api/v2/silences/templates",
+				api.Hooks.Wrap(srv.RoutePostGrafanaSilenceTemplate),
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/silences/templates/{Name}"),
+			api.authorize(http.MethodDelete, "/api/alertmanager/grafana/api/v2/silences/templates/{Name}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/alertmanager/grafana/api/v2/silences/templates/{Name}",
+				api.Hooks.Wrap(srv.RouteDeleteGrafanaSilenceTemplate),
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/silences/templates/{Name}/apply"),
+			api.authorize(http.MethodPost, "/api/alertmanager/grafana/api/v2/silences/templates/{Name}/apply"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/api/v2/silences/templates/{Name}/apply",
+				api.Hooks.Wrap(srv.RoutePostGrafanaSilenceTemplateApply),
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/alertmanager/grafana/api/v2/silences"),
 			api.authorize(http.MethodPost, "/api/alertmanager/grafana/api/v2/silences"),
@@ -343,6 +471,16 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApi, m *metrics
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/grafana/config/api/v1/receivers/{Receiver}/notifications"),
+			api.authorize(http.MethodGet, "/api/alertmanager/grafana/config/api/v1/receivers/{Receiver}/notifications"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/grafana/config/api/v1/receivers/{Receiver}/notifications",
+				api.Hooks.Wrap(srv.RouteGetGrafanaReceiverNotifications),
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/alertmanager/grafana/config/api/v1/receivers"),
 			api.authorize(http.MethodGet, "/api/alertmanager/grafana/config/api/v1/receivers"),
@@ -18,11 +18,15 @@ import (
 
 type HistoryApi interface {
 	RouteGetStateHistory(*contextmodel.ReqContext) response.Response
+	RouteGetStateHistoryStats(*contextmodel.ReqContext) response.Response
 }
 
 func (f *HistoryApiHandler) RouteGetStateHistory(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetStateHistory(ctx)
 }
+func (f *HistoryApiHandler) RouteGetStateHistoryStats(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetStateHistoryStats(ctx)
+}
 
 func (api *API) RegisterHistoryApiEndpoints(srv HistoryApi, m *metrics.API) {
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
@@ -36,5 +40,15 @@ func (api *API) RegisterHistoryApiEndpoints(srv HistoryApi, m *metrics.API) {
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/rules/history/stats"),
+			api.authorize(http.MethodGet, "/api/v1/rules/history/stats"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/rules/history/stats",
+				api.Hooks.Wrap(srv.RouteGetStateHistoryStats),
+				m,
+			),
+		)
 	}, middleware.ReqSignedIn)
 }
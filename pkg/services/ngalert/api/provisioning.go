@@ -52,6 +52,10 @@ func (f *ProvisioningApiHandler) handleRouteGetTemplates(ctx *contextmodel.ReqCo
 	return f.svc.RouteGetTemplates(ctx)
 }
 
+func (f *ProvisioningApiHandler) handleRouteGetTemplatesExport(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteGetTemplatesExport(ctx)
+}
+
 func (f *ProvisioningApiHandler) handleRouteGetTemplate(ctx *contextmodel.ReqContext, name string) response.Response {
 	return f.svc.RouteGetTemplate(ctx, name)
 }
@@ -72,6 +76,10 @@ func (f *ProvisioningApiHandler) handleRouteGetMuteTimings(ctx *contextmodel.Req
 	return f.svc.RouteGetMuteTimings(ctx)
 }
 
+func (f *ProvisioningApiHandler) handleRouteGetMuteTimingsExport(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteGetMuteTimingsExport(ctx)
+}
+
 func (f *ProvisioningApiHandler) handleRoutePostMuteTiming(ctx *contextmodel.ReqContext, mt apimodels.MuteTimeInterval) response.Response {
 	return f.svc.RoutePostMuteTiming(ctx, mt)
 }
@@ -124,6 +132,10 @@ func (f *ProvisioningApiHandler) handleRouteGetAlertRuleGroupExport(ctx *context
 	return f.svc.RouteGetAlertRuleGroupExport(ctx, folder, group)
 }
 
+func (f *ProvisioningApiHandler) handleRoutePostImportPrometheusRuleGroups(ctx *contextmodel.ReqContext, folderUID string) response.Response {
+	return f.svc.RoutePostImportPrometheusRuleGroups(ctx, folderUID)
+}
+
 func (f *ProvisioningApiHandler) handleRoutePutAlertRuleGroup(ctx *contextmodel.ReqContext, ag apimodels.AlertRuleGroup, folder, group string) response.Response {
 	return f.svc.RoutePutAlertRuleGroup(ctx, ag, folder, group)
 }
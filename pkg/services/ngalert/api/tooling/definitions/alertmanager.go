@@ -151,6 +151,14 @@ import (
 //     Responses:
 //       200: receiversResponse
 
+// swagger:route GET /api/alertmanager/grafana/config/api/v1/receivers/{Receiver}/notifications alertmanager RouteGetGrafanaReceiverNotifications
+//
+// Get the most recent attempts of a receiver to deliver notifications
+//
+//     Responses:
+//       200: notificationAttemptsResponse
+//       404: NotFound
+
 // swagger:route POST /api/alertmanager/grafana/config/api/v1/receivers/test alertmanager RoutePostTestGrafanaReceivers
 //
 // Test Grafana managed receivers without saving them.
@@ -246,6 +254,63 @@ import (
 //       400: ValidationError
 //       404: NotFound
 
+// swagger:route POST /api/alertmanager/grafana/api/v2/silences/preview alertmanager RoutePostGrafanaSilencesPreview
+//
+// Get the firing alerts and the alert rules that a silence would mute
+//
+//     Responses:
+//       200: silencePreview
+//       400: ValidationError
+
+// swagger:route POST /api/alertmanager/grafana/api/v2/silences/bulk alertmanager RoutePostGrafanaSilencesBulk
+//
+// Create or update multiple silences. If one of the silences cannot be created, none is created.
+//
+//     Responses:
+//       202: postSilencesBulkOKBody
+//       400: ValidationError
+//       404: NotFound
+
+// swagger:route POST /api/alertmanager/grafana/api/v2/silences/expire alertmanager RoutePostGrafanaSilencesExpire
+//
+// Expire all active and pending silences that have all the given matchers
+//
+//     Responses:
+//       200: postSilencesBulkOKBody
+//       400: ValidationError
+
+// swagger:route GET /api/alertmanager/grafana/api/v2/silences/templates alertmanager RouteGetGrafanaSilenceTemplates
+//
+// Get all silence templates
+//
+//     Responses:
+//       200: silenceTemplates
+
+// swagger:route POST /api/alertmanager/grafana/api/v2/silences/templates alertmanager RoutePostGrafanaSilenceTemplate
+//
+// Create a silence template or replace the template with the same name
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+
+// swagger:route DELETE /api/alertmanager/grafana/api/v2/silences/templates/{Name} alertmanager RouteDeleteGrafanaSilenceTemplate
+//
+// Delete a silence template
+//
+//     Responses:
+//       200: Ack
+//       404: NotFound
+
+// swagger:route POST /api/alertmanager/grafana/api/v2/silences/templates/{Name}/apply alertmanager RoutePostGrafanaSilenceTemplateApply
+//
+// Create a silence from a silence template that is active the next time the mute timing of the template is active
+//
+//     Responses:
+//       202: gettableSilence
+//       400: ValidationError
+//       404: NotFound
+
 // swagger:model
 type PermissionDenied struct{}
 
@@ -361,6 +426,36 @@ type GetDeleteSilenceParams struct {
 	SilenceId string
 }
 
+// swagger:parameters RoutePostGrafanaSilencesPreview
+type PreviewSilenceParams struct {
+	// in:body
+	Body SilencePreviewQuery
+}
+
+// swagger:parameters RoutePostGrafanaSilencesBulk
+type CreateSilencesParams struct {
+	// in:body
+	Silences []PostableSilence
+}
+
+// swagger:parameters RoutePostGrafanaSilencesExpire
+type ExpireSilencesParams struct {
+	// in:body
+	Body ExpireSilencesQuery
+}
+
+// swagger:parameters RoutePostGrafanaSilenceTemplate
+type PostSilenceTemplateParams struct {
+	// in:body
+	Body SilenceTemplate
+}
+
+// swagger:parameters RouteDeleteGrafanaSilenceTemplate RoutePostGrafanaSilenceTemplateApply
+type SilenceTemplateNameParams struct {
+	// in:path
+	Name string
+}
+
 // swagger:parameters RouteGetSilences RouteGetGrafanaSilences
 type GetSilencesParams struct {
 	// in:query
@@ -449,6 +544,102 @@ type PostSilencesOKBody struct { // vendored from "github.com/prometheus/alertma
 	SilenceID string `json:"silenceID,omitempty"`
 }
 
+// swagger:model postSilencesBulkOKBody
+type PostSilencesBulkOKBody struct {
+	SilenceIDs []string `json:"silenceIDs"`
+}
+
+// swagger:model
+type SilencePreviewQuery struct {
+	// Required: true
+	Matchers amv2.Matchers `json:"matchers"`
+
+	// Required: true
+	StartsAt *strfmt.DateTime `json:"startsAt"`
+
+	// Required: true
+	EndsAt *strfmt.DateTime `json:"endsAt"`
+}
+
+// Validate validates the matchers and the time range of the silence.
+func (q *SilencePreviewQuery) Validate() error {
+	if len(q.Matchers) == 0 {
+		return fmt.Errorf("at least one matcher is required")
+	}
+	if err := q.Matchers.Validate(strfmt.Default); err != nil {
+		return err
+	}
+	if q.StartsAt == nil || q.EndsAt == nil {
+		return fmt.Errorf("startsAt and endsAt are required")
+	}
+	if !time.Time(*q.StartsAt).Before(time.Time(*q.EndsAt)) {
+		return fmt.Errorf("start time must be before end time")
+	}
+	return nil
+}
+
+// swagger:model silencePreview
+type SilencePreview struct {
+	// The alerts in the Alertmanager that the silence would mute.
+	Alerts GettableAlerts `json:"alerts"`
+	// The alert rules with alert instances that the silence would mute.
+	Rules []SilencePreviewRule `json:"rules"`
+}
+
+// swagger:model
+type SilencePreviewRule struct {
+	UID    string `json:"uid"`
+	Title  string `json:"title"`
+	Folder string `json:"folder"`
+	// The number of alert instances of the rule that the silence would mute.
+	Instances int `json:"instances"`
+	// The number of firing alert instances of the rule that the silence would mute.
+	Firing int `json:"firing"`
+}
+
+// swagger:model
+type ExpireSilencesQuery struct {
+	// Required: true
+	Matchers amv2.Matchers `json:"matchers"`
+}
+
+// SilenceTemplate is a set of matchers that is silenced whenever the mute timing is active, for example during the
+// planned maintenance of a cluster.
+// swagger:model
+type SilenceTemplate struct {
+	// Required: true
+	Name string `json:"name"`
+
+	// Required: true
+	Matchers amv2.Matchers `json:"matchers"`
+
+	// The name of the mute timing that defines when the silence is active.
+	// Required: true
+	MuteTiming string `json:"muteTiming"`
+
+	Comment string `json:"comment,omitempty"`
+}
+
+// Validate validates the name and the matchers of the template.
+func (t *SilenceTemplate) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if t.MuteTiming == "" {
+		return fmt.Errorf("muteTiming is required")
+	}
+	if len(t.Matchers) == 0 {
+		return fmt.Errorf("at least one matcher is required")
+	}
+	return t.Matchers.Validate(strfmt.Default)
+}
+
+// swagger:response silenceTemplates
+type SilenceTemplates struct {
+	// in:body
+	Body []SilenceTemplate
+}
+
 // swagger:model gettableSilences
 type GettableSilences = amv2.GettableSilences
 
@@ -468,16 +659,76 @@ type AlertGroups = amv2.AlertGroups
 type AlertGroup = amv2.AlertGroup
 
 // swagger:model receiver
-type Receiver = amv2.Receiver
+type Receiver struct {
+	// active
+	// Required: true
+	Active *bool `json:"active"`
+
+	// integrations
+	// Required: true
+	Integrations []*Integration `json:"integrations"`
+
+	// name
+	// Required: true
+	Name *string `json:"name"`
+}
 
 // swagger:response receiversResponse
 type ReceiversResponse struct {
 	// in:body
-	Body []amv2.Receiver
+	Body []Receiver
 }
 
 // swagger:model integration
-type Integration = amv2.Integration
+type Integration struct {
+	amv2.Integration
+
+	// The time of the last successful attempt to deliver a notification.
+	LastNotifySuccess strfmt.DateTime `json:"lastNotifySuccess,omitempty"`
+
+	// The time of the last failed attempt to deliver a notification.
+	LastNotifyFailure strfmt.DateTime `json:"lastNotifyFailure,omitempty"`
+
+	// The error of the last failed attempt to deliver a notification.
+	LastNotifyFailureError string `json:"lastNotifyFailureError,omitempty"`
+}
+
+// swagger:parameters RouteGetGrafanaReceiverNotifications
+type RouteGetGrafanaReceiverNotificationsParams struct {
+	// in:path
+	Receiver string
+
+	// Limit response to the n most recent attempts.
+	// in:query
+	Limit int `json:"limit"`
+}
+
+// swagger:response notificationAttemptsResponse
+type NotificationAttemptsResponse struct {
+	// in:body
+	Body []NotificationAttempt
+}
+
+// NotificationAttempt is an attempt of an integration of a receiver to deliver a notification for a group of alerts.
+// swagger:model
+type NotificationAttempt struct {
+	Receiver         string          `json:"receiver"`
+	Integration      string          `json:"integration"`
+	IntegrationUID   string          `json:"integrationUid,omitempty"`
+	IntegrationIndex int             `json:"integrationIndex"`
+	GroupKey         string          `json:"groupKey"`
+	FiringAlerts     int             `json:"firingAlerts"`
+	ResolvedAlerts   int             `json:"resolvedAlerts"`
+	Timestamp        strfmt.DateTime `json:"timestamp"`
+	Duration         string          `json:"duration"`
+	// The number of previous attempts to deliver the same notification.
+	Retry int `json:"retry"`
+	// The HTTP status code of the response. It is set only for integrations that send webhooks.
+	StatusCode int `json:"statusCode,omitempty"`
+	// The body of the request, truncated to 4KiB. It is set only for integrations that send webhooks.
+	Payload string `json:"payload,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
 
 // swagger:parameters RouteGetAMAlerts RouteGetAMAlertGroups RouteGetGrafanaAMAlerts RouteGetGrafanaAMAlertGroups
 type AlertsParams struct {
@@ -169,6 +169,9 @@ type PostableRuleGroupConfig struct {
 	Name     string                     `yaml:"name" json:"name"`
 	Interval model.Duration             `yaml:"interval,omitempty" json:"interval,omitempty"`
 	Rules    []PostableExtendedRuleNode `yaml:"rules" json:"rules"`
+	// DisableEvaluationOffset makes Grafana managed rules of the group be evaluated at the beginning of the interval
+	// instead of being spread across the interval.
+	DisableEvaluationOffset bool `yaml:"disable_evaluation_offset,omitempty" json:"disable_evaluation_offset,omitempty"`
 }
 
 func (c *PostableRuleGroupConfig) UnmarshalJSON(b []byte) error {
@@ -216,6 +219,9 @@ type GettableRuleGroupConfig struct {
 	Interval      model.Duration             `yaml:"interval,omitempty" json:"interval,omitempty"`
 	SourceTenants []string                   `yaml:"source_tenants,omitempty" json:"source_tenants,omitempty"`
 	Rules         []GettableExtendedRuleNode `yaml:"rules" json:"rules"`
+	// DisableEvaluationOffset makes Grafana managed rules of the group be evaluated at the beginning of the interval
+	// instead of being spread across the interval.
+	DisableEvaluationOffset bool `yaml:"disable_evaluation_offset,omitempty" json:"disable_evaluation_offset,omitempty"`
 }
 
 func (c *GettableRuleGroupConfig) UnmarshalJSON(b []byte) error {
@@ -373,6 +379,9 @@ type PostableGrafanaRule struct {
 	NoDataState  NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
 	IsPaused     *bool               `json:"is_paused" yaml:"is_paused"`
+	// Record makes the rule a recording rule. Recording rules do not create alerts but write the result of the
+	// query or expression Record.From as a metric Record.Metric.
+	Record *Record `json:"record,omitempty" yaml:"record,omitempty"`
 }
 
 // swagger:model
@@ -393,6 +402,20 @@ type GettableGrafanaRule struct {
 	ExecErrState    ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
 	Provenance      Provenance          `json:"provenance,omitempty" yaml:"provenance,omitempty"`
 	IsPaused        bool                `json:"is_paused" yaml:"is_paused"`
+	Record          *Record             `json:"record,omitempty" yaml:"record,omitempty"`
+}
+
+// Record contains the fields of a recording rule.
+// swagger:model
+type Record struct {
+	// Name of the metric the result is written to. Must be a valid Prometheus metric name.
+	// required: true
+	// example: grafana:cpu_usage:avg5m
+	Metric string `json:"metric" yaml:"metric"`
+	// RefID of the query or expression whose result is written.
+	// required: true
+	// example: A
+	From string `json:"from" yaml:"from"`
 }
 
 // AlertQuery represents a single query associated with an alert definition.
@@ -156,6 +156,8 @@ type Rule struct {
 	Type           v1.RuleType `json:"type"`
 	LastEvaluation time.Time   `json:"lastEvaluation"`
 	EvaluationTime float64     `json:"evaluationTime"`
+	// EvaluationOffset is the number of seconds from the beginning of the evaluation interval at which a Grafana managed rule is evaluated.
+	EvaluationOffset float64 `json:"evaluationOffset,omitempty"`
 }
 
 // Alert has info for an alert.
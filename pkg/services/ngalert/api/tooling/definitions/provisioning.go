@@ -3,13 +3,15 @@ package definitions
 // AlertingFileExport is the full provisioned file export.
 // swagger:model
 type AlertingFileExport struct {
-	APIVersion    int64                      `json:"apiVersion" yaml:"apiVersion"`
-	Groups        []AlertRuleGroupExport     `json:"groups,omitempty" yaml:"groups,omitempty"`
-	ContactPoints []ContactPointExport       `json:"contactPoints,omitempty" yaml:"contactPoints,omitempty"`
-	Policies      []NotificationPolicyExport `json:"policies,omitempty" yaml:"policies,omitempty"`
+	APIVersion    int64                        `json:"apiVersion" yaml:"apiVersion"`
+	Groups        []AlertRuleGroupExport       `json:"groups,omitempty" yaml:"groups,omitempty"`
+	ContactPoints []ContactPointExport         `json:"contactPoints,omitempty" yaml:"contactPoints,omitempty"`
+	Policies      []NotificationPolicyExport   `json:"policies,omitempty" yaml:"policies,omitempty"`
+	MuteTimings   []MuteTimeIntervalExport     `json:"muteTimes,omitempty" yaml:"muteTimes,omitempty"`
+	Templates     []NotificationTemplateExport `json:"templates,omitempty" yaml:"templates,omitempty"`
 }
 
-// swagger:parameters RouteGetAlertRuleGroupExport RouteGetAlertRuleExport RouteGetAlertRulesExport RouteGetContactpointsExport RouteGetContactpointExport
+// swagger:parameters RouteGetAlertRuleGroupExport RouteGetAlertRuleExport RouteGetAlertRulesExport RouteGetContactpointsExport RouteGetContactpointExport RouteGetPolicyTreeExport RouteGetMuteTimingsExport RouteGetTemplatesExport
 type ExportQueryParams struct {
 	// Whether to initiate a download of the file or not.
 	// in: query
@@ -17,7 +19,8 @@ type ExportQueryParams struct {
 	// default: false
 	Download bool `json:"download"`
 
-	// Format of the downloaded file, either yaml or json. Accept header can also be used, but the query parameter will take precedence.
+	// Format of the downloaded file, either yaml, json or hcl. The hcl format contains Terraform resources of the Grafana provider.
+	// Accept header can also be used, but the query parameter will take precedence.
 	// in: query
 	// required: false
 	// default: yaml
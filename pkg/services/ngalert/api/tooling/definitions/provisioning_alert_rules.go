@@ -167,7 +167,21 @@ type ProvisionedAlertRule struct {
 //       200: AlertRuleGroup
 //       400: ValidationError
 
-// swagger:parameters RouteGetAlertRuleGroup RoutePutAlertRuleGroup RouteGetAlertRuleGroupExport
+// swagger:route POST /api/v1/provisioning/folder/{FolderUID}/import/prometheus provisioning stable RoutePostImportPrometheusRuleGroups
+//
+// Convert the rule groups of a Prometheus rule file to Grafana managed alert rules and save them in the folder.
+// Existing groups with the same names are replaced. The rules are marked with the provenance "converted_prometheus",
+// and they can be changed only by importing them again.
+//
+//     Consumes:
+//     - application/yaml
+//     - application/json
+//
+//     Responses:
+//       200: PrometheusImportResult
+//       400: ValidationError
+
+// swagger:parameters RouteGetAlertRuleGroup RoutePutAlertRuleGroup RouteGetAlertRuleGroupExport RoutePostImportPrometheusRuleGroups
 type FolderUIDPathParam struct {
 	// in:path
 	FolderUID string `json:"FolderUID"`
@@ -185,6 +199,45 @@ type AlertRuleGroupPayload struct {
 	Body AlertRuleGroup
 }
 
+// swagger:parameters RoutePostImportPrometheusRuleGroups
+type ImportPrometheusRuleGroupsParams struct {
+	// UID of the Prometheus compatible data source that executes the queries of the rules.
+	// in:query
+	// required: true
+	DatasourceUID string `json:"datasourceUid"`
+	// If true, the changes are calculated and returned but not saved.
+	// in:query
+	DryRun bool `json:"dryRun"`
+	// The content of a Prometheus rule file.
+	// in:body
+	Body string
+}
+
+// swagger:model
+type PrometheusImportResult struct {
+	DryRun bool                         `json:"dryRun"`
+	Groups []PrometheusImportGroupDelta `json:"groups"`
+}
+
+// PrometheusImportGroupDelta describes the changes of a rule group made by the import.
+type PrometheusImportGroupDelta struct {
+	Title     string                      `json:"title"`
+	FolderUID string                      `json:"folderUid"`
+	Added     []PrometheusImportRuleDelta `json:"added,omitempty"`
+	Updated   []PrometheusImportRuleDelta `json:"updated,omitempty"`
+	Deleted   []PrometheusImportRuleDelta `json:"deleted,omitempty"`
+	// The parts of the rules that behave differently after the import, for example template variables that Grafana does not support.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// PrometheusImportRuleDelta describes the change of a rule.
+type PrometheusImportRuleDelta struct {
+	UID   string `json:"uid"`
+	Title string `json:"title"`
+	// The changed fields of an updated rule.
+	Diff []RuleVersionFieldDiff `json:"diff,omitempty"`
+}
+
 // swagger:model
 type AlertRuleGroupMetadata struct {
 	Interval int64 `json:"interval"`
@@ -205,6 +258,9 @@ type AlertRuleGroupExport struct {
 	Folder   string            `json:"folder" yaml:"folder"`
 	Interval model.Duration    `json:"interval" yaml:"interval"`
 	Rules    []AlertRuleExport `json:"rules" yaml:"rules"`
+	// FolderUID is not part of the provisioning file format, which refers to folders by title. It is needed to
+	// export the group as a Terraform resource.
+	FolderUID string `json:"-" yaml:"-"`
 }
 
 // AlertRuleExport is the provisioned file export of models.AlertRule.
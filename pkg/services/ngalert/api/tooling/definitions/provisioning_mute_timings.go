@@ -19,6 +19,13 @@ import (
 //       200: MuteTimeInterval
 //       404: description: Not found.
 
+// swagger:route GET /api/v1/provisioning/mute-timings/export provisioning stable RouteGetMuteTimingsExport
+//
+// Export all mute timings in provisioning file format.
+//
+//     Responses:
+//       200: AlertingFileExport
+
 // swagger:route POST /api/v1/provisioning/mute-timings provisioning stable RoutePostMuteTiming
 //
 // Create a new mute timing.
@@ -79,3 +86,9 @@ func (mt *MuteTimeInterval) ResourceType() string {
 func (mt *MuteTimeInterval) ResourceID() string {
 	return mt.MuteTimeInterval.Name
 }
+
+// MuteTimeIntervalExport is the provisioned file export of alerting.MuteTimeV1.
+type MuteTimeIntervalExport struct {
+	OrgID                   int64 `json:"orgId" yaml:"orgId"`
+	config.MuteTimeInterval `json:",inline" yaml:",inline"`
+}
@@ -16,6 +16,13 @@ package definitions
 //       200: NotificationTemplate
 //       404: description: Not found.
 
+// swagger:route GET /api/v1/provisioning/templates/export provisioning stable RouteGetTemplatesExport
+//
+// Export all notification templates in provisioning file format.
+//
+//     Responses:
+//       200: AlertingFileExport
+
 // swagger:route PUT /api/v1/provisioning/templates/{name} provisioning stable RoutePutTemplate
 //
 // Updates an existing notification template.
@@ -68,3 +75,10 @@ func (t *NotificationTemplate) ResourceType() string {
 func (t *NotificationTemplate) ResourceID() string {
 	return t.Name
 }
+
+// NotificationTemplateExport is the provisioned file export of alerting.TemplateV1.
+type NotificationTemplateExport struct {
+	OrgID    int64  `json:"orgId" yaml:"orgId"`
+	Name     string `json:"name" yaml:"name"`
+	Template string `json:"template" yaml:"template"`
+}
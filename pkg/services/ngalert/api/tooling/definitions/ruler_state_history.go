@@ -1,6 +1,10 @@
 package definitions
 
-import "github.com/grafana/grafana-plugin-sdk-go/data"
+import (
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
 
 // swagger:route GET /api/v1/rules/history history RouteGetStateHistory
 //
@@ -17,3 +21,72 @@ type StateHistory struct {
 	// in:body
 	Results *data.Frame `json:"results"`
 }
+
+// swagger:route GET /api/v1/rules/history/stats history RouteGetStateHistoryStats
+//
+// Get statistics of alert state history, such as time spent in each state, flap count and mean time to resolve, per rule and per alert instance.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: StateHistoryStats
+//       400: ValidationError
+
+// swagger:parameters RouteGetStateHistoryStats
+type StateHistoryStatsParams struct {
+	// Start of the window as Unix timestamp in seconds. Defaults to 7 days before the end of the window.
+	// in: query
+	From int64 `json:"from"`
+	// End of the window as Unix timestamp in seconds. Defaults to now.
+	// in: query
+	To int64 `json:"to"`
+	// in: query
+	RuleUID string `json:"ruleUID"`
+	// in: query
+	FolderUID string `json:"folderUID"`
+	// in: query
+	RuleGroup string `json:"ruleGroup"`
+	// The number of the noisiest alert instances to return.
+	// in: query
+	// default: 10
+	TopN int `json:"topN"`
+}
+
+// swagger:model
+type StateHistoryStats struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// Statistics of every rule that has state changes in the window.
+	Rules []RuleStateHistoryStats `json:"rules"`
+	// Alert instances with the highest flap count in the window, the noisiest first.
+	NoisiestInstances []InstanceStateHistoryStats `json:"noisiestInstances"`
+}
+
+// swagger:model
+type RuleStateHistoryStats struct {
+	RuleUID string `json:"ruleUID"`
+	StateHistoryStatsSummary
+	Instances []InstanceStateHistoryStats `json:"instances"`
+}
+
+// swagger:model
+type InstanceStateHistoryStats struct {
+	RuleUID string            `json:"ruleUID"`
+	Labels  map[string]string `json:"labels"`
+	StateHistoryStatsSummary
+}
+
+// StateHistoryStatsSummary contains statistics of the state changes of a rule or an alert instance.
+type StateHistoryStatsSummary struct {
+	// Time spent in each state, in seconds, e.g. "Normal", "Alerting" or "Pending".
+	TimeInState map[string]float64 `json:"timeInStateSeconds"`
+	// Total number of state changes.
+	Transitions int `json:"transitions"`
+	// Number of times an alert started firing, i.e. the state changed from Normal or Pending to Alerting.
+	FlapCount int `json:"flapCount"`
+	// Number of times a firing alert was resolved, i.e. the state changed from Alerting to Normal.
+	Resolutions int `json:"resolutions"`
+	// Mean time between an alert started firing and was resolved, in seconds. Zero if there were no resolutions.
+	MeanTimeToResolve float64 `json:"meanTimeToResolveSeconds"`
+}
@@ -0,0 +1,78 @@
+package definitions
+
+import (
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// swagger:route GET /api/ruler/grafana/api/v1/rule/{RuleUID}/trace ruler RouteGetRuleEvaluationTrace
+//
+// Evaluate a rule at the given time and get the trace of the evaluation: the input and output of every query and expression, and the evaluation results.
+// The results of the evaluation do not change the state of the rule.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: RuleEvaluationTrace
+//       400: ValidationError
+//       404: NotFound
+
+// swagger:parameters RouteGetRuleEvaluationTrace
+type RuleEvaluationTraceParams struct {
+	// in: path
+	RuleUID string
+	// The time to evaluate the rule at, as Unix timestamp in seconds or in RFC3339 format. Defaults to now.
+	// in: query
+	Time string `json:"time"`
+}
+
+// swagger:model
+type RuleEvaluationTrace struct {
+	RuleUID     string    `json:"ruleUID"`
+	Condition   string    `json:"condition"`
+	EvaluatedAt time.Time `json:"evaluatedAt"`
+	// Duration of the evaluation in seconds.
+	Duration float64 `json:"duration"`
+	// The ID of the trace of the request in the tracing backend, if tracing is enabled.
+	TraceID string `json:"traceID,omitempty"`
+	// The queries and expressions in the order they were executed. If one of them fails, the execution stops.
+	Nodes []EvaluationNodeTrace `json:"nodes"`
+	// The results of the evaluation, one per alert instance. Missing for recording rules and if the execution failed.
+	Results []EvaluationResultTrace `json:"results,omitempty"`
+	// The error that stopped the evaluation.
+	Error string `json:"error,omitempty"`
+}
+
+// EvaluationNodeTrace is the execution of a single query or expression.
+type EvaluationNodeTrace struct {
+	RefID string `json:"refId"`
+	// The type of the node, for example "Datasource" or "Expression".
+	Type string `json:"type"`
+	// The Ref IDs of the queries and expressions that the expression uses.
+	Inputs         []string `json:"inputs,omitempty"`
+	DatasourceUID  string   `json:"datasourceUid,omitempty"`
+	DatasourceType string   `json:"datasourceType,omitempty"`
+	// The frames that the data source returned, before they were converted.
+	Response data.Frames `json:"response,omitempty"`
+	// How the response of the data source was converted, for example "vector", "single frame series" or "no-data".
+	Conversion string `json:"conversion,omitempty"`
+	// The output of the query or expression that is used by the other expressions.
+	Results   data.Frames `json:"results,omitempty"`
+	StartedAt time.Time   `json:"startedAt"`
+	// Duration of the execution in seconds.
+	Duration float64 `json:"duration"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// EvaluationResultTrace is the evaluation result of a single alert instance.
+type EvaluationResultTrace struct {
+	Labels map[string]string `json:"labels"`
+	// The state of the alert instance, for example "Alerting", "NoData" or "Error".
+	State string `json:"state"`
+	Error string `json:"error,omitempty"`
+	// The values of the queries and expressions for the alert instance, by Ref ID.
+	Values           map[string]*float64 `json:"values,omitempty"`
+	EvaluationString string              `json:"evaluationString,omitempty"`
+}
@@ -0,0 +1,125 @@
+package definitions
+
+import (
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route GET /api/ruler/grafana/api/v1/rule/{RuleUID}/versions ruler RouteGetRuleVersions
+//
+// List versions of a rule, latest first
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableRuleVersions
+//       404: NotFound
+
+// swagger:route GET /api/ruler/grafana/api/v1/rule/{RuleUID}/versions/diff ruler RouteGetRuleVersionsDiff
+//
+// Get the difference between two versions of a rule
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: RuleVersionDiff
+//       400: ValidationError
+//       404: NotFound
+
+// swagger:route POST /api/ruler/grafana/api/v1/rule/{RuleUID}/versions/{Version}/restore ruler RoutePostRestoreRuleVersion
+//
+// Restore a version of a rule. The restored definition is saved as a new version of the rule.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       202: RestoreRuleVersionResponse
+//       400: ValidationError
+//       404: NotFound
+
+// swagger:parameters RouteGetRuleVersions
+type RuleVersionsParams struct {
+	// in: path
+	RuleUID string
+	// Maximum number of versions to return. If not specified, all versions are returned.
+	// in: query
+	Limit int `json:"limit"`
+	// The 1-based number of the page of versions.
+	// in: query
+	// default: 1
+	Page int `json:"page"`
+}
+
+// swagger:parameters RouteGetRuleVersionsDiff
+type RuleVersionsDiffParams struct {
+	// in: path
+	RuleUID string
+	// The version to compare from.
+	// in: query
+	// required: true
+	From int64 `json:"from"`
+	// The version to compare to. If not specified, the current version of the rule is used.
+	// in: query
+	To int64 `json:"to"`
+}
+
+// swagger:parameters RoutePostRestoreRuleVersion
+type RestoreRuleVersionParams struct {
+	// in: path
+	RuleUID string
+	// in: path
+	Version int64
+}
+
+// swagger:model
+type GettableRuleVersions []GettableRuleVersion
+
+// swagger:model
+type GettableRuleVersion struct {
+	UID             string              `json:"uid"`
+	Version         int64               `json:"version"`
+	ParentVersion   int64               `json:"parent_version"`
+	RestoredFrom    int64               `json:"restored_from,omitempty"`
+	Created         time.Time           `json:"created"`
+	Title           string              `json:"title"`
+	Condition       string              `json:"condition"`
+	Data            []AlertQuery        `json:"data"`
+	IntervalSeconds int64               `json:"intervalSeconds"`
+	NamespaceUID    string              `json:"namespace_uid"`
+	RuleGroup       string              `json:"rule_group"`
+	NoDataState     NoDataState         `json:"no_data_state"`
+	ExecErrState    ExecutionErrorState `json:"exec_err_state"`
+	For             model.Duration      `json:"for"`
+	Annotations     map[string]string   `json:"annotations,omitempty"`
+	Labels          map[string]string   `json:"labels,omitempty"`
+	IsPaused        bool                `json:"is_paused"`
+	Record          *Record             `json:"record,omitempty"`
+}
+
+// swagger:model
+type RuleVersionDiff struct {
+	From int64                  `json:"from"`
+	To   int64                  `json:"to"`
+	Diff []RuleVersionFieldDiff `json:"diff"`
+}
+
+// RuleVersionFieldDiff describes a change of a single field of the rule.
+type RuleVersionFieldDiff struct {
+	// Path to the changed field, for example "Condition", "Labels[team]" or "Data[0].Model".
+	Field string `json:"field"`
+	// The value in the "from" version. Missing if the field was added.
+	From interface{} `json:"from,omitempty"`
+	// The value in the "to" version. Missing if the field was removed.
+	To interface{} `json:"to,omitempty"`
+}
+
+// swagger:model
+type RestoreRuleVersionResponse struct {
+	Message string `json:"message"`
+	// The new version of the rule that contains the restored definition.
+	Version int64 `json:"version"`
+}
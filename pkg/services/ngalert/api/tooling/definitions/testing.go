@@ -64,9 +64,10 @@ import (
 //
 //     Produces:
 //     - application/json
+//     - application/x-ndjson
 //
 //     Responses:
-//       200: BacktestResult
+//       200: BacktestResponse
 
 // swagger:parameters RouteTestReceiverConfig
 type TestReceiverRequest struct {
@@ -229,7 +230,46 @@ type BacktestConfig struct {
 	Annotations map[string]string `json:"annotations,omitempty"`
 
 	NoDataState NoDataState `json:"no_data_state"`
+
+	// Notifications enables simulation of notifications. If true, the state transitions are replayed through the
+	// notification policies and mute timings of the organization, and the result contains a second frame
+	// with notifications that would have been sent to contact points.
+	Notifications bool `json:"notifications,omitempty"`
+
+	// Stream enables streaming of the results. If true, the response is newline-delimited JSON, where every line is
+	// a BacktestEvaluation with the states and values of the alert instances after a single evaluation of the rule.
+	// It cannot be combined with Notifications.
+	Stream bool `json:"stream,omitempty"`
 }
 
 // swagger:model
 type BacktestResult data.Frame
+
+// swagger:model
+type BacktestResults []data.Frame
+
+// BacktestResponse is the result of backtesting. It is a BacktestResult, or BacktestResults with the result and the
+// simulated notifications if notifications is true. If stream is true, it is newline-delimited JSON with a
+// BacktestEvaluation per line.
+// swagger:response BacktestResponse
+type BacktestResponse struct {
+	// in:body
+	Body interface{}
+}
+
+// BacktestEvaluation is a single line of the streamed backtesting results.
+// swagger:model
+type BacktestEvaluation struct {
+	Time      time.Time          `json:"time"`
+	Instances []BacktestInstance `json:"instances"`
+}
+
+// BacktestInstance is the state of an alert instance after an evaluation.
+// swagger:model
+type BacktestInstance struct {
+	Labels map[string]string `json:"labels"`
+	State  string            `json:"state"`
+	Reason string            `json:"reason,omitempty"`
+	// Values contains the values of the queries and expressions of the rule by RefID. NaN and infinite values are null.
+	Values map[string]*float64 `json:"values,omitempty"`
+}
@@ -5,13 +5,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/url"
+	"sort"
 	"time"
 
 	"github.com/benbjohnson/clock"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
@@ -37,12 +40,14 @@ type stateManager interface {
 
 type Engine struct {
 	evalFactory        eval.EvaluatorFactory
+	appUrl             *url.URL
 	createStateManager func() stateManager
 }
 
 func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory) *Engine {
 	return &Engine{
 		evalFactory: evalFactory,
+		appUrl:      appUrl,
 		createStateManager: func() stateManager {
 			cfg := state.ManagerCfg{
 				Metrics:                 nil,
@@ -58,44 +63,90 @@ func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory) *Engine {
 	}
 }
 
+// Test evaluates the rule over the interval [from,to) and returns a frame with the state of every alert instance at each evaluation.
+// For every value of the queries and expressions of an instance, the frame also contains a field named after the RefID of the query
+// that has the labels of the instance.
 func (e *Engine) Test(ctx context.Context, user *user.SignedInUser, rule *models.AlertRule, from, to time.Time) (*data.Frame, error) {
-	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
-	logger := logger.FromContext(ctx)
+	return e.test(ctx, user, rule, from, to, nil)
+}
 
-	if !from.Before(to) {
-		return nil, fmt.Errorf("%w: invalid interval of the backtesting [%d,%d]", ErrInvalidInputData, from.Unix(), to.Unix())
+// TestNotifications evaluates the rule over the interval [from,to) like Test does, and additionally replays the state transitions
+// through the notification policy tree and mute timings of the provided Alertmanager configuration.
+// It returns the frame of states and a frame with the notifications that would have been sent to each contact point.
+func (e *Engine) TestNotifications(ctx context.Context, user *user.SignedInUser, rule *models.AlertRule, from, to time.Time, amConfig apimodels.PostableApiAlertingConfig) (*data.Frame, *data.Frame, error) {
+	simulator, err := newNotificationSimulator(amConfig, e.appUrl)
+	if err != nil {
+		return nil, nil, errors.Join(ErrInvalidInputData, err)
 	}
-	if to.Sub(from).Seconds() < float64(rule.IntervalSeconds) {
-		return nil, fmt.Errorf("%w: interval of the backtesting [%d,%d] is less than evaluation interval [%ds]", ErrInvalidInputData, from.Unix(), to.Unix(), rule.IntervalSeconds)
+	result, err := e.test(ctx, user, rule, from, to, simulator.Process)
+	if err != nil {
+		return nil, nil, err
 	}
-	length := int(to.Sub(from).Seconds()) / int(rule.IntervalSeconds)
+	simulator.Finish(to)
+	return result, simulator.Frame(), nil
+}
 
-	evaluator, err := backtestingEvaluatorFactory(ruleCtx, e.evalFactory, user, rule.GetEvalCondition())
+// TestStream evaluates the rule over the interval [from,to) like Test does, but instead of collecting the results into a frame,
+// it calls the callback with the states and values of the alert instances after each evaluation. This lets callers process long
+// intervals without keeping all results in memory. If the callback returns an error, the testing stops and the error is returned.
+func (e *Engine) TestStream(ctx context.Context, user *user.SignedInUser, rule *models.AlertRule, from, to time.Time, callback func(evaluation apimodels.BacktestEvaluation) error) error {
+	length, err := evaluationsCount(rule, from, to)
 	if err != nil {
-		return nil, errors.Join(ErrInvalidInputData, err)
+		return err
 	}
+	return e.evaluate(ctx, user, rule, from, length, func(_ int, now time.Time, states []state.StateTransition) error {
+		evaluation := apimodels.BacktestEvaluation{
+			Time:      now,
+			Instances: make([]apimodels.BacktestInstance, 0, len(states)),
+		}
+		for _, s := range states {
+			instance := apimodels.BacktestInstance{
+				Labels: s.Labels,
+				State:  s.State.State.String(),
+				Reason: s.StateReason,
+			}
+			if len(s.Values) > 0 {
+				instance.Values = make(map[string]*float64, len(s.Values))
+				for refID, v := range s.Values {
+					if math.IsNaN(v) || math.IsInf(v, 0) {
+						instance.Values[refID] = nil
+						continue
+					}
+					value := v
+					instance.Values[refID] = &value
+				}
+			}
+			evaluation.Instances = append(evaluation.Instances, instance)
+		}
+		sort.Slice(evaluation.Instances, func(i, j int) bool {
+			return data.Labels(evaluation.Instances[i].Labels).String() < data.Labels(evaluation.Instances[j].Labels).String()
+		})
+		return callback(evaluation)
+	})
+}
 
-	stateManager := e.createStateManager()
-
-	logger.Info("Start testing alert rule", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluations", length)
-
-	start := time.Now()
+func (e *Engine) test(ctx context.Context, user *user.SignedInUser, rule *models.AlertRule, from, to time.Time, onTransitions func(now time.Time, transitions []state.StateTransition)) (*data.Frame, error) {
+	length, err := evaluationsCount(rule, from, to)
+	if err != nil {
+		return nil, err
+	}
 
 	tsField := data.NewField("Time", nil, make([]time.Time, length))
-	valueFields := make(map[string]*data.Field)
+	stateFields := make(map[string]*data.Field)
+	valueFields := make(map[string]map[string]*data.Field)
+	fields := []*data.Field{tsField}
 
-	err = evaluator.Eval(ruleCtx, from, time.Duration(rule.IntervalSeconds)*time.Second, length, func(idx int, currentTime time.Time, results eval.Results) error {
-		if idx >= length {
-			logger.Info("Unexpected evaluation. Skipping", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluationTime", currentTime, "evaluationIndex", idx, "expectedEvaluations", length)
-			return nil
+	err = e.evaluate(ctx, user, rule, from, length, func(idx int, currentTime time.Time, states []state.StateTransition) error {
+		if onTransitions != nil {
+			onTransitions(currentTime, states)
 		}
-		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, nil)
 		tsField.Set(idx, currentTime)
 		for _, s := range states {
-			field, ok := valueFields[s.CacheID]
+			field, ok := stateFields[s.CacheID]
 			if !ok {
 				field = data.NewField("", s.Labels, make([]*string, length))
-				valueFields[s.CacheID] = field
+				stateFields[s.CacheID] = field
+				fields = append(fields, field)
 			}
 			if s.State.State != eval.NoData { // set nil if NoData
 				value := s.State.State.String()
@@ -103,23 +154,74 @@ func (e *Engine) Test(ctx context.Context, user *user.SignedInUser, rule *models
 					value += " (" + s.StateReason + ")"
 				}
 				field.Set(idx, &value)
+			}
+			if len(s.Values) == 0 {
 				continue
 			}
+			instanceFields, ok := valueFields[s.CacheID]
+			if !ok {
+				instanceFields = make(map[string]*data.Field, len(s.Values))
+				valueFields[s.CacheID] = instanceFields
+			}
+			for refID, v := range s.Values {
+				valueField, ok := instanceFields[refID]
+				if !ok {
+					valueField = data.NewField(refID, s.Labels, make([]*float64, length))
+					instanceFields[refID] = valueField
+					fields = append(fields, valueField)
+				}
+				value := v
+				valueField.Set(idx, &value)
+			}
 		}
 		return nil
 	})
-	fields := make([]*data.Field, 0, len(valueFields)+1)
-	fields = append(fields, tsField)
-	for _, f := range valueFields {
-		fields = append(fields, f)
+	if err != nil {
+		return nil, err
+	}
+	return data.NewFrame("Testing results", fields...), nil
+}
+
+// evaluationsCount returns the number of evaluations of the rule in the interval [from,to).
+func evaluationsCount(rule *models.AlertRule, from, to time.Time) (int, error) {
+	if !from.Before(to) {
+		return 0, fmt.Errorf("%w: invalid interval of the backtesting [%d,%d]", ErrInvalidInputData, from.Unix(), to.Unix())
+	}
+	if to.Sub(from).Seconds() < float64(rule.IntervalSeconds) {
+		return 0, fmt.Errorf("%w: interval of the backtesting [%d,%d] is less than evaluation interval [%ds]", ErrInvalidInputData, from.Unix(), to.Unix(), rule.IntervalSeconds)
+	}
+	return int(to.Sub(from).Seconds()) / int(rule.IntervalSeconds), nil
+}
+
+// evaluate runs the given number of evaluations of the rule starting at from, and calls the callback with the state transitions of every evaluation.
+func (e *Engine) evaluate(ctx context.Context, user *user.SignedInUser, rule *models.AlertRule, from time.Time, length int, callback func(idx int, now time.Time, states []state.StateTransition) error) error {
+	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
+	logger := logger.FromContext(ctx)
+
+	evaluator, err := backtestingEvaluatorFactory(ruleCtx, e.evalFactory, user, rule.GetEvalCondition())
+	if err != nil {
+		return errors.Join(ErrInvalidInputData, err)
 	}
-	result := data.NewFrame("Testing results", fields...)
 
+	stateManager := e.createStateManager()
+
+	logger.Info("Start testing alert rule", "from", from, "interval", rule.IntervalSeconds, "evaluations", length)
+
+	start := time.Now()
+
+	err = evaluator.Eval(ruleCtx, from, time.Duration(rule.IntervalSeconds)*time.Second, length, func(idx int, currentTime time.Time, results eval.Results) error {
+		if idx >= length {
+			logger.Info("Unexpected evaluation. Skipping", "from", from, "interval", rule.IntervalSeconds, "evaluationTime", currentTime, "evaluationIndex", idx, "expectedEvaluations", length)
+			return nil
+		}
+		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, nil)
+		return callback(idx, currentTime, states)
+	})
 	if err != nil {
-		return nil, err
+		return err
 	}
 	logger.Info("Rule testing finished successfully", "duration", time.Since(start))
-	return result, nil
+	return nil
 }
 
 func newBacktestingEvaluator(ctx context.Context, evalFactory eval.EvaluatorFactory, user *user.SignedInUser, condition models.Condition) (backtestingEvaluator, error) {
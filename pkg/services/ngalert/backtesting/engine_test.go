@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"math/rand"
 	"testing"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
 
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -340,6 +342,44 @@ func TestEvaluatorTest(t *testing.T) {
 		}
 	})
 
+	t.Run("should contain a field per value of alert instance", func(t *testing.T) {
+		from := time.Unix(0, 0)
+		to := from.Add(3 * ruleInterval)
+
+		labels := models.GenerateAlertLabels(rand.Intn(5)+1, "test-")
+		manager.stateCallback = func(now time.Time) []state.StateTransition {
+			return []state.StateTransition{
+				{
+					State: &state.State{
+						CacheID: "state-1",
+						Labels:  labels,
+						State:   eval.Alerting,
+						Values: map[string]float64{
+							"A": float64(now.Unix()),
+							"B": 1,
+						},
+					},
+				},
+			}
+		}
+
+		frame, err := engine.Test(context.Background(), nil, rule, from, to)
+		require.NoError(t, err)
+		require.Len(t, frame.Fields, 4) // timestamp, state, A and B
+
+		for _, refID := range []string{"A", "B"} {
+			field, _ := frame.FieldByName(refID)
+			require.NotNilf(t, field, "frame does not contain field %s", refID)
+			require.Equal(t, data.FieldTypeNullableFloat64, field.Type())
+			require.Equal(t, labels.String(), field.Labels.String())
+		}
+		field, _ := frame.FieldByName("A")
+		for i := 0; i < field.Len(); i++ {
+			expected := float64(from.Add(time.Duration(i) * ruleInterval).Unix())
+			require.Equal(t, &expected, field.At(i))
+		}
+	})
+
 	t.Run("should fail", func(t *testing.T) {
 		manager.stateCallback = func(now time.Time) []state.StateTransition {
 			return nil
@@ -377,6 +417,96 @@ func TestEvaluatorTest(t *testing.T) {
 	})
 }
 
+func TestEvaluatorTestStream(t *testing.T) {
+	evaluator := &fakeBacktestingEvaluator{
+		evalCallback: func(now time.Time) (eval.Results, error) {
+			return eval.GenerateResults(1, eval.ResultGen()), nil
+		},
+	}
+	backtestingEvaluatorFactory = func(ctx context.Context, evalFactory eval.EvaluatorFactory, user *user.SignedInUser, condition models.Condition) (backtestingEvaluator, error) {
+		return evaluator, nil
+	}
+	t.Cleanup(func() {
+		backtestingEvaluatorFactory = newBacktestingEvaluator
+	})
+
+	manager := &fakeStateManager{
+		stateCallback: func(now time.Time) []state.StateTransition {
+			return []state.StateTransition{
+				{
+					State: &state.State{
+						CacheID:     "state-2",
+						Labels:      data.Labels{"instance": "b"},
+						State:       eval.Alerting,
+						StateReason: "test",
+						Values:      map[string]float64{"A": 1, "B": math.NaN()},
+					},
+				},
+				{
+					State: &state.State{
+						CacheID: "state-1",
+						Labels:  data.Labels{"instance": "a"},
+						State:   eval.Normal,
+					},
+				},
+			}
+		},
+	}
+	engine := &Engine{
+		createStateManager: func() stateManager {
+			return manager
+		},
+	}
+	rule := models.AlertRuleGen(models.WithInterval(time.Second))()
+	ruleInterval := time.Duration(rule.IntervalSeconds) * time.Second
+	from := time.Unix(0, 0)
+
+	t.Run("should call callback for every evaluation", func(t *testing.T) {
+		var evaluations []apimodels.BacktestEvaluation
+		err := engine.TestStream(context.Background(), nil, rule, from, from.Add(3*ruleInterval), func(evaluation apimodels.BacktestEvaluation) error {
+			evaluations = append(evaluations, evaluation)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, evaluations, 3)
+
+		one := 1.0
+		for i, evaluation := range evaluations {
+			require.Equal(t, from.Add(time.Duration(i)*ruleInterval), evaluation.Time)
+			require.Equal(t, []apimodels.BacktestInstance{
+				{
+					Labels: map[string]string{"instance": "a"},
+					State:  "Normal",
+				},
+				{
+					Labels: map[string]string{"instance": "b"},
+					State:  "Alerting",
+					Reason: "test",
+					Values: map[string]*float64{"A": &one, "B": nil},
+				},
+			}, evaluation.Instances)
+		}
+	})
+
+	t.Run("should stop if callback fails", func(t *testing.T) {
+		expectedError := errors.New("test-error")
+		calls := 0
+		err := engine.TestStream(context.Background(), nil, rule, from, from.Add(3*ruleInterval), func(evaluation apimodels.BacktestEvaluation) error {
+			calls++
+			return expectedError
+		})
+		require.ErrorIs(t, err, expectedError)
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("should fail when interval is not correct", func(t *testing.T) {
+		err := engine.TestStream(context.Background(), nil, rule, from, from, func(evaluation apimodels.BacktestEvaluation) error {
+			return nil
+		})
+		require.ErrorIs(t, err, ErrInvalidInputData)
+	})
+}
+
 type fakeStateManager struct {
 	stateCallback func(now time.Time) []state.StateTransition
 }
@@ -0,0 +1,303 @@
+package backtesting
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// notificationSimulator replays state transitions through a notification policy tree and records the notifications
+// that the Alertmanager would have sent. It mimics the behaviour of the Alertmanager dispatcher (grouping, group_wait,
+// group_interval, repeat_interval) and the deduplication stage, and honours mute timings. Silences and inhibition rules
+// are not taken into account.
+type notificationSimulator struct {
+	route         *dispatch.Route
+	muteIntervals map[string][]timeinterval.TimeInterval
+	appURL        *url.URL
+
+	groups map[string]*aggregationGroup
+	// active contains the fingerprints of alerts that are currently firing, keyed by the state's cache ID.
+	active map[string]model.Fingerprint
+
+	notifications []notification
+}
+
+type aggregationGroup struct {
+	key       string
+	labels    model.LabelSet
+	opts      dispatch.RouteOpts
+	alerts    map[model.Fingerprint]*simulatedAlert
+	nextFlush time.Time
+
+	// state of the last notification sent for the group. Nil if no notification was sent.
+	lastNotified *notificationEntry
+}
+
+type simulatedAlert struct {
+	labels   model.LabelSet
+	resolved bool
+}
+
+type notificationEntry struct {
+	timestamp time.Time
+	firing    map[model.Fingerprint]struct{}
+	resolved  map[model.Fingerprint]struct{}
+}
+
+type notification struct {
+	at       time.Time
+	receiver string
+	groupKey string
+	labels   model.LabelSet
+	firing   []model.LabelSet
+	resolved []model.LabelSet
+}
+
+func newNotificationSimulator(cfg apimodels.PostableApiAlertingConfig, appURL *url.URL) (*notificationSimulator, error) {
+	if cfg.Route == nil {
+		return nil, errors.New("notification policy tree does not have a root route")
+	}
+	muteIntervals := make(map[string][]timeinterval.TimeInterval, len(cfg.MuteTimeIntervals))
+	for _, mt := range cfg.MuteTimeIntervals {
+		muteIntervals[mt.Name] = mt.TimeIntervals
+	}
+	return &notificationSimulator{
+		route:         dispatch.NewRoute(cfg.Route.AsAMRoute(), nil),
+		muteIntervals: muteIntervals,
+		appURL:        appURL,
+		groups:        make(map[string]*aggregationGroup),
+		active:        make(map[string]model.Fingerprint),
+	}, nil
+}
+
+// Process flushes all aggregation groups that were due before the evaluation time, and then routes the alerts
+// produced by the state transitions to their aggregation groups.
+func (s *notificationSimulator) Process(now time.Time, transitions []state.StateTransition) {
+	s.flushUntil(now)
+
+	for _, t := range transitions {
+		if isFiring(t.State.State) {
+			alert := state.StateToPostableAlert(t.State, s.appURL)
+			lbls := make(model.LabelSet, len(alert.Labels))
+			for k, v := range alert.Labels {
+				lbls[model.LabelName(k)] = model.LabelValue(v)
+			}
+			fp := lbls.Fingerprint()
+			// the alert could have changed its labels if the state changed between Alerting, NoData and Error.
+			if prev, ok := s.active[t.CacheID]; ok && prev != fp {
+				s.resolve(prev)
+			}
+			s.active[t.CacheID] = fp
+			s.insert(now, fp, lbls)
+			continue
+		}
+		if fp, ok := s.active[t.CacheID]; ok {
+			s.resolve(fp)
+			delete(s.active, t.CacheID)
+		}
+	}
+}
+
+// Finish flushes all aggregation groups that are due before the provided time.
+func (s *notificationSimulator) Finish(to time.Time) {
+	s.flushUntil(to)
+}
+
+func (s *notificationSimulator) insert(now time.Time, fp model.Fingerprint, lbls model.LabelSet) {
+	for _, r := range s.route.Match(lbls) {
+		groupLabels := model.LabelSet{}
+		for ln, lv := range lbls {
+			if _, ok := r.RouteOpts.GroupBy[ln]; ok || r.RouteOpts.GroupByAll {
+				groupLabels[ln] = lv
+			}
+		}
+		key := fmt.Sprintf("%s:%s", r.Key(), groupLabels)
+		g, ok := s.groups[key]
+		if !ok {
+			g = &aggregationGroup{
+				key:       key,
+				labels:    groupLabels,
+				opts:      r.RouteOpts,
+				alerts:    make(map[model.Fingerprint]*simulatedAlert),
+				nextFlush: now.Add(r.RouteOpts.GroupWait),
+			}
+			s.groups[key] = g
+		}
+		g.alerts[fp] = &simulatedAlert{labels: lbls}
+	}
+}
+
+func (s *notificationSimulator) resolve(fp model.Fingerprint) {
+	for _, g := range s.groups {
+		if a, ok := g.alerts[fp]; ok {
+			a.resolved = true
+		}
+	}
+}
+
+// flushUntil flushes all groups whose flush time is before the provided time in chronological order.
+func (s *notificationSimulator) flushUntil(until time.Time) {
+	for {
+		var next *aggregationGroup
+		for _, g := range s.groups {
+			if !g.nextFlush.Before(until) {
+				continue
+			}
+			if next == nil || g.nextFlush.Before(next.nextFlush) || (g.nextFlush.Equal(next.nextFlush) && g.key < next.key) {
+				next = g
+			}
+		}
+		if next == nil {
+			return
+		}
+		s.flush(next)
+	}
+}
+
+func (s *notificationSimulator) flush(g *aggregationGroup) {
+	now := g.nextFlush
+	g.nextFlush = now.Add(g.opts.GroupInterval)
+
+	if s.isMuted(g.opts.MuteTimeIntervals, now) {
+		return
+	}
+
+	firing := make(map[model.Fingerprint]struct{})
+	resolved := make(map[model.Fingerprint]struct{})
+	for fp, a := range g.alerts {
+		if a.resolved {
+			resolved[fp] = struct{}{}
+		} else {
+			firing[fp] = struct{}{}
+		}
+	}
+
+	if g.needsUpdate(now, firing, resolved) {
+		n := notification{
+			at:       now,
+			receiver: g.opts.Receiver,
+			groupKey: g.key,
+			labels:   g.labels,
+		}
+		for fp := range firing {
+			n.firing = append(n.firing, g.alerts[fp].labels)
+		}
+		for fp := range resolved {
+			n.resolved = append(n.resolved, g.alerts[fp].labels)
+		}
+		sortLabelSets(n.firing)
+		sortLabelSets(n.resolved)
+		s.notifications = append(s.notifications, n)
+		g.lastNotified = &notificationEntry{
+			timestamp: now,
+			firing:    firing,
+			resolved:  resolved,
+		}
+	}
+
+	// resolved alerts are removed from the group after they were flushed.
+	for fp := range resolved {
+		delete(g.alerts, fp)
+	}
+	if len(g.alerts) == 0 {
+		delete(s.groups, g.key)
+	}
+}
+
+// needsUpdate follows the logic of the Alertmanager's deduplication stage. All integrations are assumed to send resolved notifications.
+func (g *aggregationGroup) needsUpdate(now time.Time, firing, resolved map[model.Fingerprint]struct{}) bool {
+	entry := g.lastNotified
+	if entry == nil {
+		return len(firing) > 0
+	}
+	if !isSubset(firing, entry.firing) {
+		return true
+	}
+	if len(firing) == 0 {
+		return len(entry.firing) > 0
+	}
+	if !isSubset(resolved, entry.resolved) {
+		return true
+	}
+	return entry.timestamp.Before(now.Add(-g.opts.RepeatInterval))
+}
+
+func (s *notificationSimulator) isMuted(names []string, now time.Time) bool {
+	for _, name := range names {
+		for _, ti := range s.muteIntervals[name] {
+			if ti.ContainsTime(now.UTC()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Frame returns the notifications as a data frame with one row per notification.
+func (s *notificationSimulator) Frame() *data.Frame {
+	length := len(s.notifications)
+	tsField := data.NewField("Time", nil, make([]time.Time, length))
+	receiverField := data.NewField("Receiver", nil, make([]string, length))
+	groupKeyField := data.NewField("Group key", nil, make([]string, length))
+	groupLabelsField := data.NewField("Group labels", nil, make([]string, length))
+	statusField := data.NewField("Status", nil, make([]string, length))
+	firingField := data.NewField("Firing", nil, make([]int64, length))
+	resolvedField := data.NewField("Resolved", nil, make([]int64, length))
+	alertsField := data.NewField("Alerts", nil, make([]string, length))
+
+	for idx, n := range s.notifications {
+		status := string(model.AlertFiring)
+		if len(n.firing) == 0 {
+			status = string(model.AlertResolved)
+		}
+		alerts := make([]string, 0, len(n.firing)+len(n.resolved))
+		for _, l := range n.firing {
+			alerts = append(alerts, l.String())
+		}
+		for _, l := range n.resolved {
+			alerts = append(alerts, l.String()+" (resolved)")
+		}
+		tsField.Set(idx, n.at)
+		receiverField.Set(idx, n.receiver)
+		groupKeyField.Set(idx, n.groupKey)
+		groupLabelsField.Set(idx, n.labels.String())
+		statusField.Set(idx, status)
+		firingField.Set(idx, int64(len(n.firing)))
+		resolvedField.Set(idx, int64(len(n.resolved)))
+		alertsField.Set(idx, strings.Join(alerts, "\n"))
+	}
+
+	return data.NewFrame("Notifications", tsField, receiverField, groupKeyField, groupLabelsField, statusField, firingField, resolvedField, alertsField)
+}
+
+// isFiring returns true if the state is sent to the Alertmanager as a firing alert.
+func isFiring(s eval.State) bool {
+	return s == eval.Alerting || s == eval.NoData || s == eval.Error
+}
+
+func isSubset(subset, set map[model.Fingerprint]struct{}) bool {
+	for fp := range subset {
+		if _, ok := set[fp]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func sortLabelSets(sets []model.LabelSet) {
+	sort.Slice(sets, func(i, j int) bool {
+		return sets[i].Before(sets[j])
+	})
+}
@@ -0,0 +1,113 @@
+package backtesting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestNotificationSimulator(t *testing.T) {
+	groupWait := model.Duration(30 * time.Second)
+	groupInterval := model.Duration(5 * time.Minute)
+	repeatInterval := model.Duration(1 * time.Hour)
+
+	cfg := apimodels.PostableApiAlertingConfig{
+		Config: apimodels.Config{
+			Route: &apimodels.Route{
+				Receiver:       "default",
+				GroupByStr:     []string{"alertname"},
+				GroupBy:        []model.LabelName{"alertname"},
+				GroupWait:      &groupWait,
+				GroupInterval:  &groupInterval,
+				RepeatInterval: &repeatInterval,
+			},
+		},
+	}
+
+	from := time.Date(2023, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	transition := func(cacheID string, s eval.State, prev eval.State) state.StateTransition {
+		return state.StateTransition{
+			State: &state.State{
+				CacheID: cacheID,
+				State:   s,
+				Labels:  data.Labels{"alertname": "test", "instance": cacheID},
+			},
+			PreviousState: prev,
+		}
+	}
+
+	t.Run("should wait for group_wait and send firing and resolved notifications", func(t *testing.T) {
+		s, err := newNotificationSimulator(cfg, nil)
+		require.NoError(t, err)
+
+		s.Process(from, []state.StateTransition{transition("a", eval.Alerting, eval.Pending)})
+		s.Process(from.Add(time.Minute), []state.StateTransition{transition("a", eval.Alerting, eval.Alerting)})
+		s.Process(from.Add(10*time.Minute), []state.StateTransition{transition("a", eval.Normal, eval.Alerting)})
+		s.Finish(from.Add(time.Hour))
+
+		require.Len(t, s.notifications, 2)
+		require.Equal(t, from.Add(30*time.Second), s.notifications[0].at)
+		require.Equal(t, "default", s.notifications[0].receiver)
+		require.Len(t, s.notifications[0].firing, 1)
+		require.Empty(t, s.notifications[0].resolved)
+
+		require.Equal(t, from.Add(30*time.Second).Add(10*time.Minute), s.notifications[1].at)
+		require.Empty(t, s.notifications[1].firing)
+		require.Len(t, s.notifications[1].resolved, 1)
+		require.Empty(t, s.groups)
+	})
+
+	t.Run("should batch alerts of the same group and repeat after repeat_interval", func(t *testing.T) {
+		s, err := newNotificationSimulator(cfg, nil)
+		require.NoError(t, err)
+
+		s.Process(from, []state.StateTransition{transition("a", eval.Alerting, eval.Pending)})
+		s.Process(from.Add(10*time.Second), []state.StateTransition{transition("b", eval.Alerting, eval.Pending)})
+		s.Finish(from.Add(time.Hour + 10*time.Minute))
+
+		require.Len(t, s.notifications, 2)
+		require.Len(t, s.notifications[0].firing, 2)
+		// the first flush after repeat_interval has passed since the last notification
+		require.Equal(t, from.Add(30*time.Second).Add(time.Hour+5*time.Minute), s.notifications[1].at)
+		require.Len(t, s.notifications[1].firing, 2)
+	})
+
+	t.Run("should not send notifications during mute timings", func(t *testing.T) {
+		muted := cfg
+		route := *cfg.Route
+		route.MuteTimeIntervals = []string{"maintenance"}
+		muted.Route = &route
+		muted.MuteTimeIntervals = []config.MuteTimeInterval{
+			{
+				Name: "maintenance",
+				TimeIntervals: []timeinterval.TimeInterval{
+					{
+						Times: []timeinterval.TimeRange{{StartMinute: 10 * 60, EndMinute: 10*60 + 20}},
+					},
+				},
+			},
+		}
+
+		s, err := newNotificationSimulator(muted, nil)
+		require.NoError(t, err)
+
+		s.Process(from, []state.StateTransition{transition("a", eval.Alerting, eval.Pending)})
+		s.Finish(from.Add(30 * time.Minute))
+
+		require.Len(t, s.notifications, 1)
+		require.Equal(t, from.Add(30*time.Second).Add(20*time.Minute), s.notifications[0].at)
+
+		frame := s.Frame()
+		require.Equal(t, 1, frame.Rows())
+	})
+}
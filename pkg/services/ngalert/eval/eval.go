@@ -89,19 +89,24 @@ type evaluatorImpl struct {
 	dataSourceCache   datasources.CacheService
 	expressionService *expr.Service
 	pluginsStore      plugins.Store
+	alertStates       expr.AlertStateReader
 }
 
+// NewEvaluatorFactory creates a factory of condition evaluators. alertStates provides the current state of alert rules
+// to the queries that use expr.AlertStateDatasourceUID. It can be nil, in which case such queries fail.
 func NewEvaluatorFactory(
 	cfg setting.UnifiedAlertingSettings,
 	datasourceCache datasources.CacheService,
 	expressionService *expr.Service,
 	pluginsStore plugins.Store,
+	alertStates expr.AlertStateReader,
 ) EvaluatorFactory {
 	return &evaluatorImpl{
 		evaluationTimeout: cfg.EvaluationTimeout,
 		dataSourceCache:   datasourceCache,
 		expressionService: expressionService,
 		pluginsStore:      pluginsStore,
+		alertStates:       alertStates,
 	}
 }
 
@@ -657,7 +662,7 @@ func (e *evaluatorImpl) Validate(ctx EvaluationContext, condition models.Conditi
 			if !found {
 				return fmt.Errorf("datasource refID %s could not be found: %w", query.RefID, plugins.ErrPluginUnavailable)
 			}
-		case expr.TypeCMDNode:
+		case expr.TypeCMDNode, expr.TypeAlertStateNode:
 		}
 	}
 	_, err = e.create(condition, req)
@@ -679,6 +684,7 @@ func (e *evaluatorImpl) Create(ctx EvaluationContext, condition models.Condition
 }
 
 func (e *evaluatorImpl) create(condition models.Condition, req *expr.Request) (ConditionEvaluator, error) {
+	req.AlertStates = e.alertStates
 	pipeline, err := e.expressionService.BuildPipeline(req)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,102 @@
+package ngalert
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/quota"
+)
+
+// instanceQuotaCacheTTL is how long the limit of alert instances of an organization is cached.
+const instanceQuotaCacheTTL = time.Minute
+
+// instanceQuota reads the limits of alert instances of organizations from the quota service. The limits are needed
+// every time a rule is evaluated, therefore they are cached.
+type instanceQuota struct {
+	quotas quota.Service
+	clock  clock.Clock
+
+	mtx    sync.Mutex
+	limits map[int64]cachedInstanceLimit
+}
+
+type cachedInstanceLimit struct {
+	limit     int64
+	expiresAt time.Time
+}
+
+func newInstanceQuota(quotas quota.Service, clk clock.Clock) *instanceQuota {
+	return &instanceQuota{
+		quotas: quotas,
+		clock:  clk,
+		limits: make(map[int64]cachedInstanceLimit),
+	}
+}
+
+// OrgLimit returns the maximum number of alert instances of the organization. Returns -1 if the number is not limited
+// or if quotas are disabled.
+func (q *instanceQuota) OrgLimit(ctx context.Context, orgID int64) (int64, error) {
+	now := q.clock.Now()
+	q.mtx.Lock()
+	cached, ok := q.limits[orgID]
+	q.mtx.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.limit, nil
+	}
+
+	limit, err := q.readOrgLimit(ctx, orgID)
+	if err != nil {
+		return -1, err
+	}
+	q.mtx.Lock()
+	q.limits[orgID] = cachedInstanceLimit{limit: limit, expiresAt: now.Add(instanceQuotaCacheTTL)}
+	q.mtx.Unlock()
+	return limit, nil
+}
+
+func (q *instanceQuota) readOrgLimit(ctx context.Context, orgID int64) (int64, error) {
+	quotas, err := q.quotas.GetQuotasByScope(ctx, quota.OrgScope, orgID)
+	if err != nil {
+		if errors.Is(err, quota.ErrDisabled) {
+			return -1, nil
+		}
+		return -1, err
+	}
+	for _, dto := range quotas {
+		if dto.Service == string(models.InstanceQuotaTargetSrv) && dto.Target == string(models.InstanceQuotaTarget) {
+			return dto.Limit, nil
+		}
+	}
+	return -1, nil
+}
+
+// instanceQuotaDefaultLimits returns the default limits of the quota of alert instances.
+func instanceQuotaDefaultLimits(orgLimit int64) (*quota.Map, error) {
+	limits := &quota.Map{}
+	tag, err := quota.NewTag(models.InstanceQuotaTargetSrv, models.InstanceQuotaTarget, quota.OrgScope)
+	if err != nil {
+		return limits, err
+	}
+	limits.Set(tag, orgLimit)
+	return limits, nil
+}
+
+// instanceUsage reports the number of alert instances of the organization. If alert rules are sharded, the alert
+// instances of the rules evaluated by other instances of Grafana are counted from the state they saved.
+func (ng *AlertNG) instanceUsage(_ context.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
+	u := &quota.Map{}
+	if scopeParams == nil || scopeParams.OrgID == 0 {
+		return u, nil
+	}
+	tag, err := quota.NewTag(models.InstanceQuotaTargetSrv, models.InstanceQuotaTarget, quota.OrgScope)
+	if err != nil {
+		return u, err
+	}
+	u.Set(tag, ng.stateManager.CountInstances(scopeParams.OrgID))
+	return u, nil
+}
@@ -6,7 +6,8 @@ import (
 )
 
 type State struct {
-	AlertState *prometheus.GaugeVec
+	AlertState       *prometheus.GaugeVec
+	InstancesDropped *prometheus.CounterVec
 }
 
 func NewStateMetrics(r prometheus.Registerer) *State {
@@ -17,5 +18,11 @@ func NewStateMetrics(r prometheus.Registerer) *State {
 			Name:      "alerts",
 			Help:      "How many alerts by state.",
 		}, []string{"state"}),
+		InstancesDropped: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "alert_instances_dropped_total",
+			Help:      "The total number of alert instances dropped because rules exceeded the limit of alert instances, by the exceeded limit.",
+		}, []string{"limit"}),
 	}
 }
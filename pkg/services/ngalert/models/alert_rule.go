@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"sort"
 	"strconv"
 	"time"
@@ -12,7 +13,9 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	alertingModels "github.com/grafana/alerting/models"
+	"github.com/prometheus/common/model"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/util/cmputil"
 )
@@ -20,6 +23,8 @@ import (
 var (
 	// ErrAlertRuleNotFound is an error for an unknown alert rule.
 	ErrAlertRuleNotFound = fmt.Errorf("could not find alert rule")
+	// ErrAlertRuleVersionNotFound is an error for an unknown version of an alert rule.
+	ErrAlertRuleVersionNotFound = errors.New("could not find alert rule version")
 	// ErrAlertRuleFailedGenerateUniqueUID is an error for failure to generate alert rule UID
 	ErrAlertRuleFailedGenerateUniqueUID = errors.New("failed to generate alert rule UID")
 	// ErrCannotEditNamespace is an error returned if the user does not have permissions to edit the namespace
@@ -112,6 +117,8 @@ const (
 	StateReasonPaused        = "Paused"
 	StateReasonUpdated       = "Updated"
 	StateReasonRuleDeleted   = "RuleDeleted"
+	// StateReasonInstanceLimitExceeded is the reason of the states of a rule that exceeds the limit of alert instances.
+	StateReasonInstanceLimitExceeded = "InstanceLimitExceeded"
 )
 
 var (
@@ -167,6 +174,59 @@ type AlertRule struct {
 	Annotations map[string]string
 	Labels      map[string]string
 	IsPaused    bool
+	// Record is not empty if the rule is a recording rule. See Type.
+	Record Record `xorm:"record"`
+	// DisableEvaluationOffset makes the rule be evaluated at the beginning of its interval. See EvaluationOffset.
+	// Like the interval, it is the same for all rules of a group.
+	DisableEvaluationOffset bool `xorm:"disable_evaluation_offset"`
+}
+
+// RuleType is the type of the rule that defines what happens with the results of its evaluation.
+type RuleType string
+
+const (
+	// RuleTypeAlerting is a rule whose results create alert instances and notifications.
+	RuleTypeAlerting RuleType = "alerting"
+	// RuleTypeRecording is a rule whose results are written as a new metric.
+	RuleTypeRecording RuleType = "recording"
+)
+
+// Record contains the fields that are specific to recording rules. It is empty for alerting rules.
+type Record struct {
+	// Metric is the name of the metric the result of the evaluation is written to.
+	Metric string `json:"metric"`
+	// From is the RefID of the query or expression whose result is written.
+	From string `json:"from"`
+}
+
+// IsEmpty returns true if none of the fields are set.
+func (r Record) IsEmpty() bool {
+	return r == Record{}
+}
+
+// Validate returns an error if the metric is not a valid Prometheus metric name or From is empty.
+func (r Record) Validate() error {
+	if !model.IsValidMetricName(model.LabelValue(r.Metric)) {
+		return fmt.Errorf("%w: metric name '%s' of the recording rule is not valid", ErrAlertRuleFailedValidation, r.Metric)
+	}
+	if r.From == "" {
+		return fmt.Errorf("%w: the recording rule does not specify the query or expression to record", ErrAlertRuleFailedValidation)
+	}
+	return nil
+}
+
+func (r *Record) FromDB(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, r)
+}
+
+func (r *Record) ToDB() ([]byte, error) {
+	if r.IsEmpty() {
+		return nil, nil
+	}
+	return json.Marshal(r)
 }
 
 // AlertRuleWithOptionals This is to avoid having to pass in additional arguments deep in the call stack. Alert rule
@@ -250,6 +310,81 @@ func (alertRule *AlertRule) GetLabels(opts ...LabelOption) map[string]string {
 	return labels
 }
 
+// Type returns RuleTypeRecording if the rule is a recording rule. Otherwise, returns RuleTypeAlerting.
+func (alertRule *AlertRule) Type() RuleType {
+	if !alertRule.Record.IsEmpty() {
+		return RuleTypeRecording
+	}
+	return RuleTypeAlerting
+}
+
+// EvaluationOffset returns the offset from the beginning of the evaluation interval at which the rule is evaluated.
+// Offsets spread the evaluations of rules with the same interval across the interval instead of evaluating all of them
+// at the same time. The offset is a multiple of baseInterval derived from the hash of the rule UID, and therefore it is
+// the same after restarts and on all instances of a cluster. It is 0 if DisableEvaluationOffset is set.
+func (alertRule *AlertRule) EvaluationOffset(baseInterval time.Duration) time.Duration {
+	if alertRule.DisableEvaluationOffset || baseInterval <= 0 {
+		return 0
+	}
+	ticks := int64(time.Duration(alertRule.IntervalSeconds) * time.Second / baseInterval)
+	if ticks <= 1 {
+		return 0
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(alertRule.UID))
+	return time.Duration(h.Sum64()%uint64(ticks)) * baseInterval
+}
+
+// AlertStateDependencies returns the UIDs of the rules whose state the rule queries with alert state queries. The
+// second result is true if the rule also selects rules by labels, and therefore can depend on any rule of the organization.
+func (alertRule *AlertRule) AlertStateDependencies() ([]string, bool) {
+	var uids []string
+	byLabels := false
+	for _, q := range alertRule.Data {
+		if q.DatasourceUID != expr.AlertStateDatasourceUID {
+			continue
+		}
+		query, err := expr.UnmarshalAlertStateQuery(q.Model)
+		if err != nil {
+			// the rule will fail to evaluate anyway
+			continue
+		}
+		if len(query.RuleUIDs) == 0 {
+			byLabels = true
+			continue
+		}
+		uids = append(uids, query.RuleUIDs...)
+	}
+	return uids, byLabels
+}
+
+// EvaluationOffsets returns the offsets at which the rules are evaluated, see EvaluationOffset. Rules that query the
+// state of other rules by UID, and the rules whose state they query, are evaluated at the beginning of their interval,
+// so that a rule is evaluated in the same tick as the rules it depends on whenever their intervals allow it.
+func EvaluationOffsets(rules []*AlertRule, baseInterval time.Duration) map[AlertRuleKey]time.Duration {
+	aligned := make(map[AlertRuleKey]struct{})
+	for _, rule := range rules {
+		uids, _ := rule.AlertStateDependencies()
+		if len(uids) == 0 {
+			continue
+		}
+		aligned[rule.GetKey()] = struct{}{}
+		for _, uid := range uids {
+			aligned[AlertRuleKey{OrgID: rule.OrgID, UID: uid}] = struct{}{}
+		}
+	}
+	offsets := make(map[AlertRuleKey]time.Duration, len(rules))
+	for _, rule := range rules {
+		key := rule.GetKey()
+		if _, ok := aligned[key]; ok {
+			offsets[key] = 0
+			continue
+		}
+		offsets[key] = rule.EvaluationOffset(baseInterval)
+	}
+	return offsets
+}
+
 func (alertRule *AlertRule) GetEvalCondition() Condition {
 	return Condition{
 		Condition: alertRule.Condition,
@@ -417,6 +552,85 @@ type AlertRuleVersion struct {
 	Annotations map[string]string
 	Labels      map[string]string
 	IsPaused    bool
+	Record      Record `xorm:"record"`
+	// DisableEvaluationOffset is the same as AlertRule.DisableEvaluationOffset.
+	DisableEvaluationOffset bool `xorm:"disable_evaluation_offset"`
+}
+
+// AlertRuleVersionFieldsToIgnoreInDiff contains fields of AlertRule that are ignored when two versions of a rule are compared.
+// The fields are either not stored in the version or are calculated.
+var AlertRuleVersionFieldsToIgnoreInDiff = [...]string{"ID", "Version", "Updated", "DashboardUID", "PanelID", "RuleGroupIndex"}
+
+// ToAlertRule returns the alert rule as it was at this version.
+func (v *AlertRuleVersion) ToAlertRule() AlertRule {
+	rule := AlertRule{
+		OrgID:           v.RuleOrgID,
+		Title:           v.Title,
+		Condition:       v.Condition,
+		Data:            v.Data,
+		Updated:         v.Created,
+		IntervalSeconds: v.IntervalSeconds,
+		Version:         v.Version,
+		UID:             v.RuleUID,
+		NamespaceUID:    v.RuleNamespaceUID,
+		RuleGroup:       v.RuleGroup,
+		RuleGroupIndex:  v.RuleGroupIndex,
+		NoDataState:     v.NoDataState,
+		ExecErrState:    v.ExecErrState,
+		For:             v.For,
+		Annotations:     v.Annotations,
+		Labels:          v.Labels,
+		IsPaused:        v.IsPaused,
+		Record:          v.Record,
+	}
+	rule.DisableEvaluationOffset = v.DisableEvaluationOffset
+	// errors are ignored because the annotations were validated when the version was created
+	_ = rule.SetDashboardAndPanelFromAnnotations()
+	return rule
+}
+
+// Diff calculates the difference between two versions of an alert rule. Returns nil if the versions define the same rule.
+func (v *AlertRuleVersion) Diff(other *AlertRuleVersion) cmputil.DiffReport {
+	a, b := v.ToAlertRule(), other.ToAlertRule()
+	return a.Diff(&b, AlertRuleVersionFieldsToIgnoreInDiff[:]...)
+}
+
+// Restore returns a copy of the alert rule with the definition of the rule at this version.
+// The rule group, folder and pause state of the alert rule are not changed.
+func (v *AlertRuleVersion) Restore(rule *AlertRule) AlertRule {
+	result := *rule
+	result.Title = v.Title
+	result.Condition = v.Condition
+	result.Data = v.Data
+	result.NoDataState = v.NoDataState
+	result.ExecErrState = v.ExecErrState
+	result.For = v.For
+	result.Annotations = v.Annotations
+	result.Labels = v.Labels
+	result.Record = v.Record
+	result.DisableEvaluationOffset = v.DisableEvaluationOffset
+	result.DashboardUID = nil
+	result.PanelID = nil
+	// errors are ignored because the annotations were validated when the version was created
+	_ = result.SetDashboardAndPanelFromAnnotations()
+	return result
+}
+
+// GetAlertRuleVersionsQuery is the query for listing versions of an alert rule, latest first.
+type GetAlertRuleVersionsQuery struct {
+	OrgID   int64
+	RuleUID string
+	// Limit is the maximum number of versions to return. If zero, all versions are returned.
+	Limit int
+	// Page is the 1-based number of the page of versions of size Limit.
+	Page int
+}
+
+// GetAlertRuleVersionQuery is the query for retrieving a specific version of an alert rule.
+type GetAlertRuleVersionQuery struct {
+	OrgID   int64
+	RuleUID string
+	Version int64
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -437,6 +651,7 @@ type ListAlertRulesQuery struct {
 	NamespaceUIDs []string
 	ExcludeOrgs   []int64
 	RuleGroup     string
+	RuleUIDs      []string
 
 	// DashboardUID and PanelID are optional and allow filtering rules
 	// to return just those for a dashboard and panel.
@@ -480,6 +695,8 @@ type ListOrgRuleGroupsQuery struct {
 type UpdateRule struct {
 	Existing *AlertRule
 	New      AlertRule
+	// RestoredFrom is the version of the rule the new definition was restored from. Zero if the rule was not restored.
+	RestoredFrom int64
 }
 
 // Condition contains backend expressions and queries and the RefID
@@ -503,9 +720,9 @@ func (c Condition) IsValid() bool {
 // There are several exceptions:
 // 1. Following fields are not patched and therefore will be ignored: AlertRule.ID, AlertRule.OrgID, AlertRule.Updated, AlertRule.Version, AlertRule.UID, AlertRule.DashboardUID, AlertRule.PanelID, AlertRule.Annotations and AlertRule.Labels
 // 2. There are fields that are patched together:
-//   - AlertRule.Condition and AlertRule.Data
+//   - AlertRule.Condition, AlertRule.Data and AlertRule.Record
 //
-// If either of the pair is specified, neither is patched.
+// If either of Condition and Data is specified, neither is patched.
 func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRuleWithOptionals) {
 	if ruleToPatch.Title == "" {
 		ruleToPatch.Title = existingRule.Title
@@ -513,6 +730,7 @@ func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRuleWithOp
 	if ruleToPatch.Condition == "" || len(ruleToPatch.Data) == 0 {
 		ruleToPatch.Condition = existingRule.Condition
 		ruleToPatch.Data = existingRule.Data
+		ruleToPatch.Record = existingRule.Record
 	}
 	if ruleToPatch.IntervalSeconds == 0 {
 		ruleToPatch.IntervalSeconds = existingRule.IntervalSeconds
@@ -561,6 +779,12 @@ const (
 	QuotaTarget    quota.Target    = "alert_rule"
 )
 
+// The quota of alert instances has its own service so that reaching it does not prevent users from creating alert rules.
+const (
+	InstanceQuotaTargetSrv quota.TargetSrv = "ngalert_instance"
+	InstanceQuotaTarget    quota.Target    = "alert_instance"
+)
+
 type ruleKeyContextKey struct{}
 
 func WithRuleKey(ctx context.Context, ruleKey AlertRuleKey) context.Context {
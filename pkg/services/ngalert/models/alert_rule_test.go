@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -729,3 +730,129 @@ func TestTimeRangeYAML(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, yamlRaw, string(serialized))
 }
+
+func TestRecord(t *testing.T) {
+	t.Run("empty record means alerting rule", func(t *testing.T) {
+		rule := AlertRuleGen()()
+		require.Equal(t, RuleTypeAlerting, rule.Type())
+		rule.Record = Record{Metric: "test_metric", From: "A"}
+		require.Equal(t, RuleTypeRecording, rule.Type())
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		require.NoError(t, Record{Metric: "test:metric_total", From: "A"}.Validate())
+		require.ErrorIs(t, Record{Metric: "", From: "A"}.Validate(), ErrAlertRuleFailedValidation)
+		require.ErrorIs(t, Record{Metric: "test-metric", From: "A"}.Validate(), ErrAlertRuleFailedValidation)
+		require.ErrorIs(t, Record{Metric: "test_metric", From: ""}.Validate(), ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("should convert to and from DB", func(t *testing.T) {
+		record := Record{Metric: "test_metric", From: "A"}
+		b, err := record.ToDB()
+		require.NoError(t, err)
+		var actual Record
+		require.NoError(t, actual.FromDB(b))
+		require.Equal(t, record, actual)
+
+		b, err = (&Record{}).ToDB()
+		require.NoError(t, err)
+		require.Nil(t, b)
+		actual = Record{}
+		require.NoError(t, actual.FromDB([]byte{}))
+		require.True(t, actual.IsEmpty())
+	})
+}
+
+func TestAlertRuleVersionRestore(t *testing.T) {
+	t.Run("should restore the type of the rule", func(t *testing.T) {
+		rule := AlertRuleGen()()
+		version := &AlertRuleVersion{
+			Title:     "recording",
+			Condition: "A",
+			Data:      rule.Data,
+			Record:    Record{Metric: "test_metric", From: "A"},
+		}
+
+		restored := version.Restore(rule)
+		require.Equal(t, RuleTypeRecording, restored.Type())
+		require.Equal(t, version.Record, restored.Record)
+
+		restored = (&AlertRuleVersion{Title: "alerting", Condition: "A", Data: rule.Data}).Restore(&restored)
+		require.Equal(t, RuleTypeAlerting, restored.Type())
+	})
+
+	t.Run("should restore the evaluation offset setting", func(t *testing.T) {
+		rule := AlertRuleGen()()
+		rule.DisableEvaluationOffset = false
+		version := &AlertRuleVersion{Title: rule.Title, Condition: "A", Data: rule.Data, DisableEvaluationOffset: true}
+
+		restored := version.Restore(rule)
+		require.True(t, restored.DisableEvaluationOffset)
+
+		version.DisableEvaluationOffset = false
+		restored = version.Restore(&restored)
+		require.False(t, restored.DisableEvaluationOffset)
+	})
+}
+
+func TestEvaluationOffset(t *testing.T) {
+	baseInterval := 10 * time.Second
+
+	t.Run("should be a multiple of base interval less than the interval", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			rule := AlertRuleGen(WithInterval(time.Minute))()
+			offset := rule.EvaluationOffset(baseInterval)
+			require.GreaterOrEqual(t, offset, time.Duration(0))
+			require.Less(t, offset, time.Minute)
+			require.Zero(t, offset%baseInterval)
+		}
+	})
+
+	t.Run("should be the same for the same rule", func(t *testing.T) {
+		rule := AlertRuleGen(WithInterval(time.Minute))()
+		other := CopyRule(rule)
+		other.Title = "other"
+		require.Equal(t, rule.EvaluationOffset(baseInterval), other.EvaluationOffset(baseInterval))
+	})
+
+	t.Run("should be zero if disabled", func(t *testing.T) {
+		rule := AlertRuleGen(WithInterval(time.Minute), WithoutEvaluationOffset())()
+		require.Zero(t, rule.EvaluationOffset(baseInterval))
+	})
+
+	t.Run("should be zero if interval is base interval", func(t *testing.T) {
+		rule := AlertRuleGen(WithInterval(baseInterval))()
+		require.Zero(t, rule.EvaluationOffset(baseInterval))
+	})
+}
+
+func TestEvaluationOffsets(t *testing.T) {
+	baseInterval := 10 * time.Second
+	withStateQuery := func(rule *AlertRule, query string) *AlertRule {
+		rule.Data = append(rule.Data, AlertQuery{
+			RefID:         "S",
+			DatasourceUID: expr.AlertStateDatasourceUID,
+			Model:         json.RawMessage(query),
+		})
+		return rule
+	}
+	// find rules with a non-zero offset, so that the test does not depend on the hash of the generated UID
+	gen := func() *AlertRule {
+		for {
+			rule := AlertRuleGen(WithOrgID(1), WithInterval(time.Hour))()
+			if rule.EvaluationOffset(baseInterval) != 0 {
+				return rule
+			}
+		}
+	}
+
+	dependency, independent := gen(), gen()
+	dependent := withStateQuery(gen(), fmt.Sprintf(`{"ruleUIDs": [%q]}`, dependency.UID))
+	byLabels := withStateQuery(gen(), `{"labels": {"cluster": "a"}}`)
+
+	offsets := EvaluationOffsets([]*AlertRule{dependency, independent, dependent, byLabels}, baseInterval)
+	require.Zero(t, offsets[dependency.GetKey()])
+	require.Zero(t, offsets[dependent.GetKey()])
+	require.Equal(t, independent.EvaluationOffset(baseInterval), offsets[independent.GetKey()])
+	require.Equal(t, byLabels.EvaluationOffset(baseInterval), offsets[byLabels.GetKey()])
+}
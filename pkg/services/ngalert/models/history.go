@@ -1,20 +1,28 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"github.com/grafana/grafana/pkg/services/user"
 )
 
+// ErrUnsupportedHistoryQuery is returned by state history backends for queries they do not support.
+var ErrUnsupportedHistoryQuery = errors.New("the state history backend does not support the query")
+
 // HistoryQuery represents a query for alert state history.
 type HistoryQuery struct {
 	RuleUID      string
 	OrgID        int64
 	DashboardUID string
 	PanelID      int64
+	NamespaceUID string
+	RuleGroup    string
 	Labels       map[string]string
 	From         time.Time
 	To           time.Time
 	Limit        int
+	// Page is the 1-based number of the page of size Limit. Only supported by some backends.
+	Page         int
 	SignedInUser *user.SignedInUser
 }
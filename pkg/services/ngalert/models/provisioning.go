@@ -8,6 +8,8 @@ const (
 	ProvenanceNone Provenance = ""
 	ProvenanceAPI  Provenance = "api"
 	ProvenanceFile Provenance = "file"
+	// ProvenanceConvertedPrometheus marks resources that are converted from Prometheus rule files.
+	ProvenanceConvertedPrometheus Provenance = "converted_prometheus"
 )
 
 // Provisionable represents a resource that can be created through a provisioning mechanism, such as Terraform or config file.
@@ -167,6 +167,12 @@ func WithInterval(interval time.Duration) AlertRuleMutator {
 	}
 }
 
+func WithoutEvaluationOffset() AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.DisableEvaluationOffset = true
+	}
+}
+
 func WithTitle(title string) AlertRuleMutator {
 	return func(rule *AlertRule) {
 		rule.Title = title
@@ -271,7 +277,9 @@ func CopyRule(r *AlertRule) *AlertRule {
 		NoDataState:     r.NoDataState,
 		ExecErrState:    r.ExecErrState,
 		For:             r.For,
+		Record:          r.Record,
 	}
+	result.DisableEvaluationOffset = r.DisableEvaluationOffset
 
 	if r.DashboardUID != nil {
 		dash := *r.DashboardUID
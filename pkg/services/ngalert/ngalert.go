@@ -37,6 +37,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
@@ -127,6 +128,7 @@ type AlertNG struct {
 	ImageService        image.ImageService
 	schedule            schedule.ScheduleService
 	stateManager        *state.Manager
+	historian           Historian
 	folderService       folder.Service
 	dashboardService    dashboards.DashboardService
 	api                 *api.API
@@ -189,25 +191,10 @@ func (ng *AlertNG) init() error {
 
 	ng.AlertsRouter = alertsRouter
 
-	evalFactory := eval.NewEvaluatorFactory(ng.Cfg.UnifiedAlerting, ng.DataSourceCache, ng.ExpressionService, ng.pluginsStore)
-	schedCfg := schedule.SchedulerCfg{
-		MaxAttempts:          ng.Cfg.UnifiedAlerting.MaxAttempts,
-		C:                    clk,
-		BaseInterval:         ng.Cfg.UnifiedAlerting.BaseInterval,
-		MinRuleInterval:      ng.Cfg.UnifiedAlerting.MinInterval,
-		DisableGrafanaFolder: ng.Cfg.UnifiedAlerting.ReservedLabels.IsReservedLabelDisabled(models.FolderTitleLabel),
-		AppURL:               appUrl,
-		EvaluatorFactory:     evalFactory,
-		RuleStore:            ng.store,
-		Metrics:              ng.Metrics.GetSchedulerMetrics(),
-		AlertSender:          alertsRouter,
-		Tracer:               ng.tracer,
-	}
-
 	// There are a set of feature toggles available that act as short-circuits for common configurations.
 	// If any are set, override the config accordingly.
 	applyStateHistoryFeatureToggles(&ng.Cfg.UnifiedAlerting.StateHistory, ng.FeatureToggles, ng.Log)
-	history, err := configureHistorianBackend(initCtx, ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.store, ng.Metrics.GetHistorianMetrics(), ng.Log)
+	history, err := configureHistorianBackend(initCtx, ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.store, ng.SQLStore, ng.Metrics.GetHistorianMetrics(), ng.Log)
 	if err != nil {
 		return err
 	}
@@ -220,8 +207,42 @@ func (ng *AlertNG) init() error {
 		Historian:               history,
 		DoNotSaveNormalState:    ng.FeatureToggles.IsEnabled(featuremgmt.FlagAlertingNoNormalState),
 		MaxStateSaveConcurrency: ng.Cfg.UnifiedAlerting.MaxStateSaveConcurrency,
+		MaxInstancesPerRule:     ng.Cfg.UnifiedAlerting.MaxInstancesPerRule,
+		MaxInstancesPerGroup:    ng.Cfg.UnifiedAlerting.MaxInstancesPerGroup,
+		OrgInstanceLimit:        newInstanceQuota(ng.QuotaService, clk).OrgLimit,
 	}
 	stateManager := state.NewManager(cfg)
+
+	evalFactory := eval.NewEvaluatorFactory(ng.Cfg.UnifiedAlerting, ng.DataSourceCache, ng.ExpressionService, ng.pluginsStore, stateManager)
+	var recordingWriter schedule.RecordingWriter
+	if ng.Cfg.UnifiedAlerting.RecordingRules.Enabled {
+		recordingWriter, err = writer.New(ng.Cfg.UnifiedAlerting.RecordingRules, ng.Metrics.GetSchedulerMetrics(), log.New("ngalert.writer"))
+		if err != nil {
+			return fmt.Errorf("failed to initialize recording rule writer: %w", err)
+		}
+	}
+
+	var membershipStore schedule.MembershipStore
+	if ng.shardsEvaluation() {
+		membershipStore = kvstore.WithNamespace(ng.KVStore, 0, "ngalert.sharding")
+	}
+
+	schedCfg := schedule.SchedulerCfg{
+		MaxAttempts:          ng.Cfg.UnifiedAlerting.MaxAttempts,
+		C:                    clk,
+		BaseInterval:         ng.Cfg.UnifiedAlerting.BaseInterval,
+		MinRuleInterval:      ng.Cfg.UnifiedAlerting.MinInterval,
+		DisableGrafanaFolder: ng.Cfg.UnifiedAlerting.ReservedLabels.IsReservedLabelDisabled(models.FolderTitleLabel),
+		AppURL:               appUrl,
+		EvaluatorFactory:     evalFactory,
+		RuleStore:            ng.store,
+		Metrics:              ng.Metrics.GetSchedulerMetrics(),
+		AlertSender:          alertsRouter,
+		RecordingWriter:      recordingWriter,
+		MembershipStore:      membershipStore,
+		InstanceName:         setting.InstanceName,
+		Tracer:               ng.tracer,
+	}
 	scheduler := schedule.NewScheduler(schedCfg, stateManager)
 
 	// if it is required to include folder title to the alerts, we need to subscribe to changes of alert title
@@ -230,6 +251,7 @@ func (ng *AlertNG) init() error {
 	}
 
 	ng.stateManager = stateManager
+	ng.historian = history
 	ng.schedule = scheduler
 
 	// Provisioning
@@ -283,6 +305,19 @@ func (ng *AlertNG) init() error {
 		return err
 	}
 
+	instanceLimits, err := instanceQuotaDefaultLimits(ng.Cfg.Quota.Org.AlertInstance)
+	if err != nil {
+		return err
+	}
+
+	if err := ng.QuotaService.RegisterQuotaReporter(&quota.NewUsageReporter{
+		TargetSrv:     models.InstanceQuotaTargetSrv,
+		DefaultLimits: instanceLimits,
+		Reporter:      ng.instanceUsage,
+	}); err != nil {
+		return err
+	}
+
 	log.RegisterContextualLogProvider(func(ctx context.Context) ([]interface{}, bool) {
 		key, ok := models.RuleKeyFromContext(ctx)
 		if !ok {
@@ -311,10 +346,18 @@ func subscribeToFolderChanges(logger log.Logger, bus bus.Bus, dbStore api.RuleSt
 	})
 }
 
+// shardsEvaluation returns true if the evaluation of rules is sharded across the Grafana instances of the cluster.
+func (ng *AlertNG) shardsEvaluation() bool {
+	return ng.Cfg.UnifiedAlerting.HAEvaluationSharding && ng.Cfg.UnifiedAlerting.ExecuteAlerts
+}
+
 // Run starts the scheduler and Alertmanager.
 func (ng *AlertNG) Run(ctx context.Context) error {
 	ng.Log.Debug("Starting")
-	ng.stateManager.Warm(ctx, ng.store)
+	// with sharding, the scheduler loads the state of the rules when they are assigned to this instance
+	if !ng.shardsEvaluation() {
+		ng.stateManager.Warm(ctx, ng.store)
+	}
 
 	children, subCtx := errgroup.WithContext(ctx)
 
@@ -329,6 +372,12 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 		return ng.AlertsRouter.Run(subCtx)
 	})
 
+	if runner, ok := ng.historian.(historian.Runner); ok {
+		children.Go(func() error {
+			return runner.Run(subCtx)
+		})
+	}
+
 	if ng.Cfg.UnifiedAlerting.ExecuteAlerts {
 		children.Go(func() error {
 			return ng.schedule.Run(subCtx)
@@ -385,7 +434,7 @@ type Historian interface {
 	state.Historian
 }
 
-func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, rs historian.RuleStore, met *metrics.Historian, l log.Logger) (Historian, error) {
+func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, rs historian.RuleStore, sqlStore db.DB, met *metrics.Historian, l log.Logger) (Historian, error) {
 	if !cfg.Enabled {
 		met.Info.WithLabelValues("noop").Set(0)
 		return historian.NewNopHistorian(), nil
@@ -400,7 +449,7 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 	if backend == historian.BackendTypeMultiple {
 		primaryCfg := cfg
 		primaryCfg.Backend = cfg.MultiPrimary
-		primary, err := configureHistorianBackend(ctx, primaryCfg, ar, ds, rs, met, l)
+		primary, err := configureHistorianBackend(ctx, primaryCfg, ar, ds, rs, sqlStore, met, l)
 		if err != nil {
 			return nil, fmt.Errorf("multi-backend target \"%s\" was misconfigured: %w", cfg.MultiPrimary, err)
 		}
@@ -409,7 +458,7 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 		for _, b := range cfg.MultiSecondaries {
 			secCfg := cfg
 			secCfg.Backend = b
-			sec, err := configureHistorianBackend(ctx, secCfg, ar, ds, rs, met, l)
+			sec, err := configureHistorianBackend(ctx, secCfg, ar, ds, rs, sqlStore, met, l)
 			if err != nil {
 				return nil, fmt.Errorf("multi-backend target \"%s\" was miconfigured: %w", b, err)
 			}
@@ -438,6 +487,9 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 		}
 		return backend, nil
 	}
+	if backend == historian.BackendTypeSQL {
+		return historian.NewSQLBackend(sqlStore, cfg.ExternalLabels, cfg.SQLRetention, met), nil
+	}
 
 	return nil, fmt.Errorf("unrecognized state history backend: %s", backend)
 }
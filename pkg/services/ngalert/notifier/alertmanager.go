@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"strconv"
+	"sync"
 	"time"
 
 	alertingNotify "github.com/grafana/alerting/notify"
@@ -56,6 +57,9 @@ type Alertmanager struct {
 
 	decryptFn alertingNotify.GetDecryptedValueFn
 	orgID     int64
+
+	notificationHistory *notificationHistory
+	silenceTemplatesMtx sync.Mutex
 }
 
 // maintenanceOptions represent the options for components that need maintenance on a frequency within the Alertmanager.
@@ -143,6 +147,7 @@ func newAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store A
 		decryptFn:           decryptFn,
 		fileStore:           fileStore,
 		logger:              l,
+		notificationHistory: newNotificationHistory(maxNotificationAttemptsPerReceiver),
 	}
 
 	return am, nil
@@ -317,16 +322,24 @@ func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 
 	am.updateConfigMetrics(cfg)
 
+	apiReceivers := PostableApiAlertingConfigToApiReceivers(cfg.AlertmanagerConfig)
 	err = am.Base.ApplyConfig(AlertingConfiguration{
 		rawAlertmanagerConfig:    rawConfig,
 		alertmanagerConfig:       cfg.AlertmanagerConfig,
-		receivers:                PostableApiAlertingConfigToApiReceivers(cfg.AlertmanagerConfig),
+		receivers:                apiReceivers,
 		receiverIntegrationsFunc: am.buildReceiverIntegrations,
 	})
 	if err != nil {
 		return false, err
 	}
 
+	// Forget the delivery attempts of receivers that no longer exist.
+	names := make([]string, 0, len(apiReceivers))
+	for _, r := range apiReceivers {
+		names = append(names, r.Name)
+	}
+	am.notificationHistory.retain(names)
+
 	return true, nil
 }
 
@@ -377,6 +390,9 @@ func (am *Alertmanager) buildReceiverIntegrations(receiver *alertingNotify.APIRe
 	if err != nil {
 		return nil, err
 	}
+	for i, integration := range integrations {
+		integrations[i] = am.notificationHistory.wrap(receiver, integration)
+	}
 	return integrations, nil
 }
 
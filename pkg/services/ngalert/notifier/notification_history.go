@@ -0,0 +1,234 @@
+package notifier
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+)
+
+const (
+	// maxNotificationAttemptsPerReceiver is the number of delivery attempts that are kept for each receiver.
+	maxNotificationAttemptsPerReceiver = 100
+	// maxNotificationPayloadSize is the maximum size, in bytes, of the payload that is kept for a delivery attempt.
+	maxNotificationPayloadSize = 4096
+)
+
+// NotificationAttempt is an attempt of an integration to deliver a notification for a group of alerts.
+type NotificationAttempt struct {
+	Receiver         string
+	IntegrationName  string
+	IntegrationUID   string
+	IntegrationIndex int
+	GroupKey         string
+	FiringAlerts     int
+	ResolvedAlerts   int
+	Timestamp        time.Time
+	Duration         time.Duration
+	// Retry is the number of previous attempts to deliver the same notification.
+	Retry int
+	// StatusCode and Payload are set only for integrations that send webhooks.
+	StatusCode int
+	Payload    string
+	Error      error
+
+	// pipelineTime identifies the flush of the aggregation group that the attempt belongs to.
+	pipelineTime time.Time
+}
+
+// NotificationSummary is the outcome of the last successful and the last failed attempt of an integration.
+type NotificationSummary struct {
+	LastSuccess      time.Time
+	LastFailure      time.Time
+	LastFailureError error
+}
+
+type integrationKey struct {
+	receiver string
+	name     string
+	index    int
+}
+
+// notificationHistory keeps the most recent delivery attempts of each receiver in memory.
+type notificationHistory struct {
+	mtx       sync.RWMutex
+	limit     int
+	attempts  map[string][]NotificationAttempt
+	summaries map[integrationKey]NotificationSummary
+}
+
+func newNotificationHistory(limit int) *notificationHistory {
+	return &notificationHistory{
+		limit:     limit,
+		attempts:  make(map[string][]NotificationAttempt),
+		summaries: make(map[integrationKey]NotificationSummary),
+	}
+}
+
+// record adds the attempt to the history of its receiver, dropping the oldest attempt if the history is full.
+func (h *notificationHistory) record(a NotificationAttempt) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	attempts := h.attempts[a.Receiver]
+	// Retries of the same notification are made with the same pipeline time.
+	for i := len(attempts) - 1; i >= 0; i-- {
+		prev := attempts[i]
+		if prev.IntegrationName == a.IntegrationName && prev.IntegrationIndex == a.IntegrationIndex && prev.GroupKey == a.GroupKey {
+			if prev.pipelineTime.Equal(a.pipelineTime) {
+				a.Retry = prev.Retry + 1
+			}
+			break
+		}
+	}
+	if len(attempts) >= h.limit {
+		attempts = attempts[len(attempts)-h.limit+1:]
+	}
+	h.attempts[a.Receiver] = append(attempts, a)
+
+	key := integrationKey{receiver: a.Receiver, name: a.IntegrationName, index: a.IntegrationIndex}
+	summary := h.summaries[key]
+	if a.Error != nil {
+		summary.LastFailure = a.Timestamp
+		summary.LastFailureError = a.Error
+	} else {
+		summary.LastSuccess = a.Timestamp
+	}
+	h.summaries[key] = summary
+}
+
+// get returns at most limit attempts of the receiver, the most recent first. If limit is not positive, all attempts are returned.
+func (h *notificationHistory) get(receiver string, limit int) []NotificationAttempt {
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+
+	attempts := h.attempts[receiver]
+	if limit <= 0 || limit > len(attempts) {
+		limit = len(attempts)
+	}
+	result := make([]NotificationAttempt, 0, limit)
+	for i := len(attempts) - 1; i >= 0 && len(result) < limit; i-- {
+		result = append(result, attempts[i])
+	}
+	return result
+}
+
+func (h *notificationHistory) summary(receiver, name string, index int) NotificationSummary {
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+	return h.summaries[integrationKey{receiver: receiver, name: name, index: index}]
+}
+
+// retain removes the history of all receivers that are not in the list.
+func (h *notificationHistory) retain(receivers []string) {
+	keep := make(map[string]struct{}, len(receivers))
+	for _, r := range receivers {
+		keep[r] = struct{}{}
+	}
+
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	for r := range h.attempts {
+		if _, ok := keep[r]; !ok {
+			delete(h.attempts, r)
+		}
+	}
+	for k := range h.summaries {
+		if _, ok := keep[k.receiver]; !ok {
+			delete(h.summaries, k)
+		}
+	}
+}
+
+// wrap returns an integration that records all its attempts to deliver notifications in the history.
+func (h *notificationHistory) wrap(receiver *alertingNotify.APIReceiver, integration *alertingNotify.Integration) *alertingNotify.Integration {
+	n := &recordingNotifier{
+		history:     h,
+		integration: integration,
+		receiver:    receiver.Name,
+		uid:         integrationUID(receiver, integration.Name(), integration.Index()),
+	}
+	return alertingNotify.NewIntegration(n, integration, integration.Name(), integration.Index())
+}
+
+// integrationUID returns the UID of the integration with the given type and index among the integrations of the same
+// type, which is how the integrations of a receiver are built.
+func integrationUID(receiver *alertingNotify.APIReceiver, name string, index int) string {
+	i := 0
+	for _, integration := range receiver.Integrations {
+		if !strings.EqualFold(integration.Type, name) {
+			continue
+		}
+		if i == index {
+			return integration.UID
+		}
+		i++
+	}
+	return ""
+}
+
+type recordingNotifier struct {
+	history     *notificationHistory
+	integration *alertingNotify.Integration
+	receiver    string
+	uid         string
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	webhook := &webhookAttempt{}
+	start := time.Now()
+	retry, err := n.integration.Notify(context.WithValue(ctx, webhookAttemptKey{}, webhook), alerts...)
+
+	groupKey, _ := notify.GroupKey(ctx)
+	pipelineTime, _ := notify.Now(ctx)
+	firing := 0
+	for _, a := range alerts {
+		if !a.Resolved() {
+			firing++
+		}
+	}
+	n.history.record(NotificationAttempt{
+		Receiver:         n.receiver,
+		IntegrationName:  n.integration.Name(),
+		IntegrationUID:   n.uid,
+		IntegrationIndex: n.integration.Index(),
+		GroupKey:         groupKey,
+		FiringAlerts:     firing,
+		ResolvedAlerts:   len(alerts) - firing,
+		Timestamp:        start,
+		Duration:         time.Since(start),
+		StatusCode:       webhook.statusCode,
+		Payload:          webhook.payload,
+		Error:            err,
+		pipelineTime:     pipelineTime,
+	})
+	return retry, err
+}
+
+func (n *recordingNotifier) SendResolved() bool {
+	return n.integration.SendResolved()
+}
+
+type webhookAttemptKey struct{}
+
+// webhookAttempt is populated by the webhook sender with the details of the request of an integration.
+type webhookAttempt struct {
+	statusCode int
+	payload    string
+}
+
+func webhookAttemptFromContext(ctx context.Context) *webhookAttempt {
+	a, _ := ctx.Value(webhookAttemptKey{}).(*webhookAttempt)
+	return a
+}
+
+func truncatePayload(payload string) string {
+	if len(payload) <= maxNotificationPayloadSize {
+		return payload
+	}
+	return payload[:maxNotificationPayloadSize]
+}
@@ -0,0 +1,168 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/grafana/alerting/receivers"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/notifications"
+)
+
+type fakeWebhookNotifier struct {
+	sender receivers.WebhookSender
+	body   string
+}
+
+func (n *fakeWebhookNotifier) Notify(ctx context.Context, _ ...*types.Alert) (bool, error) {
+	err := n.sender.SendWebhook(ctx, &receivers.SendWebhookSettings{URL: "http://localhost", Body: n.body})
+	return err != nil, err
+}
+
+func (n *fakeWebhookNotifier) SendResolved() bool {
+	return true
+}
+
+func TestNotificationHistory(t *testing.T) {
+	receiver := &alertingNotify.APIReceiver{
+		ConfigReceiver: alertingNotify.ConfigReceiver{Name: "team-a"},
+		GrafanaIntegrations: alertingNotify.GrafanaIntegrations{
+			Integrations: []*alertingNotify.GrafanaIntegrationConfig{
+				{UID: "email-uid", Type: "email"},
+				{UID: "webhook-uid-1", Type: "webhook"},
+				{UID: "webhook-uid-2", Type: "webhook"},
+			},
+		},
+	}
+
+	newIntegration := func(ns *notifications.NotificationServiceMock, body string) (*notificationHistory, *alertingNotify.Integration) {
+		h := newNotificationHistory(3)
+		n := &fakeWebhookNotifier{sender: sender{ns}, body: body}
+		return h, h.wrap(receiver, alertingNotify.NewIntegration(n, n, "webhook", 1))
+	}
+
+	alerts := []*types.Alert{
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "a"}}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "b"}, EndsAt: time.Now().Add(-time.Minute)}},
+	}
+
+	pipelineContext := func(groupKey string, now time.Time) context.Context {
+		return notify.WithNow(notify.WithGroupKey(context.Background(), groupKey), now)
+	}
+
+	t.Run("records the delivery attempts of webhooks", func(t *testing.T) {
+		ns := notifications.MockNotificationService()
+		ns.WebhookHandler = func(_ context.Context, cmd *notifications.SendWebhookSync) error {
+			require.NoError(t, cmd.Validation(nil, 503))
+			return errors.New("webhook response status 503 Service Unavailable")
+		}
+		h, integration := newIntegration(ns, `{"status":"firing"}`)
+
+		now := time.Now()
+		_, err := integration.Notify(pipelineContext("group-1", now), alerts...)
+		require.Error(t, err)
+
+		// the webhook succeeds on the second attempt
+		ns.WebhookHandler = func(_ context.Context, cmd *notifications.SendWebhookSync) error {
+			return cmd.Validation(nil, 200)
+		}
+		_, err = integration.Notify(pipelineContext("group-1", now), alerts...)
+		require.NoError(t, err)
+
+		attempts := h.get("team-a", 0)
+		require.Len(t, attempts, 2)
+
+		success, failure := attempts[0], attempts[1]
+		require.Equal(t, "team-a", failure.Receiver)
+		require.Equal(t, "webhook", failure.IntegrationName)
+		require.Equal(t, "webhook-uid-2", failure.IntegrationUID)
+		require.Equal(t, 1, failure.IntegrationIndex)
+		require.Equal(t, "group-1", failure.GroupKey)
+		require.Equal(t, 1, failure.FiringAlerts)
+		require.Equal(t, 1, failure.ResolvedAlerts)
+		require.Equal(t, 503, failure.StatusCode)
+		require.Equal(t, `{"status":"firing"}`, failure.Payload)
+		require.EqualError(t, failure.Error, "webhook response status 503 Service Unavailable")
+		require.Equal(t, 0, failure.Retry)
+
+		require.Equal(t, 200, success.StatusCode)
+		require.NoError(t, success.Error)
+		require.Equal(t, 1, success.Retry)
+
+		summary := h.summary("team-a", "webhook", 1)
+		require.Equal(t, success.Timestamp, summary.LastSuccess)
+		require.Equal(t, failure.Timestamp, summary.LastFailure)
+		require.Equal(t, failure.Error, summary.LastFailureError)
+	})
+
+	t.Run("notifications of a later flush are not retries", func(t *testing.T) {
+		h, integration := newIntegration(notifications.MockNotificationService(), "")
+
+		now := time.Now()
+		_, err := integration.Notify(pipelineContext("group-1", now), alerts...)
+		require.NoError(t, err)
+		_, err = integration.Notify(pipelineContext("group-1", now.Add(time.Minute)), alerts...)
+		require.NoError(t, err)
+		_, err = integration.Notify(pipelineContext("group-2", now.Add(time.Minute)), alerts...)
+		require.NoError(t, err)
+
+		for _, a := range h.get("team-a", 0) {
+			require.Equal(t, 0, a.Retry)
+		}
+	})
+
+	t.Run("keeps only the most recent attempts", func(t *testing.T) {
+		h, integration := newIntegration(notifications.MockNotificationService(), "")
+
+		now := time.Now()
+		for i := 0; i < 5; i++ {
+			_, err := integration.Notify(pipelineContext(fmt.Sprintf("group-%d", i), now), alerts...)
+			require.NoError(t, err)
+		}
+
+		attempts := h.get("team-a", 0)
+		require.Len(t, attempts, 3)
+		require.Equal(t, "group-4", attempts[0].GroupKey)
+		require.Equal(t, "group-3", attempts[1].GroupKey)
+		require.Equal(t, "group-2", attempts[2].GroupKey)
+
+		attempts = h.get("team-a", 1)
+		require.Len(t, attempts, 1)
+		require.Equal(t, "group-4", attempts[0].GroupKey)
+	})
+
+	t.Run("truncates large payloads", func(t *testing.T) {
+		h, integration := newIntegration(notifications.MockNotificationService(), strings.Repeat("a", maxNotificationPayloadSize+1))
+
+		_, err := integration.Notify(pipelineContext("group-1", time.Now()), alerts...)
+		require.NoError(t, err)
+
+		attempts := h.get("team-a", 0)
+		require.Len(t, attempts, 1)
+		require.Len(t, attempts[0].Payload, maxNotificationPayloadSize)
+	})
+
+	t.Run("forgets receivers that no longer exist", func(t *testing.T) {
+		h, integration := newIntegration(notifications.MockNotificationService(), "")
+
+		_, err := integration.Notify(pipelineContext("group-1", time.Now()), alerts...)
+		require.NoError(t, err)
+
+		h.retain([]string{"team-a", "team-b"})
+		require.Len(t, h.get("team-a", 0), 1)
+		require.False(t, h.summary("team-a", "webhook", 1).LastSuccess.IsZero())
+
+		h.retain([]string{"team-b"})
+		require.Empty(t, h.get("team-a", 0))
+		require.True(t, h.summary("team-a", "webhook", 1).LastSuccess.IsZero())
+	})
+}
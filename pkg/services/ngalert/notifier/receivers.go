@@ -3,6 +3,7 @@ package notifier
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"time"
 
 	alertingNotify "github.com/grafana/alerting/notify"
@@ -14,6 +15,8 @@ import (
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 )
 
+var ErrReceiverNotFound = errors.New("receiver not found")
+
 type TestReceiversResult struct {
 	Alert     types.Alert
 	Receivers []TestReceiverResult
@@ -95,22 +98,23 @@ func (am *Alertmanager) GetReceivers(_ context.Context) []apimodels.Receiver {
 	apiReceivers := make([]apimodels.Receiver, 0, len(am.Base.GetReceivers()))
 	for _, rcv := range am.Base.GetReceivers() {
 		// Build integrations slice for each receiver.
-		integrations := make([]*models.Integration, 0, len(rcv.Integrations()))
+		integrations := make([]*apimodels.Integration, 0, len(rcv.Integrations()))
 		for _, integration := range rcv.Integrations() {
 			name := integration.Name()
 			sendResolved := integration.SendResolved()
 			ts, d, err := integration.GetReport()
+			summary := am.notificationHistory.summary(rcv.Name(), name, integration.Index())
 			integrations = append(integrations, &apimodels.Integration{
-				Name:                      &name,
-				SendResolved:              &sendResolved,
-				LastNotifyAttempt:         strfmt.DateTime(ts),
-				LastNotifyAttemptDuration: d.String(),
-				LastNotifyAttemptError: func() string {
-					if err != nil {
-						return err.Error()
-					}
-					return ""
-				}(),
+				Integration: models.Integration{
+					Name:                      &name,
+					SendResolved:              &sendResolved,
+					LastNotifyAttempt:         strfmt.DateTime(ts),
+					LastNotifyAttemptDuration: d.String(),
+					LastNotifyAttemptError:    errorString(err),
+				},
+				LastNotifySuccess:      strfmt.DateTime(summary.LastSuccess),
+				LastNotifyFailure:      strfmt.DateTime(summary.LastFailure),
+				LastNotifyFailureError: errorString(summary.LastFailureError),
 			})
 		}
 
@@ -125,3 +129,21 @@ func (am *Alertmanager) GetReceivers(_ context.Context) []apimodels.Receiver {
 
 	return apiReceivers
 }
+
+// GetReceiverNotifications returns at most limit of the most recent attempts of the receiver to deliver notifications,
+// the most recent first. If limit is not positive, all the attempts that are kept are returned.
+func (am *Alertmanager) GetReceiverNotifications(_ context.Context, receiver string, limit int) ([]NotificationAttempt, error) {
+	for _, rcv := range am.Base.GetReceivers() {
+		if rcv.Name() == receiver {
+			return am.notificationHistory.get(receiver, limit), nil
+		}
+	}
+	return nil, ErrReceiverNotFound
+}
+
+func errorString(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return ""
+}
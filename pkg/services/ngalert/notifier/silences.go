@@ -1,7 +1,32 @@
 package notifier
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-openapi/strfmt"
 	alertingNotify "github.com/grafana/alerting/notify"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/alertmanager/types"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	silenceTemplatesKey = "silence_templates"
+	// muteTimingSearchHorizon is how far ahead the next time a mute timing is active is searched for.
+	muteTimingSearchHorizon = 366 * 24 * time.Hour
+)
+
+var (
+	ErrSilenceTemplateNotFound = errors.New("silence template not found")
+	ErrSilenceTemplateInvalid  = errors.New("invalid silence template")
 )
 
 func (am *Alertmanager) ListSilences(filter []string) (alertingNotify.GettableSilences, error) {
@@ -19,3 +44,318 @@ func (am *Alertmanager) CreateSilence(ps *alertingNotify.PostableSilence) (strin
 func (am *Alertmanager) DeleteSilence(silenceID string) error {
 	return am.Base.DeleteSilence(silenceID)
 }
+
+// CreateSilences creates or updates all the silences. If one of them cannot be created, the changes made before are
+// rolled back: the silences that were created are expired again and the silences that were updated are restored to
+// their previous version. Then the error is returned.
+func (am *Alertmanager) CreateSilences(silences []alertingNotify.PostableSilence) ([]string, error) {
+	ids := make([]string, 0, len(silences))
+	previous := make([]*alertingNotify.GettableSilence, 0, len(silences))
+	for i := range silences {
+		var prev *alertingNotify.GettableSilence
+		if silences[i].ID != "" {
+			s, err := am.Base.GetSilence(silences[i].ID)
+			if err != nil {
+				am.rollbackSilences(ids, previous)
+				return nil, fmt.Errorf("silence %d: %w", i, err)
+			}
+			prev = &s
+		}
+		id, err := am.Base.CreateSilence(&silences[i])
+		if err != nil {
+			am.rollbackSilences(ids, previous)
+			return nil, fmt.Errorf("silence %d: %w", i, err)
+		}
+		ids = append(ids, id)
+		previous = append(previous, prev)
+	}
+	return ids, nil
+}
+
+// rollbackSilences reverts the changes of CreateSilences. ids are the IDs returned for the silences and previous are
+// the versions of the silences before they were updated, or nil for the silences that were created.
+func (am *Alertmanager) rollbackSilences(ids []string, previous []*alertingNotify.GettableSilence) {
+	for i := len(ids) - 1; i >= 0; i-- {
+		prev := previous[i]
+		// An update that cannot be made in place, for example because the matchers changed, expires the previous
+		// silence and creates a new one with a different ID.
+		if prev == nil || *prev.ID != ids[i] {
+			if err := am.Base.DeleteSilence(ids[i]); err != nil {
+				am.logger.Error("Failed to expire silence after a failed bulk create", "silenceID", ids[i], "error", err)
+			}
+		}
+		if prev == nil || (prev.Status != nil && prev.Status.State != nil && *prev.Status.State == string(types.SilenceStateExpired)) {
+			continue
+		}
+		ps := alertingNotify.PostableSilence{ID: *prev.ID, Silence: prev.Silence}
+		if _, err := am.Base.CreateSilence(&ps); err != nil {
+			am.logger.Error("Failed to restore silence after a failed bulk create", "silenceID", *prev.ID, "error", err)
+		}
+	}
+}
+
+// ExpireSilences expires all active and pending silences that have all the given matchers, and returns their IDs.
+func (am *Alertmanager) ExpireSilences(matchers amv2.Matchers) ([]string, error) {
+	silences, err := am.Base.ListSilences(nil)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0)
+	for _, s := range silences {
+		if s.Status == nil || s.Status.State == nil || *s.Status.State == string(types.SilenceStateExpired) {
+			continue
+		}
+		if !containsMatchers(s.Matchers, matchers) {
+			continue
+		}
+		if err := am.Base.DeleteSilence(*s.ID); err != nil {
+			if errors.Is(err, alertingNotify.ErrSilenceNotFound) {
+				continue
+			}
+			return ids, err
+		}
+		ids = append(ids, *s.ID)
+	}
+	return ids, nil
+}
+
+// containsMatchers returns true if all matchers in subset are also in set.
+func containsMatchers(set, subset amv2.Matchers) bool {
+	for _, m := range subset {
+		found := false
+		for _, n := range set {
+			if matcherEqual(m, n) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func matcherEqual(a, b *amv2.Matcher) bool {
+	// IsEqual is true if it is not set.
+	isEqual := func(m *amv2.Matcher) bool { return m.IsEqual == nil || *m.IsEqual }
+	return stringValue(a.Name) == stringValue(b.Name) &&
+		stringValue(a.Value) == stringValue(b.Value) &&
+		boolValue(a.IsRegex) == boolValue(b.IsRegex) &&
+		isEqual(a) == isEqual(b)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func boolValue(b *bool) bool {
+	return b != nil && *b
+}
+
+// GetSilenceTemplates returns all silence templates of the organization, sorted by name.
+func (am *Alertmanager) GetSilenceTemplates(ctx context.Context) ([]apimodels.SilenceTemplate, error) {
+	content, exists, err := am.fileStore.kv.Get(ctx, silenceTemplatesKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read silence templates: %w", err)
+	}
+	templates := []apimodels.SilenceTemplate{}
+	if !exists {
+		return templates, nil
+	}
+	if err := json.Unmarshal([]byte(content), &templates); err != nil {
+		return nil, fmt.Errorf("failed to parse silence templates: %w", err)
+	}
+	return templates, nil
+}
+
+// SaveSilenceTemplate creates the silence template or replaces the template with the same name.
+func (am *Alertmanager) SaveSilenceTemplate(ctx context.Context, template apimodels.SilenceTemplate) error {
+	if err := template.Validate(); err != nil {
+		return fmt.Errorf("%w: %s", ErrSilenceTemplateInvalid, err)
+	}
+
+	if _, err := am.getMuteTiming(ctx, template.MuteTiming); err != nil {
+		return err
+	}
+
+	am.silenceTemplatesMtx.Lock()
+	defer am.silenceTemplatesMtx.Unlock()
+
+	templates, err := am.GetSilenceTemplates(ctx)
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range templates {
+		if templates[i].Name == template.Name {
+			templates[i] = template
+			replaced = true
+		}
+	}
+	if !replaced {
+		templates = append(templates, template)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return am.saveSilenceTemplates(ctx, templates)
+}
+
+// DeleteSilenceTemplate deletes the silence template. It returns ErrSilenceTemplateNotFound if the template does not exist.
+func (am *Alertmanager) DeleteSilenceTemplate(ctx context.Context, name string) error {
+	am.silenceTemplatesMtx.Lock()
+	defer am.silenceTemplatesMtx.Unlock()
+
+	templates, err := am.GetSilenceTemplates(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range templates {
+		if templates[i].Name == name {
+			return am.saveSilenceTemplates(ctx, append(templates[:i], templates[i+1:]...))
+		}
+	}
+	return ErrSilenceTemplateNotFound
+}
+
+func (am *Alertmanager) saveSilenceTemplates(ctx context.Context, templates []apimodels.SilenceTemplate) error {
+	b, err := json.Marshal(templates)
+	if err != nil {
+		return err
+	}
+	if err := am.fileStore.kv.Set(ctx, silenceTemplatesKey, string(b)); err != nil {
+		return fmt.Errorf("failed to save silence templates: %w", err)
+	}
+	return nil
+}
+
+// ApplySilenceTemplate creates a silence with the matchers of the template, which is active the next time the mute
+// timing of the template is active. If the mute timing is active at the moment, the silence starts immediately.
+func (am *Alertmanager) ApplySilenceTemplate(ctx context.Context, name string, createdBy string) (alertingNotify.GettableSilence, error) {
+	templates, err := am.GetSilenceTemplates(ctx)
+	if err != nil {
+		return alertingNotify.GettableSilence{}, err
+	}
+	var template *apimodels.SilenceTemplate
+	for i := range templates {
+		if templates[i].Name == name {
+			template = &templates[i]
+			break
+		}
+	}
+	if template == nil {
+		return alertingNotify.GettableSilence{}, ErrSilenceTemplateNotFound
+	}
+
+	intervals, err := am.getMuteTiming(ctx, template.MuteTiming)
+	if err != nil {
+		return alertingNotify.GettableSilence{}, err
+	}
+
+	start, end, ok := nextActiveWindow(intervals, time.Now(), muteTimingSearchHorizon)
+	if !ok {
+		return alertingNotify.GettableSilence{}, fmt.Errorf("%w: mute timing %q is not active within the next year", ErrSilenceTemplateInvalid, template.MuteTiming)
+	}
+
+	startsAt, endsAt := strfmt.DateTime(start), strfmt.DateTime(end)
+	comment := template.Comment
+	if comment == "" {
+		comment = fmt.Sprintf("Created from silence template %s", template.Name)
+	}
+	ps := alertingNotify.PostableSilence{
+		Silence: amv2.Silence{
+			Matchers:  template.Matchers,
+			StartsAt:  &startsAt,
+			EndsAt:    &endsAt,
+			Comment:   &comment,
+			CreatedBy: &createdBy,
+		},
+	}
+	id, err := am.Base.CreateSilence(&ps)
+	if err != nil {
+		return alertingNotify.GettableSilence{}, err
+	}
+	return am.Base.GetSilence(id)
+}
+
+// getMuteTiming returns the time intervals of the mute timing in the latest Alertmanager configuration.
+func (am *Alertmanager) getMuteTiming(ctx context.Context, name string) ([]timeinterval.TimeInterval, error) {
+	query := ngmodels.GetLatestAlertmanagerConfigurationQuery{OrgID: am.orgID}
+	amConfig, err := am.Store.GetLatestAlertmanagerConfiguration(ctx, &query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the Alertmanager configuration: %w", err)
+	}
+	cfg, err := Load([]byte(amConfig.AlertmanagerConfiguration))
+	if err != nil {
+		return nil, err
+	}
+	for _, mt := range cfg.AlertmanagerConfig.MuteTimeIntervals {
+		if mt.Name == name {
+			return mt.TimeIntervals, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: mute timing %q does not exist", ErrSilenceTemplateInvalid, name)
+}
+
+// nextActiveWindow returns the start and the end of the next period, starting at or after now, during which at least
+// one of the intervals is active. Whether an interval is active can only change at the start or the end of one of its
+// time ranges and at midnight, so the search steps from one of these boundaries to the next. The search ends at
+// now+horizon, which is also the end of a period that has not ended by then.
+func nextActiveWindow(intervals []timeinterval.TimeInterval, now time.Time, horizon time.Duration) (time.Time, time.Time, bool) {
+	if len(intervals) == 0 {
+		return time.Time{}, time.Time{}, false
+	}
+	active := func(t time.Time) bool {
+		for _, ti := range intervals {
+			if ti.ContainsTime(t.UTC()) {
+				return true
+			}
+		}
+		return false
+	}
+
+	limit := now.Add(horizon)
+	start := now
+	for !active(start) {
+		start = nextIntervalBoundary(intervals, start)
+		if start.After(limit) {
+			return time.Time{}, time.Time{}, false
+		}
+	}
+	end := nextIntervalBoundary(intervals, start)
+	for end.Before(limit) && active(end) {
+		end = nextIntervalBoundary(intervals, end)
+	}
+	if end.After(limit) {
+		end = limit
+	}
+	return start, end, true
+}
+
+// nextIntervalBoundary returns the first time after t at which one of the intervals can become active or inactive,
+// that is the next start or end of one of their time ranges or the next midnight in their location.
+func nextIntervalBoundary(intervals []timeinterval.TimeInterval, t time.Time) time.Time {
+	var next time.Time
+	for _, ti := range intervals {
+		loc := time.UTC
+		if ti.Location != nil {
+			loc = ti.Location.Location
+		}
+		lt := t.In(loc)
+		year, month, day := lt.Date()
+		candidates := []time.Time{time.Date(year, month, day+1, 0, 0, 0, 0, loc)}
+		for _, tr := range ti.Times {
+			candidates = append(candidates,
+				time.Date(year, month, day, 0, tr.StartMinute, 0, 0, loc),
+				time.Date(year, month, day, 0, tr.EndMinute, 0, 0, loc))
+		}
+		for _, c := range candidates {
+			if c.After(t) && (next.IsZero() || c.Before(next)) {
+				next = c
+			}
+		}
+	}
+	return next
+}
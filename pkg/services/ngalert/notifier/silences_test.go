@@ -0,0 +1,143 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	alertingNotify "github.com/grafana/alerting/notify"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestNextActiveWindow(t *testing.T) {
+	// 2023-05-10 is a Wednesday.
+	now := time.Date(2023, 5, 10, 10, 30, 15, 0, time.UTC)
+
+	businessHours := timeinterval.TimeInterval{
+		Times: []timeinterval.TimeRange{{StartMinute: 9 * 60, EndMinute: 17 * 60}},
+	}
+	weekends := timeinterval.TimeInterval{
+		Weekdays: []timeinterval.WeekdayRange{{InclusiveRange: timeinterval.InclusiveRange{Begin: 6, End: 6}}, {InclusiveRange: timeinterval.InclusiveRange{Begin: 0, End: 0}}},
+	}
+	lastYear := timeinterval.TimeInterval{
+		Years: []timeinterval.YearRange{{InclusiveRange: timeinterval.InclusiveRange{Begin: 2022, End: 2022}}},
+	}
+
+	t.Run("starts now if the mute timing is active", func(t *testing.T) {
+		start, end, ok := nextActiveWindow([]timeinterval.TimeInterval{businessHours}, now, muteTimingSearchHorizon)
+		require.True(t, ok)
+		require.Equal(t, now, start)
+		require.Equal(t, time.Date(2023, 5, 10, 17, 0, 0, 0, time.UTC), end)
+	})
+
+	t.Run("starts when the mute timing is next active", func(t *testing.T) {
+		start, end, ok := nextActiveWindow([]timeinterval.TimeInterval{weekends}, now, muteTimingSearchHorizon)
+		require.True(t, ok)
+		require.Equal(t, time.Date(2023, 5, 13, 0, 0, 0, 0, time.UTC), start)
+		require.Equal(t, time.Date(2023, 5, 15, 0, 0, 0, 0, time.UTC), end)
+	})
+
+	t.Run("ends at the horizon if the mute timing is always active", func(t *testing.T) {
+		start, end, ok := nextActiveWindow([]timeinterval.TimeInterval{{}}, now, time.Hour)
+		require.True(t, ok)
+		require.Equal(t, now, start)
+		require.Equal(t, now.Add(time.Hour), end)
+	})
+
+	t.Run("not found if the mute timing is never active", func(t *testing.T) {
+		_, _, ok := nextActiveWindow([]timeinterval.TimeInterval{lastYear}, now, muteTimingSearchHorizon)
+		require.False(t, ok)
+	})
+}
+
+func TestContainsMatchers(t *testing.T) {
+	matcher := func(name, value string, isRegex bool, isEqual *bool) *amv2.Matcher {
+		return &amv2.Matcher{Name: util.Pointer(name), Value: util.Pointer(value), IsRegex: util.Pointer(isRegex), IsEqual: isEqual}
+	}
+	set := amv2.Matchers{
+		matcher("cluster", "prod", false, nil),
+		matcher("team", "a|b", true, util.Pointer(true)),
+	}
+
+	require.True(t, containsMatchers(set, nil))
+	require.True(t, containsMatchers(set, amv2.Matchers{matcher("cluster", "prod", false, util.Pointer(true))}))
+	require.True(t, containsMatchers(set, amv2.Matchers{matcher("team", "a|b", true, nil), matcher("cluster", "prod", false, nil)}))
+	require.False(t, containsMatchers(set, amv2.Matchers{matcher("cluster", "prod", false, util.Pointer(false))}))
+	require.False(t, containsMatchers(set, amv2.Matchers{matcher("team", "a|b", false, nil)}))
+	require.False(t, containsMatchers(set, amv2.Matchers{matcher("cluster", "prod", false, nil), matcher("env", "dev", false, nil)}))
+}
+
+func TestCreateSilences(t *testing.T) {
+	am := setupAMTest(t)
+	silence := func(id, value, comment string) alertingNotify.PostableSilence {
+		startsAt, endsAt := strfmt.DateTime(time.Now()), strfmt.DateTime(time.Now().Add(time.Hour))
+		return alertingNotify.PostableSilence{
+			ID: id,
+			Silence: amv2.Silence{
+				Matchers:  amv2.Matchers{{Name: util.Pointer("team"), Value: util.Pointer(value), IsRegex: util.Pointer(false)}},
+				StartsAt:  &startsAt,
+				EndsAt:    &endsAt,
+				Comment:   util.Pointer(comment),
+				CreatedBy: util.Pointer("test"),
+			},
+		}
+	}
+	state := func(id string) string {
+		s, err := am.GetSilence(id)
+		require.NoError(t, err)
+		return *s.Status.State
+	}
+
+	existingID, err := am.CreateSilence(util.Pointer(silence("", "a", "original")))
+	require.NoError(t, err)
+	otherID, err := am.CreateSilence(util.Pointer(silence("", "b", "original")))
+	require.NoError(t, err)
+
+	t.Run("rolls back created and updated silences if one of them fails", func(t *testing.T) {
+		before, err := am.ListSilences(nil)
+		require.NoError(t, err)
+
+		_, err = am.CreateSilences([]alertingNotify.PostableSilence{
+			silence("", "c", "new"),
+			silence(existingID, "a", "updated"),
+			silence(otherID, "changed", "updated"),
+			silence("unknown", "d", "invalid"),
+		})
+		require.ErrorIs(t, err, alertingNotify.ErrSilenceNotFound)
+
+		after, err := am.ListSilences(nil)
+		require.NoError(t, err)
+		require.Greater(t, len(after), len(before))
+		active := []string{}
+		for _, s := range after {
+			if *s.Status.State == string(types.SilenceStateExpired) {
+				continue
+			}
+			require.Equal(t, "original", *s.Comment, "only the original silences should be active")
+			active = append(active, *s.Matchers[0].Value)
+		}
+		require.ElementsMatch(t, []string{"a", "b"}, active)
+		existing, err := am.GetSilence(existingID)
+		require.NoError(t, err)
+		require.Equal(t, "original", *existing.Comment)
+		require.Equal(t, string(types.SilenceStateActive), state(existingID))
+	})
+
+	t.Run("returns the IDs of all silences", func(t *testing.T) {
+		ids, err := am.CreateSilences([]alertingNotify.PostableSilence{
+			silence("", "e", "new"),
+			silence(existingID, "a", "updated"),
+		})
+		require.NoError(t, err)
+		require.Len(t, ids, 2)
+		require.Equal(t, string(types.SilenceStateActive), state(ids[0]))
+		existing, err := am.GetSilence(ids[1])
+		require.NoError(t, err)
+		require.Equal(t, "updated", *existing.Comment)
+	})
+}
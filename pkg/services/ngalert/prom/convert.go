@@ -0,0 +1,259 @@
+// Package prom converts Prometheus rule files to Grafana managed alert rules.
+package prom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/prometheus/model/rulefmt"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+var (
+	// ErrInvalidRuleFile is returned if the content is not a valid Prometheus rule file.
+	ErrInvalidRuleFile = errors.New("invalid Prometheus rule file")
+	// ErrUnsupportedRule is returned if a rule uses a feature that Grafana managed alert rules do not support.
+	ErrUnsupportedRule = errors.New("unsupported rule")
+)
+
+const (
+	// DefaultQueryTimeRange is the relative time range of the queries if Config.QueryTimeRange is not set.
+	DefaultQueryTimeRange = 10 * time.Minute
+
+	queryRefID     = "A"
+	conditionRefID = "B"
+	// conditionExpression is true for every series returned by the query because a Prometheus alerting rule
+	// fires for every series its expression returns, regardless of the value.
+	conditionExpression = "is_number($A) || is_nan($A) || is_inf($A)"
+)
+
+var (
+	// templateActionRegex matches the actions of a template, in which variables are expanded.
+	templateActionRegex = regexp.MustCompile(`(?s){{.*?}}`)
+	// valueVariableRegex matches the $value variable, but not $values.
+	valueVariableRegex = regexp.MustCompile(`\$value\b`)
+	// unsupportedTemplateRegex matches the variables and functions of Prometheus templates that Grafana does not have.
+	unsupportedTemplateRegex = regexp.MustCompile(`\$externalLabels\b|\$externalURL\b|\bquery\b`)
+)
+
+// Warning describes a part of a rule that behaves differently after the conversion.
+type Warning struct {
+	Group   string
+	Rule    string
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("rule '%s' of group '%s': %s", w.Rule, w.Group, w.Message)
+}
+
+// Config contains the settings of the conversion.
+type Config struct {
+	// DatasourceUID is the UID of the Prometheus compatible data source that executes the queries of the rules.
+	DatasourceUID string
+	// DefaultInterval is the evaluation interval of groups that do not specify an interval.
+	DefaultInterval time.Duration
+	// QueryTimeRange is the relative time range of the queries. DefaultQueryTimeRange is used if it is zero.
+	QueryTimeRange time.Duration
+	// RecordingRules allows conversion of recording rules. If it is false, recording rules cause ErrUnsupportedRule.
+	RecordingRules bool
+}
+
+// ParseRuleGroups parses and validates the content of a Prometheus rule file.
+func ParseRuleGroups(content []byte) ([]rulefmt.RuleGroup, error) {
+	groups, errs := rulefmt.Parse(content)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRuleFile, errors.Join(errs...))
+	}
+	return groups.Groups, nil
+}
+
+// ConvertRuleGroups converts Prometheus rule groups to groups of Grafana managed alert rules in the folder namespaceUID.
+//
+// Every rule gets a single instant query of the data source with the expression of the rule. The condition of an
+// alerting rule is a math expression that is true for every series the query returns. Because titles of Grafana
+// managed alert rules must be unique in a folder, a number is appended to the title of rules with the same name.
+// UIDs of the rules are derived from the folder, group and title, so converting the same file again produces the
+// same rules, and they can be used to update the existing ones.
+//
+// Templates in labels and annotations are converted with convertTemplate. Warnings are returned for the parts of
+// the templates that cannot be converted.
+func ConvertRuleGroups(orgID int64, namespaceUID string, groups []rulefmt.RuleGroup, cfg Config) ([]models.AlertRuleGroup, []Warning, error) {
+	if cfg.DatasourceUID == "" {
+		return nil, nil, errors.New("data source UID must be specified")
+	}
+	if cfg.QueryTimeRange == 0 {
+		cfg.QueryTimeRange = DefaultQueryTimeRange
+	}
+
+	titles := make(map[string]struct{})
+	uniqueTitle := func(name string) string {
+		title := name
+		for i := 2; ; i++ {
+			if _, ok := titles[title]; !ok {
+				break
+			}
+			title = fmt.Sprintf("%s (%d)", name, i)
+		}
+		titles[title] = struct{}{}
+		return title
+	}
+
+	result := make([]models.AlertRuleGroup, 0, len(groups))
+	var warnings []Warning
+	for _, group := range groups {
+		if group.Limit != 0 {
+			return nil, nil, fmt.Errorf("%w: group '%s' has a limit of alerts, which is not supported", ErrUnsupportedRule, group.Name)
+		}
+		interval := time.Duration(group.Interval)
+		if interval == 0 {
+			interval = cfg.DefaultInterval
+		}
+		converted := models.AlertRuleGroup{
+			Title:      group.Name,
+			FolderUID:  namespaceUID,
+			Interval:   int64(interval.Seconds()),
+			Provenance: models.ProvenanceConvertedPrometheus,
+			Rules:      make([]models.AlertRule, 0, len(group.Rules)),
+		}
+		for idx, node := range group.Rules {
+			rule, messages, err := convertRule(node, cfg)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to convert rule %d of group '%s': %w", idx+1, group.Name, err)
+			}
+			// the store sets the default properties of the queries, which would otherwise be changes on every import
+			for i := range rule.Data {
+				if err := rule.Data[i].PreSave(); err != nil {
+					return nil, nil, fmt.Errorf("failed to convert rule %d of group '%s': %w", idx+1, group.Name, err)
+				}
+			}
+			rule.Title = uniqueTitle(rule.Title)
+			for _, msg := range messages {
+				warnings = append(warnings, Warning{Group: group.Name, Rule: rule.Title, Message: msg})
+			}
+			rule.UID = ruleUID(orgID, namespaceUID, group.Name, rule.Title)
+			rule.OrgID = orgID
+			rule.NamespaceUID = namespaceUID
+			rule.RuleGroup = group.Name
+			rule.RuleGroupIndex = idx + 1
+			rule.IntervalSeconds = converted.Interval
+			converted.Rules = append(converted.Rules, rule)
+		}
+		result = append(result, converted)
+	}
+	return result, warnings, nil
+}
+
+func convertRule(node rulefmt.RuleNode, cfg Config) (models.AlertRule, []string, error) {
+	if node.KeepFiringFor != 0 {
+		return models.AlertRule{}, nil, fmt.Errorf("%w: keep_firing_for is not supported", ErrUnsupportedRule)
+	}
+	query, err := json.Marshal(map[string]interface{}{
+		"refId":   queryRefID,
+		"expr":    node.Expr.Value,
+		"instant": true,
+		"range":   false,
+	})
+	if err != nil {
+		return models.AlertRule{}, nil, err
+	}
+	labels, labelWarnings := convertTemplates("label", node.Labels)
+	annotations, annotationWarnings := convertTemplates("annotation", node.Annotations)
+	warnings := append(labelWarnings, annotationWarnings...)
+	rule := models.AlertRule{
+		Data: []models.AlertQuery{{
+			RefID:             queryRefID,
+			DatasourceUID:     cfg.DatasourceUID,
+			RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(cfg.QueryTimeRange)},
+			Model:             query,
+		}},
+		Labels:      labels,
+		Annotations: annotations,
+		// a Prometheus rule produces no alerts if its expression returns nothing
+		NoDataState:  models.OK,
+		ExecErrState: models.ErrorErrState,
+	}
+
+	if node.Record.Value != "" {
+		if !cfg.RecordingRules {
+			return models.AlertRule{}, nil, fmt.Errorf("%w: conversion of recording rules is disabled", ErrUnsupportedRule)
+		}
+		rule.Title = node.Record.Value
+		rule.Condition = queryRefID
+		rule.Record = models.Record{Metric: node.Record.Value, From: queryRefID}
+		return rule, warnings, nil
+	}
+
+	condition, err := json.Marshal(map[string]interface{}{
+		"refId":      conditionRefID,
+		"type":       "math",
+		"expression": conditionExpression,
+	})
+	if err != nil {
+		return models.AlertRule{}, nil, err
+	}
+	rule.Title = node.Alert.Value
+	rule.For = time.Duration(node.For)
+	rule.Condition = conditionRefID
+	rule.Data = append(rule.Data, models.AlertQuery{
+		RefID:         conditionRefID,
+		DatasourceUID: expr.DatasourceUID,
+		Model:         condition,
+	})
+	return rule, warnings, nil
+}
+
+// convertTemplates converts the templates of labels or annotations with convertTemplate, and returns warnings for the
+// ones that use variables or functions that are not available in Grafana.
+func convertTemplates(kind string, templates map[string]string) (map[string]string, []string) {
+	if len(templates) == 0 {
+		return nil, nil
+	}
+	result := make(map[string]string, len(templates))
+	var warnings []string
+	for k, v := range templates {
+		converted, unsupported := convertTemplate(v)
+		result[k] = converted
+		if len(unsupported) > 0 {
+			warnings = append(warnings, fmt.Sprintf("%s '%s' uses %s, which Grafana templates do not support", kind, k, strings.Join(unsupported, ", ")))
+		}
+	}
+	sort.Strings(warnings)
+	return result, warnings
+}
+
+// convertTemplate converts a Prometheus template to a Grafana template. $labels has the same meaning in both, but
+// $value is the value of the expression in Prometheus and a description of all values in Grafana, so it is replaced by
+// the value of the query. It also returns the variables and functions of the template that Grafana does not have.
+func convertTemplate(tmpl string) (string, []string) {
+	var unsupported []string
+	seen := map[string]struct{}{}
+	converted := templateActionRegex.ReplaceAllStringFunc(tmpl, func(action string) string {
+		for _, name := range unsupportedTemplateRegex.FindAllString(action, -1) {
+			if _, ok := seen[name]; !ok {
+				seen[name] = struct{}{}
+				unsupported = append(unsupported, name)
+			}
+		}
+		return valueVariableRegex.ReplaceAllString(action, "$$values."+queryRefID+".Value")
+	})
+	return converted, unsupported
+}
+
+func ruleUID(orgID int64, namespaceUID, group, title string) string {
+	h := fnv.New64a()
+	for _, s := range []string{strconv.FormatInt(orgID, 10), namespaceUID, group, title} {
+		_, _ = h.Write([]byte(s))
+		_, _ = h.Write([]byte{0})
+	}
+	return fmt.Sprintf("prom-%016x", h.Sum64())
+}
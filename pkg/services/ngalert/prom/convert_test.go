@@ -0,0 +1,199 @@
+package prom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const testRuleFile = `
+groups:
+  - name: group-1
+    interval: 30s
+    rules:
+      - alert: HighLatency
+        expr: job:request_latency_seconds:mean5m{job="api"} > 0.5
+        for: 5m
+        labels:
+          severity: page
+        annotations:
+          summary: High request latency
+      - alert: HighLatency
+        expr: job:request_latency_seconds:mean5m{job="web"} > 0.5
+  - name: group-2
+    rules:
+      - record: job:http_requests:rate5m
+        expr: sum by (job) (rate(http_requests_total[5m]))
+`
+
+func TestConvertRuleGroups(t *testing.T) {
+	groups, err := ParseRuleGroups([]byte(testRuleFile))
+	require.NoError(t, err)
+	cfg := Config{
+		DatasourceUID:   "prometheus",
+		DefaultInterval: time.Minute,
+		RecordingRules:  true,
+	}
+
+	result, warnings, err := ConvertRuleGroups(1, "folder", groups, cfg)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	require.Empty(t, warnings)
+
+	t.Run("should convert groups", func(t *testing.T) {
+		require.Equal(t, "group-1", result[0].Title)
+		require.Equal(t, "folder", result[0].FolderUID)
+		require.EqualValues(t, 30, result[0].Interval)
+		require.Equal(t, models.ProvenanceConvertedPrometheus, result[0].Provenance)
+		require.Len(t, result[0].Rules, 2)
+
+		require.Equal(t, "group-2", result[1].Title)
+		require.EqualValues(t, 60, result[1].Interval, "default interval should be used")
+	})
+
+	t.Run("should convert alerting rules", func(t *testing.T) {
+		rule := result[0].Rules[0]
+		require.Equal(t, "HighLatency", rule.Title)
+		require.Equal(t, int64(1), rule.OrgID)
+		require.Equal(t, "folder", rule.NamespaceUID)
+		require.Equal(t, "group-1", rule.RuleGroup)
+		require.Equal(t, 1, rule.RuleGroupIndex)
+		require.EqualValues(t, 30, rule.IntervalSeconds)
+		require.Equal(t, 5*time.Minute, rule.For)
+		require.Equal(t, map[string]string{"severity": "page"}, rule.Labels)
+		require.Equal(t, map[string]string{"summary": "High request latency"}, rule.Annotations)
+		require.Equal(t, models.OK, rule.NoDataState)
+		require.Equal(t, models.ErrorErrState, rule.ExecErrState)
+		require.True(t, rule.Record.IsEmpty())
+
+		require.Equal(t, conditionRefID, rule.Condition)
+		require.Len(t, rule.Data, 2)
+		require.Equal(t, "prometheus", rule.Data[0].DatasourceUID)
+		require.Equal(t, models.Duration(DefaultQueryTimeRange), rule.Data[0].RelativeTimeRange.From)
+		var query map[string]interface{}
+		require.NoError(t, json.Unmarshal(rule.Data[0].Model, &query))
+		require.Equal(t, `job:request_latency_seconds:mean5m{job="api"} > 0.5`, query["expr"])
+		require.Equal(t, true, query["instant"])
+		require.Equal(t, expr.DatasourceUID, rule.Data[1].DatasourceUID)
+	})
+
+	t.Run("should make titles unique", func(t *testing.T) {
+		require.Equal(t, "HighLatency (2)", result[0].Rules[1].Title)
+		require.NotEqual(t, result[0].Rules[0].UID, result[0].Rules[1].UID)
+	})
+
+	t.Run("should convert recording rules", func(t *testing.T) {
+		rule := result[1].Rules[0]
+		require.Equal(t, "job:http_requests:rate5m", rule.Title)
+		require.Equal(t, queryRefID, rule.Condition)
+		require.Equal(t, models.Record{Metric: "job:http_requests:rate5m", From: queryRefID}, rule.Record)
+		require.Len(t, rule.Data, 1)
+	})
+
+	t.Run("should produce the same UIDs for the same file", func(t *testing.T) {
+		again, _, err := ConvertRuleGroups(1, "folder", groups, cfg)
+		require.NoError(t, err)
+		require.Equal(t, result[0].Rules[0].UID, again[0].Rules[0].UID)
+
+		other, _, err := ConvertRuleGroups(1, "other-folder", groups, cfg)
+		require.NoError(t, err)
+		require.NotEqual(t, result[0].Rules[0].UID, other[0].Rules[0].UID)
+	})
+
+	t.Run("should fail if recording rules are disabled", func(t *testing.T) {
+		cfg := cfg
+		cfg.RecordingRules = false
+		_, _, err := ConvertRuleGroups(1, "folder", groups, cfg)
+		require.ErrorIs(t, err, ErrUnsupportedRule)
+	})
+
+	t.Run("should fail if data source is not specified", func(t *testing.T) {
+		_, _, err := ConvertRuleGroups(1, "folder", groups, Config{})
+		require.Error(t, err)
+	})
+}
+
+func TestConvertRuleGroups_Unsupported(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{
+			name: "keep_firing_for",
+			content: `
+groups:
+  - name: group
+    rules:
+      - alert: test
+        expr: up == 0
+        keep_firing_for: 5m
+`,
+		},
+		{
+			name: "limit",
+			content: `
+groups:
+  - name: group
+    limit: 10
+    rules:
+      - alert: test
+        expr: up == 0
+`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			groups, err := ParseRuleGroups([]byte(tc.content))
+			require.NoError(t, err)
+			_, _, err = ConvertRuleGroups(1, "folder", groups, Config{DatasourceUID: "prometheus", DefaultInterval: time.Minute})
+			require.ErrorIs(t, err, ErrUnsupportedRule)
+		})
+	}
+}
+
+func TestConvertRuleGroups_Templates(t *testing.T) {
+	groups, err := ParseRuleGroups([]byte(`
+groups:
+  - name: group
+    rules:
+      - alert: test
+        expr: up == 0
+        labels:
+          instance: '{{ $labels.instance }}'
+        annotations:
+          summary: 'Value of {{ $labels.job }} is {{ $value | humanize }}, $value'
+          runbook: '{{ $externalURL }}/runbook?cluster={{ $externalLabels.cluster }}'
+`))
+	require.NoError(t, err)
+
+	result, warnings, err := ConvertRuleGroups(1, "folder", groups, Config{DatasourceUID: "prometheus", DefaultInterval: time.Minute})
+
+	require.NoError(t, err)
+	rule := result[0].Rules[0]
+	require.Equal(t, map[string]string{"instance": "{{ $labels.instance }}"}, rule.Labels)
+	require.Equal(t, map[string]string{
+		"summary": "Value of {{ $labels.job }} is {{ $values.A.Value | humanize }}, $value",
+		"runbook": "{{ $externalURL }}/runbook?cluster={{ $externalLabels.cluster }}",
+	}, rule.Annotations)
+	require.Equal(t, []Warning{{
+		Group:   "group",
+		Rule:    "test",
+		Message: "annotation 'runbook' uses $externalURL, $externalLabels, which Grafana templates do not support",
+	}}, warnings)
+}
+
+func TestParseRuleGroups(t *testing.T) {
+	_, err := ParseRuleGroups([]byte(`
+groups:
+  - name: group
+    rules:
+      - alert: test
+        expr: up ==
+`))
+	require.ErrorIs(t, err, ErrInvalidRuleFile)
+}
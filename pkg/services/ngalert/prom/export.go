@@ -0,0 +1,79 @@
+package prom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ErrRecordingRuleNotExportable is returned if a converted recording rule is exported to a provisioning file,
+// because provisioning files cannot define recording rules.
+var ErrRecordingRuleNotExportable = errors.New("recording rules cannot be exported to a provisioning file")
+
+// ProvisioningFile returns the converted rule groups as an alerting provisioning file in YAML.
+// The groups are provisioned in the folder with the title.
+func ProvisioningFile(orgID int64, folderTitle string, groups []models.AlertRuleGroup) ([]byte, error) {
+	export := definitions.AlertingFileExport{APIVersion: 1}
+	for _, group := range groups {
+		rules := make([]definitions.AlertRuleExport, 0, len(group.Rules))
+		for _, rule := range group.Rules {
+			if rule.Type() == models.RuleTypeRecording {
+				return nil, fmt.Errorf("%w: rule '%s' of group '%s'", ErrRecordingRuleNotExportable, rule.Title, group.Title)
+			}
+			r, err := exportRule(rule)
+			if err != nil {
+				return nil, fmt.Errorf("failed to export rule '%s' of group '%s': %w", rule.Title, group.Title, err)
+			}
+			rules = append(rules, r)
+		}
+		export.Groups = append(export.Groups, definitions.AlertRuleGroupExport{
+			OrgID:     orgID,
+			Name:      group.Title,
+			Folder:    folderTitle,
+			FolderUID: group.FolderUID,
+			Interval:  model.Duration(time.Duration(group.Interval) * time.Second),
+			Rules:     rules,
+		})
+	}
+	return yaml.Marshal(export)
+}
+
+// exportRule converts the rule to the export format. Converted rules are not linked to dashboards and are not paused.
+func exportRule(rule models.AlertRule) (definitions.AlertRuleExport, error) {
+	data := make([]definitions.AlertQueryExport, 0, len(rule.Data))
+	for _, query := range rule.Data {
+		// the model is unmarshalled into a map to marshal it as YAML
+		var mdl map[string]interface{}
+		if err := json.Unmarshal(query.Model, &mdl); err != nil {
+			return definitions.AlertRuleExport{}, err
+		}
+		data = append(data, definitions.AlertQueryExport{
+			RefID:     query.RefID,
+			QueryType: query.QueryType,
+			RelativeTimeRange: definitions.RelativeTimeRange{
+				From: definitions.Duration(query.RelativeTimeRange.From),
+				To:   definitions.Duration(query.RelativeTimeRange.To),
+			},
+			DatasourceUID: query.DatasourceUID,
+			Model:         mdl,
+		})
+	}
+	return definitions.AlertRuleExport{
+		UID:          rule.UID,
+		Title:        rule.Title,
+		Condition:    rule.Condition,
+		Data:         data,
+		NoDataState:  definitions.NoDataState(rule.NoDataState),
+		ExecErrState: definitions.ExecutionErrorState(rule.ExecErrState),
+		For:          model.Duration(rule.For),
+		Annotations:  rule.Annotations,
+		Labels:       rule.Labels,
+	}, nil
+}
@@ -0,0 +1,51 @@
+package prom
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestProvisioningFile(t *testing.T) {
+	groups, err := ParseRuleGroups([]byte(testRuleFile))
+	require.NoError(t, err)
+	cfg := Config{
+		DatasourceUID:   "prometheus",
+		DefaultInterval: time.Minute,
+	}
+
+	t.Run("should export alerting rules", func(t *testing.T) {
+		converted, _, err := ConvertRuleGroups(1, "folder", groups[:1], cfg)
+		require.NoError(t, err)
+
+		out, err := ProvisioningFile(1, "Folder", converted)
+		require.NoError(t, err)
+
+		file := definitions.AlertingFileExport{}
+		require.NoError(t, yaml.Unmarshal(out, &file))
+		require.EqualValues(t, 1, file.APIVersion)
+		require.Len(t, file.Groups, 1)
+		group := file.Groups[0]
+		require.Equal(t, "group-1", group.Name)
+		require.Equal(t, "Folder", group.Folder)
+		require.Len(t, group.Rules, 2)
+		require.Equal(t, converted[0].Rules[0].UID, group.Rules[0].UID)
+		require.Equal(t, "HighLatency", group.Rules[0].Title)
+		require.Equal(t, "prometheus", group.Rules[0].Data[0].DatasourceUID)
+		require.Equal(t, "page", group.Rules[0].Labels["severity"])
+	})
+
+	t.Run("should fail if there are recording rules", func(t *testing.T) {
+		cfg := cfg
+		cfg.RecordingRules = true
+		converted, _, err := ConvertRuleGroups(1, "folder", groups, cfg)
+		require.NoError(t, err)
+
+		_, err = ProvisioningFile(1, "Folder", converted)
+		require.ErrorIs(t, err, ErrRecordingRuleNotExportable)
+	})
+}
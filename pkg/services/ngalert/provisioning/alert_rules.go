@@ -210,10 +210,113 @@ func (service *AlertRuleService) UpdateRuleGroup(ctx context.Context, orgID int6
 }
 
 func (service *AlertRuleService) ReplaceRuleGroup(ctx context.Context, orgID int64, group models.AlertRuleGroup, userID int64, provenance models.Provenance) error {
-	if err := models.ValidateRuleGroupInterval(group.Interval, service.baseIntervalSeconds); err != nil {
+	delta, err := service.calcDelta(ctx, orgID, group)
+	if err != nil {
+		return err
+	}
+
+	if len(delta.New) == 0 && len(delta.Update) == 0 && len(delta.Delete) == 0 {
+		return nil
+	}
+
+	return service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := service.persistDelta(ctx, orgID, delta, provenance); err != nil {
+			return err
+		}
+		return service.checkLimitsTransactionCtx(ctx, orgID, userID)
+	})
+}
+
+// ReplaceRuleGroups replaces the rules of all groups like ReplaceRuleGroup, in a single transaction. Either all groups
+// are changed or none of them. Unlike ReplaceRuleGroup, rules with UIDs that do not exist are created with these UIDs.
+func (service *AlertRuleService) ReplaceRuleGroups(ctx context.Context, orgID int64, groups []models.AlertRuleGroup, userID int64, provenance models.Provenance) error {
+	return service.xact.InTransaction(ctx, func(ctx context.Context) error {
+		changed := false
+		for _, group := range groups {
+			delta, err := service.calcDeltaWithNewUIDs(ctx, orgID, group)
+			if err != nil {
+				return err
+			}
+			if len(delta.New) == 0 && len(delta.Update) == 0 && len(delta.Delete) == 0 {
+				continue
+			}
+			if err := service.persistDelta(ctx, orgID, delta, provenance); err != nil {
+				return err
+			}
+			changed = true
+		}
+		if !changed {
+			return nil
+		}
+		return service.checkLimitsTransactionCtx(ctx, orgID, userID)
+	})
+}
+
+// persistDelta saves the changes of a rule group. It must be called in a transaction.
+func (service *AlertRuleService) persistDelta(ctx context.Context, orgID int64, delta *store.GroupDelta, provenance models.Provenance) error {
+	// check that provenance is not changed in an invalid way
+	if err := service.checkProvenanceOfChanges(ctx, orgID, delta, provenance); err != nil {
 		return err
 	}
 
+	// Delete first as this could prevent future unique constraint violations.
+	if len(delta.Delete) > 0 {
+		if err := service.deleteRules(ctx, orgID, delta.Delete...); err != nil {
+			return err
+		}
+	}
+
+	if len(delta.Update) > 0 {
+		updates := make([]models.UpdateRule, 0, len(delta.Update))
+		for _, update := range delta.Update {
+			updates = append(updates, models.UpdateRule{
+				Existing: update.Existing,
+				New:      *update.New,
+			})
+		}
+		if err := service.ruleStore.UpdateAlertRules(ctx, updates); err != nil {
+			return fmt.Errorf("failed to update alert rules: %w", err)
+		}
+		for _, update := range delta.Update {
+			if err := service.provenanceStore.SetProvenance(ctx, update.New, orgID, provenance); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(delta.New) > 0 {
+		uids, err := service.ruleStore.InsertAlertRules(ctx, withoutNilAlertRules(delta.New))
+		if err != nil {
+			return fmt.Errorf("failed to insert alert rules: %w", err)
+		}
+		for uid := range uids {
+			if err := service.provenanceStore.SetProvenance(ctx, &models.AlertRule{UID: uid}, orgID, provenance); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CalculateRuleGroupChanges returns the changes that ReplaceRuleGroups would make to the rule group without applying them.
+// It returns an error if the changes are not allowed with the provenance.
+func (service *AlertRuleService) CalculateRuleGroupChanges(ctx context.Context, orgID int64, group models.AlertRuleGroup, provenance models.Provenance) (*store.GroupDelta, error) {
+	delta, err := service.calcDeltaWithNewUIDs(ctx, orgID, group)
+	if err != nil {
+		return nil, err
+	}
+	if err := service.checkProvenanceOfChanges(ctx, orgID, delta, provenance); err != nil {
+		return nil, err
+	}
+	return delta, nil
+}
+
+// calcDelta calculates the changes that are needed to replace the rules of the group with the rules in the specified group.
+func (service *AlertRuleService) calcDelta(ctx context.Context, orgID int64, group models.AlertRuleGroup) (*store.GroupDelta, error) {
+	if err := models.ValidateRuleGroupInterval(group.Interval, service.baseIntervalSeconds); err != nil {
+		return nil, err
+	}
+
 	// If the provided request did not provide the rules list at all, treat it as though it does not wish to change rules.
 	// This is done for backwards compatibility. Requests which specify only the interval must update only the interval.
 	if group.Rules == nil {
@@ -224,7 +327,7 @@ func (service *AlertRuleService) ReplaceRuleGroup(ctx context.Context, orgID int
 		}
 		ruleList, err := service.ruleStore.ListAlertRules(ctx, &listRulesQuery)
 		if err != nil {
-			return fmt.Errorf("failed to list alert rules: %w", err)
+			return nil, fmt.Errorf("failed to list alert rules: %w", err)
 		}
 		group.Rules = make([]models.AlertRule, 0, len(ruleList))
 		for _, r := range ruleList {
@@ -243,84 +346,87 @@ func (service *AlertRuleService) ReplaceRuleGroup(ctx context.Context, orgID int
 	group = *syncGroupRuleFields(&group, orgID)
 	for i := range group.Rules {
 		if err := group.Rules[i].SetDashboardAndPanelFromAnnotations(); err != nil {
-			return err
+			return nil, err
 		}
 		rules = append(rules, &models.AlertRuleWithOptionals{AlertRule: group.Rules[i], HasPause: true})
 	}
 	delta, err := store.CalculateChanges(ctx, service.ruleStore, key, rules)
 	if err != nil {
-		return fmt.Errorf("failed to calculate diff for alert rules: %w", err)
+		return nil, fmt.Errorf("failed to calculate diff for alert rules: %w", err)
 	}
 
 	// Refresh all calculated fields across all rules.
-	delta = store.UpdateCalculatedRuleFields(delta)
+	return store.UpdateCalculatedRuleFields(delta), nil
+}
 
-	if len(delta.New) == 0 && len(delta.Update) == 0 && len(delta.Delete) == 0 {
-		return nil
+// calcDeltaWithNewUIDs is like calcDelta but treats the rules with UIDs that do not exist as new rules, which keep their UIDs,
+// instead of failing to update them.
+func (service *AlertRuleService) calcDeltaWithNewUIDs(ctx context.Context, orgID int64, group models.AlertRuleGroup) (*store.GroupDelta, error) {
+	uids := make([]string, 0, len(group.Rules))
+	for _, r := range group.Rules {
+		if r.UID != "" {
+			uids = append(uids, r.UID)
+		}
+	}
+	if len(uids) == 0 {
+		return service.calcDelta(ctx, orgID, group)
+	}
+	existing, err := service.ruleStore.ListAlertRules(ctx, &models.ListAlertRulesQuery{OrgID: orgID, RuleUIDs: uids})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alert rules: %w", err)
+	}
+	existingUIDs := make(map[string]struct{}, len(existing))
+	for _, r := range existing {
+		existingUIDs[r.UID] = struct{}{}
 	}
 
-	return service.xact.InTransaction(ctx, func(ctx context.Context) error {
-		// Delete first as this could prevent future unique constraint violations.
-		if len(delta.Delete) > 0 {
-			for _, del := range delta.Delete {
-				// check that provenance is not changed in an invalid way
-				storedProvenance, err := service.provenanceStore.GetProvenance(ctx, del, orgID)
-				if err != nil {
-					return err
-				}
-				if canUpdate := canUpdateProvenanceInRuleGroup(storedProvenance, provenance); !canUpdate {
-					return fmt.Errorf("cannot update with provided provenance '%s', needs '%s'", provenance, storedProvenance)
-				}
-			}
-			if err := service.deleteRules(ctx, orgID, delta.Delete...); err != nil {
-				return err
-			}
+	// The UIDs of new rules are cleared so that the delta adds the rules, and set again on the added rules.
+	// Titles are unique in the group, so they identify the added rules.
+	newUIDs := make(map[string]string)
+	rules := make([]models.AlertRule, len(group.Rules))
+	copy(rules, group.Rules)
+	for i := range rules {
+		if _, ok := existingUIDs[rules[i].UID]; ok || rules[i].UID == "" {
+			continue
 		}
+		newUIDs[rules[i].Title] = rules[i].UID
+		rules[i].UID = ""
+	}
+	group.Rules = rules
 
-		if len(delta.Update) > 0 {
-			updates := make([]models.UpdateRule, 0, len(delta.Update))
-			for _, update := range delta.Update {
-				// check that provenance is not changed in an invalid way
-				storedProvenance, err := service.provenanceStore.GetProvenance(ctx, update.New, orgID)
-				if err != nil {
-					return err
-				}
-				if canUpdate := canUpdateProvenanceInRuleGroup(storedProvenance, provenance); !canUpdate {
-					return fmt.Errorf("cannot update with provided provenance '%s', needs '%s'", provenance, storedProvenance)
-				}
-				updates = append(updates, models.UpdateRule{
-					Existing: update.Existing,
-					New:      *update.New,
-				})
-			}
-			if err = service.ruleStore.UpdateAlertRules(ctx, updates); err != nil {
-				return fmt.Errorf("failed to update alert rules: %w", err)
-			}
-			for _, update := range delta.Update {
-				if err := service.provenanceStore.SetProvenance(ctx, update.New, orgID, provenance); err != nil {
-					return err
-				}
-			}
+	delta, err := service.calcDelta(ctx, orgID, group)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range delta.New {
+		if uid, ok := newUIDs[r.Title]; ok {
+			r.UID = uid
 		}
+	}
+	return delta, nil
+}
 
-		if len(delta.New) > 0 {
-			uids, err := service.ruleStore.InsertAlertRules(ctx, withoutNilAlertRules(delta.New))
-			if err != nil {
-				return fmt.Errorf("failed to insert alert rules: %w", err)
-			}
-			for uid := range uids {
-				if err := service.provenanceStore.SetProvenance(ctx, &models.AlertRule{UID: uid}, orgID, provenance); err != nil {
-					return err
-				}
-			}
+// checkProvenanceOfChanges checks that the rules that are updated or deleted can be changed with the provenance.
+func (service *AlertRuleService) checkProvenanceOfChanges(ctx context.Context, orgID int64, delta *store.GroupDelta, provenance models.Provenance) error {
+	for _, del := range delta.Delete {
+		storedProvenance, err := service.provenanceStore.GetProvenance(ctx, del, orgID)
+		if err != nil {
+			return err
 		}
-
-		if err = service.checkLimitsTransactionCtx(ctx, orgID, userID); err != nil {
+		if canUpdate := canUpdateProvenanceInRuleGroup(storedProvenance, provenance); !canUpdate {
+			return fmt.Errorf("cannot update with provided provenance '%s', needs '%s'", provenance, storedProvenance)
+		}
+	}
+	for _, update := range delta.Update {
+		storedProvenance, err := service.provenanceStore.GetProvenance(ctx, update.New, orgID)
+		if err != nil {
 			return err
 		}
-
-		return nil
-	})
+		if canUpdate := canUpdateProvenanceInRuleGroup(storedProvenance, provenance); !canUpdate {
+			return fmt.Errorf("cannot update with provided provenance '%s', needs '%s'", provenance, storedProvenance)
+		}
+	}
+	return nil
 }
 
 // UpdateAlertRule updates an alert rule.
@@ -80,6 +80,29 @@ func TestAlertRuleService(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("replacing several groups should save all of them", func(t *testing.T) {
+		groups := []models.AlertRuleGroup{createDummyGroup("groups-test-1", orgID), createDummyGroup("groups-test-2", orgID)}
+		err := ruleService.ReplaceRuleGroups(context.Background(), orgID, groups, 0, models.ProvenanceAPI)
+		require.NoError(t, err)
+
+		for _, group := range groups {
+			readGroup, err := ruleService.GetRuleGroup(context.Background(), orgID, "my-namespace", group.Title)
+			require.NoError(t, err)
+			require.Len(t, readGroup.Rules, 1)
+		}
+	})
+
+	t.Run("replacing several groups should save none of them if one fails", func(t *testing.T) {
+		invalid := createDummyGroup("groups-test-invalid", orgID)
+		invalid.Interval = 7
+		groups := []models.AlertRuleGroup{createDummyGroup("groups-test-3", orgID), invalid}
+		err := ruleService.ReplaceRuleGroups(context.Background(), orgID, groups, 0, models.ProvenanceAPI)
+		require.Error(t, err)
+
+		_, err = ruleService.GetRuleGroup(context.Background(), orgID, "my-namespace", "groups-test-3")
+		require.ErrorIs(t, err, store.ErrAlertRuleGroupNotFound)
+	})
+
 	t.Run("group creation should propagate group title correctly", func(t *testing.T) {
 		group := createDummyGroup("group-test-3", orgID)
 		group.Rules[0].RuleGroup = "something different"
@@ -0,0 +1,97 @@
+package schedule
+
+import (
+	"sort"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// sortByDependencies orders the items so that rules whose state is queried by other rules are evaluated before the dependent rules
+// within a tick, and makes the dependent rules wait until the evaluations of the rules they query have finished. The order of
+// independent rules is preserved. Cyclic dependencies are broken at an arbitrary rule.
+func sortByDependencies(items []readyToRunItem) {
+	type node struct {
+		deps []int
+		// dependent is true if the rule queries the state of other rules.
+		dependent bool
+		level     int
+		// visit is 0 if the level is not calculated yet, 1 if it is being calculated, and 2 if it is calculated.
+		visit int
+	}
+	nodes := make([]node, len(items))
+	byKey := make(map[ngmodels.AlertRuleKey]int, len(items))
+	for i, item := range items {
+		byKey[item.rule.GetKey()] = i
+	}
+	hasDependent := false
+	for i, item := range items {
+		uids, byLabels := item.rule.AlertStateDependencies()
+		if len(uids) == 0 && !byLabels {
+			continue
+		}
+		hasDependent = true
+		n := &nodes[i]
+		n.dependent = true
+		if byLabels {
+			for j, other := range items {
+				if j != i && other.rule.OrgID == item.rule.OrgID {
+					n.deps = append(n.deps, j)
+				}
+			}
+			continue
+		}
+		for _, uid := range uids {
+			if j, ok := byKey[ngmodels.AlertRuleKey{OrgID: item.rule.OrgID, UID: uid}]; ok && j != i {
+				n.deps = append(n.deps, j)
+			}
+		}
+	}
+	if !hasDependent {
+		return
+	}
+
+	var levelOf func(i int) int
+	levelOf = func(i int) int {
+		n := &nodes[i]
+		switch n.visit {
+		case 1: // cycle
+			return -1
+		case 2:
+			return n.level
+		}
+		n.visit = 1
+		level := 0
+		for _, j := range n.deps {
+			if l := levelOf(j); l+1 > level {
+				level = l + 1
+			}
+		}
+		n.level = level
+		n.visit = 2
+		return level
+	}
+	for i := range items {
+		levelOf(i)
+	}
+
+	// a rule waits only for the rules at lower levels, so that rules in a cycle do not wait for each other
+	for i := range items {
+		for _, j := range nodes[i].deps {
+			if nodes[j].level >= nodes[i].level {
+				continue
+			}
+			if items[j].done == nil {
+				items[j].done = newEvaluationDone()
+			}
+			items[i].after = append(items[i].after, items[j].done)
+		}
+	}
+
+	levels := make(map[ngmodels.AlertRuleKey]int, len(items))
+	for i, item := range items {
+		levels[item.rule.GetKey()] = nodes[i].level
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return levels[items[i].rule.GetKey()] < levels[items[j].rule.GetKey()]
+	})
+}
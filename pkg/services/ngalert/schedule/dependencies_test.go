@@ -0,0 +1,135 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestSortByDependencies(t *testing.T) {
+	gen := models.AlertRuleGen(models.WithOrgID(1))
+	withStateQuery := func(rule *models.AlertRule, query string) *models.AlertRule {
+		rule.Data = append(rule.Data, models.AlertQuery{
+			RefID:         "S",
+			DatasourceUID: expr.AlertStateDatasourceUID,
+			Model:         json.RawMessage(query),
+		})
+		return rule
+	}
+	uids := func(items []readyToRunItem) []string {
+		result := make([]string, 0, len(items))
+		for _, item := range items {
+			result = append(result, item.rule.UID)
+		}
+		return result
+	}
+	toItems := func(rules ...*models.AlertRule) []readyToRunItem {
+		result := make([]readyToRunItem, 0, len(rules))
+		for _, rule := range rules {
+			result = append(result, readyToRunItem{evaluation: evaluation{rule: rule}})
+		}
+		return result
+	}
+
+	t.Run("keeps the order if there are no dependencies", func(t *testing.T) {
+		items := toItems(gen(), gen(), gen())
+		expected := uids(items)
+		sortByDependencies(items)
+		require.Equal(t, expected, uids(items))
+	})
+
+	t.Run("evaluates rules selected by UID before dependent rules", func(t *testing.T) {
+		db, api, other := gen(), gen(), gen()
+		db.UID, api.UID, other.UID = "db", "api", "other"
+		composite := withStateQuery(gen(), `{"ruleUIDs": ["db", "api"]}`)
+		composite.UID = "composite"
+		chained := withStateQuery(gen(), `{"ruleUIDs": ["composite"]}`)
+		chained.UID = "chained"
+
+		items := toItems(chained, composite, db, other, api)
+		sortByDependencies(items)
+		require.Equal(t, []string{"db", "other", "api", "composite", "chained"}, uids(items))
+	})
+
+	t.Run("evaluates rules selected by labels after independent rules of the same organization", func(t *testing.T) {
+		a, b := gen(), gen()
+		a.UID, b.UID = "a", "b"
+		otherOrg := withStateQuery(gen(), `{"labels": {"cluster": "a"}}`)
+		otherOrg.UID, otherOrg.OrgID = "other-org", 2
+		byLabels := withStateQuery(gen(), `{"labels": {"cluster": "a"}}`)
+		byLabels.UID = "by-labels"
+
+		items := toItems(byLabels, otherOrg, a, b)
+		sortByDependencies(items)
+		require.Equal(t, []string{"other-org", "a", "b", "by-labels"}, uids(items))
+	})
+
+	t.Run("breaks cycles", func(t *testing.T) {
+		a := withStateQuery(gen(), `{"ruleUIDs": ["b"]}`)
+		a.UID = "a"
+		b := withStateQuery(gen(), `{"ruleUIDs": ["a"]}`)
+		b.UID = "b"
+
+		items := toItems(a, b)
+		sortByDependencies(items)
+		require.Len(t, items, 2)
+	})
+	t.Run("makes dependent rules wait for the evaluation of the rules they query", func(t *testing.T) {
+		db, other := gen(), gen()
+		db.UID, other.UID = "db", "other"
+		composite := withStateQuery(gen(), `{"ruleUIDs": ["db"]}`)
+		composite.UID = "composite"
+		chained := withStateQuery(gen(), `{"ruleUIDs": ["composite"]}`)
+		chained.UID = "chained"
+
+		items := toItems(chained, composite, db, other)
+		sortByDependencies(items)
+		require.Equal(t, []string{"db", "other", "composite", "chained"}, uids(items))
+		require.NotNil(t, items[0].done)
+		require.Nil(t, items[1].done)
+		require.Equal(t, []*evaluationDone{items[0].done}, items[2].after)
+		require.Equal(t, []*evaluationDone{items[2].done}, items[3].after)
+	})
+
+	t.Run("does not make rules in a cycle wait for each other", func(t *testing.T) {
+		a := withStateQuery(gen(), `{"ruleUIDs": ["b"]}`)
+		a.UID = "a"
+		b := withStateQuery(gen(), `{"ruleUIDs": ["a"]}`)
+		b.UID = "b"
+
+		items := toItems(a, b)
+		sortByDependencies(items)
+		require.Empty(t, items[0].after)
+		require.Len(t, items[1].after, 1)
+	})
+}
+
+func TestEvaluationWaitForDependencies(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns when dependencies are done", func(t *testing.T) {
+		done := newEvaluationDone()
+		e := &evaluation{after: []*evaluationDone{done}}
+		go done.close()
+		require.True(t, e.waitForDependencies(ctx, time.Minute))
+	})
+
+	t.Run("stops waiting after timeout", func(t *testing.T) {
+		e := &evaluation{after: []*evaluationDone{newEvaluationDone()}}
+		require.False(t, e.waitForDependencies(ctx, 10*time.Millisecond))
+	})
+
+	t.Run("closing is idempotent and safe without waiters", func(t *testing.T) {
+		done := newEvaluationDone()
+		done.close()
+		done.close()
+		var missing *evaluationDone
+		missing.close()
+	})
+}
@@ -18,6 +18,9 @@ import (
 
 var errRuleDeleted = errors.New("rule deleted")
 
+// errRuleHandedOff is the reason to stop the evaluation of the rule that is assigned to another instance of the cluster.
+var errRuleHandedOff = errors.New("rule handed off to another instance")
+
 type alertRuleInfoRegistry struct {
 	mu            sync.Mutex
 	alertRuleInfo map[models.AlertRuleKey]*alertRuleInfo
@@ -130,6 +133,49 @@ type evaluation struct {
 	scheduledAt time.Time
 	rule        *models.AlertRule
 	folderTitle string
+	// done is closed when the evaluation has finished. It is nil if no other evaluation waits for it.
+	done *evaluationDone
+	// after are the evaluations of the same tick that must finish before this evaluation starts.
+	after []*evaluationDone
+}
+
+// waitForDependencies waits until the evaluations that the evaluation depends on have finished, but not longer than
+// the timeout. Returns false if it stopped waiting before they finished.
+func (e *evaluation) waitForDependencies(ctx context.Context, timeout time.Duration) bool {
+	if len(e.after) == 0 {
+		return true
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for _, d := range e.after {
+		select {
+		case <-d.ch:
+		case <-timer.C:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// evaluationDone signals that an evaluation has finished, or that it is not going to happen.
+type evaluationDone struct {
+	once sync.Once
+	ch   chan struct{}
+}
+
+func newEvaluationDone() *evaluationDone {
+	return &evaluationDone{ch: make(chan struct{})}
+}
+
+func (d *evaluationDone) close() {
+	if d == nil {
+		return
+	}
+	d.once.Do(func() {
+		close(d.ch)
+	})
 }
 
 type alertRulesRegistry struct {
@@ -329,6 +375,8 @@ func (r ruleWithFolder) Fingerprint() fingerprint {
 	writeLabels(rule.Labels)
 	writeString(rule.Condition)
 	writeQuery()
+	writeString(rule.Record.Metric)
+	writeString(rule.Record.From)
 
 	if rule.IsPaused {
 		writeInt(1)
@@ -353,5 +401,10 @@ func (r ruleWithFolder) Fingerprint() fingerprint {
 	writeInt(int64(rule.RuleGroupIndex))
 	writeString(string(rule.NoDataState))
 	writeString(string(rule.ExecErrState))
+	if rule.DisableEvaluationOffset {
+		writeInt(1)
+	} else {
+		writeInt(0)
+	}
 	return fingerprint(sum.Sum64())
 }
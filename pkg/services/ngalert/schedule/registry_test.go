@@ -414,7 +414,9 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			Labels: map[string]string{
 				"key-label": "value-label",
 			},
-			IsPaused: false,
+			IsPaused:                false,
+			Record:                  models.Record{},
+			DisableEvaluationOffset: false,
 		}
 		r2 := &models.AlertRule{
 			ID:        2,
@@ -450,6 +452,11 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 				"key-label": "value-label23",
 			},
 			IsPaused: true,
+			Record: models.Record{
+				Metric: "test_metric",
+				From:   "B",
+			},
+			DisableEvaluationOffset: true,
 		}
 
 		excludedFields := map[string]struct{}{
@@ -5,9 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"sync/atomic"
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/sync/errgroup"
 
@@ -39,6 +41,11 @@ type AlertsSender interface {
 	Send(key ngmodels.AlertRuleKey, alerts definitions.PostableAlerts)
 }
 
+// RecordingWriter is an interface for a service that writes the results of recording rules.
+type RecordingWriter interface {
+	Write(ctx context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error
+}
+
 // RulesStore is a store that provides alert rules for scheduling
 type RulesStore interface {
 	GetAlertRulesKeysForScheduling(ctx context.Context) ([]ngmodels.AlertRuleKeyWithVersion, error)
@@ -80,6 +87,7 @@ type schedule struct {
 	metrics *metrics.Scheduler
 
 	alertsSender    AlertsSender
+	recordingWriter RecordingWriter
 	minRuleInterval time.Duration
 
 	// schedulableAlertRules contains the alert rules that are considered for
@@ -88,6 +96,11 @@ type schedule struct {
 	// last evaluated.
 	schedulableAlertRules alertRulesRegistry
 
+	// sharder is not nil if the evaluation of rules is sharded across the members of the cluster.
+	sharder *ruleSharder
+	// loadingPeerStates is true while the state of the rules evaluated by other members of the cluster is loaded.
+	loadingPeerStates atomic.Bool
+
 	tracer tracing.Tracer
 }
 
@@ -103,7 +116,12 @@ type SchedulerCfg struct {
 	RuleStore            RulesStore
 	Metrics              *metrics.Scheduler
 	AlertSender          AlertsSender
-	Tracer               tracing.Tracer
+	RecordingWriter      RecordingWriter
+	// MembershipStore enables sharding of the evaluation of rules across the members of the cluster if it is not nil.
+	// InstanceName is the name of this instance in the cluster, which must be unique.
+	MembershipStore MembershipStore
+	InstanceName    string
+	Tracer          tracing.Tracer
 }
 
 // NewScheduler returns a new schedule.
@@ -123,8 +141,12 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		minRuleInterval:       cfg.MinRuleInterval,
 		schedulableAlertRules: alertRulesRegistry{rules: make(map[ngmodels.AlertRuleKey]*ngmodels.AlertRule)},
 		alertsSender:          cfg.AlertSender,
+		recordingWriter:       cfg.RecordingWriter,
 		tracer:                cfg.Tracer,
 	}
+	if cfg.MembershipStore != nil {
+		sch.sharder = newRuleSharder(cfg.MembershipStore, cfg.InstanceName, cfg.BaseInterval, sch.log.New("component", "sharding"))
+	}
 
 	return &sch
 }
@@ -163,6 +185,34 @@ func (sch *schedule) deleteAlertRule(keys ...ngmodels.AlertRuleKey) {
 	sch.updateRulesMetrics(alertRules)
 }
 
+// handOffAlertRule stops evaluation of the rules that are assigned to another member of the cluster. Unlike deleteAlertRule,
+// it keeps the rules in the active rules and keeps their state in the database, so the new owner can continue from it.
+func (sch *schedule) handOffAlertRule(keys ...ngmodels.AlertRuleKey) {
+	for _, key := range keys {
+		ruleInfo, ok := sch.registry.del(key)
+		if !ok {
+			continue
+		}
+		sch.log.Debug("Alert rule is handed off to another instance", key.LogContext()...)
+		ruleInfo.stop(errRuleHandedOff)
+	}
+}
+
+// loadPeerStates loads the state of the rules that other members of the cluster evaluate in the background, so that
+// the state of all rules can be read from this instance. The load is skipped if the previous one has not finished yet.
+func (sch *schedule) loadPeerStates(ctx context.Context, dispatcherGroup *errgroup.Group, rules []*ngmodels.AlertRule) {
+	if !sch.loadingPeerStates.CompareAndSwap(false, true) {
+		return
+	}
+	dispatcherGroup.Go(func() error {
+		defer sch.loadingPeerStates.Store(false)
+		if err := sch.stateManager.LoadPeerStates(ctx, rules); err != nil {
+			sch.log.Error("Failed to load the state of the rules evaluated by other instances", "error", err)
+		}
+		return nil
+	})
+}
+
 func (sch *schedule) schedulePeriodic(ctx context.Context, t *ticker.T) error {
 	dispatcherGroup, ctx := errgroup.WithContext(ctx)
 	for {
@@ -239,11 +289,28 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 
 	sch.updateRulesMetrics(alertRules)
 
+	var shards map[ngmodels.AlertRuleKey]string
+	if sch.sharder != nil {
+		sch.sharder.refresh(ctx, tick)
+		shards = shardKeys(alertRules)
+	}
+	handedOff := make([]ngmodels.AlertRuleKey, 0)
+	peerRules := make([]*ngmodels.AlertRule, 0)
+	offsets := ngmodels.EvaluationOffsets(alertRules, sch.baseInterval)
+
 	readyToRun := make([]readyToRunItem, 0)
 	updatedRules := make([]ngmodels.AlertRuleKeyWithVersion, 0, len(updated)) // this is needed for tests only
 	missingFolder := make(map[string][]string)
 	for _, item := range alertRules {
 		key := item.GetKey()
+		if sch.sharder != nil && !sch.sharder.owns(shards[key]) {
+			peerRules = append(peerRules, item)
+			if _, ok := registeredDefinitions[key]; ok {
+				handedOff = append(handedOff, key)
+				delete(registeredDefinitions, key)
+			}
+			continue
+		}
 		ruleInfo, newRoutine := sch.registry.getOrCreateInfo(ctx, key)
 
 		// enforce minimum evaluation interval
@@ -255,7 +322,15 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		invalidInterval := item.IntervalSeconds%int64(sch.baseInterval.Seconds()) != 0
 
 		if newRoutine && !invalidInterval {
+			rule := item
 			dispatcherGroup.Go(func() error {
+				// With sharding, the state is not loaded at startup but when the rule gets assigned to this instance,
+				// because it was updated by the instance that evaluated the rule before.
+				if sch.sharder != nil {
+					if err := sch.stateManager.WarmRule(ngmodels.WithRuleKey(ruleInfo.ctx, key), rule); err != nil {
+						sch.log.Error("Failed to load the state of the rule assigned to this instance", append(key.LogContext(), "error", err)...)
+					}
+				}
 				return sch.ruleRoutine(ruleInfo.ctx, key, ruleInfo.evalCh, ruleInfo.updateCh)
 			})
 		}
@@ -268,7 +343,9 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		}
 
 		itemFrequency := item.IntervalSeconds / int64(sch.baseInterval.Seconds())
-		isReadyToRun := item.IntervalSeconds != 0 && tickNum%itemFrequency == 0
+		// rules with the same interval are spread across the interval, see ngmodels.EvaluationOffsets
+		offset := int64(offsets[key] / sch.baseInterval)
+		isReadyToRun := item.IntervalSeconds != 0 && tickNum%itemFrequency == offset
 
 		var folderTitle string
 		if !sch.disableGrafanaFolder {
@@ -306,10 +383,18 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		delete(registeredDefinitions, key)
 	}
 
+	sch.handOffAlertRule(handedOff...)
+	if sch.sharder != nil {
+		sch.loadPeerStates(ctx, dispatcherGroup, peerRules)
+	}
+
 	if len(missingFolder) > 0 { // if this happens then there can be problems with fetching folders from the database.
 		sch.log.Warn("Unable to obtain folder titles for some rules", "missingFolderUIDToRuleUID", missingFolder)
 	}
 
+	// rules that query the state of other rules should be evaluated after those rules
+	sortByDependencies(readyToRun)
+
 	var step int64 = 0
 	if len(readyToRun) > 0 {
 		step = sch.baseInterval.Nanoseconds() / int64(len(readyToRun))
@@ -322,10 +407,12 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 			key := item.rule.GetKey()
 			success, dropped := item.ruleInfo.eval(&item.evaluation)
 			if !success {
+				item.done.close()
 				sch.log.Debug("Scheduled evaluation was canceled because evaluation routine was stopped", append(key.LogContext(), "time", tick)...)
 				return
 			}
 			if dropped != nil {
+				dropped.done.close()
 				sch.log.Warn("Tick dropped because alert rule evaluation is too slow", append(key.LogContext(), "time", tick)...)
 				orgID := fmt.Sprint(key.OrgID)
 				sch.metrics.EvaluationMissed.WithLabelValues(orgID, item.rule.Title).Inc()
@@ -443,6 +530,51 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 		}
 	}
 
+	record := func(ctx context.Context, f fingerprint, attempt int64, e *evaluation, span tracing.Span) error {
+		logger := logger.New("version", e.rule.Version, "fingerprint", f, "attempt", attempt, "now", e.scheduledAt).FromContext(ctx)
+		start := sch.clock.Now()
+
+		err := func() error {
+			evalCtx := eval.NewContext(ctx, SchedulerUserFor(e.rule.OrgID))
+			ruleEval, err := sch.evaluatorFactory.Create(evalCtx, e.rule.GetEvalCondition())
+			if err != nil {
+				return fmt.Errorf("failed to build rule evaluator: %w", err)
+			}
+			resp, err := ruleEval.EvaluateRaw(ctx, e.scheduledAt)
+			if err != nil {
+				return fmt.Errorf("failed to evaluate rule: %w", err)
+			}
+			result, ok := resp.Responses[e.rule.Record.From]
+			if !ok {
+				return fmt.Errorf("no result for query %s", e.rule.Record.From)
+			}
+			if result.Error != nil {
+				return fmt.Errorf("failed to evaluate query %s: %w", e.rule.Record.From, result.Error)
+			}
+			return sch.recordingWriter.Write(ctx, e.rule.Record.Metric, e.scheduledAt, result.Frames, e.rule.Labels)
+		}()
+		dur := sch.clock.Now().Sub(start)
+
+		evalTotal.Inc()
+		evalDuration.Observe(dur.Seconds())
+
+		if err != nil {
+			evalTotalFailures.Inc()
+			logger.Error("Failed to record rule", "error", err, "duration", dur)
+			span.RecordError(err)
+			span.AddEvents(
+				[]string{"error", "message"},
+				[]tracing.EventValue{
+					{Str: fmt.Sprintf("%v", err)},
+					{Str: "rule recording failed"},
+				})
+			return err
+		}
+		logger.Debug("Recording rule evaluated", "metric", e.rule.Record.Metric, "duration", dur)
+		span.AddEvents([]string{"message"}, []tracing.EventValue{{Str: "rule recorded"}})
+		return nil
+	}
+
 	retryIfError := func(f func(attempt int64) error) error {
 		var attempt int64
 		var err error
@@ -485,9 +617,15 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 				evalRunning = true
 				defer func() {
 					evalRunning = false
+					ctx.done.close()
 					sch.evalApplied(key, ctx.scheduledAt)
 				}()
 
+				// the rules whose state the rule queries are evaluated in the same tick
+				if !ctx.waitForDependencies(grafanaCtx, sch.baseInterval) {
+					logger.Warn("Evaluating the rule before the evaluation of the rules whose state it queries has finished", "tick", ctx.scheduledAt)
+				}
+
 				err := retryIfError(func(attempt int64) error {
 					isPaused := ctx.rule.IsPaused
 					f := ruleWithFolder{ctx.rule, ctx.folderTitle}.Fingerprint()
@@ -520,6 +658,13 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 					utcTick := ctx.scheduledAt.UTC().Format(time.RFC3339Nano)
 					span.SetAttributes("tick", utcTick, attribute.String("tick", utcTick))
 
+					if ctx.rule.Type() == ngmodels.RuleTypeRecording {
+						if sch.recordingWriter == nil {
+							logger.Debug("Skip evaluation of recording rule because recording rules are disabled")
+							return nil
+						}
+						return record(tracingCtx, f, attempt, ctx, span)
+					}
 					evaluate(tracingCtx, f, attempt, ctx, span)
 					return nil
 				})
@@ -538,6 +683,10 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key ngmodels.AlertR
 				states := sch.stateManager.DeleteStateByRuleUID(ngmodels.WithRuleKey(ctx, key), key, ngmodels.StateReasonRuleDeleted)
 				notify(states)
 			}
+			// keep the state in the database if the rule is evaluated by another instance now.
+			if errors.Is(grafanaCtx.Err(), errRuleHandedOff) {
+				sch.stateManager.ForgetRule(grafanaCtx, key)
+			}
 			logger.Debug("Stopping alert rule routine")
 			return nil
 		}
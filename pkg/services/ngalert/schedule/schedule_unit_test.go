@@ -129,7 +129,7 @@ func TestProcessTicks(t *testing.T) {
 	})
 
 	// add alert rule under main org with three base intervals
-	alertRule2 := models.AlertRuleGen(models.WithOrgID(mainOrgID), models.WithInterval(3*cfg.BaseInterval), models.WithTitle("rule-2"))()
+	alertRule2 := models.AlertRuleGen(models.WithOrgID(mainOrgID), models.WithInterval(3*cfg.BaseInterval), models.WithoutEvaluationOffset(), models.WithTitle("rule-2"))()
 	ruleStore.PutRule(ctx, alertRule2)
 
 	t.Run("on 2nd tick first alert rule should be evaluated", func(t *testing.T) {
@@ -353,6 +353,44 @@ func TestProcessTicks(t *testing.T) {
 	})
 }
 
+func TestProcessTicks_EvaluationOffset(t *testing.T) {
+	ruleStore := newFakeRulesStore()
+	sch := setupScheduler(t, ruleStore, nil, nil, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+
+	interval := 10 * sch.baseInterval
+	rules := models.GenerateAlertRules(20, models.AlertRuleGen(models.WithOrgID(1), models.WithInterval(interval)))
+	rules = append(rules, models.GenerateAlertRules(5, models.AlertRuleGen(models.WithOrgID(1), models.WithInterval(interval), models.WithoutEvaluationOffset()))...)
+	ruleStore.PutRule(ctx, rules...)
+	for _, rule := range rules {
+		// register rules in advance to not start evaluation routines
+		_, _ = sch.registry.getOrCreateInfo(ctx, rule.GetKey())
+	}
+
+	scheduledAt := make(map[models.AlertRuleKey][]time.Time, len(rules))
+	start := time.Unix(0, 0)
+	for i := 0; i < 10; i++ {
+		tick := start.Add(time.Duration(i) * sch.baseInterval)
+		scheduled, _, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		for _, item := range scheduled {
+			scheduledAt[item.rule.GetKey()] = append(scheduledAt[item.rule.GetKey()], item.scheduledAt)
+		}
+	}
+
+	offsets := map[time.Duration]struct{}{}
+	for _, rule := range rules {
+		offset := rule.EvaluationOffset(sch.baseInterval)
+		offsets[offset] = struct{}{}
+		require.Equalf(t, []time.Time{start.Add(offset)}, scheduledAt[rule.GetKey()], "rule should be evaluated once per interval at its offset")
+		if rule.DisableEvaluationOffset {
+			require.Zero(t, offset)
+		}
+	}
+	require.Greater(t, len(offsets), 1, "rules should be spread across the interval")
+}
+
 func TestSchedule_ruleRoutine(t *testing.T) {
 	createSchedule := func(
 		evalAppliedChan chan time.Time,
@@ -784,7 +822,7 @@ func setupScheduler(t *testing.T, rs *fakeRulesStore, is *state.FakeInstanceStor
 
 	var evaluator = evalMock
 	if evalMock == nil {
-		evaluator = eval.NewEvaluatorFactory(setting.UnifiedAlertingSettings{}, nil, expr.ProvideService(&setting.Cfg{ExpressionsEnabled: true}, nil, nil, &featuremgmt.FeatureManager{}, nil, tracing.InitializeTracerForTest()), &fakes.FakePluginStore{})
+		evaluator = eval.NewEvaluatorFactory(setting.UnifiedAlertingSettings{}, nil, expr.ProvideService(&setting.Cfg{ExpressionsEnabled: true}, nil, nil, &featuremgmt.FeatureManager{}, nil, tracing.InitializeTracerForTest()), &fakes.FakePluginStore{}, nil)
 	}
 
 	if registry == nil {
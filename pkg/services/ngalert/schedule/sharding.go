@@ -0,0 +1,292 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// MembershipStore is the storage shared by all Grafana instances of the cluster, in which every instance records that
+// it is alive. All instances read the members of the cluster from it, so that they agree on the owners of the rules.
+type MembershipStore interface {
+	Set(ctx context.Context, key string, value string) error
+	Del(ctx context.Context, key string) error
+	GetAll(ctx context.Context) (map[int64]map[string]string, error)
+}
+
+const (
+	// memberTimeoutIntervals is the number of base intervals after which an instance that stopped recording its
+	// heartbeat is no longer a member of the cluster.
+	memberTimeoutIntervals = 3
+	// memberCleanupIntervals is the number of base intervals after which the heartbeat of an instance that left the
+	// cluster is deleted from the store.
+	memberCleanupIntervals = 100
+)
+
+// memberHeartbeat is the record of a Grafana instance in the membership store. The times are the ticks of the
+// scheduler in milliseconds, rather than the wall clock, so that all instances evaluate them in the same way.
+type memberHeartbeat struct {
+	JoinedAt int64 `json:"joinedAt"`
+	LastSeen int64 `json:"lastSeen"`
+}
+
+// ringTokensPerMember is the number of points of every member on the hash ring. More points give a more even distribution of rules.
+const ringTokensPerMember = 128
+
+// hashRing assigns keys to members using consistent hashing. Adding or removing a member moves only the keys that
+// are assigned to that member.
+type hashRing struct {
+	tokens  []uint64
+	members map[uint64]string
+}
+
+func newHashRing(members []string) *hashRing {
+	r := &hashRing{
+		tokens:  make([]uint64, 0, len(members)*ringTokensPerMember),
+		members: make(map[uint64]string, len(members)*ringTokensPerMember),
+	}
+	for _, member := range members {
+		for i := 0; i < ringTokensPerMember; i++ {
+			token := hashString(member + "-" + strconv.Itoa(i))
+			if _, ok := r.members[token]; ok {
+				continue
+			}
+			r.members[token] = member
+			r.tokens = append(r.tokens, token)
+		}
+	}
+	sort.Slice(r.tokens, func(i, j int) bool {
+		return r.tokens[i] < r.tokens[j]
+	})
+	return r
+}
+
+// owner returns the member that the key is assigned to, i.e. the member of the first token that follows the hash of the key.
+func (r *hashRing) owner(key string) string {
+	if len(r.tokens) == 0 {
+		return ""
+	}
+	h := hashString(key)
+	idx := sort.Search(len(r.tokens), func(i int) bool {
+		return r.tokens[i] >= h
+	})
+	if idx == len(r.tokens) {
+		idx = 0
+	}
+	return r.members[r.tokens[idx]]
+}
+
+// hashString returns the FNV-1a hash of the string, mixed with the finalizer of MurmurHash3. FNV-1a alone places
+// similar strings, such as the names of rule groups that differ only in a number, close to each other on the ring.
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// shardKeys returns the keys by which the rules are assigned to the members of the cluster. All rules of a rule group
+// have the same key, so that the limit of alert instances of the group is applied by the member that evaluates them.
+// Rules that query the state of other rules have the same key as those rules, so that they read the current state of
+// the rules from the cache of the member. A rule that selects rules by labels can depend on any rule of the
+// organization, so in that case all rules of the organization have the same key.
+func shardKeys(rules []*ngmodels.AlertRule) map[ngmodels.AlertRuleKey]string {
+	groups := make(map[ngmodels.AlertRuleKey]string, len(rules))
+	for _, rule := range rules {
+		groups[rule.GetKey()] = groupShardKey(rule.GetGroupKey())
+	}
+
+	// linked groups form a tree whose root is the smallest key, which makes the key of the linked groups the same on
+	// every member regardless of the order of the rules
+	parent := make(map[string]string)
+	var root func(key string) string
+	root = func(key string) string {
+		p, ok := parent[key]
+		if !ok || p == key {
+			return key
+		}
+		r := root(p)
+		parent[key] = r
+		return r
+	}
+	link := func(a, b string) {
+		ra, rb := root(a), root(b)
+		if ra == rb {
+			return
+		}
+		if rb < ra {
+			ra, rb = rb, ra
+		}
+		parent[rb] = ra
+	}
+
+	byLabels := make(map[int64]struct{})
+	orgGroups := make(map[int64][]string)
+	for _, rule := range rules {
+		group := groups[rule.GetKey()]
+		orgGroups[rule.OrgID] = append(orgGroups[rule.OrgID], group)
+		uids, selectsByLabels := rule.AlertStateDependencies()
+		if selectsByLabels {
+			byLabels[rule.OrgID] = struct{}{}
+		}
+		for _, uid := range uids {
+			if dependency, ok := groups[ngmodels.AlertRuleKey{OrgID: rule.OrgID, UID: uid}]; ok {
+				link(group, dependency)
+			}
+		}
+	}
+	for orgID := range byLabels {
+		for _, group := range orgGroups[orgID] {
+			link(orgGroups[orgID][0], group)
+		}
+	}
+
+	keys := make(map[ngmodels.AlertRuleKey]string, len(groups))
+	for key, group := range groups {
+		keys[key] = root(group)
+	}
+	return keys
+}
+
+func groupShardKey(key ngmodels.AlertRuleGroupKey) string {
+	return strconv.FormatInt(key.OrgID, 10) + "/" + key.NamespaceUID + "/" + key.RuleGroup
+}
+
+// ruleSharder decides which rules are evaluated by this instance when the evaluation is sharded across the cluster.
+//
+// Every instance records a heartbeat in the shared membership store on every tick. An instance is a member of the
+// cluster at a tick if it joined at least one base interval before the tick, and its last heartbeat is not older than
+// memberTimeoutIntervals base intervals. Heartbeats of the current tick therefore never change the members, which makes
+// all instances that read the store at the same tick agree on the members, and so on the owners of the rules.
+type ruleSharder struct {
+	store    MembershipStore
+	self     string
+	interval time.Duration
+	log      log.Logger
+
+	joinedAt time.Time
+	members  []string
+	ring     *hashRing
+}
+
+func newRuleSharder(store MembershipStore, self string, interval time.Duration, logger log.Logger) *ruleSharder {
+	return &ruleSharder{
+		store:    store,
+		self:     self,
+		interval: interval,
+		log:      logger,
+	}
+}
+
+// refresh records the heartbeat of this instance and updates the hash ring if the members of the cluster have changed.
+// Returns true if they have. If the members cannot be read, the previous ring is kept. If there are no members yet,
+// for example, because all instances have just started, the ring is dropped and this instance evaluates all rules.
+func (s *ruleSharder) refresh(ctx context.Context, tick time.Time) bool {
+	heartbeats, err := s.readHeartbeats(ctx)
+	if err != nil {
+		s.log.Error("Failed to read the members of the cluster. Keeping the current assignment of rules", "error", err)
+		return false
+	}
+
+	if s.joinedAt.IsZero() {
+		s.joinedAt = tick
+		// an instance that restarts before it times out keeps its rules
+		if hb, ok := heartbeats[s.self]; ok && hb.LastSeen >= s.ticksAgo(tick, memberTimeoutIntervals) {
+			s.joinedAt = time.UnixMilli(hb.JoinedAt)
+		}
+	}
+	own := memberHeartbeat{JoinedAt: s.joinedAt.UnixMilli(), LastSeen: tick.UnixMilli()}
+	if err := s.writeHeartbeat(ctx, own); err != nil {
+		s.log.Error("Failed to record the heartbeat of this instance", "error", err)
+	}
+	heartbeats[s.self] = own
+
+	members := make([]string, 0, len(heartbeats))
+	for name, hb := range heartbeats {
+		switch {
+		case hb.JoinedAt <= s.ticksAgo(tick, 1) && hb.LastSeen >= s.ticksAgo(tick, memberTimeoutIntervals):
+			members = append(members, name)
+		case hb.LastSeen < s.ticksAgo(tick, memberCleanupIntervals):
+			if err := s.store.Del(ctx, name); err != nil {
+				s.log.Warn("Failed to delete the heartbeat of an instance that left the cluster", "instance", name, "error", err)
+			}
+		}
+	}
+	sort.Strings(members)
+
+	if equalMembers(members, s.members) && (s.ring != nil || len(members) == 0) {
+		return false
+	}
+	s.members = members
+
+	if len(members) == 0 {
+		s.log.Warn("The cluster has no members yet. Evaluating all rules", "instance", s.self)
+		s.ring = nil
+		return true
+	}
+	s.log.Info("Members of the cluster have changed. Rebalancing alert rules", "instance", s.self, "members", members)
+	s.ring = newHashRing(members)
+	return true
+}
+
+// owns returns true if the rules with the shard key should be evaluated by this instance, see shardKeys.
+func (s *ruleSharder) owns(key string) bool {
+	if s.ring == nil {
+		return true
+	}
+	return s.ring.owner(key) == s.self
+}
+
+func (s *ruleSharder) ticksAgo(tick time.Time, intervals int) int64 {
+	return tick.Add(-time.Duration(intervals) * s.interval).UnixMilli()
+}
+
+func (s *ruleSharder) readHeartbeats(ctx context.Context) (map[string]memberHeartbeat, error) {
+	all, err := s.store.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	heartbeats := make(map[string]memberHeartbeat)
+	for _, values := range all {
+		for name, value := range values {
+			var hb memberHeartbeat
+			if err := json.Unmarshal([]byte(value), &hb); err != nil {
+				s.log.Warn("Ignoring invalid heartbeat of an instance", "instance", name, "error", err)
+				continue
+			}
+			heartbeats[name] = hb
+		}
+	}
+	return heartbeats, nil
+}
+
+func (s *ruleSharder) writeHeartbeat(ctx context.Context, hb memberHeartbeat) error {
+	value, err := json.Marshal(hb)
+	if err != nil {
+		return err
+	}
+	return s.store.Set(ctx, s.self, string(value))
+}
+
+func equalMembers(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
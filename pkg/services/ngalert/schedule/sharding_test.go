@@ -0,0 +1,367 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+type fakeMembershipStore struct {
+	mtx    sync.Mutex
+	values map[string]string
+}
+
+func newMembershipStore() *fakeMembershipStore {
+	return &fakeMembershipStore{values: map[string]string{}}
+}
+
+func (f *fakeMembershipStore) Set(_ context.Context, key string, value string) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.values[key] = value
+	return nil
+}
+
+func (f *fakeMembershipStore) Del(_ context.Context, key string) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	delete(f.values, key)
+	return nil
+}
+
+func (f *fakeMembershipStore) GetAll(context.Context) (map[int64]map[string]string, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	values := make(map[string]string, len(f.values))
+	for k, v := range f.values {
+		values[k] = v
+	}
+	return map[int64]map[string]string{0: values}, nil
+}
+
+func setHeartbeat(t *testing.T, store MembershipStore, name string, joinedAt, lastSeen time.Time) {
+	t.Helper()
+	value, err := json.Marshal(memberHeartbeat{JoinedAt: joinedAt.UnixMilli(), LastSeen: lastSeen.UnixMilli()})
+	require.NoError(t, err)
+	require.NoError(t, store.Set(context.Background(), name, string(value)))
+}
+
+type loadingInstanceStore struct {
+	*state.FakeInstanceStore
+	mtx    sync.Mutex
+	loaded map[models.AlertRuleKey]int
+}
+
+func (f *loadingInstanceStore) ListAlertInstances(ctx context.Context, q *models.ListAlertInstancesQuery) ([]*models.AlertInstance, error) {
+	f.mtx.Lock()
+	f.loaded[models.AlertRuleKey{OrgID: q.RuleOrgID, UID: q.RuleUID}]++
+	f.mtx.Unlock()
+	return f.FakeInstanceStore.ListAlertInstances(ctx, q)
+}
+
+func (f *loadingInstanceStore) loadedCount(key models.AlertRuleKey) int {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.loaded[key]
+}
+
+func TestHashRing(t *testing.T) {
+	keys := make([]string, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		keys = append(keys, fmt.Sprintf("1/folder/group-%d", i))
+	}
+
+	t.Run("should assign keys to all members", func(t *testing.T) {
+		ring := newHashRing([]string{"a", "b", "c"})
+		counts := map[string]int{}
+		for _, key := range keys {
+			counts[ring.owner(key)]++
+		}
+		require.Len(t, counts, 3)
+		for member, count := range counts {
+			require.Greaterf(t, count, 150, "member %s got too few keys", member)
+		}
+	})
+
+	t.Run("should move only keys of the removed member", func(t *testing.T) {
+		before := newHashRing([]string{"a", "b", "c"})
+		after := newHashRing([]string{"a", "c"})
+		for _, key := range keys {
+			owner := before.owner(key)
+			if owner != "b" {
+				require.Equal(t, owner, after.owner(key))
+			}
+		}
+	})
+
+	t.Run("should return empty owner if there are no members", func(t *testing.T) {
+		require.Empty(t, newHashRing(nil).owner(keys[0]))
+	})
+}
+
+func TestShardKeys(t *testing.T) {
+	rule := func(orgID int64, uid, group string, stateQuery string) *models.AlertRule {
+		r := models.AlertRuleGen(models.WithOrgID(orgID))()
+		r.UID = uid
+		r.NamespaceUID = "folder"
+		r.RuleGroup = group
+		if stateQuery != "" {
+			r.Data = append(r.Data, models.AlertQuery{
+				RefID:         "S",
+				DatasourceUID: expr.AlertStateDatasourceUID,
+				Model:         json.RawMessage(stateQuery),
+			})
+		}
+		return r
+	}
+
+	t.Run("should assign rules by rule group", func(t *testing.T) {
+		keys := shardKeys([]*models.AlertRule{
+			rule(1, "a", "group-1", ""),
+			rule(1, "b", "group-1", ""),
+			rule(1, "c", "group-2", ""),
+			rule(2, "d", "group-1", ""),
+		})
+		require.Equal(t, map[models.AlertRuleKey]string{
+			{OrgID: 1, UID: "a"}: "1/folder/group-1",
+			{OrgID: 1, UID: "b"}: "1/folder/group-1",
+			{OrgID: 1, UID: "c"}: "1/folder/group-2",
+			{OrgID: 2, UID: "d"}: "2/folder/group-1",
+		}, keys)
+	})
+
+	t.Run("should assign rule groups linked by alert state queries together", func(t *testing.T) {
+		rules := []*models.AlertRule{
+			rule(1, "a", "group-3", `{"ruleUIDs": ["b"]}`),
+			rule(1, "b", "group-2", `{"ruleUIDs": ["c"]}`),
+			rule(1, "c", "group-1", ""),
+			rule(1, "d", "group-4", ""),
+			rule(2, "e", "group-1", `{"ruleUIDs": ["a"]}`),
+		}
+		expected := map[models.AlertRuleKey]string{
+			{OrgID: 1, UID: "a"}: "1/folder/group-1",
+			{OrgID: 1, UID: "b"}: "1/folder/group-1",
+			{OrgID: 1, UID: "c"}: "1/folder/group-1",
+			{OrgID: 1, UID: "d"}: "1/folder/group-4",
+			{OrgID: 2, UID: "e"}: "2/folder/group-1",
+		}
+		require.Equal(t, expected, shardKeys(rules))
+
+		reversed := make([]*models.AlertRule, 0, len(rules))
+		for i := len(rules) - 1; i >= 0; i-- {
+			reversed = append(reversed, rules[i])
+		}
+		require.Equal(t, expected, shardKeys(reversed), "keys should not depend on the order of the rules")
+	})
+
+	t.Run("should assign all rules of the organization together if a rule selects rules by labels", func(t *testing.T) {
+		keys := shardKeys([]*models.AlertRule{
+			rule(1, "a", "group-3", `{"labels": {"team": "a"}}`),
+			rule(1, "b", "group-2", ""),
+			rule(1, "c", "group-1", ""),
+			rule(2, "d", "group-2", ""),
+		})
+		require.Equal(t, map[models.AlertRuleKey]string{
+			{OrgID: 1, UID: "a"}: "1/folder/group-1",
+			{OrgID: 1, UID: "b"}: "1/folder/group-1",
+			{OrgID: 1, UID: "c"}: "1/folder/group-1",
+			{OrgID: 2, UID: "d"}: "2/folder/group-2",
+		}, keys)
+	})
+}
+
+func TestRuleSharder(t *testing.T) {
+	ctx := context.Background()
+	store := newMembershipStore()
+	newSharder := func(name string) *ruleSharder {
+		return newRuleSharder(store, name, time.Second, log.NewNopLogger())
+	}
+	keys := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		keys = append(keys, fmt.Sprintf("1/folder/group-%d", i))
+	}
+	requireSingleOwner := func(t *testing.T, sharders ...*ruleSharder) {
+		t.Helper()
+		for _, key := range keys {
+			owners := 0
+			for _, s := range sharders {
+				if s.owns(key) {
+					owners++
+				}
+			}
+			require.Equalf(t, 1, owners, "rules %s should have exactly one owner", key)
+		}
+	}
+
+	a, b, c := newSharder("a"), newSharder("b"), newSharder("c")
+	tick := time.Unix(1000, 0)
+
+	t.Run("should own all rules while the cluster has no members", func(t *testing.T) {
+		require.False(t, a.refresh(ctx, tick))
+		require.False(t, b.refresh(ctx, tick))
+		require.True(t, a.owns(keys[0]))
+		require.True(t, b.owns(keys[0]))
+	})
+
+	t.Run("should share the rules between the members after they joined", func(t *testing.T) {
+		tick = tick.Add(time.Second)
+		require.True(t, a.refresh(ctx, tick))
+		require.True(t, b.refresh(ctx, tick))
+		require.Equal(t, []string{"a", "b"}, a.members)
+		requireSingleOwner(t, a, b)
+	})
+
+	t.Run("should not own any rules before joining the cluster", func(t *testing.T) {
+		tick = tick.Add(time.Second)
+		require.False(t, a.refresh(ctx, tick))
+		require.True(t, c.refresh(ctx, tick))
+		require.False(t, b.refresh(ctx, tick))
+		require.Equal(t, a.members, b.members, "members that read the store before and after the new instance should agree")
+		for _, key := range keys {
+			require.False(t, c.owns(key))
+		}
+		requireSingleOwner(t, a, b)
+	})
+
+	t.Run("should agree on the members after a new instance joined", func(t *testing.T) {
+		tick = tick.Add(time.Second)
+		require.True(t, a.refresh(ctx, tick))
+		require.True(t, b.refresh(ctx, tick))
+		require.True(t, c.refresh(ctx, tick))
+		require.Equal(t, []string{"a", "b", "c"}, a.members)
+		requireSingleOwner(t, a, b, c)
+	})
+
+	t.Run("should remove members that time out", func(t *testing.T) {
+		lastSeen := tick
+		for tick.Sub(lastSeen) < memberTimeoutIntervals*time.Second {
+			tick = tick.Add(time.Second)
+			require.False(t, a.refresh(ctx, tick))
+			require.False(t, c.refresh(ctx, tick))
+		}
+		tick = tick.Add(time.Second)
+		require.True(t, a.refresh(ctx, tick))
+		require.True(t, c.refresh(ctx, tick))
+		require.Equal(t, []string{"a", "c"}, a.members)
+		requireSingleOwner(t, a, c)
+	})
+
+	t.Run("should keep the rules of an instance that restarts before it times out", func(t *testing.T) {
+		tick = tick.Add(time.Second)
+		restarted := newSharder("a")
+		require.True(t, restarted.refresh(ctx, tick))
+		require.False(t, c.refresh(ctx, tick))
+		requireSingleOwner(t, restarted, c)
+	})
+
+	t.Run("should delete heartbeats of instances that left the cluster", func(t *testing.T) {
+		tick = tick.Add(memberCleanupIntervals * time.Second)
+		a.refresh(ctx, tick)
+		all, err := store.GetAll(ctx)
+		require.NoError(t, err)
+		require.NotContains(t, all[0], "b")
+	})
+}
+
+func TestProcessTicks_Sharding(t *testing.T) {
+	ruleStore := newFakeRulesStore()
+	sch := setupScheduler(t, ruleStore, nil, nil, nil, nil)
+	instanceStore := &loadingInstanceStore{FakeInstanceStore: &state.FakeInstanceStore{}, loaded: map[models.AlertRuleKey]int{}}
+	sch.stateManager = state.NewManager(state.ManagerCfg{
+		Metrics:                 metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		InstanceStore:           instanceStore,
+		Images:                  &state.NoopImageService{},
+		Clock:                   clock.NewMock(),
+		MaxStateSaveConcurrency: 1,
+	})
+	store := newMembershipStore()
+	sch.sharder = newRuleSharder(store, "a", time.Second, log.NewNopLogger())
+
+	stopped := make(chan models.AlertRuleKey, 100)
+	sch.stopAppliedFunc = func(key models.AlertRuleKey) {
+		stopped <- key
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+
+	rules := models.GenerateAlertRules(20, models.AlertRuleGen(models.WithOrgID(1), models.WithInterval(time.Second), withQueryForState(t, eval.Normal)))
+	ruleStore.PutRule(ctx, rules...)
+
+	start := time.Unix(1000, 0)
+	tick := start
+	scheduled, _, _ := sch.processTick(ctx, dispatcherGroup, tick)
+	require.Len(t, scheduled, len(rules), "instance should evaluate all rules while the cluster has no members")
+	require.Eventually(t, func() bool {
+		for _, rule := range rules {
+			if instanceStore.loadedCount(rule.GetKey()) != 1 {
+				return false
+			}
+		}
+		return true
+	}, time.Second, 10*time.Millisecond, "state of rules should be loaded when they are assigned to the instance")
+	infos := make(map[models.AlertRuleKey]*alertRuleInfo, len(rules))
+	for _, rule := range rules {
+		infos[rule.GetKey()], _ = sch.registry.getOrCreateInfo(ctx, rule.GetKey())
+	}
+
+	tick = tick.Add(time.Second)
+	setHeartbeat(t, store, "b", start, tick)
+	scheduled, deleted, _ := sch.processTick(ctx, dispatcherGroup, tick)
+	require.Empty(t, deleted, "handed off rules should not be deleted")
+	require.NotEmpty(t, scheduled)
+	require.Less(t, len(scheduled), len(rules))
+
+	shards := shardKeys(rules)
+	handedOff := map[models.AlertRuleKey]struct{}{}
+	for _, rule := range rules {
+		if sch.sharder.ring.owner(shards[rule.GetKey()]) == "b" {
+			handedOff[rule.GetKey()] = struct{}{}
+			require.False(t, sch.registry.exists(rule.GetKey()))
+			require.ErrorIs(t, infos[rule.GetKey()].ctx.Err(), errRuleHandedOff)
+		} else {
+			require.True(t, sch.registry.exists(rule.GetKey()))
+		}
+	}
+	require.Len(t, handedOff, len(rules)-len(scheduled))
+	require.Eventually(t, func() bool {
+		return instanceStore.loadedCount(models.AlertRuleKey{OrgID: 1}) > 0
+	}, time.Second, 10*time.Millisecond, "state of rules evaluated by other instances should be loaded")
+	for range handedOff {
+		select {
+		case key := <-stopped:
+			require.Contains(t, handedOff, key)
+		case <-time.After(time.Second):
+			t.Fatal("rule routine was not stopped")
+		}
+	}
+
+	// instance b stops recording its heartbeat
+	tick = tick.Add((memberTimeoutIntervals + 1) * time.Second)
+	scheduled, _, _ = sch.processTick(ctx, dispatcherGroup, tick)
+	require.Len(t, scheduled, len(rules))
+	require.Eventually(t, func() bool {
+		for key := range handedOff {
+			if instanceStore.loadedCount(key) != 2 {
+				return false
+			}
+		}
+		return true
+	}, time.Second, 10*time.Millisecond, "state of rules assigned back to the instance should be loaded")
+}
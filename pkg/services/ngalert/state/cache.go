@@ -19,6 +19,8 @@ import (
 )
 
 type ruleStates struct {
+	// group is the rule group of the rule. It is used to count the alert instances of rule groups.
+	group  ngModels.AlertRuleGroupKey
 	states map[string]*State
 }
 
@@ -53,6 +55,7 @@ func (c *cache) getOrCreate(ctx context.Context, log log.Logger, alertRule *ngMo
 		states = &ruleStates{states: make(map[string]*State)}
 		c.states[stateCandidate.OrgID][stateCandidate.AlertRuleUID] = states
 	}
+	states.group = alertRule.GetGroupKey()
 	return states.getOrAdd(stateCandidate)
 }
 
@@ -205,6 +208,15 @@ func (c *cache) setAllStates(newStates map[int64]map[string]*ruleStates) {
 	c.states = newStates
 }
 
+func (c *cache) setRuleStates(orgID int64, ruleUID string, states *ruleStates) {
+	c.mtxStates.Lock()
+	defer c.mtxStates.Unlock()
+	if _, ok := c.states[orgID]; !ok {
+		c.states[orgID] = make(map[string]*ruleStates)
+	}
+	c.states[orgID][ruleUID] = states
+}
+
 func (c *cache) set(entry *State) {
 	c.mtxStates.Lock()
 	defer c.mtxStates.Unlock()
@@ -267,6 +279,57 @@ func (c *cache) getStatesForRuleUID(orgID int64, alertRuleUID string, skipNormal
 	return result
 }
 
+// count returns the number of alert instances of the organization, without the instances of the rules in skip.
+func (c *cache) count(orgID int64, skip map[string]struct{}) int64 {
+	c.mtxStates.RLock()
+	defer c.mtxStates.RUnlock()
+	var count int64
+	for uid, rs := range c.states[orgID] {
+		if _, ok := skip[uid]; ok {
+			continue
+		}
+		count += int64(len(rs.states))
+	}
+	return count
+}
+
+// ruleUIDs returns the UIDs of the rules of the organization whose state is in the cache.
+func (c *cache) ruleUIDs(orgID int64) map[string]struct{} {
+	c.mtxStates.RLock()
+	defer c.mtxStates.RUnlock()
+	uids := make(map[string]struct{}, len(c.states[orgID]))
+	for uid := range c.states[orgID] {
+		uids[uid] = struct{}{}
+	}
+	return uids
+}
+
+// hasRule returns true if the state of the rule is in the cache.
+func (c *cache) hasRule(orgID int64, uid string) bool {
+	c.mtxStates.RLock()
+	defer c.mtxStates.RUnlock()
+	_, ok := c.states[orgID][uid]
+	return ok
+}
+
+// countInstances returns the number of alert instances of the other rules of the rule group of the rule, and of the
+// other rules of the organization of the rule.
+func (c *cache) countInstances(rule *ngModels.AlertRule) (group int64, org int64) {
+	c.mtxStates.RLock()
+	defer c.mtxStates.RUnlock()
+	groupKey := rule.GetGroupKey()
+	for uid, rs := range c.states[rule.OrgID] {
+		if uid == rule.UID {
+			continue
+		}
+		org += int64(len(rs.states))
+		if rs.group == groupKey {
+			group += int64(len(rs.states))
+		}
+	}
+	return group, org
+}
+
 // removeByRuleUID deletes all entries in the state cache that match the given UID. Returns removed states
 func (c *cache) removeByRuleUID(orgID int64, uid string) []*State {
 	c.mtxStates.Lock()
@@ -89,11 +89,12 @@ func (h *AnnotationBackend) Record(ctx context.Context, rule history_model.RuleM
 func (h *AnnotationBackend) Query(ctx context.Context, query ngmodels.HistoryQuery) (*data.Frame, error) {
 	logger := h.log.FromContext(ctx)
 	if query.RuleUID == "" {
-		return nil, fmt.Errorf("ruleUID is required to query annotations")
+		return nil, fmt.Errorf("%w: ruleUID is required to query annotations", ngmodels.ErrUnsupportedHistoryQuery)
 	}
 
-	if query.Labels != nil {
-		logger.Warn("Annotation state history backend does not support label queries, ignoring that filter")
+	// Annotations do not store the labels in a structured way, see below.
+	if len(query.Labels) > 0 {
+		return nil, fmt.Errorf("%w: labels cannot be queried in annotations", ngmodels.ErrUnsupportedHistoryQuery)
 	}
 
 	rq := ngmodels.GetAlertRuleByUIDQuery{
@@ -111,9 +112,10 @@ func (h *AnnotationBackend) Query(ctx context.Context, query ngmodels.HistoryQue
 	q := annotations.ItemQuery{
 		AlertID:      rule.ID,
 		OrgID:        query.OrgID,
-		From:         query.From.Unix(),
-		To:           query.To.Unix(),
+		From:         query.From.UnixMilli(),
+		To:           query.To.UnixMilli(),
 		SignedInUser: query.SignedInUser,
+		Limit:        int64(query.Limit),
 	}
 	items, err := h.store.Find(ctx, &q)
 	if err != nil {
@@ -154,7 +156,7 @@ func (h *AnnotationBackend) Query(ctx context.Context, query ngmodels.HistoryQue
 			logger.Error("Annotation service gave an annotation with unparseable data, skipping", "id", item.ID, "err", err)
 			continue
 		}
-		times = append(times, time.Unix(item.Time, 0))
+		times = append(times, time.UnixMilli(item.Time))
 		texts = append(texts, item.Text)
 		prevStates = append(prevStates, item.PrevState)
 		nextStates = append(nextStates, item.NewState)
@@ -47,6 +47,22 @@ func TestAnnotationHistorian(t *testing.T) {
 		}
 	})
 
+	t.Run("querying without a rule UID is not supported", func(t *testing.T) {
+		anns := createTestAnnotationBackendSut(t)
+
+		_, err := anns.Query(context.Background(), models.HistoryQuery{OrgID: 1})
+
+		require.ErrorIs(t, err, models.ErrUnsupportedHistoryQuery)
+	})
+
+	t.Run("querying by labels is not supported", func(t *testing.T) {
+		anns := createTestAnnotationBackendSut(t)
+
+		_, err := anns.Query(context.Background(), models.HistoryQuery{RuleUID: "my-rule", OrgID: 1, Labels: map[string]string{"a": "b"}})
+
+		require.ErrorIs(t, err, models.ErrUnsupportedHistoryQuery)
+	})
+
 	t.Run("writing state transitions as annotations succeeds", func(t *testing.T) {
 		anns := createTestAnnotationBackendSut(t)
 		rule := createTestRule()
@@ -151,6 +151,22 @@ func buildSelectors(query models.HistoryQuery) ([]Selector, error) {
 	}
 	selectors[1] = selector
 
+	// Rule group and folder are stream labels, so they can be selected without parsing the log lines.
+	if query.NamespaceUID != "" {
+		selector, err = NewSelector(FolderUIDLabel, "=", query.NamespaceUID)
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+	}
+	if query.RuleGroup != "" {
+		selector, err = NewSelector(GroupLabel, "=", query.RuleGroup)
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+	}
+
 	return selectors, nil
 }
 
@@ -275,6 +275,15 @@ func TestRemoteLokiBackend(t *testing.T) {
 				},
 				exp: `{orgID="123",from="state-history"} | json | ruleUID="rule-uid" | labels_customlabel="customvalue"`,
 			},
+			{
+				name: "adds stream label filters for folder and group",
+				query: models.HistoryQuery{
+					OrgID:        123,
+					NamespaceUID: "folder-uid",
+					RuleGroup:    "group",
+				},
+				exp: `{orgID="123",from="state-history",folderUID="folder-uid",group="group"}`,
+			},
 		}
 
 		for _, tc := range cases {
@@ -0,0 +1,398 @@
+package historian
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+)
+
+const (
+	// sqlCleanupInterval is how often the SQL backend deletes state history that is older than the retention period.
+	sqlCleanupInterval = 10 * time.Minute
+	// sqlCleanupBatchSize is the maximum number of entries deleted by a single statement during cleanup.
+	sqlCleanupBatchSize = 1000
+	// sqlLabelMaxLength is the length of the name and value columns of the label table.
+	sqlLabelMaxLength = 190
+)
+
+// sqlHistoryEntry is a single state transition stored in the database.
+type sqlHistoryEntry struct {
+	ID           int64  `xorm:"pk autoincr 'id'"`
+	OrgID        int64  `xorm:"org_id"`
+	RuleUID      string `xorm:"rule_uid"`
+	NamespaceUID string `xorm:"rule_namespace_uid"`
+	RuleGroup    string `xorm:"rule_group"`
+	DashboardUID string `xorm:"dashboard_uid"`
+	PanelID      int64  `xorm:"panel_id"`
+	Condition    string `xorm:"condition"`
+	Previous     string `xorm:"previous_state"`
+	Current      string `xorm:"current_state"`
+	Error        string `xorm:"error_message"`
+	Values       string `xorm:"state_values"`
+	Labels       string `xorm:"labels"`
+	Fingerprint  string `xorm:"fingerprint"`
+	// Epoch is the time of the transition in Unix milliseconds.
+	Epoch int64 `xorm:"epoch"`
+
+	labels data.Labels `xorm:"-"`
+}
+
+func (e sqlHistoryEntry) TableName() string {
+	return "alert_state_history"
+}
+
+// sqlHistoryLabel is a single label of a state transition. Labels are stored separately to make it possible to filter by them.
+type sqlHistoryLabel struct {
+	ID        int64  `xorm:"pk autoincr 'id'"`
+	HistoryID int64  `xorm:"history_id"`
+	Name      string `xorm:"name"`
+	Value     string `xorm:"value"`
+}
+
+func (l sqlHistoryLabel) TableName() string {
+	return "alert_state_history_label"
+}
+
+// indexedLabel returns a label name or value as it is stored in the label table. Strings that do not fit into the
+// column are shortened to a prefix followed by a hash of the whole string, so that they can still be matched exactly.
+// The full labels are stored with the entry.
+func indexedLabel(s string) string {
+	if utf8.RuneCountInString(s) <= sqlLabelMaxLength {
+		return s
+	}
+	sum := sha256.Sum256([]byte(s))
+	hash := hex.EncodeToString(sum[:])
+	return string([]rune(s)[:sqlLabelMaxLength-len(hash)-1]) + "~" + hash
+}
+
+// SQLBackend is a state.Historian that records state history to dedicated tables in the Grafana database.
+// Unlike the annotation backend, it stores the full set of labels of every transition, which makes it possible to query history by labels.
+type SQLBackend struct {
+	db             db.DB
+	externalLabels map[string]string
+	retention      time.Duration
+	clock          clock.Clock
+	metrics        *metrics.Historian
+	log            log.Logger
+}
+
+func NewSQLBackend(db db.DB, externalLabels map[string]string, retention time.Duration, metrics *metrics.Historian) *SQLBackend {
+	return &SQLBackend{
+		db:             db,
+		externalLabels: externalLabels,
+		retention:      retention,
+		clock:          clock.New(),
+		metrics:        metrics,
+		log:            log.New("ngalert.state.historian", "backend", "sql"),
+	}
+}
+
+// Record writes a number of state transitions for a given rule to the database.
+func (h *SQLBackend) Record(ctx context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
+	logger := h.log.FromContext(ctx)
+	// Build entries before starting goroutine, to make sure all data is copied and won't mutate underneath us.
+	entries := statesToSQLEntries(rule, states, logger)
+
+	errCh := make(chan error, 1)
+	if len(entries) == 0 {
+		close(errCh)
+		return errCh
+	}
+
+	// This is a new background job, so let's create a brand new context for it.
+	// We want it to be isolated, i.e. we don't want grafana shutdowns to interrupt this work
+	// immediately but rather try to flush writes.
+	// This also prevents timeouts or other lingering objects (like transactions) from being
+	// incorrectly propagated here from other areas.
+	writeCtx := context.Background()
+	writeCtx, cancel := context.WithTimeout(writeCtx, StateHistoryWriteTimeout)
+	writeCtx = history_model.WithRuleData(writeCtx, rule)
+	writeCtx = tracing.ContextWithSpan(writeCtx, tracing.SpanFromContext(ctx))
+
+	go func(ctx context.Context) {
+		defer cancel()
+		defer close(errCh)
+		logger := h.log.FromContext(ctx)
+
+		org := fmt.Sprint(rule.OrgID)
+		h.metrics.WritesTotal.WithLabelValues(org, "sql").Inc()
+		h.metrics.TransitionsTotal.WithLabelValues(org).Add(float64(len(entries)))
+
+		if err := h.save(ctx, entries); err != nil {
+			logger.Error("Failed to save alert state history batch", "error", err)
+			h.metrics.WritesFailed.WithLabelValues(org, "sql").Inc()
+			h.metrics.TransitionsFailed.WithLabelValues(org).Add(float64(len(entries)))
+			errCh <- fmt.Errorf("failed to save alert state history batch: %w", err)
+			return
+		}
+		logger.Debug("Done saving alert state history batch")
+	}(writeCtx)
+	return errCh
+}
+
+func (h *SQLBackend) save(ctx context.Context, entries []sqlHistoryEntry) error {
+	return h.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		for i := range entries {
+			entry := &entries[i]
+			if _, err := sess.Insert(entry); err != nil {
+				return err
+			}
+			if len(entry.labels) == 0 {
+				continue
+			}
+			labels := make([]*sqlHistoryLabel, 0, len(entry.labels))
+			for name, value := range entry.labels {
+				labels = append(labels, &sqlHistoryLabel{HistoryID: entry.ID, Name: indexedLabel(name), Value: indexedLabel(value)})
+			}
+			if _, err := sess.Insert(&labels); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Query retrieves state history entries from the database and formats the results into a dataframe.
+// The entries are paged from the latest to the oldest, and entries within the page are sorted by time.
+func (h *SQLBackend) Query(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	now := h.clock.Now().UTC()
+	if query.To.IsZero() || query.To.Unix() == 0 {
+		query.To = now
+	}
+	if query.From.IsZero() || query.From.Unix() == 0 {
+		query.From = now.Add(-defaultQueryRange)
+	}
+	if query.Limit < 0 || query.Page < 0 {
+		return nil, fmt.Errorf("limit and page must not be negative")
+	}
+
+	var entries []sqlHistoryEntry
+	err := h.db.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Table(sqlHistoryEntry{}.TableName()).
+			Where("org_id = ?", query.OrgID).
+			And("epoch >= ?", query.From.UnixMilli()).
+			And("epoch <= ?", query.To.UnixMilli())
+		if query.RuleUID != "" {
+			q = q.And("rule_uid = ?", query.RuleUID)
+		}
+		if query.NamespaceUID != "" {
+			q = q.And("rule_namespace_uid = ?", query.NamespaceUID)
+		}
+		if query.RuleGroup != "" {
+			q = q.And("rule_group = ?", query.RuleGroup)
+		}
+		if query.DashboardUID != "" {
+			q = q.And("dashboard_uid = ?", query.DashboardUID)
+		}
+		if query.PanelID != 0 {
+			q = q.And("panel_id = ?", query.PanelID)
+		}
+
+		// Ensure that all queries we build are deterministic.
+		names := make([]string, 0, len(query.Labels))
+		for name := range query.Labels {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			q = q.And(fmt.Sprintf("EXISTS (SELECT 1 FROM %[1]s WHERE %[1]s.history_id = %[2]s.id AND %[1]s.name = ? AND %[1]s.value = ?)", sqlHistoryLabel{}.TableName(), sqlHistoryEntry{}.TableName()), indexedLabel(name), indexedLabel(query.Labels[name]))
+		}
+
+		q = q.Desc("epoch", "id")
+		if query.Limit > 0 {
+			page := query.Page
+			if page == 0 {
+				page = 1
+			}
+			q = q.Limit(query.Limit, (page-1)*query.Limit)
+		}
+		return q.Find(&entries)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query state history: %w", err)
+	}
+	return h.entriesToFrame(entries)
+}
+
+// entriesToFrame formats the state history entries into the dataframe of the same shape the Loki backend returns.
+func (h *SQLBackend) entriesToFrame(entries []sqlHistoryEntry) (*data.Frame, error) {
+	frame := data.NewFrame("states")
+	lbls := data.Labels(map[string]string{})
+
+	times := make([]time.Time, 0, len(entries))
+	lines := make([]json.RawMessage, 0, len(entries))
+	labels := make([]json.RawMessage, 0, len(entries))
+	// The entries are fetched latest first to page them, but the frame is expected to be sorted by time.
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		line, err := h.entryToLine(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize state history entry %d: %w", entry.ID, err)
+		}
+		lblsJson, err := json.Marshal(h.streamLabels(entry))
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize stream labels: %w", err)
+		}
+		times = append(times, time.UnixMilli(entry.Epoch))
+		lines = append(lines, line)
+		labels = append(labels, lblsJson)
+	}
+
+	frame.Fields = append(frame.Fields, data.NewField(dfTime, lbls, times))
+	frame.Fields = append(frame.Fields, data.NewField(dfLine, lbls, lines))
+	frame.Fields = append(frame.Fields, data.NewField(dfLabels, lbls, labels))
+	return frame, nil
+}
+
+func (h *SQLBackend) entryToLine(entry sqlHistoryEntry) (json.RawMessage, error) {
+	line := lokiEntry{
+		SchemaVersion:  1,
+		Previous:       entry.Previous,
+		Current:        entry.Current,
+		Error:          entry.Error,
+		Condition:      entry.Condition,
+		DashboardUID:   entry.DashboardUID,
+		PanelID:        entry.PanelID,
+		Fingerprint:    entry.Fingerprint,
+		RuleUID:        entry.RuleUID,
+		InstanceLabels: map[string]string{},
+	}
+	if entry.Values != "" && entry.Values != "null" {
+		if err := json.Unmarshal([]byte(entry.Values), &line.Values); err != nil {
+			return nil, err
+		}
+	}
+	if entry.Labels != "" {
+		if err := json.Unmarshal([]byte(entry.Labels), &line.InstanceLabels); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(line)
+}
+
+// streamLabels returns the labels that the Loki backend would attach to the stream the entry belongs to.
+func (h *SQLBackend) streamLabels(entry sqlHistoryEntry) map[string]string {
+	labels := mergeLabels(make(map[string]string), h.externalLabels)
+	labels[StateHistoryLabelKey] = StateHistoryLabelValue
+	labels[OrgIDLabel] = fmt.Sprint(entry.OrgID)
+	labels[GroupLabel] = entry.RuleGroup
+	labels[FolderUIDLabel] = entry.NamespaceUID
+	return labels
+}
+
+// Run periodically deletes state history that is older than the retention period until the context is cancelled.
+func (h *SQLBackend) Run(ctx context.Context) error {
+	if h.retention <= 0 {
+		return nil
+	}
+	ticker := h.clock.Ticker(sqlCleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			deleted, err := h.DeleteExpired(ctx)
+			if err != nil {
+				h.log.Error("Failed to delete expired state history", "error", err)
+				continue
+			}
+			h.log.Debug("Deleted expired state history", "count", deleted)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// DeleteExpired deletes state history that is older than the retention period. Returns the number of deleted entries.
+func (h *SQLBackend) DeleteExpired(ctx context.Context) (int64, error) {
+	if h.retention <= 0 {
+		return 0, nil
+	}
+	cutoff := h.clock.Now().Add(-h.retention).UnixMilli()
+
+	var total int64
+	for {
+		var deleted int64
+		err := h.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+			var ids []int64
+			if err := sess.Table(sqlHistoryEntry{}.TableName()).Where("epoch < ?", cutoff).Limit(sqlCleanupBatchSize).Cols("id").Find(&ids); err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				return nil
+			}
+			if _, err := sess.In("history_id", ids).Delete(&sqlHistoryLabel{}); err != nil {
+				return err
+			}
+			affected, err := sess.In("id", ids).Delete(&sqlHistoryEntry{})
+			deleted = affected
+			return err
+		})
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+		if deleted < sqlCleanupBatchSize {
+			return total, nil
+		}
+	}
+}
+
+func statesToSQLEntries(rule history_model.RuleMeta, states []state.StateTransition, logger log.Logger) []sqlHistoryEntry {
+	entries := make([]sqlHistoryEntry, 0, len(states))
+	for _, state := range states {
+		if !shouldRecord(state) {
+			continue
+		}
+
+		sanitizedLabels := removePrivateLabels(state.Labels)
+		labels, err := json.Marshal(sanitizedLabels)
+		if err != nil {
+			logger.Error("Failed to serialize labels of state, skipping", "error", err)
+			continue
+		}
+		values, err := json.Marshal(valuesAsDataBlob(state.State))
+		if err != nil {
+			logger.Error("Failed to serialize values of state, skipping", "error", err)
+			continue
+		}
+
+		entry := sqlHistoryEntry{
+			OrgID:        rule.OrgID,
+			RuleUID:      rule.UID,
+			NamespaceUID: rule.NamespaceUID,
+			RuleGroup:    rule.Group,
+			DashboardUID: rule.DashboardUID,
+			PanelID:      rule.PanelID,
+			Condition:    rule.Condition,
+			Previous:     state.PreviousFormatted(),
+			Current:      state.Formatted(),
+			Values:       string(values),
+			Labels:       string(labels),
+			Fingerprint:  labelFingerprint(sanitizedLabels),
+			Epoch:        state.State.LastEvaluationTime.UnixMilli(),
+			labels:       sanitizedLabels,
+		}
+		if state.State.State == eval.Error && state.Error != nil {
+			entry.Error = state.Error.Error()
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
@@ -0,0 +1,219 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+)
+
+func TestIntegrationSQLBackend(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	now := time.Now().Truncate(time.Millisecond)
+
+	t.Run("recorded transitions are queryable with full labels", func(t *testing.T) {
+		backend := createTestSQLBackend(t, now)
+		rule := createTestRule()
+		states := singleFromNormal(&state.State{
+			State:              eval.Alerting,
+			Labels:             data.Labels{"a": "b", "__private__": "hidden"},
+			Values:             map[string]float64{"A": 1.5},
+			LastEvaluationTime: now.Add(-time.Minute),
+		})
+
+		require.NoError(t, <-backend.Record(context.Background(), rule, states))
+
+		frame, err := backend.Query(context.Background(), models.HistoryQuery{OrgID: rule.OrgID, RuleUID: rule.UID})
+		require.NoError(t, err)
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, 1, frame.Rows())
+
+		require.Equal(t, now.Add(-time.Minute), frame.Fields[0].At(0).(time.Time))
+		entry := lokiEntry{}
+		require.NoError(t, json.Unmarshal(frame.Fields[1].At(0).(json.RawMessage), &entry))
+		require.Equal(t, "Normal", entry.Previous)
+		require.Equal(t, "Alerting", entry.Current)
+		require.Equal(t, rule.UID, entry.RuleUID)
+		require.Equal(t, rule.DashboardUID, entry.DashboardUID)
+		require.Equal(t, rule.PanelID, entry.PanelID)
+		require.Equal(t, map[string]string{"a": "b"}, entry.InstanceLabels)
+		require.Equal(t, 1.5, entry.Values.Get("A").MustFloat64())
+
+		streamLabels := map[string]string{}
+		require.NoError(t, json.Unmarshal(frame.Fields[2].At(0).(json.RawMessage), &streamLabels))
+		require.Equal(t, map[string]string{
+			StateHistoryLabelKey: StateHistoryLabelValue,
+			OrgIDLabel:           "1",
+			GroupLabel:           rule.Group,
+			FolderUIDLabel:       rule.NamespaceUID,
+			"extra":              "label",
+		}, streamLabels)
+	})
+
+	t.Run("query filters by labels, rule group and folder", func(t *testing.T) {
+		backend := createTestSQLBackend(t, now)
+		ruleA := createTestRule()
+		ruleB := createTestRule()
+		ruleB.UID = "other-rule-uid"
+		ruleB.Group = "other-group"
+		ruleB.NamespaceUID = "other-folder"
+
+		recordTransition(t, backend, ruleA, data.Labels{"team": "a", "env": "prod"}, now.Add(-3*time.Minute))
+		recordTransition(t, backend, ruleA, data.Labels{"team": "b", "env": "prod"}, now.Add(-2*time.Minute))
+		recordTransition(t, backend, ruleB, data.Labels{"team": "a", "env": "dev"}, now.Add(-1*time.Minute))
+
+		cases := []struct {
+			name  string
+			query models.HistoryQuery
+			exp   int
+		}{
+			{
+				name:  "no filters",
+				query: models.HistoryQuery{OrgID: 1},
+				exp:   3,
+			},
+			{
+				name:  "single label",
+				query: models.HistoryQuery{OrgID: 1, Labels: map[string]string{"team": "a"}},
+				exp:   2,
+			},
+			{
+				name:  "multiple labels",
+				query: models.HistoryQuery{OrgID: 1, Labels: map[string]string{"team": "a", "env": "prod"}},
+				exp:   1,
+			},
+			{
+				name:  "rule group",
+				query: models.HistoryQuery{OrgID: 1, RuleGroup: "other-group"},
+				exp:   1,
+			},
+			{
+				name:  "folder",
+				query: models.HistoryQuery{OrgID: 1, NamespaceUID: ruleA.NamespaceUID},
+				exp:   2,
+			},
+			{
+				name:  "other org",
+				query: models.HistoryQuery{OrgID: 2},
+				exp:   0,
+			},
+			{
+				name:  "time range",
+				query: models.HistoryQuery{OrgID: 1, From: now.Add(-150 * time.Second), To: now},
+				exp:   2,
+			},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				frame, err := backend.Query(context.Background(), tc.query)
+				require.NoError(t, err)
+				require.Equal(t, tc.exp, frame.Rows())
+			})
+		}
+	})
+
+	t.Run("query filters by label values longer than the label column", func(t *testing.T) {
+		backend := createTestSQLBackend(t, now)
+		rule := createTestRule()
+		long := strings.Repeat("ä", 2*sqlLabelMaxLength)
+		recordTransition(t, backend, rule, data.Labels{"description": long}, now.Add(-2*time.Minute))
+		recordTransition(t, backend, rule, data.Labels{"description": long + "x"}, now.Add(-time.Minute))
+
+		frame, err := backend.Query(context.Background(), models.HistoryQuery{OrgID: 1, Labels: map[string]string{"description": long}})
+		require.NoError(t, err)
+		require.Equal(t, 1, frame.Rows())
+		entry := lokiEntry{}
+		require.NoError(t, json.Unmarshal(frame.Fields[1].At(0).(json.RawMessage), &entry))
+		require.Equal(t, map[string]string{"description": long}, entry.InstanceLabels)
+	})
+
+	t.Run("query pages entries from the latest", func(t *testing.T) {
+		backend := createTestSQLBackend(t, now)
+		rule := createTestRule()
+		for i := 5; i > 0; i-- {
+			recordTransition(t, backend, rule, data.Labels{"a": "b"}, now.Add(-time.Duration(i)*time.Minute))
+		}
+
+		frame, err := backend.Query(context.Background(), models.HistoryQuery{OrgID: 1, Limit: 2, Page: 1})
+		require.NoError(t, err)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, now.Add(-2*time.Minute), frame.Fields[0].At(0).(time.Time))
+		require.Equal(t, now.Add(-1*time.Minute), frame.Fields[0].At(1).(time.Time))
+
+		frame, err = backend.Query(context.Background(), models.HistoryQuery{OrgID: 1, Limit: 2, Page: 3})
+		require.NoError(t, err)
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, now.Add(-5*time.Minute), frame.Fields[0].At(0).(time.Time))
+	})
+
+	t.Run("expired entries are deleted with their labels", func(t *testing.T) {
+		backend := createTestSQLBackend(t, now)
+		rule := createTestRule()
+		recordTransition(t, backend, rule, data.Labels{"a": "b"}, now.Add(-48*time.Hour))
+		recordTransition(t, backend, rule, data.Labels{"a": "c"}, now.Add(-time.Hour))
+
+		deleted, err := backend.DeleteExpired(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, int64(1), deleted)
+
+		frame, err := backend.Query(context.Background(), models.HistoryQuery{OrgID: 1, From: now.Add(-72 * time.Hour), To: now})
+		require.NoError(t, err)
+		require.Equal(t, 1, frame.Rows())
+
+		err = backend.db.WithDbSession(context.Background(), func(sess *db.Session) error {
+			count, err := sess.Count(&sqlHistoryLabel{})
+			require.Equal(t, int64(1), count)
+			return err
+		})
+		require.NoError(t, err)
+	})
+}
+
+func createTestSQLBackend(t *testing.T, now time.Time) *SQLBackend {
+	t.Helper()
+	met := metrics.NewHistorianMetrics(prometheus.NewRegistry())
+	backend := NewSQLBackend(db.InitTestDB(t), map[string]string{"extra": "label"}, 24*time.Hour, met)
+	clk := clock.NewMock()
+	clk.Set(now)
+	backend.clock = clk
+	return backend
+}
+
+func recordTransition(t *testing.T, backend *SQLBackend, rule history_model.RuleMeta, labels data.Labels, at time.Time) {
+	t.Helper()
+	states := singleFromNormal(&state.State{
+		State:              eval.Alerting,
+		Labels:             labels,
+		LastEvaluationTime: at,
+	})
+	require.NoError(t, <-backend.Record(context.Background(), rule, states))
+}
+
+func TestIndexedLabel(t *testing.T) {
+	require.Equal(t, "value", indexedLabel("value"))
+	exact := strings.Repeat("ä", sqlLabelMaxLength)
+	require.Equal(t, exact, indexedLabel(exact))
+
+	long := indexedLabel(exact + "a")
+	require.Equal(t, sqlLabelMaxLength, utf8.RuneCountInString(long))
+	require.True(t, strings.HasPrefix(long, strings.Repeat("ä", 100)))
+	require.NotEqual(t, long, indexedLabel(exact+"b"))
+	require.Equal(t, long, indexedLabel(exact+"a"))
+}
@@ -0,0 +1,97 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	limitedByRule  = "rule"
+	limitedByGroup = "group"
+	limitedByOrg   = "org"
+)
+
+// limitInstances drops the results that exceed the limit of alert instances of the rule. The results are sorted by
+// their labels before the excess results are dropped, so that the same alert instances are kept in every evaluation.
+// Alert instances that are dropped stop being updated and are resolved as stale. They keep counting towards the limits
+// of the rule group and the organization until then. If no alert instance can be kept, because the other rules already
+// use up the limit of the rule group or the organization, the results are replaced with a single error result, so
+// that the rule reports the exceeded limit instead of silently resolving all of its alert instances.
+// Returns true if results were dropped.
+func (st *Manager) limitInstances(ctx context.Context, logger log.Logger, alertRule *ngModels.AlertRule, results eval.Results) (eval.Results, bool) {
+	limit, limitedBy := st.instanceLimit(ctx, logger, alertRule)
+	if limit < 0 || int64(len(results)) <= limit {
+		return results, false
+	}
+
+	dropped := int64(len(results)) - limit
+	logger.Warn("Rule exceeds the limit of alert instances, excess alert instances are dropped", "limit", limit, "limitedBy", limitedBy, "results", len(results), "dropped", dropped)
+	st.metrics.InstancesDropped.WithLabelValues(limitedBy).Add(float64(dropped))
+	if limit == 0 {
+		return eval.Results{{
+			Instance:           data.Labels{},
+			State:              eval.Error,
+			Error:              fmt.Errorf("the rule exceeds the %s limit of alert instances, all %d alert instances are dropped", limitedBy, len(results)),
+			EvaluatedAt:        results[0].EvaluatedAt,
+			EvaluationDuration: results[0].EvaluationDuration,
+		}}, true
+	}
+
+	sorted := make(eval.Results, len(results))
+	copy(sorted, results)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Instance.String() < sorted[j].Instance.String()
+	})
+
+	return sorted[:limit], true
+}
+
+// instanceLimit returns the maximum number of alert instances of the rule and the limit that determines it. The
+// limits of the rule group and the organization are shared with the other rules, therefore the alert instances of the
+// other rules are subtracted from them. When alert rules are sharded between replicas, the alert instances of the rules
+// that other replicas evaluate are counted from the state they saved, see Manager.LoadPeerStates.
+// Returns -1 if the number of alert instances is not limited.
+func (st *Manager) instanceLimit(ctx context.Context, logger log.Logger, alertRule *ngModels.AlertRule) (int64, string) {
+	limit, limitedBy := int64(-1), ""
+	apply := func(l int64, by string) {
+		if l < 0 {
+			l = 0
+		}
+		if limit < 0 || l < limit {
+			limit, limitedBy = l, by
+		}
+	}
+
+	if st.maxInstancesPerRule > 0 {
+		apply(st.maxInstancesPerRule, limitedByRule)
+	}
+
+	orgLimit := int64(-1)
+	if st.orgInstanceLimit != nil {
+		l, err := st.orgInstanceLimit(ctx, alertRule.OrgID)
+		if err != nil {
+			logger.Warn("Failed to get the limit of alert instances of the organization, the limit is ignored", "error", err)
+		} else {
+			orgLimit = l
+		}
+	}
+	if st.maxInstancesPerGroup <= 0 && orgLimit < 0 {
+		return limit, limitedBy
+	}
+
+	groupCount, orgCount := st.cache.countInstances(alertRule)
+	if st.maxInstancesPerGroup > 0 {
+		apply(st.maxInstancesPerGroup-groupCount, limitedByGroup)
+	}
+	if orgLimit >= 0 {
+		apply(orgLimit-orgCount-st.countPeerInstances(alertRule.OrgID), limitedByOrg)
+	}
+	return limit, limitedBy
+}
@@ -0,0 +1,144 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestInstanceLimits(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	newManager := func(cfg ManagerCfg) *Manager {
+		clk := clock.NewMock()
+		cfg.Clock = clk
+		cfg.Metrics = metrics.NewStateMetrics(prometheus.NewPedanticRegistry())
+		cfg.Images = &NoopImageService{}
+		return NewManager(cfg)
+	}
+	results := func(count int) eval.Results {
+		result := make(eval.Results, 0, count)
+		// add results in reverse order to make sure that they are sorted before they are dropped
+		for i := count - 1; i >= 0; i-- {
+			result = append(result, eval.ResultGen(eval.WithState(eval.Normal), eval.WithEvaluatedAt(now), eval.WithLabels(data.Labels{"instance": fmt.Sprint(i)}))())
+		}
+		return result
+	}
+	instances := func(transitions []StateTransition) []string {
+		result := make([]string, 0, len(transitions))
+		for _, s := range transitions {
+			result = append(result, s.Labels["instance"])
+		}
+		return result
+	}
+
+	t.Run("keeps all instances if there are no limits", func(t *testing.T) {
+		st := newManager(ManagerCfg{})
+		rule := ngmodels.AlertRuleGen()()
+		transitions := st.ProcessEvalResults(ctx, now, rule, results(5), nil)
+		require.Len(t, transitions, 5)
+		for _, s := range transitions {
+			require.NotEqual(t, ngmodels.StateReasonInstanceLimitExceeded, s.StateReason)
+		}
+	})
+
+	t.Run("drops instances of rule that exceed the limit of the rule", func(t *testing.T) {
+		st := newManager(ManagerCfg{MaxInstancesPerRule: 3})
+		rule := ngmodels.AlertRuleGen()()
+		transitions := st.ProcessEvalResults(ctx, now, rule, results(5), nil)
+		require.ElementsMatch(t, []string{"0", "1", "2"}, instances(transitions))
+		for _, s := range transitions {
+			require.Equal(t, ngmodels.StateReasonInstanceLimitExceeded, s.StateReason)
+		}
+		require.Equal(t, float64(2), testutil.ToFloat64(st.metrics.InstancesDropped.WithLabelValues(limitedByRule)))
+	})
+
+	t.Run("counts instances of rules evaluated by other instances towards the limit of the organization", func(t *testing.T) {
+		st := newManager(ManagerCfg{OrgInstanceLimit: func(context.Context, int64) (int64, error) { return 3, nil }})
+		peer := ngmodels.AlertRuleGen(ngmodels.WithOrgID(1))()
+		st.instanceStore = &listingInstanceStore{instances: []*ngmodels.AlertInstance{
+			firingInstance(peer, ngmodels.InstanceLabels{"instance": "peer-1"}),
+			firingInstance(peer, ngmodels.InstanceLabels{"instance": "peer-2"}),
+		}}
+		require.NoError(t, st.LoadPeerStates(ctx, []*ngmodels.AlertRule{peer}))
+
+		rule := ngmodels.AlertRuleGen(ngmodels.WithOrgID(1))()
+		transitions := st.ProcessEvalResults(ctx, now, rule, results(3), nil)
+		require.Equal(t, []string{"0"}, instances(transitions))
+		require.Equal(t, float64(2), testutil.ToFloat64(st.metrics.InstancesDropped.WithLabelValues(limitedByOrg)))
+	})
+
+	t.Run("shares the limit of the group between the rules of the group", func(t *testing.T) {
+		st := newManager(ManagerCfg{MaxInstancesPerGroup: 3})
+		rule1 := ngmodels.AlertRuleGen(ngmodels.WithOrgID(1))()
+		rule2 := ngmodels.AlertRuleGen(ngmodels.WithOrgID(1))()
+		rule2.NamespaceUID = rule1.NamespaceUID
+		rule2.RuleGroup = rule1.RuleGroup
+		otherGroup := ngmodels.AlertRuleGen(ngmodels.WithOrgID(1))()
+
+		require.Len(t, st.ProcessEvalResults(ctx, now, rule1, results(2), nil), 2)
+		require.Len(t, st.ProcessEvalResults(ctx, now, otherGroup, results(3), nil), 3)
+		transitions := st.ProcessEvalResults(ctx, now, rule2, results(3), nil)
+		require.Equal(t, []string{"0"}, instances(transitions))
+		require.Equal(t, float64(2), testutil.ToFloat64(st.metrics.InstancesDropped.WithLabelValues(limitedByGroup)))
+	})
+
+	t.Run("shares the limit of the organization between the rules of the organization", func(t *testing.T) {
+		st := newManager(ManagerCfg{
+			OrgInstanceLimit: func(_ context.Context, orgID int64) (int64, error) {
+				if orgID == 1 {
+					return 4, nil
+				}
+				return -1, nil
+			},
+		})
+		rule1 := ngmodels.AlertRuleGen(ngmodels.WithOrgID(1))()
+		rule2 := ngmodels.AlertRuleGen(ngmodels.WithOrgID(1))()
+		otherOrg := ngmodels.AlertRuleGen(ngmodels.WithOrgID(2))()
+
+		require.Len(t, st.ProcessEvalResults(ctx, now, otherOrg, results(5), nil), 5)
+		require.Len(t, st.ProcessEvalResults(ctx, now, rule1, results(3), nil), 3)
+		transitions := st.ProcessEvalResults(ctx, now, rule2, results(3), nil)
+		require.Equal(t, []string{"0"}, instances(transitions))
+		require.Equal(t, int64(4), st.CountInstances(1))
+		require.Equal(t, float64(2), testutil.ToFloat64(st.metrics.InstancesDropped.WithLabelValues(limitedByOrg)))
+	})
+
+	t.Run("reports an error if no instance of the rule is within the limit", func(t *testing.T) {
+		st := newManager(ManagerCfg{MaxInstancesPerGroup: 3})
+		rule1 := ngmodels.AlertRuleGen(ngmodels.WithOrgID(1))()
+		rule2 := ngmodels.AlertRuleGen(ngmodels.WithOrgID(1))()
+		rule2.NamespaceUID = rule1.NamespaceUID
+		rule2.RuleGroup = rule1.RuleGroup
+		rule2.ExecErrState = ngmodels.ErrorErrState
+
+		require.Len(t, st.ProcessEvalResults(ctx, now, rule1, results(3), nil), 3)
+		transitions := st.ProcessEvalResults(ctx, now, rule2, results(2), nil)
+		require.Len(t, transitions, 1)
+		require.Equal(t, eval.Error, transitions[0].State.State)
+		require.ErrorContains(t, transitions[0].Error, "exceeds the group limit")
+		require.Equal(t, ngmodels.StateReasonInstanceLimitExceeded, transitions[0].StateReason)
+		require.Equal(t, float64(2), testutil.ToFloat64(st.metrics.InstancesDropped.WithLabelValues(limitedByGroup)))
+	})
+
+	t.Run("ignores the limit of the organization if it cannot be read", func(t *testing.T) {
+		st := newManager(ManagerCfg{
+			OrgInstanceLimit: func(context.Context, int64) (int64, error) {
+				return 0, fmt.Errorf("failed")
+			},
+		})
+		rule := ngmodels.AlertRuleGen()()
+		require.Len(t, st.ProcessEvalResults(ctx, now, rule, results(3), nil), 3)
+	})
+}
@@ -2,6 +2,7 @@ package state
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/grafana/dskit/concurrency"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
@@ -35,6 +37,9 @@ type Manager struct {
 	cache       *cache
 	ResendDelay time.Duration
 
+	// peerCache holds the state of the rules that other Grafana instances evaluate, see LoadPeerStates.
+	peerCache *cache
+
 	instanceStore InstanceStore
 	images        ImageCapturer
 	historian     Historian
@@ -42,6 +47,10 @@ type Manager struct {
 
 	doNotSaveNormalState    bool
 	maxStateSaveConcurrency int
+
+	maxInstancesPerRule  int64
+	maxInstancesPerGroup int64
+	orgInstanceLimit     func(ctx context.Context, orgID int64) (int64, error)
 }
 
 type ManagerCfg struct {
@@ -55,11 +64,17 @@ type ManagerCfg struct {
 	DoNotSaveNormalState bool
 	// MaxStateSaveConcurrency controls the number of goroutines (per rule) that can save alert state in parallel.
 	MaxStateSaveConcurrency int
+	// MaxInstancesPerRule and MaxInstancesPerGroup limit the number of alert instances of a rule and of a rule group. Zero means no limit.
+	MaxInstancesPerRule  int64
+	MaxInstancesPerGroup int64
+	// OrgInstanceLimit returns the maximum number of alert instances of the organization. A negative limit means no limit.
+	OrgInstanceLimit func(ctx context.Context, orgID int64) (int64, error)
 }
 
 func NewManager(cfg ManagerCfg) *Manager {
 	return &Manager{
 		cache:                   newCache(),
+		peerCache:               newCache(),
 		ResendDelay:             ResendDelay, // TODO: make this configurable
 		log:                     log.New("ngalert.state.manager"),
 		metrics:                 cfg.Metrics,
@@ -70,6 +85,9 @@ func NewManager(cfg ManagerCfg) *Manager {
 		externalURL:             cfg.ExternalURL,
 		doNotSaveNormalState:    cfg.DoNotSaveNormalState,
 		maxStateSaveConcurrency: cfg.MaxStateSaveConcurrency,
+		maxInstancesPerRule:     cfg.MaxInstancesPerRule,
+		maxInstancesPerGroup:    cfg.MaxInstancesPerGroup,
+		orgInstanceLimit:        cfg.OrgInstanceLimit,
 	}
 }
 
@@ -139,28 +157,15 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 
 			rulesStates, ok := orgStates[entry.RuleUID]
 			if !ok {
-				rulesStates = &ruleStates{states: make(map[string]*State)}
+				rulesStates = &ruleStates{group: ruleForEntry.GetGroupKey(), states: make(map[string]*State)}
 				orgStates[entry.RuleUID] = rulesStates
 			}
 
-			lbs := map[string]string(entry.Labels)
 			cacheID, err := entry.Labels.StringKey()
 			if err != nil {
 				st.log.Error("Error getting cacheId for entry", "error", err)
 			}
-			rulesStates.states[cacheID] = &State{
-				AlertRuleUID:         entry.RuleUID,
-				OrgID:                entry.RuleOrgID,
-				CacheID:              cacheID,
-				Labels:               lbs,
-				State:                translateInstanceState(entry.CurrentState),
-				StateReason:          entry.CurrentReason,
-				LastEvaluationString: "",
-				StartsAt:             entry.CurrentStateSince,
-				EndsAt:               entry.CurrentStateEnd,
-				LastEvaluationTime:   entry.LastEvalTime,
-				Annotations:          ruleForEntry.Annotations,
-			}
+			rulesStates.states[cacheID] = stateFromInstance(entry, cacheID, ruleForEntry)
 			statesCount++
 		}
 	}
@@ -168,6 +173,104 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 	st.log.Info("State cache has been initialized", "states", statesCount, "duration", time.Since(startTime))
 }
 
+// WarmRule replaces the cached state of the rule with the state stored in the instance store.
+// It is used when the evaluation of the rule is handed over from another Grafana instance, which saved the state.
+func (st *Manager) WarmRule(ctx context.Context, rule *ngModels.AlertRule) error {
+	if st.instanceStore == nil {
+		return nil
+	}
+	alertInstances, err := st.instanceStore.ListAlertInstances(ctx, &ngModels.ListAlertInstancesQuery{
+		RuleOrgID: rule.OrgID,
+		RuleUID:   rule.UID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch state of the rule: %w", err)
+	}
+	rulesStates := &ruleStates{group: rule.GetGroupKey(), states: make(map[string]*State, len(alertInstances))}
+	for _, entry := range alertInstances {
+		cacheID, err := entry.Labels.StringKey()
+		if err != nil {
+			return fmt.Errorf("failed to get cache ID of the alert instance: %w", err)
+		}
+		rulesStates.states[cacheID] = stateFromInstance(entry, cacheID, rule)
+	}
+	st.cache.setRuleStates(rule.OrgID, rule.UID, rulesStates)
+	st.log.FromContext(ctx).Debug("State of the rule has been loaded", "states", len(rulesStates.states))
+	return nil
+}
+
+// ForgetRule removes the state of the rule from the cache but, unlike DeleteStateByRuleUID, keeps it in the instance store.
+// It is used when the evaluation of the rule is handed over to another Grafana instance.
+func (st *Manager) ForgetRule(ctx context.Context, ruleKey ngModels.AlertRuleKey) {
+	states := st.cache.removeByRuleUID(ruleKey.OrgID, ruleKey.UID)
+	st.log.FromContext(ctx).Debug("State of the rule has been removed from the cache", "states", len(states))
+}
+
+// LoadPeerStates replaces the state of the rules that other Grafana instances evaluate with the state that these
+// instances saved in the instance store. When the evaluation of rules is sharded, the cache of every instance holds
+// only the state of the rules it evaluates. The state of the other rules is read from the instance store, so that the
+// state of all rules can be read from any instance, and the alert instances of all rules of the organization count
+// towards its limit.
+func (st *Manager) LoadPeerStates(ctx context.Context, rules []*ngModels.AlertRule) error {
+	if st.instanceStore == nil {
+		return nil
+	}
+	ruleByUID := make(map[int64]map[string]*ngModels.AlertRule)
+	for _, rule := range rules {
+		if ruleByUID[rule.OrgID] == nil {
+			ruleByUID[rule.OrgID] = make(map[string]*ngModels.AlertRule)
+		}
+		ruleByUID[rule.OrgID][rule.UID] = rule
+	}
+
+	statesCount := 0
+	states := make(map[int64]map[string]*ruleStates, len(ruleByUID))
+	for orgID, orgRules := range ruleByUID {
+		alertInstances, err := st.instanceStore.ListAlertInstances(ctx, &ngModels.ListAlertInstancesQuery{RuleOrgID: orgID})
+		if err != nil {
+			return fmt.Errorf("failed to fetch state of the rules of organization %d: %w", orgID, err)
+		}
+		orgStates := make(map[string]*ruleStates, len(orgRules))
+		states[orgID] = orgStates
+		for _, entry := range alertInstances {
+			rule, ok := orgRules[entry.RuleUID]
+			if !ok {
+				continue
+			}
+			rulesStates, ok := orgStates[entry.RuleUID]
+			if !ok {
+				rulesStates = &ruleStates{group: rule.GetGroupKey(), states: make(map[string]*State)}
+				orgStates[entry.RuleUID] = rulesStates
+			}
+			cacheID, err := entry.Labels.StringKey()
+			if err != nil {
+				return fmt.Errorf("failed to get cache ID of the alert instance: %w", err)
+			}
+			rulesStates.states[cacheID] = stateFromInstance(entry, cacheID, rule)
+			statesCount++
+		}
+	}
+	st.peerCache.setAllStates(states)
+	st.log.FromContext(ctx).Debug("State of the rules evaluated by other instances has been loaded", "rules", len(rules), "states", statesCount)
+	return nil
+}
+
+func stateFromInstance(entry *ngModels.AlertInstance, cacheID string, rule *ngModels.AlertRule) *State {
+	return &State{
+		AlertRuleUID:         entry.RuleUID,
+		OrgID:                entry.RuleOrgID,
+		CacheID:              cacheID,
+		Labels:               map[string]string(entry.Labels),
+		State:                translateInstanceState(entry.CurrentState),
+		StateReason:          entry.CurrentReason,
+		LastEvaluationString: "",
+		StartsAt:             entry.CurrentStateSince,
+		EndsAt:               entry.CurrentStateEnd,
+		LastEvaluationTime:   entry.LastEvalTime,
+		Annotations:          rule.Annotations,
+	}
+}
+
 func (st *Manager) Get(orgID int64, alertRuleUID, stateId string) *State {
 	return st.cache.get(orgID, alertRuleUID, stateId)
 }
@@ -244,10 +347,14 @@ func (st *Manager) ResetStateByRuleUID(ctx context.Context, rule *ngModels.Alert
 func (st *Manager) ProcessEvalResults(ctx context.Context, evaluatedAt time.Time, alertRule *ngModels.AlertRule, results eval.Results, extraLabels data.Labels) []StateTransition {
 	logger := st.log.FromContext(ctx)
 	logger.Debug("State manager processing evaluation results", "resultCount", len(results))
+	results, limited := st.limitInstances(ctx, logger, alertRule, results)
 	states := make([]StateTransition, 0, len(results))
 
 	for _, result := range results {
 		s := st.setNextState(ctx, alertRule, result, extraLabels, logger)
+		if limited {
+			s.State.StateReason = ngModels.StateReasonInstanceLimitExceeded
+		}
 		states = append(states, s)
 	}
 	staleStates := st.deleteStaleStatesFromCache(ctx, logger, evaluatedAt, alertRule)
@@ -356,14 +463,74 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 	return nextState
 }
 
+// CountInstances returns the number of alert instances of the organization.
+func (st *Manager) CountInstances(orgID int64) int64 {
+	return st.cache.count(orgID, nil) + st.countPeerInstances(orgID)
+}
+
+// countPeerInstances returns the number of alert instances of the rules of the organization that other Grafana
+// instances evaluate. Rules that have just been handed over to this instance are in both caches, and are counted
+// only in the cache of this instance.
+func (st *Manager) countPeerInstances(orgID int64) int64 {
+	return st.peerCache.count(orgID, st.cache.ruleUIDs(orgID))
+}
+
 func (st *Manager) GetAll(orgID int64) []*State {
 	allStates := st.cache.getAll(orgID, st.doNotSaveNormalState)
+	for _, s := range st.peerCache.getAll(orgID, st.doNotSaveNormalState) {
+		if !st.cache.hasRule(orgID, s.AlertRuleUID) {
+			allStates = append(allStates, s)
+		}
+	}
 	return allStates
 }
 func (st *Manager) GetStatesForRuleUID(orgID int64, alertRuleUID string) []*State {
+	if !st.cache.hasRule(orgID, alertRuleUID) {
+		return st.peerCache.getStatesForRuleUID(orgID, alertRuleUID, st.doNotSaveNormalState)
+	}
 	return st.cache.getStatesForRuleUID(orgID, alertRuleUID, st.doNotSaveNormalState)
 }
 
+// GetAlertInstanceStates returns the current states of alert instances of the rules with the given UIDs, or of all rules of the
+// organization if ruleUIDs is empty. It implements expr.AlertStateReader, which lets alert rules query the state of other rules.
+// The rule that is being evaluated in the context is skipped, so that a rule cannot depend on itself.
+func (st *Manager) GetAlertInstanceStates(ctx context.Context, orgID int64, ruleUIDs []string) []expr.AlertInstanceState {
+	var states []*State
+	if len(ruleUIDs) == 0 {
+		states = st.GetAll(orgID)
+	} else {
+		for _, uid := range ruleUIDs {
+			states = append(states, st.GetStatesForRuleUID(orgID, uid)...)
+		}
+	}
+
+	self, hasSelf := ngModels.RuleKeyFromContext(ctx)
+	result := make([]expr.AlertInstanceState, 0, len(states))
+	for _, s := range states {
+		if hasSelf && self.OrgID == s.OrgID && self.UID == s.AlertRuleUID {
+			continue
+		}
+		labels := make(data.Labels, len(s.Labels))
+		for k, v := range s.Labels {
+			if _, ok := ngModels.InternalLabelNameSet[k]; ok {
+				continue
+			}
+			labels[k] = v
+		}
+		values := make(map[string]float64, len(s.Values))
+		for k, v := range s.Values {
+			values[k] = v
+		}
+		result = append(result, expr.AlertInstanceState{
+			RuleUID: s.AlertRuleUID,
+			Labels:  labels,
+			State:   s.State.String(),
+			Values:  values,
+		})
+	}
+	return result
+}
+
 func (st *Manager) Put(states []*State) {
 	for _, s := range states {
 		st.cache.set(s)
@@ -147,3 +147,77 @@ func TestManager_saveAlertStates(t *testing.T) {
 		}
 	})
 }
+
+// listingInstanceStore returns the alert instances it holds.
+type listingInstanceStore struct {
+	FakeInstanceStore
+	instances []*ngmodels.AlertInstance
+}
+
+func (f *listingInstanceStore) ListAlertInstances(_ context.Context, q *ngmodels.ListAlertInstancesQuery) ([]*ngmodels.AlertInstance, error) {
+	var result []*ngmodels.AlertInstance
+	for _, instance := range f.instances {
+		if instance.RuleOrgID == q.RuleOrgID && (q.RuleUID == "" || instance.RuleUID == q.RuleUID) {
+			result = append(result, instance)
+		}
+	}
+	return result, nil
+}
+
+func firingInstance(rule *ngmodels.AlertRule, labels ngmodels.InstanceLabels) *ngmodels.AlertInstance {
+	return &ngmodels.AlertInstance{
+		AlertInstanceKey: ngmodels.AlertInstanceKey{RuleOrgID: rule.OrgID, RuleUID: rule.UID},
+		Labels:           labels,
+		CurrentState:     ngmodels.InstanceStateFiring,
+	}
+}
+
+func TestManager_LoadPeerStates(t *testing.T) {
+	ctx := context.Background()
+	local := ngmodels.AlertRuleGen(ngmodels.WithOrgID(1))()
+	peer := ngmodels.AlertRuleGen(ngmodels.WithOrgID(1))()
+	deleted := ngmodels.AlertRuleGen(ngmodels.WithOrgID(1))()
+	store := &listingInstanceStore{instances: []*ngmodels.AlertInstance{
+		firingInstance(local, ngmodels.InstanceLabels{"instance": "local"}),
+		firingInstance(peer, ngmodels.InstanceLabels{"instance": "peer-1"}),
+		firingInstance(peer, ngmodels.InstanceLabels{"instance": "peer-2"}),
+		firingInstance(deleted, ngmodels.InstanceLabels{"instance": "deleted"}),
+	}}
+	st := NewManager(ManagerCfg{InstanceStore: store})
+	require.NoError(t, st.WarmRule(ctx, local))
+
+	t.Run("should not read the state of rules evaluated by other instances before it is loaded", func(t *testing.T) {
+		require.Empty(t, st.GetStatesForRuleUID(1, peer.UID))
+		require.Len(t, st.GetAll(1), 1)
+		require.Equal(t, int64(1), st.CountInstances(1))
+	})
+
+	require.NoError(t, st.LoadPeerStates(ctx, []*ngmodels.AlertRule{peer}))
+
+	t.Run("should read the state of rules evaluated by other instances", func(t *testing.T) {
+		states := st.GetStatesForRuleUID(1, peer.UID)
+		require.Len(t, states, 2)
+		for _, s := range states {
+			require.Equal(t, eval.Alerting, s.State)
+			require.Equal(t, peer.Annotations, s.Annotations)
+		}
+		require.Len(t, st.GetAll(1), 3)
+		require.Equal(t, int64(3), st.CountInstances(1))
+		require.Empty(t, st.GetStatesForRuleUID(1, deleted.UID), "only the state of the given rules should be loaded")
+	})
+
+	t.Run("should prefer the state of rules evaluated by this instance", func(t *testing.T) {
+		require.NoError(t, st.WarmRule(ctx, peer))
+		st.Put([]*State{{OrgID: 1, AlertRuleUID: peer.UID, CacheID: "new", State: eval.Normal, StateReason: "reason"}})
+		require.Len(t, st.GetStatesForRuleUID(1, peer.UID), 3)
+		require.Len(t, st.GetAll(1), 4)
+		require.Equal(t, int64(4), st.CountInstances(1))
+	})
+
+	t.Run("should replace the state of rules evaluated by other instances", func(t *testing.T) {
+		st.ForgetRule(ctx, peer.GetKey())
+		require.NoError(t, st.LoadPeerStates(ctx, nil))
+		require.Empty(t, st.GetStatesForRuleUID(1, peer.UID))
+		require.Len(t, st.GetAll(1), 1)
+	})
+}
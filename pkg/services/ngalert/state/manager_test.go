@@ -12,6 +12,7 @@ import (
 	"github.com/benbjohnson/clock"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	alertingModels "github.com/grafana/alerting/models"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
@@ -2949,3 +2950,59 @@ func TestResetStateByRuleUID(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAlertInstanceStates(t *testing.T) {
+	st := state.NewManager(state.ManagerCfg{
+		Metrics:                 testMetrics.GetStateMetrics(),
+		Images:                  &state.NoopImageService{},
+		Clock:                   clock.NewMock(),
+		Historian:               &state.FakeHistorian{},
+		MaxStateSaveConcurrency: 1,
+	})
+	st.Put([]*state.State{
+		{
+			OrgID:        1,
+			AlertRuleUID: "rule-1",
+			CacheID:      "1",
+			State:        eval.Alerting,
+			Labels:       data.Labels{"cluster": "a", alertingModels.RuleUIDLabel: "rule-1"},
+			Values:       map[string]float64{"A": 1.5},
+		},
+		{
+			OrgID:        1,
+			AlertRuleUID: "rule-2",
+			CacheID:      "2",
+			State:        eval.Normal,
+			Labels:       data.Labels{"cluster": "b"},
+		},
+		{
+			OrgID:        2,
+			AlertRuleUID: "rule-1",
+			CacheID:      "3",
+			State:        eval.Alerting,
+			Labels:       data.Labels{"cluster": "c"},
+		},
+	})
+
+	t.Run("returns states of selected rules without internal labels", func(t *testing.T) {
+		states := st.GetAlertInstanceStates(context.Background(), 1, []string{"rule-1"})
+		require.Equal(t, []expr.AlertInstanceState{{
+			RuleUID: "rule-1",
+			Labels:  data.Labels{"cluster": "a"},
+			State:   "Alerting",
+			Values:  map[string]float64{"A": 1.5},
+		}}, states)
+	})
+
+	t.Run("returns states of all rules of the organization", func(t *testing.T) {
+		states := st.GetAlertInstanceStates(context.Background(), 1, nil)
+		require.Len(t, states, 2)
+	})
+
+	t.Run("skips the rule that is evaluated", func(t *testing.T) {
+		ctx := models.WithRuleKey(context.Background(), models.AlertRuleKey{OrgID: 1, UID: "rule-1"})
+		states := st.GetAlertInstanceStates(ctx, 1, nil)
+		require.Len(t, states, 1)
+		require.Equal(t, "rule-2", states[0].RuleUID)
+	})
+}
@@ -98,6 +98,45 @@ func (st DBstore) GetAlertRuleByUID(ctx context.Context, query *ngmodels.GetAler
 	return result, err
 }
 
+// GetAlertRuleVersions returns the versions of the alert rule sorted from the latest to the oldest.
+func (st DBstore) GetAlertRuleVersions(ctx context.Context, query *ngmodels.GetAlertRuleVersionsQuery) (result []*ngmodels.AlertRuleVersion, err error) {
+	err = st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Table("alert_rule_version").Where("rule_org_id = ? AND rule_uid = ?", query.OrgID, query.RuleUID).Desc("version")
+		if query.Limit > 0 {
+			offset := 0
+			if query.Page > 1 {
+				offset = (query.Page - 1) * query.Limit
+			}
+			q = q.Limit(query.Limit, offset)
+		}
+		versions := make([]*ngmodels.AlertRuleVersion, 0)
+		if err := q.Find(&versions); err != nil {
+			return err
+		}
+		result = versions
+		return nil
+	})
+	return result, err
+}
+
+// GetAlertRuleVersion returns the specific version of the alert rule.
+// It returns ngmodels.ErrAlertRuleVersionNotFound if the rule does not have such a version.
+func (st DBstore) GetAlertRuleVersion(ctx context.Context, query *ngmodels.GetAlertRuleVersionQuery) (result *ngmodels.AlertRuleVersion, err error) {
+	err = st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		version := ngmodels.AlertRuleVersion{}
+		has, err := sess.Table("alert_rule_version").Where("rule_org_id = ? AND rule_uid = ? AND version = ?", query.OrgID, query.RuleUID, query.Version).Get(&version)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ngmodels.ErrAlertRuleVersionNotFound
+		}
+		result = &version
+		return nil
+	})
+	return result, err
+}
+
 // GetAlertRulesGroupByRuleUID is a handler for retrieving a group of alert rules from that database by UID and organisation ID of one of rules that belong to that group.
 func (st DBstore) GetAlertRulesGroupByRuleUID(ctx context.Context, query *ngmodels.GetAlertRulesGroupByRuleUIDQuery) (result []*ngmodels.AlertRule, err error) {
 	err = st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
@@ -139,22 +178,24 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 			}
 			newRules = append(newRules, r)
 			ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
-				RuleUID:          r.UID,
-				RuleOrgID:        r.OrgID,
-				RuleNamespaceUID: r.NamespaceUID,
-				RuleGroup:        r.RuleGroup,
-				ParentVersion:    0,
-				Version:          r.Version,
-				Created:          r.Updated,
-				Condition:        r.Condition,
-				Title:            r.Title,
-				Data:             r.Data,
-				IntervalSeconds:  r.IntervalSeconds,
-				NoDataState:      r.NoDataState,
-				ExecErrState:     r.ExecErrState,
-				For:              r.For,
-				Annotations:      r.Annotations,
-				Labels:           r.Labels,
+				RuleUID:                 r.UID,
+				RuleOrgID:               r.OrgID,
+				RuleNamespaceUID:        r.NamespaceUID,
+				RuleGroup:               r.RuleGroup,
+				ParentVersion:           0,
+				Version:                 r.Version,
+				Created:                 r.Updated,
+				Condition:               r.Condition,
+				Title:                   r.Title,
+				Data:                    r.Data,
+				IntervalSeconds:         r.IntervalSeconds,
+				NoDataState:             r.NoDataState,
+				ExecErrState:            r.ExecErrState,
+				For:                     r.For,
+				Annotations:             r.Annotations,
+				Labels:                  r.Labels,
+				Record:                  r.Record,
+				DisableEvaluationOffset: r.DisableEvaluationOffset,
 			})
 		}
 		if len(newRules) > 0 {
@@ -200,7 +241,10 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 				return err
 			}
 			// no way to update multiple rules at once
-			if updated, err := sess.ID(r.Existing.ID).AllCols().Update(r.New); err != nil || updated == 0 {
+			// the rule is passed by pointer because xorm converts fields, such as Record, only if they are addressable.
+			// It is a copy because xorm modifies the version of the bean.
+			updated := r.New
+			if affected, err := sess.ID(r.Existing.ID).AllCols().Update(&updated); err != nil || affected == 0 {
 				if err != nil {
 					if st.SQLStore.GetDialect().IsUniqueConstraintViolation(err) {
 						return ngmodels.ErrAlertRuleUniqueConstraintViolation
@@ -211,23 +255,26 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 			}
 			parentVersion = r.Existing.Version
 			ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
-				RuleOrgID:        r.New.OrgID,
-				RuleUID:          r.New.UID,
-				RuleNamespaceUID: r.New.NamespaceUID,
-				RuleGroup:        r.New.RuleGroup,
-				RuleGroupIndex:   r.New.RuleGroupIndex,
-				ParentVersion:    parentVersion,
-				Version:          r.New.Version + 1,
-				Created:          r.New.Updated,
-				Condition:        r.New.Condition,
-				Title:            r.New.Title,
-				Data:             r.New.Data,
-				IntervalSeconds:  r.New.IntervalSeconds,
-				NoDataState:      r.New.NoDataState,
-				ExecErrState:     r.New.ExecErrState,
-				For:              r.New.For,
-				Annotations:      r.New.Annotations,
-				Labels:           r.New.Labels,
+				RuleOrgID:               r.New.OrgID,
+				RuleUID:                 r.New.UID,
+				RuleNamespaceUID:        r.New.NamespaceUID,
+				RuleGroup:               r.New.RuleGroup,
+				RuleGroupIndex:          r.New.RuleGroupIndex,
+				ParentVersion:           parentVersion,
+				RestoredFrom:            r.RestoredFrom,
+				Version:                 r.New.Version + 1,
+				Created:                 r.New.Updated,
+				Condition:               r.New.Condition,
+				Title:                   r.New.Title,
+				Data:                    r.New.Data,
+				IntervalSeconds:         r.New.IntervalSeconds,
+				NoDataState:             r.New.NoDataState,
+				ExecErrState:            r.New.ExecErrState,
+				For:                     r.New.For,
+				Annotations:             r.New.Annotations,
+				Labels:                  r.New.Labels,
+				Record:                  r.New.Record,
+				DisableEvaluationOffset: r.New.DisableEvaluationOffset,
 			})
 		}
 		if len(ruleVersions) > 0 {
@@ -353,6 +400,10 @@ func (st DBstore) ListAlertRules(ctx context.Context, query *ngmodels.ListAlertR
 			q = q.Where("rule_group = ?", query.RuleGroup)
 		}
 
+		if len(query.RuleUIDs) > 0 {
+			q = q.In("uid", query.RuleUIDs)
+		}
+
 		q = q.Asc("namespace_uid", "rule_group", "rule_group_idx", "id")
 
 		alertRules := make([]*ngmodels.AlertRule, 0)
@@ -674,5 +725,14 @@ func (st DBstore) validateAlertRule(alertRule ngmodels.AlertRule) error {
 	if alertRule.For < 0 {
 		return fmt.Errorf("%w: field `for` cannot be negative", ngmodels.ErrAlertRuleFailedValidation)
 	}
+
+	if alertRule.Type() == ngmodels.RuleTypeRecording {
+		if err := alertRule.Record.Validate(); err != nil {
+			return err
+		}
+		if alertRule.Condition != alertRule.Record.From {
+			return fmt.Errorf("%w: condition of the recording rule must be the query or expression to record", ngmodels.ErrAlertRuleFailedValidation)
+		}
+	}
 	return nil
 }
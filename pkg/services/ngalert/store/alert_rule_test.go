@@ -83,6 +83,76 @@ func TestIntegrationUpdateAlertRules(t *testing.T) {
 	})
 }
 
+func TestIntegrationAlertRuleVersions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	cfg := setting.NewCfg()
+	cfg.UnifiedAlerting = setting.UnifiedAlertingSettings{BaseInterval: time.Duration(rand.Int63n(100)+1) * time.Second}
+	sqlStore := db.InitTestDB(t)
+	store := &DBstore{
+		SQLStore:      sqlStore,
+		Cfg:           cfg.UnifiedAlerting,
+		FolderService: setupFolderService(t, sqlStore, cfg),
+		Logger:        &logtest.Fake{},
+	}
+	generator := models.AlertRuleGen(withIntervalMatching(store.Cfg.BaseInterval), models.WithUniqueID())
+
+	// insert the rule via the store so that the first version is recorded
+	rule := generator()
+	_, err := store.InsertAlertRules(context.Background(), []models.AlertRule{*rule})
+	require.NoError(t, err)
+	rule, err = store.GetAlertRuleByUID(context.Background(), &models.GetAlertRuleByUIDQuery{OrgID: rule.OrgID, UID: rule.UID})
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		newRule := models.CopyRule(rule)
+		newRule.Title = util.GenerateShortUID()
+		restoredFrom := int64(0)
+		if i == 2 {
+			restoredFrom = 1
+		}
+		err := store.UpdateAlertRules(context.Background(), []models.UpdateRule{{
+			Existing:     rule,
+			New:          *newRule,
+			RestoredFrom: restoredFrom,
+		}})
+		require.NoError(t, err)
+		rule, err = store.GetAlertRuleByUID(context.Background(), &models.GetAlertRuleByUIDQuery{OrgID: rule.OrgID, UID: rule.UID})
+		require.NoError(t, err)
+	}
+
+	t.Run("should return versions latest first", func(t *testing.T) {
+		versions, err := store.GetAlertRuleVersions(context.Background(), &models.GetAlertRuleVersionsQuery{OrgID: rule.OrgID, RuleUID: rule.UID})
+		require.NoError(t, err)
+		require.Len(t, versions, 4)
+		for i, v := range versions {
+			require.Equal(t, int64(4-i), v.Version)
+		}
+		require.Equal(t, rule.Title, versions[0].Title)
+		require.Equal(t, int64(3), versions[0].ParentVersion)
+		require.Equal(t, int64(1), versions[0].RestoredFrom)
+	})
+
+	t.Run("should page versions", func(t *testing.T) {
+		versions, err := store.GetAlertRuleVersions(context.Background(), &models.GetAlertRuleVersionsQuery{OrgID: rule.OrgID, RuleUID: rule.UID, Limit: 3, Page: 2})
+		require.NoError(t, err)
+		require.Len(t, versions, 1)
+		require.Equal(t, int64(1), versions[0].Version)
+	})
+
+	t.Run("should return specific version", func(t *testing.T) {
+		version, err := store.GetAlertRuleVersion(context.Background(), &models.GetAlertRuleVersionQuery{OrgID: rule.OrgID, RuleUID: rule.UID, Version: 2})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), version.Version)
+		require.Equal(t, int64(1), version.ParentVersion)
+	})
+
+	t.Run("should return ErrAlertRuleVersionNotFound if version does not exist", func(t *testing.T) {
+		_, err := store.GetAlertRuleVersion(context.Background(), &models.GetAlertRuleVersionQuery{OrgID: rule.OrgID, RuleUID: rule.UID, Version: 100})
+		require.ErrorIs(t, err, models.ErrAlertRuleVersionNotFound)
+	})
+}
+
 func TestIntegrationUpdateAlertRulesWithUniqueConstraintViolation(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
@@ -5,10 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"sort"
 	"sync"
 	"testing"
 	"time"
 
+	"golang.org/x/exp/slices"
+
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -24,6 +27,8 @@ type RuleStore struct {
 	Hook        func(cmd interface{}) error // use Hook if you need to intercept some query and return an error
 	RecordedOps []interface{}
 	Folders     map[int64][]*folder.Folder
+	// OrgID -> RuleUID -> Versions
+	Versions map[int64]map[string][]*models.AlertRuleVersion
 }
 
 type GenericRecordedQuery struct {
@@ -33,8 +38,9 @@ type GenericRecordedQuery struct {
 
 func NewRuleStore(t *testing.T) *RuleStore {
 	return &RuleStore{
-		t:     t,
-		Rules: map[int64][]*models.AlertRule{},
+		t:        t,
+		Rules:    map[int64][]*models.AlertRule{},
+		Versions: map[int64]map[string][]*models.AlertRuleVersion{},
 		Hook: func(interface{}) error {
 			return nil
 		},
@@ -140,6 +146,61 @@ func (f *RuleStore) GetAlertRuleByUID(_ context.Context, q *models.GetAlertRuleB
 	return nil, nil
 }
 
+// PutRuleVersion puts the versions of the rule in the Versions map.
+func (f *RuleStore) PutRuleVersion(versions ...*models.AlertRuleVersion) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, v := range versions {
+		if _, ok := f.Versions[v.RuleOrgID]; !ok {
+			f.Versions[v.RuleOrgID] = map[string][]*models.AlertRuleVersion{}
+		}
+		f.Versions[v.RuleOrgID][v.RuleUID] = append(f.Versions[v.RuleOrgID][v.RuleUID], v)
+	}
+}
+
+func (f *RuleStore) GetAlertRuleVersions(_ context.Context, q *models.GetAlertRuleVersionsQuery) ([]*models.AlertRuleVersion, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.RecordedOps = append(f.RecordedOps, *q)
+	if err := f.Hook(*q); err != nil {
+		return nil, err
+	}
+	versions := make([]*models.AlertRuleVersion, 0)
+	versions = append(versions, f.Versions[q.OrgID][q.RuleUID]...)
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version > versions[j].Version
+	})
+	if q.Limit > 0 {
+		offset := 0
+		if q.Page > 1 {
+			offset = (q.Page - 1) * q.Limit
+		}
+		if offset >= len(versions) {
+			return []*models.AlertRuleVersion{}, nil
+		}
+		versions = versions[offset:]
+		if len(versions) > q.Limit {
+			versions = versions[:q.Limit]
+		}
+	}
+	return versions, nil
+}
+
+func (f *RuleStore) GetAlertRuleVersion(_ context.Context, q *models.GetAlertRuleVersionQuery) (*models.AlertRuleVersion, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.RecordedOps = append(f.RecordedOps, *q)
+	if err := f.Hook(*q); err != nil {
+		return nil, err
+	}
+	for _, v := range f.Versions[q.OrgID][q.RuleUID] {
+		if v.Version == q.Version {
+			return v, nil
+		}
+	}
+	return nil, models.ErrAlertRuleVersionNotFound
+}
+
 func (f *RuleStore) GetAlertRulesGroupByRuleUID(_ context.Context, q *models.GetAlertRulesGroupByRuleUIDQuery) ([]*models.AlertRule, error) {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
@@ -222,6 +283,9 @@ func (f *RuleStore) ListAlertRules(_ context.Context, q *models.ListAlertRulesQu
 		if q.RuleGroup != "" && r.RuleGroup != q.RuleGroup {
 			continue
 		}
+		if len(q.RuleUIDs) > 0 && !slices.Contains(q.RuleUIDs, r.UID) {
+			continue
+		}
 		ruleList = append(ruleList, r)
 	}
 
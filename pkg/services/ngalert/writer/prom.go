@@ -0,0 +1,137 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live/remotewrite"
+)
+
+// ErrUnsupportedData is returned if the frames cannot be written as samples of a metric.
+var ErrUnsupportedData = errors.New("unsupported data")
+
+type PrometheusWriterConfig struct {
+	URL               string
+	BasicAuthUsername string
+	BasicAuthPassword string
+	Timeout           time.Duration
+}
+
+// PrometheusWriter writes the results of recording rules to a Prometheus remote write endpoint.
+type PrometheusWriter struct {
+	url        *url.URL
+	username   string
+	password   string
+	httpClient *http.Client
+	logger     log.Logger
+}
+
+func NewPrometheusWriter(cfg PrometheusWriterConfig, logger log.Logger) (*PrometheusWriter, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse remote write URL: %w", err)
+	}
+	return &PrometheusWriter{
+		url:        u,
+		username:   cfg.BasicAuthUsername,
+		password:   cfg.BasicAuthPassword,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+		logger:     logger,
+	}, nil
+}
+
+// Write converts the frames to samples of the metric and sends them to the remote write endpoint.
+// Every numeric field of the frames becomes a series with a single sample at the time t. Frames that
+// contain a time field are not supported because a recording rule must produce a single value per series.
+func (w *PrometheusWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error {
+	samples, err := framesToSamples(t, frames, extraLabels)
+	if err != nil {
+		return err
+	}
+	if len(samples) == 0 {
+		w.logger.Debug("No data to write", "metric", name)
+		return nil
+	}
+
+	series := remotewrite.TimeSeriesFromFramesWithMetricName(name, samples...)
+	body, err := remotewrite.TimeSeriesToBytes(series)
+	if err != nil {
+		return fmt.Errorf("failed to serialize time series: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create remote write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if w.username != "" {
+		req.SetBasicAuth(w.username, w.password)
+	}
+
+	started := time.Now()
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send remote write request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			w.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("remote write endpoint responded with status %d: %s", resp.StatusCode, string(msg))
+	}
+	w.logger.Debug("Wrote samples of recording rule", "metric", name, "series", len(series), "duration", time.Since(started))
+	return nil
+}
+
+// framesToSamples converts every value of the numeric fields of the frames to a frame with a single sample at the time t.
+func framesToSamples(t time.Time, frames data.Frames, extraLabels map[string]string) ([]*data.Frame, error) {
+	result := make([]*data.Frame, 0, len(frames))
+	for _, frame := range frames {
+		for _, field := range frame.Fields {
+			if field.Type().Time() {
+				return nil, fmt.Errorf("%w: the result is a time series but it must be a number. Use a Reduce expression to convert the time series to numbers", ErrUnsupportedData)
+			}
+		}
+		for _, field := range frame.Fields {
+			if !field.Type().Numeric() {
+				continue
+			}
+			labels := make(data.Labels, len(field.Labels)+len(extraLabels))
+			for k, v := range field.Labels {
+				labels[k] = v
+			}
+			for k, v := range extraLabels {
+				labels[k] = v
+			}
+			for i := 0; i < field.Len(); i++ {
+				value, err := field.NullableFloatAt(i)
+				if err != nil {
+					return nil, err
+				}
+				if value == nil || math.IsNaN(*value) {
+					continue
+				}
+				result = append(result, data.NewFrame("",
+					data.NewField("time", nil, []time.Time{t}),
+					data.NewField("value", labels, []float64{*value}),
+				))
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,114 @@
+package writer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestPrometheusWriter_Write(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	var received *prompb.WriteRequest
+	var headers http.Header
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		decoded, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		received = &prompb.WriteRequest{}
+		require.NoError(t, proto.Unmarshal(decoded, received))
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	writer, err := NewPrometheusWriter(PrometheusWriterConfig{
+		URL:               server.URL,
+		BasicAuthUsername: "user",
+		BasicAuthPassword: "password",
+		Timeout:           time.Second,
+	}, log.NewNopLogger())
+	require.NoError(t, err)
+
+	t.Run("should write numbers as samples with merged labels", func(t *testing.T) {
+		received = nil
+		frames := data.Frames{
+			data.NewFrame("", data.NewField("", data.Labels{"instance": "a", "team": "other"}, []float64{1})),
+			data.NewFrame("", data.NewField("", data.Labels{"instance": "b"}, []*float64{nil})),
+		}
+		err := writer.Write(context.Background(), "test_metric", now, frames, map[string]string{"team": "alerting"})
+		require.NoError(t, err)
+
+		require.NotNil(t, received)
+		require.Len(t, received.Timeseries, 1)
+		series := received.Timeseries[0]
+		require.ElementsMatch(t, []prompb.Label{
+			{Name: "__name__", Value: "test_metric"},
+			{Name: "instance", Value: "a"},
+			{Name: "team", Value: "alerting"},
+		}, series.Labels)
+		require.Equal(t, []prompb.Sample{{Value: 1, Timestamp: now.UnixMilli()}}, series.Samples)
+
+		require.Equal(t, "snappy", headers.Get("Content-Encoding"))
+		require.Equal(t, "application/x-protobuf", headers.Get("Content-Type"))
+		username, password, ok := (&http.Request{Header: headers}).BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "user", username)
+		require.Equal(t, "password", password)
+	})
+
+	t.Run("should not send request if there is no data", func(t *testing.T) {
+		received = nil
+		err := writer.Write(context.Background(), "test_metric", now, data.Frames{data.NewFrame("")}, nil)
+		require.NoError(t, err)
+		require.Nil(t, received)
+	})
+
+	t.Run("should fail if result is time series", func(t *testing.T) {
+		frames := data.Frames{
+			data.NewFrame("",
+				data.NewField("time", nil, []time.Time{now}),
+				data.NewField("value", nil, []float64{1}),
+			),
+		}
+		err := writer.Write(context.Background(), "test_metric", now, frames, nil)
+		require.ErrorIs(t, err, ErrUnsupportedData)
+	})
+
+	t.Run("should fail if endpoint responds with error", func(t *testing.T) {
+		status = http.StatusBadRequest
+		t.Cleanup(func() { status = http.StatusNoContent })
+		frames := data.Frames{data.NewFrame("", data.NewField("", nil, []float64{1}))}
+		err := writer.Write(context.Background(), "test_metric", now, frames, nil)
+		require.ErrorContains(t, err, "400")
+	})
+}
+
+func TestNew_WithoutURLCountsDroppedResults(t *testing.T) {
+	m := metrics.NewSchedulerMetrics(prometheus.NewRegistry())
+	w, err := New(setting.UnifiedAlertingRecordingRuleSettings{}, m, log.NewNopLogger())
+	require.NoError(t, err)
+	require.IsType(t, &NoopWriter{}, w)
+
+	frames := data.Frames{data.NewFrame("", data.NewField("value", nil, []float64{1}))}
+	require.NoError(t, w.Write(context.Background(), "metric", time.Now(), frames, nil))
+	require.NoError(t, w.Write(context.Background(), "metric", time.Now(), frames, nil))
+	require.Equal(t, 2.0, testutil.ToFloat64(m.RecordingResultsDropped))
+}
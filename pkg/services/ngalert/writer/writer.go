@@ -0,0 +1,55 @@
+package writer
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Writer writes the results of recording rules.
+type Writer interface {
+	// Write writes the data frames as the metric with the given name at the time t.
+	// The extra labels are added to the labels of every series and take precedence over them.
+	Write(ctx context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error
+}
+
+// New creates a writer according to the configuration of recording rules.
+// If the remote write URL is not configured, the writer discards all results.
+func New(cfg setting.UnifiedAlertingRecordingRuleSettings, metrics *metrics.Scheduler, logger log.Logger) (Writer, error) {
+	if cfg.URL == "" {
+		logger.Warn("Remote write URL for recording rules is not configured. The results of recording rules will be discarded")
+		return NewNoopWriter(metrics.RecordingResultsDropped, logger), nil
+	}
+	return NewPrometheusWriter(PrometheusWriterConfig{
+		URL:               cfg.URL,
+		BasicAuthUsername: cfg.BasicAuthUsername,
+		BasicAuthPassword: cfg.BasicAuthPassword,
+		Timeout:           cfg.Timeout,
+	}, logger)
+}
+
+// NoopWriter is a writer that discards all results.
+// Every discarded result is logged and counted so that recording rules do not fail silently.
+type NoopWriter struct {
+	dropped prometheus.Counter
+	logger  log.Logger
+}
+
+func NewNoopWriter(dropped prometheus.Counter, logger log.Logger) *NoopWriter {
+	return &NoopWriter{
+		dropped: dropped,
+		logger:  logger,
+	}
+}
+
+func (w *NoopWriter) Write(_ context.Context, name string, _ time.Time, _ data.Frames, _ map[string]string) error {
+	w.logger.Warn("Discarding the result of recording rule because remote write URL is not configured", "metric", name)
+	w.dropped.Inc()
+	return nil
+}
@@ -3,7 +3,6 @@ package alerting
 import (
 	"context"
 	"fmt"
-	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -39,17 +38,16 @@ func (cr *rulesConfigReader) readConfig(ctx context.Context, path string) ([]*Al
 			cr.log.Warn(fmt.Sprintf("file has invalid suffix '%s' (.yaml,.yml,.json accepted), skipping", file.Name()))
 			continue
 		}
-		alertFileV1, err := cr.parseConfig(path, file)
+		content, err := cr.readFile(path, file.Name())
 		if err != nil {
 			return nil, fmt.Errorf("failure to parse file %s: %w", file.Name(), err)
 		}
-		if alertFileV1 != nil {
-			alertFileV1.Filename = file.Name()
-			alertFile, err := alertFileV1.MapToModel()
-			if err != nil {
-				return nil, fmt.Errorf("failure to map file %s: %w", alertFileV1.Filename, err)
-			}
-			alertFiles = append(alertFiles, &alertFile)
+		alertFile, err := cr.parseFile(file.Name(), content)
+		if err != nil {
+			return nil, err
+		}
+		if alertFile != nil {
+			alertFiles = append(alertFiles, alertFile)
 		}
 	}
 	return alertFiles, nil
@@ -63,18 +61,27 @@ func (cr *rulesConfigReader) isJSON(file string) bool {
 	return strings.HasSuffix(file, ".json")
 }
 
-func (cr *rulesConfigReader) parseConfig(path string, file fs.DirEntry) (*AlertingFileV1, error) {
-	filename, _ := filepath.Abs(filepath.Join(path, file.Name()))
+func (cr *rulesConfigReader) readFile(path string, name string) ([]byte, error) {
+	filename, _ := filepath.Abs(filepath.Join(path, name))
 	// nolint:gosec
 	// We can ignore the gosec G304 warning on this one because `filename` comes from ps.Cfg.ProvisioningPath
-	yamlFile, err := os.ReadFile(filename)
+	return os.ReadFile(filename)
+}
+
+// parseFile parses the content of the file with the given name. It returns nil if the file is empty.
+func (cr *rulesConfigReader) parseFile(name string, content []byte) (*AlertingFile, error) {
+	var cfg *AlertingFileV1
+	err := yaml.Unmarshal(content, &cfg)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failure to parse file %s: %w", name, err)
 	}
-	var cfg *AlertingFileV1
-	err = yaml.Unmarshal(yamlFile, &cfg)
+	if cfg == nil {
+		return nil, nil
+	}
+	cfg.Filename = name
+	alertFile, err := cfg.MapToModel()
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failure to map file %s: %w", name, err)
 	}
-	return cfg, nil
+	return &alertFile, nil
 }
@@ -29,51 +29,73 @@ func Provision(ctx context.Context, cfg ProvisionerConfig) error {
 	}
 	logger.Info("starting to provision alerting")
 	logger.Debug("read all alerting files", "file_count", len(files))
-	ruleProvisioner := NewAlertRuleProvisioner(
-		logger,
-		cfg.DashboardService,
-		cfg.DashboardProvService,
-		cfg.RuleService)
-	err = ruleProvisioner.Provision(ctx, files)
+	err = newProvisioner(logger, cfg).provision(ctx, files)
+	if err != nil {
+		return err
+	}
+	logger.Info("finished to provision alerting")
+	return nil
+}
+
+// provisioner applies alerting files using the provisioners of all types of resources.
+type provisioner struct {
+	ruleProvisioner AlertRuleProvisioner
+	cpProvisioner   ContactPointProvisioner
+	mtProvisioner   MuteTimesProvisioner
+	ttProvisioner   TextTemplateProvisioner
+	npProvisioner   NotificationPolicyProvisioner
+}
+
+func newProvisioner(logger log.Logger, cfg ProvisionerConfig) *provisioner {
+	return &provisioner{
+		ruleProvisioner: NewAlertRuleProvisioner(
+			logger,
+			cfg.DashboardService,
+			cfg.DashboardProvService,
+			cfg.RuleService),
+		cpProvisioner: NewContactPointProvisoner(logger, cfg.ContactPointService),
+		mtProvisioner: NewMuteTimesProvisioner(logger, cfg.MuteTimingService),
+		ttProvisioner: NewTextTemplateProvisioner(logger, cfg.TemplateService),
+		npProvisioner: NewNotificationPolicyProvisoner(logger, cfg.NotificiationPolicyService),
+	}
+}
+
+func (p *provisioner) provision(ctx context.Context, files []*AlertingFile) error {
+	err := p.ruleProvisioner.Provision(ctx, files)
 	if err != nil {
 		return fmt.Errorf("alert rules: %w", err)
 	}
-	cpProvisioner := NewContactPointProvisoner(logger, cfg.ContactPointService)
-	err = cpProvisioner.Provision(ctx, files)
+	err = p.cpProvisioner.Provision(ctx, files)
 	if err != nil {
 		return fmt.Errorf("contact points: %w", err)
 	}
-	mtProvisioner := NewMuteTimesProvisioner(logger, cfg.MuteTimingService)
-	err = mtProvisioner.Provision(ctx, files)
+	err = p.mtProvisioner.Provision(ctx, files)
 	if err != nil {
 		return fmt.Errorf("mute times: %w", err)
 	}
-	ttProvsioner := NewTextTemplateProvisioner(logger, cfg.TemplateService)
-	err = ttProvsioner.Provision(ctx, files)
+	err = p.ttProvisioner.Provision(ctx, files)
 	if err != nil {
 		return fmt.Errorf("text templates: %w", err)
 	}
-	npProvisioner := NewNotificationPolicyProvisoner(logger, cfg.NotificiationPolicyService)
-	err = npProvisioner.Provision(ctx, files)
+	err = p.npProvisioner.Provision(ctx, files)
 	if err != nil {
 		return fmt.Errorf("notification policies: %w", err)
 	}
-	err = npProvisioner.Unprovision(ctx, files)
+	err = p.npProvisioner.Unprovision(ctx, files)
 	if err != nil {
 		return fmt.Errorf("notification policies: %w", err)
 	}
-	err = cpProvisioner.Unprovision(ctx, files)
+	err = p.cpProvisioner.Unprovision(ctx, files)
 	if err != nil {
 		return fmt.Errorf("contact points: %w", err)
 	}
-	err = mtProvisioner.Unprovision(ctx, files)
+	err = p.mtProvisioner.Unprovision(ctx, files)
 	if err != nil {
 		return fmt.Errorf("mute times: %w", err)
 	}
-	err = ttProvsioner.Unprovision(ctx, files)
+	err = p.ttProvisioner.Unprovision(ctx, files)
 	if err != nil {
 		return fmt.Errorf("text templates: %w", err)
 	}
-	logger.Info("finished to provision alerting")
 	return nil
 }
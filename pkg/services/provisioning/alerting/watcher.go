@@ -0,0 +1,213 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// FileWatcher re-reads the alerting provisioning files periodically and applies the files that changed since they
+// were applied the last time. Resources that were removed from a file, or whose file was removed, are deleted unless
+// another file still defines them.
+type FileWatcher struct {
+	logger    log.Logger
+	path      string
+	reader    rulesConfigReader
+	provision func(ctx context.Context, files []*AlertingFile) error
+	// files are the files as they were applied the last time, by file name.
+	files map[string]watchedFile
+}
+
+type watchedFile struct {
+	checksum string
+	// file is nil if the file is empty.
+	file *AlertingFile
+}
+
+func NewFileWatcher(cfg ProvisionerConfig) *FileWatcher {
+	logger := log.New("provisioning.alerting")
+	return &FileWatcher{
+		logger:    logger,
+		path:      cfg.Path,
+		reader:    newRulesConfigReader(logger),
+		provision: newProvisioner(logger, cfg).provision,
+		files:     make(map[string]watchedFile),
+	}
+}
+
+// Watch re-reads the files every interval until the context is done. The files, as they are when Watch is called,
+// are expected to be provisioned already.
+func (w *FileWatcher) Watch(ctx context.Context, interval time.Duration) {
+	files, _, err := w.read()
+	if err != nil {
+		w.logger.Error("failed to read alerting provisioning files", "path", w.path, "error", err)
+	}
+	w.files = files
+
+	w.logger.Info("watching alerting provisioning files for changes", "path", w.path, "interval", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.sync(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sync applies the files that changed or were removed since the last time they were applied, and returns the errors
+// by file name. A file that fails to be read, parsed or applied is tried again the next time.
+func (w *FileWatcher) sync(ctx context.Context) map[string]error {
+	current, errs, err := w.read()
+	if err != nil {
+		w.logger.Error("failed to read alerting provisioning files", "path", w.path, "error", err)
+		return nil
+	}
+	// Files that cannot be read keep their previous content, so that their resources are not deleted.
+	for name := range errs {
+		if prev, ok := w.files[name]; ok {
+			current[name] = prev
+		}
+	}
+
+	var changed []string
+	for name, file := range current {
+		if prev, ok := w.files[name]; !ok || prev.checksum != file.checksum {
+			changed = append(changed, name)
+		}
+	}
+	for name := range w.files {
+		if _, ok := current[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+
+	for _, name := range changed {
+		var files []*AlertingFile
+		if file, ok := current[name]; ok && file.file != nil {
+			files = append(files, file.file)
+		}
+		if prev, ok := w.files[name]; ok && prev.file != nil {
+			files = append(files, removedResources(prev.file, current))
+		}
+		if err := w.provision(ctx, files); err != nil {
+			errs[name] = err
+			continue
+		}
+		if file, ok := current[name]; ok {
+			w.logger.Info("applied changes of alerting provisioning file", "file", name)
+			w.files[name] = file
+		} else {
+			w.logger.Info("removed resources of deleted alerting provisioning file", "file", name)
+			delete(w.files, name)
+		}
+	}
+
+	for name, err := range errs {
+		w.logger.Error("failed to apply alerting provisioning file", "file", name, "error", err)
+	}
+	return errs
+}
+
+// read reads all provisioning files in the directory. Files that have not changed since they were applied the last
+// time are not parsed again.
+func (w *FileWatcher) read() (map[string]watchedFile, map[string]error, error) {
+	entries, err := os.ReadDir(w.path)
+	if err != nil {
+		return nil, nil, err
+	}
+	files := make(map[string]watchedFile, len(entries))
+	errs := make(map[string]error)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || (!w.reader.isYAML(name) && !w.reader.isJSON(name)) {
+			continue
+		}
+		content, err := w.reader.readFile(w.path, name)
+		if err != nil {
+			errs[name] = err
+			continue
+		}
+		checksum, err := util.Md5Sum(bytes.NewReader(content))
+		if err != nil {
+			errs[name] = err
+			continue
+		}
+		if prev, ok := w.files[name]; ok && prev.checksum == checksum {
+			files[name] = prev
+			continue
+		}
+		file, err := w.reader.parseFile(name, content)
+		if err != nil {
+			errs[name] = err
+			continue
+		}
+		files[name] = watchedFile{checksum: checksum, file: file}
+	}
+	return files, errs, nil
+}
+
+// removedResources returns a file that deletes all resources of the file that are not defined by any of the current
+// files.
+func removedResources(file *AlertingFile, current map[string]watchedFile) *AlertingFile {
+	removed := definedResources(file)
+	for _, f := range current {
+		if f.file == nil {
+			continue
+		}
+		defined := definedResources(f.file)
+		removed.DeleteRules = without(removed.DeleteRules, defined.DeleteRules)
+		removed.DeleteContactPoints = without(removed.DeleteContactPoints, defined.DeleteContactPoints)
+		removed.DeleteMuteTimes = without(removed.DeleteMuteTimes, defined.DeleteMuteTimes)
+		removed.DeleteTemplates = without(removed.DeleteTemplates, defined.DeleteTemplates)
+		removed.ResetPolicies = without(removed.ResetPolicies, defined.ResetPolicies)
+	}
+	return removed
+}
+
+// definedResources returns a file that deletes all resources that the file creates or updates.
+func definedResources(file *AlertingFile) *AlertingFile {
+	result := &AlertingFile{Filename: file.Filename}
+	for _, group := range file.Groups {
+		for _, rule := range group.Rules {
+			result.DeleteRules = append(result.DeleteRules, RuleDelete{UID: rule.UID, OrgID: group.OrgID})
+		}
+	}
+	for _, cp := range file.ContactPoints {
+		for _, receiver := range cp.ContactPoints {
+			result.DeleteContactPoints = append(result.DeleteContactPoints, DeleteContactPoint{OrgID: cp.OrgID, UID: receiver.UID})
+		}
+	}
+	for _, mt := range file.MuteTimes {
+		result.DeleteMuteTimes = append(result.DeleteMuteTimes, DeleteMuteTime{OrgID: mt.OrgID, Name: mt.MuteTime.Name})
+	}
+	for _, t := range file.Templates {
+		result.DeleteTemplates = append(result.DeleteTemplates, DeleteTemplate{OrgID: t.OrgID, Name: t.Data.Name})
+	}
+	for _, np := range file.Policies {
+		result.ResetPolicies = append(result.ResetPolicies, OrgID(np.OrgID))
+	}
+	return result
+}
+
+func without[T comparable](items []T, remove []T) []T {
+	var result []T
+outer:
+	for _, item := range items {
+		for _, r := range remove {
+			if item == r {
+				continue outer
+			}
+		}
+		result = append(result, item)
+	}
+	return result
+}
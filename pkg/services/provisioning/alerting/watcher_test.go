@@ -0,0 +1,146 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+const (
+	templatesFile = `apiVersion: 1
+templates:
+  - name: a
+    template: Template A
+  - name: b
+    template: Template B
+`
+	muteTimesFile = `apiVersion: 1
+muteTimes:
+  - name: weekends
+    time_intervals:
+    - weekdays: ['saturday', 'sunday']
+`
+)
+
+func TestFileWatcher(t *testing.T) {
+	setup := func(t *testing.T) (*FileWatcher, *[][]*AlertingFile, string) {
+		dir := t.TempDir()
+		applied := [][]*AlertingFile{}
+		w := &FileWatcher{
+			logger: log.NewNopLogger(),
+			path:   dir,
+			reader: newRulesConfigReader(log.NewNopLogger()),
+			provision: func(_ context.Context, files []*AlertingFile) error {
+				applied = append(applied, files)
+				return nil
+			},
+			files: map[string]watchedFile{},
+		}
+		return w, &applied, dir
+	}
+
+	writeFile := func(t *testing.T, dir, name, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+
+	t.Run("applies only files that changed", func(t *testing.T) {
+		w, applied, dir := setup(t)
+		writeFile(t, dir, "templates.yaml", templatesFile)
+		writeFile(t, dir, "mute-times.yaml", muteTimesFile)
+
+		require.Empty(t, w.sync(context.Background()))
+		require.Len(t, *applied, 2)
+
+		*applied = nil
+		require.Empty(t, w.sync(context.Background()))
+		require.Empty(t, *applied)
+
+		writeFile(t, dir, "templates.yaml", templatesFile+"    # changed\n")
+		require.Empty(t, w.sync(context.Background()))
+		require.Len(t, *applied, 1)
+		require.Equal(t, "templates.yaml", (*applied)[0][0].Filename)
+	})
+
+	t.Run("deletes resources that were removed from a file", func(t *testing.T) {
+		w, applied, dir := setup(t)
+		writeFile(t, dir, "templates.yaml", templatesFile)
+		require.Empty(t, w.sync(context.Background()))
+
+		*applied = nil
+		writeFile(t, dir, "templates.yaml", `apiVersion: 1
+templates:
+  - name: a
+    template: Template A
+`)
+		require.Empty(t, w.sync(context.Background()))
+		require.Len(t, *applied, 1)
+		files := (*applied)[0]
+		require.Len(t, files, 2)
+		require.Len(t, files[0].Templates, 1)
+		require.Equal(t, []DeleteTemplate{{OrgID: 1, Name: "b"}}, files[1].DeleteTemplates)
+	})
+
+	t.Run("deletes resources of a removed file unless another file defines them", func(t *testing.T) {
+		w, applied, dir := setup(t)
+		writeFile(t, dir, "templates.yaml", templatesFile)
+		writeFile(t, dir, "mute-times.yaml", muteTimesFile)
+		require.Empty(t, w.sync(context.Background()))
+
+		*applied = nil
+		require.NoError(t, os.Remove(filepath.Join(dir, "templates.yaml")))
+		writeFile(t, dir, "template-a.yaml", `apiVersion: 1
+templates:
+  - name: a
+    template: Template A
+`)
+		require.Empty(t, w.sync(context.Background()))
+		require.Len(t, *applied, 2)
+
+		require.Equal(t, "template-a.yaml", (*applied)[0][0].Filename)
+		require.Len(t, (*applied)[1], 1)
+		require.Equal(t, "templates.yaml", (*applied)[1][0].Filename)
+		require.Equal(t, []DeleteTemplate{{OrgID: 1, Name: "b"}}, (*applied)[1][0].DeleteTemplates)
+		require.NotContains(t, w.files, "templates.yaml")
+	})
+
+	t.Run("reports errors per file and keeps the resources of broken files", func(t *testing.T) {
+		w, applied, dir := setup(t)
+		writeFile(t, dir, "templates.yaml", templatesFile)
+		writeFile(t, dir, "mute-times.yaml", muteTimesFile)
+		require.Empty(t, w.sync(context.Background()))
+
+		*applied = nil
+		writeFile(t, dir, "templates.yaml", "apiVersion: 1\ntemplates: [")
+		writeFile(t, dir, "mute-times.yaml", muteTimesFile+"    # changed\n")
+		errs := w.sync(context.Background())
+		require.Len(t, errs, 1)
+		require.ErrorContains(t, errs["templates.yaml"], "failure to parse file templates.yaml")
+		require.Len(t, *applied, 1)
+		require.Equal(t, "mute-times.yaml", (*applied)[0][0].Filename)
+		require.Empty(t, (*applied)[0][1].DeleteTemplates)
+	})
+
+	t.Run("tries files that failed to apply again", func(t *testing.T) {
+		w, applied, dir := setup(t)
+		provision := w.provision
+		w.provision = func(context.Context, []*AlertingFile) error {
+			return errors.New("failed")
+		}
+		writeFile(t, dir, "templates.yaml", templatesFile)
+
+		errs := w.sync(context.Background())
+		require.Len(t, errs, 1)
+		require.EqualError(t, errs["templates.yaml"], "failed")
+
+		w.provision = provision
+		require.Empty(t, w.sync(context.Background()))
+		require.Len(t, *applied, 1)
+	})
+}
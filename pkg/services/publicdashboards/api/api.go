@@ -85,6 +85,21 @@ func (api *Api) RegisterAPIEndpoints() {
 	api.RouteRegister.Delete("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid",
 		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
 		routing.Wrap(api.DeletePublicDashboard))
+
+	// List access tokens of public dashboard
+	api.RouteRegister.Get("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/tokens",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, uidScope)),
+		routing.Wrap(api.ListPublicDashboardTokens))
+
+	// Create access token of public dashboard
+	api.RouteRegister.Post("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/tokens",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.CreatePublicDashboardToken))
+
+	// Revoke access token of public dashboard
+	api.RouteRegister.Post("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/tokens/:tokenUid/revoke",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.RevokePublicDashboardToken))
 }
 
 // ListPublicDashboards Gets list of public dashboards by orgId
@@ -219,6 +234,102 @@ func (api *Api) DeletePublicDashboard(c *contextmodel.ReqContext) response.Respo
 	return response.JSON(http.StatusOK, nil)
 }
 
+// ListPublicDashboardTokens Gets the access tokens of a public dashboard
+// GET /api/dashboards/uid/:dashboardUid/public-dashboards/:uid/tokens
+func (api *Api) ListPublicDashboardTokens(c *contextmodel.ReqContext) response.Response {
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return response.Err(ErrInvalidUid.Errorf("ListPublicDashboardTokens: invalid dashboard Uid %s", dashboardUid))
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return response.Err(ErrInvalidUid.Errorf("ListPublicDashboardTokens: invalid Uid %s", uid))
+	}
+
+	if err := api.requirePublicDashboardOfOrg(c, uid); err != nil {
+		return response.Err(err)
+	}
+
+	tokens, err := api.PublicDashboardService.FindTokens(c.Req.Context(), dashboardUid, uid)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, tokens)
+}
+
+// CreatePublicDashboardToken Creates an access token for a public dashboard
+// POST /api/dashboards/uid/:dashboardUid/public-dashboards/:uid/tokens
+func (api *Api) CreatePublicDashboardToken(c *contextmodel.ReqContext) response.Response {
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return response.Err(ErrInvalidUid.Errorf("CreatePublicDashboardToken: invalid dashboard Uid %s", dashboardUid))
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return response.Err(ErrInvalidUid.Errorf("CreatePublicDashboardToken: invalid Uid %s", uid))
+	}
+
+	dto := &CreatePublicDashboardTokenDTO{}
+	if err := web.Bind(c.Req, dto); err != nil {
+		return response.Err(ErrBadRequest.Errorf("CreatePublicDashboardToken: bad request data %v", err))
+	}
+
+	if err := api.requirePublicDashboardOfOrg(c, uid); err != nil {
+		return response.Err(err)
+	}
+
+	token, err := api.PublicDashboardService.CreateToken(c.Req.Context(), c.SignedInUser, dashboardUid, uid, dto)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, token)
+}
+
+// RevokePublicDashboardToken Revokes an access token of a public dashboard
+// POST /api/dashboards/uid/:dashboardUid/public-dashboards/:uid/tokens/:tokenUid/revoke
+func (api *Api) RevokePublicDashboardToken(c *contextmodel.ReqContext) response.Response {
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return response.Err(ErrInvalidUid.Errorf("RevokePublicDashboardToken: invalid dashboard Uid %s", dashboardUid))
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return response.Err(ErrInvalidUid.Errorf("RevokePublicDashboardToken: invalid Uid %s", uid))
+	}
+
+	tokenUid := web.Params(c.Req)[":tokenUid"]
+	if !validation.IsValidShortUID(tokenUid) {
+		return response.Err(ErrInvalidUid.Errorf("RevokePublicDashboardToken: invalid token Uid %s", tokenUid))
+	}
+
+	if err := api.requirePublicDashboardOfOrg(c, uid); err != nil {
+		return response.Err(err)
+	}
+
+	if err := api.PublicDashboardService.RevokeToken(c.Req.Context(), dashboardUid, uid, tokenUid); err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, nil)
+}
+
+// requirePublicDashboardOfOrg asserts that the public dashboard belongs to the org of the signed in user
+func (api *Api) requirePublicDashboardOfOrg(c *contextmodel.ReqContext, uid string) error {
+	pd, err := api.PublicDashboardService.Find(c.Req.Context(), uid)
+	if err != nil {
+		return err
+	}
+	if pd == nil || pd.OrgId != c.OrgID {
+		return ErrPublicDashboardNotFound.Errorf("requirePublicDashboardOfOrg: public dashboard %s not found", uid)
+	}
+	return nil
+}
+
 // Copied from pkg/api/metrics.go
 func toJsonStreamingResponse(features *featuremgmt.FeatureManager, qdr *backend.QueryDataResponse) response.Response {
 	statusWhenError := http.StatusBadRequest
@@ -296,6 +296,66 @@ func TestAPIDeletePublicDashboard(t *testing.T) {
 	}
 }
 
+func TestAPIPublicDashboardTokens(t *testing.T) {
+	dashboardUid := "abc1234"
+	publicDashboardUid := "1234asdfasdf"
+	pubdash := &PublicDashboard{Uid: publicDashboardUid, DashboardUid: dashboardUid, OrgId: 1}
+	token := &PublicDashboardToken{Uid: "token1234", PublicDashboardUid: publicDashboardUid, Name: "viewer", AccessToken: "abcd"}
+	tokensPath := fmt.Sprintf("/api/dashboards/uid/%s/public-dashboards/%s/tokens", dashboardUid, publicDashboardUid)
+
+	t.Run("lists tokens", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("Find", mock.Anything, publicDashboardUid).Return(pubdash, nil)
+		service.On("FindTokens", mock.Anything, dashboardUid, publicDashboardUid).Return([]*PublicDashboardToken{token}, nil)
+
+		testServer := setupTestServer(t, setting.NewCfg(), featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards), service, nil, userViewerRBAC)
+		response := callAPI(testServer, http.MethodGet, tokensPath, nil, t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var tokens []*PublicDashboardToken
+		err := json.Unmarshal(response.Body.Bytes(), &tokens)
+		require.NoError(t, err)
+		assert.Equal(t, []*PublicDashboardToken{token}, tokens)
+	})
+
+	t.Run("creates token", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("Find", mock.Anything, publicDashboardUid).Return(pubdash, nil)
+		service.On("CreateToken", mock.Anything, mock.Anything, dashboardUid, publicDashboardUid, &CreatePublicDashboardTokenDTO{Name: "viewer"}).Return(token, nil)
+
+		testServer := setupTestServer(t, setting.NewCfg(), featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards), service, nil, userAdminRBAC)
+		response := callAPI(testServer, http.MethodPost, tokensPath, strings.NewReader(`{"name": "viewer"}`), t)
+		require.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("viewer cannot create token", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+
+		testServer := setupTestServer(t, setting.NewCfg(), featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards), service, nil, userViewerRBAC)
+		response := callAPI(testServer, http.MethodPost, tokensPath, strings.NewReader(`{"name": "viewer"}`), t)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+	})
+
+	t.Run("revokes token", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("Find", mock.Anything, publicDashboardUid).Return(pubdash, nil)
+		service.On("RevokeToken", mock.Anything, dashboardUid, publicDashboardUid, token.Uid).Return(nil)
+
+		testServer := setupTestServer(t, setting.NewCfg(), featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards), service, nil, userAdminRBAC)
+		response := callAPI(testServer, http.MethodPost, fmt.Sprintf("%s/%s/revoke", tokensPath, token.Uid), nil, t)
+		require.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("returns not found when public dashboard is of another org", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("Find", mock.Anything, publicDashboardUid).Return(&PublicDashboard{Uid: publicDashboardUid, DashboardUid: dashboardUid, OrgId: 2}, nil)
+
+		testServer := setupTestServer(t, setting.NewCfg(), featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards), service, nil, userAdminRBAC)
+		response := callAPI(testServer, http.MethodPost, fmt.Sprintf("%s/%s/revoke", tokensPath, token.Uid), nil, t)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+}
+
 func TestAPIGetPublicDashboard(t *testing.T) {
 	pubdash := &PublicDashboard{IsEnabled: true}
 
@@ -1,11 +1,13 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -62,6 +64,27 @@ func RequiresExistingAccessToken(publicDashboardService publicdashboards.Service
 	}
 }
 
+// RequiresAllowedAccessToken Middleware to enforce that a named access token is not expired or revoked and that the
+// client is allowed to use it. Use on the page of a public dashboard, the referrer of its API requests is Grafana itself.
+func RequiresAllowedAccessToken(publicDashboardService publicdashboards.Service) func(c *contextmodel.ReqContext) {
+	return func(c *contextmodel.ReqContext) {
+		accessToken, ok := web.Params(c.Req)[":accessToken"]
+		if !ok || !validation.IsValidAccessToken(accessToken) {
+			return
+		}
+
+		_, err := publicDashboardService.FindByAccessToken(c.Req.Context(), accessToken)
+		switch {
+		case errors.Is(err, ErrTokenExpired):
+			c.JsonApiErr(http.StatusForbidden, "Access token expired", nil)
+		case errors.Is(err, ErrTokenRevoked):
+			c.JsonApiErr(http.StatusForbidden, "Access token revoked", nil)
+		case errors.Is(err, ErrTokenNotAllowed):
+			c.JsonApiErr(http.StatusForbidden, "Access denied", nil)
+		}
+	}
+}
+
 func CountPublicDashboardRequest() func(c *contextmodel.ReqContext) {
 	return func(c *contextmodel.ReqContext) {
 		metrics.MPublicDashboardRequestCount.Inc()
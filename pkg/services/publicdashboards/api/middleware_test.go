@@ -10,6 +10,7 @@ import (
 
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/service"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/web"
@@ -83,6 +84,70 @@ func TestRequiresExistingAccessToken(t *testing.T) {
 	}
 }
 
+func TestRequiresAllowedAccessToken(t *testing.T) {
+	tests := []struct {
+		Name                 string
+		AccessToken          string
+		FindErr              error
+		ShouldCallService    bool
+		ExpectedResponseCode int
+	}{
+		{
+			Name:                 "Returns 200 when access token is allowed",
+			AccessToken:          validAccessToken,
+			ShouldCallService:    true,
+			ExpectedResponseCode: http.StatusOK,
+		},
+		{
+			Name:                 "Returns 200 when public dashboard is not found",
+			AccessToken:          validAccessToken,
+			FindErr:              ErrPublicDashboardNotFound.Errorf(""),
+			ShouldCallService:    true,
+			ExpectedResponseCode: http.StatusOK,
+		},
+		{
+			Name:                 "Returns 200 when access token is invalid",
+			AccessToken:          "invalidAccessToken",
+			ShouldCallService:    false,
+			ExpectedResponseCode: http.StatusOK,
+		},
+		{
+			Name:                 "Returns 403 when access token is expired",
+			AccessToken:          validAccessToken,
+			FindErr:              ErrTokenExpired.Errorf(""),
+			ShouldCallService:    true,
+			ExpectedResponseCode: http.StatusForbidden,
+		},
+		{
+			Name:                 "Returns 403 when access token is revoked",
+			AccessToken:          validAccessToken,
+			FindErr:              ErrTokenRevoked.Errorf(""),
+			ShouldCallService:    true,
+			ExpectedResponseCode: http.StatusForbidden,
+		},
+		{
+			Name:                 "Returns 403 when client is not allowed",
+			AccessToken:          validAccessToken,
+			FindErr:              ErrTokenNotAllowed.Errorf(""),
+			ShouldCallService:    true,
+			ExpectedResponseCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			publicdashboardService := publicdashboards.NewFakePublicDashboardService(t)
+			if tt.ShouldCallService {
+				publicdashboardService.On("FindByAccessToken", mock.Anything, tt.AccessToken).Return(nil, tt.FindErr)
+			}
+			params := map[string]string{":accessToken": tt.AccessToken}
+			mw := RequiresAllowedAccessToken(publicdashboardService)
+			_, resp := runMw(t, nil, "GET", "/public-dashboards/myAccesstoken", params, mw)
+			require.Equal(t, tt.ExpectedResponseCode, resp.Code)
+		})
+	}
+}
+
 func TestSetPublicDashboardOrgIdOnContext(t *testing.T) {
 	tests := []struct {
 		Name          string
@@ -42,7 +42,7 @@ func (api *Api) ViewPublicDashboard(c *contextmodel.ReqContext) response.Respons
 		Version:                    dash.Version,
 		IsFolder:                   false,
 		FolderId:                   dash.FolderID,
-		PublicDashboardAccessToken: pubdash.AccessToken,
+		PublicDashboardAccessToken: accessToken,
 		PublicDashboardEnabled:     pubdash.IsEnabled,
 	}
 	dash.Data.Get("timepicker").Set("hidden", !pubdash.TimeSelectionEnabled)
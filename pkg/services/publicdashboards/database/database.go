@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -137,7 +138,8 @@ func (d *PublicDashboardStoreImpl) Find(ctx context.Context, uid string) (*Publi
 	return publicDashboard, nil
 }
 
-// FindByAccessToken Returns public dashboard by access token or nil if not found
+// FindByAccessToken Returns public dashboard by its default access token or by one of its named access tokens or nil
+// if not found. The named access token is set as Token of the public dashboard.
 func (d *PublicDashboardStoreImpl) FindByAccessToken(ctx context.Context, accessToken string) (*PublicDashboard, error) {
 	if accessToken == "" {
 		return nil, nil
@@ -148,6 +150,19 @@ func (d *PublicDashboardStoreImpl) FindByAccessToken(ctx context.Context, access
 	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
 		var err error
 		found, err = sess.Get(publicDashboard)
+		if err != nil || found {
+			return err
+		}
+
+		token := &PublicDashboardToken{AccessToken: accessToken}
+		tokenFound, err := sess.Get(token)
+		if err != nil || !tokenFound {
+			return err
+		}
+
+		publicDashboard = &PublicDashboard{Uid: token.PublicDashboardUid}
+		found, err = sess.Get(publicDashboard)
+		publicDashboard.Token = token
 		return err
 	})
 
@@ -212,9 +227,10 @@ func (d *PublicDashboardStoreImpl) ExistsEnabledByDashboardUid(ctx context.Conte
 func (d *PublicDashboardStoreImpl) ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error) {
 	hasPublicDashboard := false
 	err := d.sqlStore.WithDbSession(ctx, func(dbSession *db.Session) error {
-		sql := "SELECT COUNT(*) FROM dashboard_public WHERE access_token=? AND is_enabled=true"
+		sql := "SELECT COUNT(*) FROM dashboard_public WHERE is_enabled=true AND (access_token=? OR uid IN " +
+			"(SELECT public_dashboard_uid FROM dashboard_public_token WHERE access_token=? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)))"
 
-		result, err := dbSession.SQL(sql, accessToken).Count()
+		result, err := dbSession.SQL(sql, accessToken, accessToken, time.Now().UTC().Format("2006-01-02 15:04:05")).Count()
 		if err != nil {
 			return err
 		}
@@ -230,9 +246,9 @@ func (d *PublicDashboardStoreImpl) ExistsEnabledByAccessToken(ctx context.Contex
 func (d *PublicDashboardStoreImpl) GetOrgIdByAccessToken(ctx context.Context, accessToken string) (int64, error) {
 	var orgId int64
 	err := d.sqlStore.WithDbSession(ctx, func(dbSession *db.Session) error {
-		sql := "SELECT org_id FROM dashboard_public WHERE access_token=?"
+		sql := "SELECT org_id FROM dashboard_public WHERE access_token=? OR uid IN (SELECT public_dashboard_uid FROM dashboard_public_token WHERE access_token=?)"
 
-		_, err := dbSession.SQL(sql, accessToken).Get(&orgId)
+		_, err := dbSession.SQL(sql, accessToken, accessToken).Get(&orgId)
 		if err != nil {
 			return err
 		}
@@ -268,10 +284,12 @@ func (d *PublicDashboardStoreImpl) Update(ctx context.Context, cmd SavePublicDas
 			return err
 		}
 
-		sqlResult, err := sess.Exec("UPDATE dashboard_public SET is_enabled = ?, annotations_enabled = ?, time_selection_enabled = ?, share = ?, time_settings = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
+		sqlResult, err := sess.Exec("UPDATE dashboard_public SET is_enabled = ?, annotations_enabled = ?, time_selection_enabled = ?, template_variables_enabled = ?, query_variables_enabled = ?, share = ?, time_settings = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
 			cmd.PublicDashboard.IsEnabled,
 			cmd.PublicDashboard.AnnotationsEnabled,
 			cmd.PublicDashboard.TimeSelectionEnabled,
+			cmd.PublicDashboard.TemplateVariablesEnabled,
+			cmd.PublicDashboard.QueryVariablesEnabled,
 			cmd.PublicDashboard.Share,
 			string(timeSettingsJSON),
 			cmd.PublicDashboard.UpdatedBy,
@@ -297,13 +315,86 @@ func (d *PublicDashboardStoreImpl) Delete(ctx context.Context, uid string) (int6
 	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
 		var err error
 		affectedRows, err = sess.Delete(dashboard)
+		if err != nil {
+			return err
+		}
+
+		_, err = sess.Delete(&PublicDashboardToken{PublicDashboardUid: uid})
+		return err
+	})
+
+	return affectedRows, err
+}
+
+// Creates a named access token of a public dashboard
+func (d *PublicDashboardStoreImpl) CreateToken(ctx context.Context, token *PublicDashboardToken) error {
+	return d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(token)
+		return err
+	})
+}
+
+// FindToken Returns named access token by uid or nil if not found
+func (d *PublicDashboardStoreImpl) FindToken(ctx context.Context, uid string) (*PublicDashboardToken, error) {
+	if uid == "" {
+		return nil, nil
+	}
+
+	var found bool
+	token := &PublicDashboardToken{Uid: uid}
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		found, err = sess.Get(token)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	return token, nil
+}
+
+// FindTokens Returns the named access tokens of a public dashboard ordered by creation time
+func (d *PublicDashboardStoreImpl) FindTokens(ctx context.Context, publicDashboardUid string) ([]*PublicDashboardToken, error) {
+	tokens := make([]*PublicDashboardToken, 0)
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("public_dashboard_uid = ?", publicDashboardUid).Asc("created_at").Find(&tokens)
+	})
+
+	return tokens, err
+}
+
+// Revokes a named access token, already revoked tokens are not updated
+func (d *PublicDashboardStoreImpl) RevokeToken(ctx context.Context, uid string, revokedAt time.Time) (int64, error) {
+	var affectedRows int64
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		sqlResult, err := sess.Exec("UPDATE dashboard_public_token SET revoked_at = ? WHERE uid = ? AND revoked_at IS NULL",
+			revokedAt.UTC().Format("2006-01-02 15:04:05"), uid)
+		if err != nil {
+			return err
+		}
 
+		affectedRows, err = sqlResult.RowsAffected()
 		return err
 	})
 
 	return affectedRows, err
 }
 
+// Records the last time a named access token was used
+func (d *PublicDashboardStoreImpl) UpdateTokenLastUsedAt(ctx context.Context, uid string, lastUsedAt time.Time) error {
+	return d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE dashboard_public_token SET last_used_at = ? WHERE uid = ?",
+			lastUsedAt.UTC().Format("2006-01-02 15:04:05"), uid)
+		return err
+	})
+}
+
 func (d *PublicDashboardStoreImpl) FindByDashboardFolder(ctx context.Context, dashboard *dashboards.Dashboard) ([]*PublicDashboard, error) {
 	if dashboard == nil || !dashboard.IsFolder {
 		return nil, nil
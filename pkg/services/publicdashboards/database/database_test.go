@@ -609,16 +609,18 @@ func TestIntegrationUpdatePublicDashboard(t *testing.T) {
 		assert.EqualValues(t, affectedRows, 1)
 
 		updatedPublicDashboard := PublicDashboard{
-			Uid:                  pdUid,
-			DashboardUid:         savedDashboard.UID,
-			OrgId:                savedDashboard.OrgID,
-			IsEnabled:            false,
-			AnnotationsEnabled:   true,
-			TimeSelectionEnabled: true,
-			Share:                EmailShareType,
-			TimeSettings:         &TimeSettings{From: "now-8", To: "now"},
-			UpdatedAt:            time.Now().UTC().Round(time.Second),
-			UpdatedBy:            8,
+			Uid:                      pdUid,
+			DashboardUid:             savedDashboard.UID,
+			OrgId:                    savedDashboard.OrgID,
+			IsEnabled:                false,
+			AnnotationsEnabled:       true,
+			TimeSelectionEnabled:     true,
+			TemplateVariablesEnabled: true,
+			QueryVariablesEnabled:    true,
+			Share:                    EmailShareType,
+			TimeSettings:             &TimeSettings{From: "now-8", To: "now"},
+			UpdatedAt:                time.Now().UTC().Round(time.Second),
+			UpdatedBy:                8,
 		}
 
 		// update initial record
@@ -637,6 +639,8 @@ func TestIntegrationUpdatePublicDashboard(t *testing.T) {
 		assert.Equal(t, updatedPublicDashboard.IsEnabled, pdRetrieved.IsEnabled)
 		assert.Equal(t, updatedPublicDashboard.AnnotationsEnabled, pdRetrieved.AnnotationsEnabled)
 		assert.Equal(t, updatedPublicDashboard.TimeSelectionEnabled, pdRetrieved.TimeSelectionEnabled)
+		assert.Equal(t, updatedPublicDashboard.TemplateVariablesEnabled, pdRetrieved.TemplateVariablesEnabled)
+		assert.Equal(t, updatedPublicDashboard.QueryVariablesEnabled, pdRetrieved.QueryVariablesEnabled)
 		assert.Equal(t, updatedPublicDashboard.Share, pdRetrieved.Share)
 
 		// not updated dashboard shouldn't have changed
@@ -764,6 +768,112 @@ func TestIntegrationDelete(t *testing.T) {
 	})
 }
 
+func TestIntegrationPublicDashboardTokens(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	var sqlStore db.DB
+	var cfg *setting.Cfg
+	var dashboardStore dashboards.Store
+	var publicdashboardStore *PublicDashboardStoreImpl
+	var savedDashboard *dashboards.Dashboard
+	var savedPublicDashboard *PublicDashboard
+	var err error
+
+	setup := func() {
+		sqlStore, cfg = db.InitTestDBwithCfg(t)
+		dashboardStore, err = dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore, cfg), quotatest.New(false, nil))
+		require.NoError(t, err)
+		publicdashboardStore = ProvideStore(sqlStore, cfg, featuremgmt.WithFeatures())
+		savedDashboard = insertTestDashboard(t, dashboardStore, "testDashie", 1, 0, true)
+		savedPublicDashboard = insertPublicDashboard(t, publicdashboardStore, savedDashboard.UID, savedDashboard.OrgID, true, PublicShareType)
+	}
+
+	t.Run("finds public dashboard by named access token", func(t *testing.T) {
+		setup()
+		token := insertPublicDashboardToken(t, publicdashboardStore, savedPublicDashboard, nil)
+
+		pubdash, err := publicdashboardStore.FindByAccessToken(context.Background(), token.AccessToken)
+		require.NoError(t, err)
+		require.NotNil(t, pubdash)
+		assert.Equal(t, savedPublicDashboard.Uid, pubdash.Uid)
+		require.NotNil(t, pubdash.Token)
+		assert.Equal(t, token.Uid, pubdash.Token.Uid)
+		assert.Equal(t, []string{"10.0.0.0/8"}, pubdash.Token.AllowedIPs.Values())
+
+		pubdash, err = publicdashboardStore.FindByAccessToken(context.Background(), savedPublicDashboard.AccessToken)
+		require.NoError(t, err)
+		assert.Nil(t, pubdash.Token)
+
+		orgId, err := publicdashboardStore.GetOrgIdByAccessToken(context.Background(), token.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, savedPublicDashboard.OrgId, orgId)
+	})
+
+	t.Run("named access token exists unless revoked or expired", func(t *testing.T) {
+		setup()
+		future := time.Now().Add(time.Hour)
+		past := time.Now().Add(-time.Hour)
+		token := insertPublicDashboardToken(t, publicdashboardStore, savedPublicDashboard, &future)
+		expiredToken := insertPublicDashboardToken(t, publicdashboardStore, savedPublicDashboard, &past)
+
+		exists, err := publicdashboardStore.ExistsEnabledByAccessToken(context.Background(), token.AccessToken)
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		exists, err = publicdashboardStore.ExistsEnabledByAccessToken(context.Background(), expiredToken.AccessToken)
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		affectedRows, err := publicdashboardStore.RevokeToken(context.Background(), token.Uid, time.Now())
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, affectedRows)
+
+		exists, err = publicdashboardStore.ExistsEnabledByAccessToken(context.Background(), token.AccessToken)
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		// revoking twice does not change the revocation time
+		affectedRows, err = publicdashboardStore.RevokeToken(context.Background(), token.Uid, time.Now())
+		require.NoError(t, err)
+		assert.EqualValues(t, 0, affectedRows)
+
+		revoked, err := publicdashboardStore.FindToken(context.Background(), token.Uid)
+		require.NoError(t, err)
+		assert.True(t, revoked.IsRevoked())
+	})
+
+	t.Run("finds tokens and records usage", func(t *testing.T) {
+		setup()
+		token := insertPublicDashboardToken(t, publicdashboardStore, savedPublicDashboard, nil)
+
+		err := publicdashboardStore.UpdateTokenLastUsedAt(context.Background(), token.Uid, time.Now())
+		require.NoError(t, err)
+
+		tokens, err := publicdashboardStore.FindTokens(context.Background(), savedPublicDashboard.Uid)
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		assert.Equal(t, token.Uid, tokens[0].Uid)
+		assert.NotNil(t, tokens[0].LastUsedAt)
+
+		tokens, err = publicdashboardStore.FindTokens(context.Background(), "other-uid")
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+	})
+
+	t.Run("deletes tokens with public dashboard", func(t *testing.T) {
+		setup()
+		token := insertPublicDashboardToken(t, publicdashboardStore, savedPublicDashboard, nil)
+
+		_, err := publicdashboardStore.Delete(context.Background(), savedPublicDashboard.Uid)
+		require.NoError(t, err)
+
+		found, err := publicdashboardStore.FindToken(context.Background(), token.Uid)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+}
+
 func TestGetDashboardByFolder(t *testing.T) {
 	t.Run("returns nil when dashboard is not a folder", func(t *testing.T) {
 		sqlStore, _ := db.InitTestDBwithCfg(t)
@@ -920,3 +1030,26 @@ func insertPublicDashboard(t *testing.T, publicdashboardStore *PublicDashboardSt
 
 	return pubdash
 }
+
+func insertPublicDashboardToken(t *testing.T, publicdashboardStore *PublicDashboardStoreImpl, pubdash *PublicDashboard, expiresAt *time.Time) *PublicDashboardToken {
+	accessToken, err := service.GenerateAccessToken()
+	require.NoError(t, err)
+
+	ips := AllowList{"10.0.0.0/8"}
+	token := &PublicDashboardToken{
+		Uid:                util.GenerateShortUID(),
+		PublicDashboardUid: pubdash.Uid,
+		OrgId:              pubdash.OrgId,
+		Name:               "viewer",
+		AccessToken:        accessToken,
+		ExpiresAt:          expiresAt,
+		AllowedIPs:         &ips,
+		CreatedBy:          1,
+		CreatedAt:          time.Now(),
+	}
+
+	err = publicdashboardStore.CreateToken(context.Background(), token)
+	require.NoError(t, err)
+
+	return token
+}
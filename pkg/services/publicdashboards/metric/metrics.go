@@ -8,8 +8,17 @@ const (
 	namespace = "grafana"
 )
 
+// Statuses of public dashboard queries
+const (
+	QueryStatusCacheHit    = "cache_hit"
+	QueryStatusCacheMiss   = "cache_miss"
+	QueryStatusRateLimited = "rate_limited"
+	QueryStatusError       = "error"
+)
+
 type Metrics struct {
 	PublicDashboardsAmount *prometheus.GaugeVec
+	QueriesTotal           *prometheus.CounterVec
 }
 
 func newMetrics() *Metrics {
@@ -19,5 +28,10 @@ func newMetrics() *Metrics {
 			Name:      "public_dashboards_amount",
 			Help:      "Total amount of public dashboards",
 		}, []string{"is_enabled", "share_type"}),
+		QueriesTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "public_dashboards_queries_total",
+			Help:      "Total amount of panel queries of public dashboards by status",
+		}, []string{"public_dashboard_uid", "status"}),
 	}
 }
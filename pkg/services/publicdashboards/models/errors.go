@@ -6,6 +6,7 @@ var (
 	ErrInternalServerError = errutil.NewBase(errutil.StatusInternal, "publicdashboards.internalServerError", errutil.WithPublicMessage("Internal server error"))
 
 	ErrPublicDashboardNotFound = errutil.NewBase(errutil.StatusNotFound, "publicdashboards.notFound", errutil.WithPublicMessage("Public dashboard not found"))
+	ErrTokenNotFound           = errutil.NewBase(errutil.StatusNotFound, "publicdashboards.tokenNotFound", errutil.WithPublicMessage("Access token not found"))
 	ErrDashboardNotFound       = errutil.NewBase(errutil.StatusNotFound, "publicdashboards.dashboardNotFound", errutil.WithPublicMessage("Dashboard not found"))
 	ErrPanelNotFound           = errutil.NewBase(errutil.StatusNotFound, "publicdashboards.panelNotFound", errutil.WithPublicMessage("Public dashboard panel not found"))
 
@@ -19,8 +20,15 @@ var (
 	ErrInvalidInterval                     = errutil.NewBase(errutil.StatusBadRequest, "publicdashboards.invalidInterval", errutil.WithPublicMessage("intervalMS should be greater than 0"))
 	ErrInvalidMaxDataPoints                = errutil.NewBase(errutil.StatusBadRequest, "publicdashboards.maxDataPoints", errutil.WithPublicMessage("maxDataPoints should be greater than 0"))
 	ErrInvalidTimeRange                    = errutil.NewBase(errutil.StatusBadRequest, "publicdashboards.invalidTimeRange", errutil.WithPublicMessage("Invalid time range"))
+	ErrInvalidTemplateVariables            = errutil.NewBase(errutil.StatusBadRequest, "publicdashboards.invalidTemplateVariables", errutil.WithPublicMessage("Invalid template variables"))
+	ErrInvalidToken                        = errutil.NewBase(errutil.StatusBadRequest, "publicdashboards.invalidToken", errutil.WithPublicMessage("Invalid access token settings"))
 	ErrInvalidShareType                    = errutil.NewBase(errutil.StatusBadRequest, "publicdashboards.invalidShareType", errutil.WithPublicMessage("Invalid share type"))
 	ErrDashboardIsPublic                   = errutil.NewBase(errutil.StatusBadRequest, "publicdashboards.dashboardIsPublic", errutil.WithPublicMessage("Dashboard is already public"))
 
 	ErrPublicDashboardNotEnabled = errutil.NewBase(errutil.StatusForbidden, "publicdashboards.notEnabled", errutil.WithPublicMessage("Public dashboard paused"))
+	ErrTokenExpired              = errutil.NewBase(errutil.StatusForbidden, "publicdashboards.tokenExpired", errutil.WithPublicMessage("Access token expired"))
+	ErrTokenRevoked              = errutil.NewBase(errutil.StatusForbidden, "publicdashboards.tokenRevoked", errutil.WithPublicMessage("Access token revoked"))
+	ErrTokenNotAllowed           = errutil.NewBase(errutil.StatusForbidden, "publicdashboards.tokenNotAllowed", errutil.WithPublicMessage("Access denied"))
+
+	ErrTooManyQueries = errutil.NewBase(errutil.StatusTooManyRequests, "publicdashboards.tooManyQueries", errutil.WithPublicMessage("Too many queries, try again later"))
 )
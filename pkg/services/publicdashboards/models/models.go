@@ -51,15 +51,23 @@ type PublicDashboard struct {
 	TimeSelectionEnabled bool          `json:"timeSelectionEnabled" xorm:"time_selection_enabled"`
 	IsEnabled            bool          `json:"isEnabled" xorm:"is_enabled"`
 	AnnotationsEnabled   bool          `json:"annotationsEnabled" xorm:"annotations_enabled"`
-	Share                ShareType     `json:"share" xorm:"share"`
-	Recipients           []EmailDTO    `json:"recipients,omitempty" xorm:"-"`
+	// TemplateVariablesEnabled allows viewers to set the values of custom, constant and interval template variables.
+	TemplateVariablesEnabled bool `json:"templateVariablesEnabled" xorm:"template_variables_enabled"`
+	// QueryVariablesEnabled additionally allows viewers to set the values of query template variables.
+	QueryVariablesEnabled bool       `json:"queryVariablesEnabled" xorm:"query_variables_enabled"`
+	Share                 ShareType  `json:"share" xorm:"share"`
+	Recipients            []EmailDTO `json:"recipients,omitempty" xorm:"-"`
+	// Token is the named access token the public dashboard was found by, nil if it was found by its default access token.
+	Token *PublicDashboardToken `json:"-" xorm:"-"`
 }
 
 type PublicDashboardDTO struct {
-	TimeSelectionEnabled *bool     `json:"timeSelectionEnabled"`
-	IsEnabled            *bool     `json:"isEnabled"`
-	AnnotationsEnabled   *bool     `json:"annotationsEnabled"`
-	Share                ShareType `json:"share"`
+	TimeSelectionEnabled     *bool     `json:"timeSelectionEnabled"`
+	IsEnabled                *bool     `json:"isEnabled"`
+	AnnotationsEnabled       *bool     `json:"annotationsEnabled"`
+	TemplateVariablesEnabled *bool     `json:"templateVariablesEnabled"`
+	QueryVariablesEnabled    *bool     `json:"queryVariablesEnabled"`
+	Share                    ShareType `json:"share"`
 }
 
 type EmailDTO struct {
@@ -150,6 +158,8 @@ type PublicDashboardQueryDTO struct {
 	MaxDataPoints   int64
 	QueryCachingTTL int64
 	TimeRange       TimeRangeDTO
+	// Variables are the values of template variables by the name of the variable.
+	Variables map[string][]string
 }
 
 type AnnotationsQueryDTO struct {
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// MaxTokenNameLength is the size of the name column of the dashboard_public_token table
+const MaxTokenNameLength = 190
+
+// PublicDashboardToken is a named access token of a public dashboard. A public dashboard can have multiple tokens in
+// addition to its default access token, so that each viewer can be given their own link that can be revoked without
+// affecting the others.
+type PublicDashboardToken struct {
+	Uid                string     `json:"uid" xorm:"pk uid"`
+	PublicDashboardUid string     `json:"publicDashboardUid" xorm:"public_dashboard_uid"`
+	OrgId              int64      `json:"-" xorm:"org_id"` // Don't ever marshal orgId to Json
+	Name               string     `json:"name" xorm:"name"`
+	AccessToken        string     `json:"accessToken" xorm:"access_token"`
+	ExpiresAt          *time.Time `json:"expiresAt,omitempty" xorm:"expires_at"`
+	// AllowedReferrers are the hosts of the pages the public dashboard can be embedded in. Any page is allowed if empty.
+	AllowedReferrers *AllowList `json:"allowedReferrers" xorm:"allowed_referrers"`
+	// AllowedIPs are the IP addresses and CIDR ranges the public dashboard can be viewed from. Any IP address is allowed if empty.
+	AllowedIPs *AllowList `json:"allowedIps" xorm:"allowed_ips"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty" xorm:"last_used_at"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty" xorm:"revoked_at"`
+	CreatedBy  int64      `json:"createdBy" xorm:"created_by"`
+	CreatedAt  time.Time  `json:"createdAt" xorm:"created_at"`
+}
+
+func (t PublicDashboardToken) TableName() string {
+	return "dashboard_public_token"
+}
+
+// IsRevoked returns true if the token has been revoked.
+func (t *PublicDashboardToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
+
+// IsExpired returns true if the token has expired at the given time.
+func (t *PublicDashboardToken) IsExpired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// AllowList is a list of allowed values that is stored as JSON.
+type AllowList []string
+
+func (l *AllowList) FromDB(data []byte) error {
+	return json.Unmarshal(data, l)
+}
+
+func (l *AllowList) ToDB() ([]byte, error) {
+	return json.Marshal(l)
+}
+
+// Values returns the values of the allow list, nil if the list is not set.
+func (l *AllowList) Values() []string {
+	if l == nil {
+		return nil
+	}
+	return *l
+}
+
+// DTO for creating an access token in the api
+type CreatePublicDashboardTokenDTO struct {
+	Name             string     `json:"name"`
+	ExpiresAt        *time.Time `json:"expiresAt"`
+	AllowedReferrers []string   `json:"allowedReferrers"`
+	AllowedIPs       []string   `json:"allowedIps"`
+}
+
+// TokenClient is the client that uses an access token of a public dashboard, it is checked against the allow lists of
+// the token.
+type TokenClient struct {
+	// Referrer is the host of the page the request is made from.
+	Referrer string
+	IP       string
+}
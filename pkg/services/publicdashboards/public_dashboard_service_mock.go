@@ -46,6 +46,29 @@ func (_m *FakePublicDashboardService) Create(ctx context.Context, u *user.Signed
 	return r0, r1
 }
 
+// CreateToken provides a mock function with given fields: ctx, u, dashboardUid, publicDashboardUid, dto
+func (_m *FakePublicDashboardService) CreateToken(ctx context.Context, u *user.SignedInUser, dashboardUid string, publicDashboardUid string, dto *models.CreatePublicDashboardTokenDTO) (*models.PublicDashboardToken, error) {
+	ret := _m.Called(ctx, u, dashboardUid, publicDashboardUid, dto)
+
+	var r0 *models.PublicDashboardToken
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, string, string, *models.CreatePublicDashboardTokenDTO) *models.PublicDashboardToken); ok {
+		r0 = rf(ctx, u, dashboardUid, publicDashboardUid, dto)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboardToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *user.SignedInUser, string, string, *models.CreatePublicDashboardTokenDTO) error); ok {
+		r1 = rf(ctx, u, dashboardUid, publicDashboardUid, dto)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Delete provides a mock function with given fields: ctx, uid
 func (_m *FakePublicDashboardService) Delete(ctx context.Context, uid string) error {
 	ret := _m.Called(ctx, uid)
@@ -318,6 +341,29 @@ func (_m *FakePublicDashboardService) FindPublicDashboardAndDashboardByAccessTok
 	return r0, r1, r2
 }
 
+// FindTokens provides a mock function with given fields: ctx, dashboardUid, publicDashboardUid
+func (_m *FakePublicDashboardService) FindTokens(ctx context.Context, dashboardUid string, publicDashboardUid string) ([]*models.PublicDashboardToken, error) {
+	ret := _m.Called(ctx, dashboardUid, publicDashboardUid)
+
+	var r0 []*models.PublicDashboardToken
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []*models.PublicDashboardToken); ok {
+		r0 = rf(ctx, dashboardUid, publicDashboardUid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.PublicDashboardToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, dashboardUid, publicDashboardUid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetMetricRequest provides a mock function with given fields: ctx, dashboard, publicDashboard, panelId, reqDTO
 func (_m *FakePublicDashboardService) GetMetricRequest(ctx context.Context, dashboard *dashboards.Dashboard, publicDashboard *models.PublicDashboard, panelId int64, reqDTO models.PublicDashboardQueryDTO) (dtos.MetricRequest, error) {
 	ret := _m.Called(ctx, dashboard, publicDashboard, panelId, reqDTO)
@@ -425,6 +471,20 @@ func (_m *FakePublicDashboardService) NewPublicDashboardUid(ctx context.Context)
 	return r0, r1
 }
 
+// RevokeToken provides a mock function with given fields: ctx, dashboardUid, publicDashboardUid, tokenUid
+func (_m *FakePublicDashboardService) RevokeToken(ctx context.Context, dashboardUid string, publicDashboardUid string, tokenUid string) error {
+	ret := _m.Called(ctx, dashboardUid, publicDashboardUid, tokenUid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, dashboardUid, publicDashboardUid, tokenUid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, u, dto
 func (_m *FakePublicDashboardService) Update(ctx context.Context, u *user.SignedInUser, dto *models.SavePublicDashboardDTO) (*models.PublicDashboard, error) {
 	ret := _m.Called(ctx, u, dto)
@@ -2,6 +2,7 @@ package publicdashboards
 
 import (
 	"context"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/api/dtos"
@@ -28,6 +29,10 @@ type Service interface {
 	Delete(ctx context.Context, uid string) error
 	DeleteByDashboard(ctx context.Context, dashboard *dashboards.Dashboard) error
 
+	CreateToken(ctx context.Context, u *user.SignedInUser, dashboardUid string, publicDashboardUid string, dto *CreatePublicDashboardTokenDTO) (*PublicDashboardToken, error)
+	FindTokens(ctx context.Context, dashboardUid string, publicDashboardUid string) ([]*PublicDashboardToken, error)
+	RevokeToken(ctx context.Context, dashboardUid string, publicDashboardUid string, tokenUid string) error
+
 	GetMetricRequest(ctx context.Context, dashboard *dashboards.Dashboard, publicDashboard *PublicDashboard, panelId int64, reqDTO PublicDashboardQueryDTO) (dtos.MetricRequest, error)
 	GetQueryDataResponse(ctx context.Context, skipDSCache bool, reqDTO PublicDashboardQueryDTO, panelId int64, accessToken string) (*backend.QueryDataResponse, error)
 	GetOrgIdByAccessToken(ctx context.Context, accessToken string) (int64, error)
@@ -57,6 +62,12 @@ type Store interface {
 	Update(ctx context.Context, cmd SavePublicDashboardCommand) (int64, error)
 	Delete(ctx context.Context, uid string) (int64, error)
 
+	CreateToken(ctx context.Context, token *PublicDashboardToken) error
+	FindToken(ctx context.Context, uid string) (*PublicDashboardToken, error)
+	FindTokens(ctx context.Context, publicDashboardUid string) ([]*PublicDashboardToken, error)
+	RevokeToken(ctx context.Context, uid string, revokedAt time.Time) (int64, error)
+	UpdateTokenLastUsedAt(ctx context.Context, uid string, lastUsedAt time.Time) error
+
 	GetOrgIdByAccessToken(ctx context.Context, accessToken string) (int64, error)
 	FindByDashboardFolder(ctx context.Context, dashboard *dashboards.Dashboard) ([]*PublicDashboard, error)
 	ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error)
@@ -11,8 +11,10 @@ import (
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/caching"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/metric"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -138,11 +140,35 @@ func (pd *PublicDashboardServiceImpl) GetQueryDataResponse(ctx context.Context,
 		return nil, models.ErrPanelQueriesNotFound.Errorf("GetQueryDataResponse: failed to extract queries from panel")
 	}
 
+	// the cache is mandatory for public dashboards, viewers can't skip it
+	alignTimeRange(&metricReq, pd.minQueryCacheTTL())
+	var cached caching.CachedQueryDataResponse
+	if pd.queryCache != nil {
+		cacheReq, err := newQueryCacheRequest(dashboard.OrgID, metricReq)
+		if err != nil {
+			return nil, models.ErrInternalServerError.Errorf("GetQueryDataResponse: failed to build cache request: %w", err)
+		}
+
+		var hit bool
+		hit, cached = pd.queryCache.HandleQueryRequest(ctx, cacheReq)
+		if hit {
+			pd.recordQuery(publicDashboard, metric.QueryStatusCacheHit)
+			return cached.Response, nil
+		}
+	}
+
+	if !pd.allowQuery(publicDashboard) {
+		pd.recordQuery(publicDashboard, metric.QueryStatusRateLimited)
+		return nil, models.ErrTooManyQueries.Errorf("GetQueryDataResponse: query rate limit of public dashboard %s exceeded", publicDashboard.Uid)
+	}
+	pd.recordQuery(publicDashboard, metric.QueryStatusCacheMiss)
+
 	anonymousUser := buildAnonymousUser(ctx, dashboard)
 	res, err := pd.QueryDataService.QueryData(ctx, anonymousUser, skipDSCache, metricReq)
 
 	reqDatasources := metricReq.GetUniqueDatasourceTypes()
 	if err != nil {
+		pd.recordQuery(publicDashboard, metric.QueryStatusError)
 		LogQueryFailure(reqDatasources, pd.log, err)
 		return nil, err
 	}
@@ -150,9 +176,36 @@ func (pd *PublicDashboardServiceImpl) GetQueryDataResponse(ctx context.Context,
 
 	sanitizeMetadataFromQueryData(res)
 
+	if cached.UpdateCacheFn != nil {
+		cached.UpdateCacheFn(ctx, res)
+	}
+
 	return res, nil
 }
 
+// allowQuery reports whether the public dashboard, and its access token if it was found by a named token, are below
+// their query rate limits
+func (pd *PublicDashboardServiceImpl) allowQuery(publicDashboard *models.PublicDashboard) bool {
+	if publicDashboard.Token != nil && !pd.tokenQueryLimiter.Allow(publicDashboard.Token.Uid) {
+		return false
+	}
+	return pd.queryLimiter.Allow(publicDashboard.Uid)
+}
+
+func (pd *PublicDashboardServiceImpl) recordQuery(publicDashboard *models.PublicDashboard, status string) {
+	if pd.metrics != nil {
+		pd.metrics.RecordQuery(publicDashboard.Uid, status)
+	}
+}
+
+// minQueryCacheTTL returns the minimum time query results of public dashboards are cached
+func (pd *PublicDashboardServiceImpl) minQueryCacheTTL() time.Duration {
+	if pd.cfg == nil {
+		return 0
+	}
+	return pd.cfg.PublicDashboards.MinQueryCacheTTL
+}
+
 // buildMetricRequest merges public dashboard parameters with dashboard and returns a metrics request to be sent to query backend
 func (pd *PublicDashboardServiceImpl) buildMetricRequest(dashboard *dashboards.Dashboard, publicDashboard *models.PublicDashboard, panelId int64, reqDTO models.PublicDashboardQueryDTO) (dtos.MetricRequest, error) {
 	// group queries by panel
@@ -162,14 +215,27 @@ func (pd *PublicDashboardServiceImpl) buildMetricRequest(dashboard *dashboards.D
 		return dtos.MetricRequest{}, models.ErrPanelNotFound.Errorf("buildMetricRequest: public dashboard panel not found")
 	}
 
+	vars, err := buildTemplateVariables(dashboard.Data, publicDashboard, reqDTO.Variables)
+	if err != nil {
+		return dtos.MetricRequest{}, err
+	}
+
 	ts := buildTimeSettings(dashboard, reqDTO, publicDashboard)
 
 	// determine safe resolution to query data at
 	safeInterval, safeResolution := pd.getSafeIntervalAndMaxDataPoints(reqDTO, ts)
+	// results of public dashboards are cached at least for the minimum TTL
+	queryCachingTTL := reqDTO.QueryCachingTTL
+	if minTTL := pd.minQueryCacheTTL().Milliseconds(); queryCachingTTL < minTTL {
+		queryCachingTTL = minTTL
+	}
 	for i := range queries {
+		if vars != nil {
+			vars.InterpolateJSON(queries[i])
+		}
 		queries[i].Set("intervalMs", safeInterval)
 		queries[i].Set("maxDataPoints", safeResolution)
-		queries[i].Set("queryCachingTTL", reqDTO.QueryCachingTTL)
+		queries[i].Set("queryCachingTTL", queryCachingTTL)
 	}
 
 	return dtos.MetricRequest{
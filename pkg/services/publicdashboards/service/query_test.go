@@ -9,6 +9,7 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -21,6 +22,7 @@ import (
 	. "github.com/grafana/grafana/pkg/services/publicdashboards"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/database"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/internal"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/metric"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/services/quota/quotatest"
@@ -31,6 +33,8 @@ import (
 	"github.com/grafana/grafana/pkg/tsdb/legacydata"
 	"github.com/grafana/grafana/pkg/util"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -739,6 +743,101 @@ func TestGetQueryDataResponse(t *testing.T) {
 	})
 }
 
+func TestGetQueryDataResponseCachingAndRateLimits(t *testing.T) {
+	dashboard := &dashboards.Dashboard{UID: "dash", OrgID: 1, Data: simplejson.NewFromAny(map[string]interface{}{
+		"time": map[string]interface{}{"from": "now-1h", "to": "now"},
+		"panels": []interface{}{
+			map[string]interface{}{
+				"id":         1,
+				"datasource": map[string]interface{}{"uid": "ds1", "type": "prometheus"},
+				"targets":    []interface{}{map[string]interface{}{"refId": "A", "expr": "up"}},
+			},
+			map[string]interface{}{
+				"id":         2,
+				"datasource": map[string]interface{}{"uid": "ds1", "type": "prometheus"},
+				"targets":    []interface{}{map[string]interface{}{"refId": "A", "expr": "down"}},
+			},
+			map[string]interface{}{
+				"id":         3,
+				"datasource": map[string]interface{}{"uid": "ds1", "type": "prometheus"},
+				"targets":    []interface{}{map[string]interface{}{"refId": "A", "expr": "sideways"}},
+			},
+		},
+	})}
+	queryDto := PublicDashboardQueryDTO{IntervalMs: int64(1), MaxDataPoints: int64(1)}
+
+	setup := func(t *testing.T, pubdash *PublicDashboard) (*PublicDashboardServiceImpl, *query.FakeQueryService) {
+		cfg := setting.NewCfg()
+		cfg.PublicDashboards = setting.PublicDashboardsSettings{
+			QueryRateLimit:           1000,
+			QueryRateLimitBurst:      2,
+			TokenQueryRateLimit:      1000,
+			TokenQueryRateLimitBurst: 1,
+			MinQueryCacheTTL:         time.Hour,
+		}
+
+		store := NewFakePublicDashboardStore(t)
+		store.On("FindByAccessToken", mock.Anything, mock.Anything).Return(pubdash, nil)
+		store.On("FindDashboard", mock.Anything, mock.Anything, mock.Anything).Return(dashboard, nil)
+		if pubdash.Token != nil {
+			store.On("UpdateTokenLastUsedAt", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+		}
+
+		metrics, err := metric.ProvideService(store, prometheus.NewRegistry())
+		require.NoError(t, err)
+
+		queryService := query.NewFakeQueryService(t)
+		service := ProvideService(cfg, store, queryService, nil, nil, nil, metrics)
+		service.queryLimiter = newQueryRateLimiter(0.001, cfg.PublicDashboards.QueryRateLimitBurst)
+		service.tokenQueryLimiter = newQueryRateLimiter(0.001, cfg.PublicDashboards.TokenQueryRateLimitBurst)
+		return service, queryService
+	}
+
+	t.Run("answers queries from the cache and enforces the minimum TTL", func(t *testing.T) {
+		pubdash := &PublicDashboard{Uid: "pubdash", DashboardUid: "dash", OrgId: 1, IsEnabled: true}
+		service, queryService := setup(t, pubdash)
+		res := &backend.QueryDataResponse{Responses: backend.Responses{"A": backend.DataResponse{Status: backend.StatusOK}}}
+		queryService.On("QueryData", mock.Anything, mock.Anything, false, mock.MatchedBy(func(req dtos.MetricRequest) bool {
+			return req.Queries[0].Get("queryCachingTTL").MustInt64() == time.Hour.Milliseconds()
+		})).Return(res, nil).Once()
+
+		for i := 0; i < 3; i++ {
+			resp, err := service.GetQueryDataResponse(context.Background(), false, queryDto, 1, "token")
+			require.NoError(t, err)
+			assert.Equal(t, res, resp)
+		}
+
+		assert.Equal(t, 1.0, testutil.ToFloat64(service.metrics.Metrics.QueriesTotal.WithLabelValues("pubdash", metric.QueryStatusCacheMiss)))
+		assert.Equal(t, 2.0, testutil.ToFloat64(service.metrics.Metrics.QueriesTotal.WithLabelValues("pubdash", metric.QueryStatusCacheHit)))
+	})
+
+	t.Run("rate limits queries of public dashboard", func(t *testing.T) {
+		pubdash := &PublicDashboard{Uid: "pubdash", DashboardUid: "dash", OrgId: 1, IsEnabled: true}
+		service, queryService := setup(t, pubdash)
+		queryService.On("QueryData", mock.Anything, mock.Anything, false, mock.Anything).Return(&backend.QueryDataResponse{}, nil).Twice()
+
+		_, err := service.GetQueryDataResponse(context.Background(), false, queryDto, 1, "token")
+		require.NoError(t, err)
+		_, err = service.GetQueryDataResponse(context.Background(), false, queryDto, 2, "token")
+		require.NoError(t, err)
+		_, err = service.GetQueryDataResponse(context.Background(), false, queryDto, 3, "token")
+		assert.ErrorIs(t, err, ErrTooManyQueries)
+
+		assert.Equal(t, 1.0, testutil.ToFloat64(service.metrics.Metrics.QueriesTotal.WithLabelValues("pubdash", metric.QueryStatusRateLimited)))
+	})
+
+	t.Run("rate limits queries of access token", func(t *testing.T) {
+		pubdash := &PublicDashboard{Uid: "pubdash", DashboardUid: "dash", OrgId: 1, IsEnabled: true, Token: &PublicDashboardToken{Uid: "named"}}
+		service, queryService := setup(t, pubdash)
+		queryService.On("QueryData", mock.Anything, mock.Anything, false, mock.Anything).Return(&backend.QueryDataResponse{}, nil).Once()
+
+		_, err := service.GetQueryDataResponse(context.Background(), false, queryDto, 1, "token")
+		require.NoError(t, err)
+		_, err = service.GetQueryDataResponse(context.Background(), false, queryDto, 2, "token")
+		assert.ErrorIs(t, err, ErrTooManyQueries)
+	})
+}
+
 func TestFindAnnotations(t *testing.T) {
 	color := "red"
 	name := "annoName"
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/services/caching"
+)
+
+// queryCache caches the query results of public dashboards in memory, separately from the results of other users.
+// Results are cached for the query caching TTL of the queries, but at least for the minimum TTL. They are stored
+// encoded as JSON and decoded on every hit.
+type queryCache struct {
+	cache  *localcache.CacheService
+	minTTL time.Duration
+}
+
+var _ caching.CachingService = (*queryCache)(nil)
+
+func newQueryCache(minTTL time.Duration) *queryCache {
+	cleanupInterval := 2 * minTTL
+	if cleanupInterval < time.Minute {
+		cleanupInterval = time.Minute
+	}
+	return &queryCache{
+		cache:  localcache.New(minTTL, cleanupInterval),
+		minTTL: minTTL,
+	}
+}
+
+// HandleQueryRequest returns the cached response of the request, or a function to cache the response of the request
+// if there is none. Responses with errors are not cached.
+func (c *queryCache) HandleQueryRequest(ctx context.Context, req *backend.QueryDataRequest) (bool, caching.CachedQueryDataResponse) {
+	key, err := queryCacheKey(req)
+	if err != nil {
+		return false, caching.CachedQueryDataResponse{}
+	}
+
+	if cached, ok := c.cache.Get(key); ok {
+		// every viewer gets its own copy of the response, so that changes to it don't leak to other viewers
+		res := &backend.QueryDataResponse{}
+		if err := json.Unmarshal(cached.([]byte), res); err == nil {
+			return true, caching.CachedQueryDataResponse{Response: res}
+		}
+	}
+
+	ttl := c.ttl(req)
+	if ttl <= 0 {
+		return false, caching.CachedQueryDataResponse{}
+	}
+
+	return false, caching.CachedQueryDataResponse{
+		UpdateCacheFn: func(ctx context.Context, res *backend.QueryDataResponse) {
+			for _, r := range res.Responses {
+				if r.Error != nil {
+					return
+				}
+			}
+			data, err := json.Marshal(res)
+			if err != nil {
+				return
+			}
+			c.cache.Set(key, data, ttl)
+		},
+	}
+}
+
+// HandleResourceRequest never caches, public dashboards don't make resource requests
+func (c *queryCache) HandleResourceRequest(ctx context.Context, req *backend.CallResourceRequest) (bool, caching.CachedResourceDataResponse) {
+	return false, caching.CachedResourceDataResponse{}
+}
+
+// ttl returns the longest query caching TTL of the queries of the request or the minimum TTL if it's longer
+func (c *queryCache) ttl(req *backend.QueryDataRequest) time.Duration {
+	ttl := c.minTTL
+	for _, q := range req.Queries {
+		var query struct {
+			QueryCachingTTL int64 `json:"queryCachingTTL"`
+		}
+		if err := json.Unmarshal(q.JSON, &query); err != nil {
+			continue
+		}
+		if queryTTL := time.Duration(query.QueryCachingTTL) * time.Millisecond; queryTTL > ttl {
+			ttl = queryTTL
+		}
+	}
+	return ttl
+}
+
+// queryCacheKey returns a key identifying the org, time range and queries of a request
+func queryCacheKey(req *backend.QueryDataRequest) (string, error) {
+	type cacheQuery struct {
+		From  int64
+		To    int64
+		Query json.RawMessage
+	}
+
+	queries := make([]cacheQuery, 0, len(req.Queries))
+	for _, q := range req.Queries {
+		queries = append(queries, cacheQuery{From: q.TimeRange.From.UnixMilli(), To: q.TimeRange.To.UnixMilli(), Query: q.JSON})
+	}
+
+	data, err := json.Marshal(struct {
+		OrgID   int64
+		Queries []cacheQuery
+	}{
+		OrgID:   req.PluginContext.OrgID,
+		Queries: queries,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// newQueryCacheRequest returns the request that identifies a metric request of a public dashboard in the cache. The
+// time range of the metric request is in epoch milliseconds.
+func newQueryCacheRequest(orgID int64, metricReq dtos.MetricRequest) (*backend.QueryDataRequest, error) {
+	from, err := strconv.ParseInt(metricReq.From, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	to, err := strconv.ParseInt(metricReq.To, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	timeRange := backend.TimeRange{From: time.UnixMilli(from), To: time.UnixMilli(to)}
+
+	req := &backend.QueryDataRequest{
+		PluginContext: backend.PluginContext{OrgID: orgID},
+		Queries:       make([]backend.DataQuery, 0, len(metricReq.Queries)),
+	}
+
+	for _, query := range metricReq.Queries {
+		data, err := query.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		req.Queries = append(req.Queries, backend.DataQuery{
+			RefID:     query.Get("refId").MustString(),
+			TimeRange: timeRange,
+			JSON:      data,
+		})
+	}
+
+	return req, nil
+}
+
+// alignTimeRange truncates the time range of a metric request to a multiple of the interval, so that the requests of
+// all viewers of a public dashboard within the interval are the same and can be answered from the cache
+func alignTimeRange(metricReq *dtos.MetricRequest, interval time.Duration) {
+	ms := interval.Milliseconds()
+	if ms <= 0 {
+		return
+	}
+	if from, err := strconv.ParseInt(metricReq.From, 10, 64); err == nil {
+		metricReq.From = strconv.FormatInt(from-from%ms, 10)
+	}
+	if to, err := strconv.ParseInt(metricReq.To, 10, 64); err == nil {
+		metricReq.To = strconv.FormatInt(to-to%ms, 10)
+	}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestQueryCache(t *testing.T) {
+	metricReq := dtos.MetricRequest{
+		From: "1672531200000",
+		To:   "1672534800000",
+		Queries: []*simplejson.Json{
+			simplejson.NewFromAny(map[string]interface{}{"refId": "A", "expr": "up"}),
+		},
+	}
+
+	t.Run("caches response of request", func(t *testing.T) {
+		cache := newQueryCache(time.Minute)
+		req, err := newQueryCacheRequest(1, metricReq)
+		require.NoError(t, err)
+
+		hit, cached := cache.HandleQueryRequest(context.Background(), req)
+		assert.False(t, hit)
+		require.NotNil(t, cached.UpdateCacheFn)
+
+		res := &backend.QueryDataResponse{Responses: backend.Responses{"A": backend.DataResponse{
+			Frames: data.Frames{data.NewFrame("up", data.NewField("value", nil, []float64{1}))},
+			Status: backend.StatusOK,
+		}}}
+		cached.UpdateCacheFn(context.Background(), res)
+
+		req, err = newQueryCacheRequest(1, metricReq)
+		require.NoError(t, err)
+		hit, cached = cache.HandleQueryRequest(context.Background(), req)
+		assert.True(t, hit)
+		assert.Equal(t, res, cached.Response)
+
+		// viewers get copies of the cached response
+		cached.Response.Responses["A"].Frames[0].Name = "changed"
+		_, cached = cache.HandleQueryRequest(context.Background(), req)
+		assert.Equal(t, "up", cached.Response.Responses["A"].Frames[0].Name)
+
+		// other orgs don't share the cache
+		req, err = newQueryCacheRequest(2, metricReq)
+		require.NoError(t, err)
+		hit, _ = cache.HandleQueryRequest(context.Background(), req)
+		assert.False(t, hit)
+	})
+
+	t.Run("does not cache response with errors", func(t *testing.T) {
+		cache := newQueryCache(time.Minute)
+		req, err := newQueryCacheRequest(1, metricReq)
+		require.NoError(t, err)
+
+		_, cached := cache.HandleQueryRequest(context.Background(), req)
+		cached.UpdateCacheFn(context.Background(), &backend.QueryDataResponse{Responses: backend.Responses{"A": backend.DataResponse{Error: errors.New("failed")}}})
+
+		hit, _ := cache.HandleQueryRequest(context.Background(), req)
+		assert.False(t, hit)
+	})
+
+	t.Run("does not cache without TTL", func(t *testing.T) {
+		cache := newQueryCache(0)
+		req, err := newQueryCacheRequest(1, metricReq)
+		require.NoError(t, err)
+
+		hit, cached := cache.HandleQueryRequest(context.Background(), req)
+		assert.False(t, hit)
+		assert.Nil(t, cached.UpdateCacheFn)
+	})
+
+	t.Run("uses longest query caching TTL", func(t *testing.T) {
+		cache := newQueryCache(time.Second)
+		query := simplejson.NewFromAny(map[string]interface{}{"refId": "A", "queryCachingTTL": 60000})
+		req, err := newQueryCacheRequest(1, dtos.MetricRequest{From: metricReq.From, To: metricReq.To, Queries: []*simplejson.Json{query}})
+		require.NoError(t, err)
+
+		assert.Equal(t, time.Minute, cache.ttl(req))
+		assert.Equal(t, time.Second, cache.ttl(&backend.QueryDataRequest{}))
+	})
+}
+
+func TestAlignTimeRange(t *testing.T) {
+	metricReq := dtos.MetricRequest{From: "1672531205123", To: "1672534809999"}
+
+	alignTimeRange(&metricReq, 10*time.Second)
+	assert.Equal(t, "1672531200000", metricReq.From)
+	assert.Equal(t, "1672534800000", metricReq.To)
+
+	alignTimeRange(&metricReq, 0)
+	assert.Equal(t, "1672531200000", metricReq.From)
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// queryRateLimiter limits the rate of queries per key, for example per public dashboard or per access token. A nil
+// limiter allows all queries.
+type queryRateLimiter struct {
+	limit rate.Limit
+	burst int
+	// idle is the time after which the limiter of an unused key is full again, so that it can be evicted
+	idle time.Duration
+	// pruneInterval is the minimum time between two evictions of idle limiters
+	pruneInterval time.Duration
+
+	mu        sync.Mutex
+	limiters  map[string]*keyRateLimiter
+	lastPrune time.Time
+}
+
+type keyRateLimiter struct {
+	*rate.Limiter
+	lastUsed time.Time
+}
+
+// newQueryRateLimiter returns a limiter allowing limit queries per second per key with bursts of burst queries, or nil
+// if limit is not positive.
+func newQueryRateLimiter(limit float64, burst int) *queryRateLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	idle := time.Duration(float64(burst) / limit * float64(time.Second))
+	pruneInterval := idle
+	if pruneInterval < time.Minute {
+		pruneInterval = time.Minute
+	}
+	return &queryRateLimiter{
+		limit:         rate.Limit(limit),
+		burst:         burst,
+		idle:          idle,
+		pruneInterval: pruneInterval,
+		limiters:      make(map[string]*keyRateLimiter),
+		lastPrune:     time.Now(),
+	}
+}
+
+// Allow reports whether a query of the key may happen now
+func (l *queryRateLimiter) Allow(key string) bool {
+	if l == nil {
+		return true
+	}
+
+	now := time.Now()
+	l.mu.Lock()
+	if now.Sub(l.lastPrune) >= l.pruneInterval {
+		l.prune(now)
+	}
+	limiter, ok := l.limiters[key]
+	if !ok {
+		limiter = &keyRateLimiter{Limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.limiters[key] = limiter
+	}
+	limiter.lastUsed = now
+	l.mu.Unlock()
+
+	return limiter.AllowN(now, 1)
+}
+
+// prune evicts the limiters of keys that were not used for so long that their limiters are full again. A new limiter
+// of such a key behaves the same as the evicted one.
+func (l *queryRateLimiter) prune(now time.Time) {
+	for key, limiter := range l.limiters {
+		if now.Sub(limiter.lastUsed) >= l.idle {
+			delete(l.limiters, key)
+		}
+	}
+	l.lastPrune = now
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryRateLimiter(t *testing.T) {
+	t.Run("nil limiter allows all queries", func(t *testing.T) {
+		limiter := newQueryRateLimiter(0, 10)
+		assert.Nil(t, limiter)
+		for i := 0; i < 100; i++ {
+			assert.True(t, limiter.Allow("pubdash"))
+		}
+	})
+
+	t.Run("limits queries per key", func(t *testing.T) {
+		limiter := newQueryRateLimiter(0.001, 2)
+		assert.True(t, limiter.Allow("pubdash"))
+		assert.True(t, limiter.Allow("pubdash"))
+		assert.False(t, limiter.Allow("pubdash"))
+
+		assert.True(t, limiter.Allow("other"))
+	})
+
+	t.Run("evicts idle limiters", func(t *testing.T) {
+		limiter := newQueryRateLimiter(1000, 1)
+		limiter.pruneInterval = 0
+		assert.True(t, limiter.Allow("idle"))
+		time.Sleep(5 * time.Millisecond)
+
+		assert.True(t, limiter.Allow("pubdash"))
+		assert.Len(t, limiter.limiters, 1)
+		assert.Contains(t, limiter.limiters, "pubdash")
+	})
+}
@@ -11,8 +11,11 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/caching"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/metric"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
 	"github.com/grafana/grafana/pkg/services/query"
@@ -34,10 +37,18 @@ type PublicDashboardServiceImpl struct {
 	AnnotationsRepo    annotations.Repository
 	ac                 accesscontrol.AccessControl
 	serviceWrapper     publicdashboards.ServiceWrapper
+	metrics            *metric.Service
+	// queryCache caches query results of public dashboards separately from the results of other users
+	queryCache        caching.CachingService
+	queryLimiter      *queryRateLimiter
+	tokenQueryLimiter *queryRateLimiter
 }
 
 var LogPrefix = "publicdashboards.service"
 
+// tokenLastUsedAtPrecision is how often the last usage of a named access token is recorded
+const tokenLastUsedAtPrecision = time.Minute
+
 // Gives us compile time error if the service does not adhere to the contract of
 // the interface
 var _ publicdashboards.Service = (*PublicDashboardServiceImpl)(nil)
@@ -51,6 +62,7 @@ func ProvideService(
 	anno annotations.Repository,
 	ac accesscontrol.AccessControl,
 	serviceWrapper publicdashboards.ServiceWrapper,
+	metrics *metric.Service,
 ) *PublicDashboardServiceImpl {
 	return &PublicDashboardServiceImpl{
 		log:                log.New(LogPrefix),
@@ -61,6 +73,10 @@ func ProvideService(
 		AnnotationsRepo:    anno,
 		ac:                 ac,
 		serviceWrapper:     serviceWrapper,
+		metrics:            metrics,
+		queryCache:         newQueryCache(cfg.PublicDashboards.MinQueryCacheTTL),
+		queryLimiter:       newQueryRateLimiter(cfg.PublicDashboards.QueryRateLimit, cfg.PublicDashboards.QueryRateLimitBurst),
+		tokenQueryLimiter:  newQueryRateLimiter(cfg.PublicDashboards.TokenQueryRateLimit, cfg.PublicDashboards.TokenQueryRateLimitBurst),
 	}
 }
 
@@ -102,9 +118,52 @@ func (pd *PublicDashboardServiceImpl) FindByAccessToken(ctx context.Context, acc
 		return nil, ErrPublicDashboardNotFound.Errorf("FindByAccessToken: Public dashboard not found accessToken: %s", accessToken)
 	}
 
+	if pubdash.Token != nil {
+		if err := pd.checkToken(ctx, pubdash.Token); err != nil {
+			return nil, err
+		}
+	}
+
 	return pubdash, nil
 }
 
+// checkToken asserts that a named access token is usable by the client of the request and records its usage
+func (pd *PublicDashboardServiceImpl) checkToken(ctx context.Context, token *PublicDashboardToken) error {
+	now := time.Now()
+	if err := validation.ValidateToken(token, now); err != nil {
+		return err
+	}
+
+	var trustedProxies []string
+	var grafanaHost string
+	if pd.cfg != nil {
+		trustedProxies = pd.cfg.PublicDashboards.TrustedProxies
+		grafanaHost = validation.ReferrerHost(pd.cfg.AppURL)
+	}
+
+	var client TokenClient
+	if reqCtx := contexthandler.FromContext(ctx); reqCtx != nil && reqCtx.Req != nil {
+		client = TokenClient{Referrer: reqCtx.Req.Referer(), IP: validation.ClientIP(reqCtx.Req, trustedProxies)}
+		// the page of the public dashboard must be opened from an allowed referrer, only its API requests come from Grafana
+		if reqCtx.IsPublicDashboardView {
+			grafanaHost = ""
+		}
+	}
+
+	if err := validation.ValidateTokenClient(token, client, grafanaHost); err != nil {
+		return err
+	}
+
+	// a public dashboard makes many requests at once, the last usage doesn't need to be more precise than this
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > tokenLastUsedAtPrecision {
+		if err := pd.store.UpdateTokenLastUsedAt(ctx, token.Uid, now); err != nil {
+			pd.log.Warn("Failed to update last usage of access token", "uid", token.Uid, "error", err)
+		}
+	}
+
+	return nil
+}
+
 // FindEnabledPublicDashboardAndDashboardByAccessToken Gets public dashboard and a dashboard by access token if public dashboard is enabled
 func (pd *PublicDashboardServiceImpl) FindEnabledPublicDashboardAndDashboardByAccessToken(ctx context.Context, accessToken string) (*PublicDashboard, *dashboards.Dashboard, error) {
 	pubdash, dash, err := pd.FindPublicDashboardAndDashboardByAccessToken(ctx, accessToken)
@@ -249,6 +308,98 @@ func (pd *PublicDashboardServiceImpl) Update(ctx context.Context, u *user.Signed
 	return newPubdash, nil
 }
 
+// CreateToken creates a named access token for a public dashboard
+func (pd *PublicDashboardServiceImpl) CreateToken(ctx context.Context, u *user.SignedInUser, dashboardUid string, publicDashboardUid string, dto *CreatePublicDashboardTokenDTO) (*PublicDashboardToken, error) {
+	if err := validation.ValidatePublicDashboardToken(dto, time.Now()); err != nil {
+		return nil, err
+	}
+
+	pubdash, err := pd.findPublicDashboardOfDashboard(ctx, dashboardUid, publicDashboardUid)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := pd.NewPublicDashboardAccessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token := &PublicDashboardToken{
+		Uid:                util.GenerateShortUID(),
+		PublicDashboardUid: pubdash.Uid,
+		OrgId:              pubdash.OrgId,
+		Name:               dto.Name,
+		AccessToken:        accessToken,
+		ExpiresAt:          dto.ExpiresAt,
+		CreatedBy:          u.UserID,
+		CreatedAt:          time.Now(),
+	}
+	if len(dto.AllowedReferrers) > 0 {
+		referrers := AllowList(dto.AllowedReferrers)
+		token.AllowedReferrers = &referrers
+	}
+	if len(dto.AllowedIPs) > 0 {
+		ips := AllowList(dto.AllowedIPs)
+		token.AllowedIPs = &ips
+	}
+
+	if err := pd.store.CreateToken(ctx, token); err != nil {
+		return nil, ErrInternalServerError.Errorf("CreateToken: failed to create access token: %w", err)
+	}
+
+	return token, nil
+}
+
+// FindTokens returns the named access tokens of a public dashboard
+func (pd *PublicDashboardServiceImpl) FindTokens(ctx context.Context, dashboardUid string, publicDashboardUid string) ([]*PublicDashboardToken, error) {
+	pubdash, err := pd.findPublicDashboardOfDashboard(ctx, dashboardUid, publicDashboardUid)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := pd.store.FindTokens(ctx, pubdash.Uid)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("FindTokens: failed to find access tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// RevokeToken revokes a named access token of a public dashboard, revoking a token twice is not an error
+func (pd *PublicDashboardServiceImpl) RevokeToken(ctx context.Context, dashboardUid string, publicDashboardUid string, tokenUid string) error {
+	pubdash, err := pd.findPublicDashboardOfDashboard(ctx, dashboardUid, publicDashboardUid)
+	if err != nil {
+		return err
+	}
+
+	token, err := pd.store.FindToken(ctx, tokenUid)
+	if err != nil {
+		return ErrInternalServerError.Errorf("RevokeToken: failed to find access token: %w", err)
+	}
+	if token == nil || token.PublicDashboardUid != pubdash.Uid {
+		return ErrTokenNotFound.Errorf("RevokeToken: access token %s not found", tokenUid)
+	}
+
+	if _, err := pd.store.RevokeToken(ctx, token.Uid, time.Now()); err != nil {
+		return ErrInternalServerError.Errorf("RevokeToken: failed to revoke access token: %w", err)
+	}
+
+	return nil
+}
+
+// findPublicDashboardOfDashboard returns the public dashboard by uid if it belongs to the dashboard
+func (pd *PublicDashboardServiceImpl) findPublicDashboardOfDashboard(ctx context.Context, dashboardUid string, publicDashboardUid string) (*PublicDashboard, error) {
+	pubdash, err := pd.Find(ctx, publicDashboardUid)
+	if err != nil {
+		return nil, err
+	}
+	if pubdash == nil || pubdash.DashboardUid != dashboardUid {
+		return nil, ErrPublicDashboardNotFound.Errorf("findPublicDashboardOfDashboard: public dashboard %s not found for dashboard %s", publicDashboardUid, dashboardUid)
+	}
+
+	return pubdash, nil
+}
+
 // NewPublicDashboardUid Generates a unique uid to create a public dashboard. Will make 3 attempts and fail if it cannot find an unused uid
 func (pd *PublicDashboardServiceImpl) NewPublicDashboardUid(ctx context.Context) (string, error) {
 	var uid string
@@ -412,6 +563,8 @@ func (pd *PublicDashboardServiceImpl) newCreatePublicDashboard(ctx context.Conte
 	isEnabled := returnValueOrDefault(dto.PublicDashboard.IsEnabled, false)
 	annotationsEnabled := returnValueOrDefault(dto.PublicDashboard.AnnotationsEnabled, false)
 	timeSelectionEnabled := returnValueOrDefault(dto.PublicDashboard.TimeSelectionEnabled, false)
+	templateVariablesEnabled := returnValueOrDefault(dto.PublicDashboard.TemplateVariablesEnabled, false)
+	queryVariablesEnabled := returnValueOrDefault(dto.PublicDashboard.QueryVariablesEnabled, false)
 
 	share := dto.PublicDashboard.Share
 	if dto.PublicDashboard.Share == "" {
@@ -421,19 +574,21 @@ func (pd *PublicDashboardServiceImpl) newCreatePublicDashboard(ctx context.Conte
 	now := time.Now()
 
 	return &PublicDashboard{
-		Uid:                  uid,
-		DashboardUid:         dto.DashboardUid,
-		OrgId:                dto.OrgID,
-		IsEnabled:            isEnabled,
-		AnnotationsEnabled:   annotationsEnabled,
-		TimeSelectionEnabled: timeSelectionEnabled,
-		TimeSettings:         &TimeSettings{},
-		Share:                share,
-		CreatedBy:            dto.UserId,
-		CreatedAt:            now,
-		UpdatedBy:            dto.UserId,
-		UpdatedAt:            now,
-		AccessToken:          accessToken,
+		Uid:                      uid,
+		DashboardUid:             dto.DashboardUid,
+		OrgId:                    dto.OrgID,
+		IsEnabled:                isEnabled,
+		AnnotationsEnabled:       annotationsEnabled,
+		TimeSelectionEnabled:     timeSelectionEnabled,
+		TemplateVariablesEnabled: templateVariablesEnabled,
+		QueryVariablesEnabled:    queryVariablesEnabled,
+		TimeSettings:             &TimeSettings{},
+		Share:                    share,
+		CreatedBy:                dto.UserId,
+		CreatedAt:                now,
+		UpdatedBy:                dto.UserId,
+		UpdatedAt:                now,
+		AccessToken:              accessToken,
 	}, nil
 }
 
@@ -442,6 +597,8 @@ func newUpdatePublicDashboard(dto *SavePublicDashboardDTO, pd *PublicDashboard)
 	timeSelectionEnabled := returnValueOrDefault(pubdashDTO.TimeSelectionEnabled, pd.TimeSelectionEnabled)
 	isEnabled := returnValueOrDefault(pubdashDTO.IsEnabled, pd.IsEnabled)
 	annotationsEnabled := returnValueOrDefault(pubdashDTO.AnnotationsEnabled, pd.AnnotationsEnabled)
+	templateVariablesEnabled := returnValueOrDefault(pubdashDTO.TemplateVariablesEnabled, pd.TemplateVariablesEnabled)
+	queryVariablesEnabled := returnValueOrDefault(pubdashDTO.QueryVariablesEnabled, pd.QueryVariablesEnabled)
 
 	share := pubdashDTO.Share
 	if pubdashDTO.Share == "" {
@@ -449,14 +606,16 @@ func newUpdatePublicDashboard(dto *SavePublicDashboardDTO, pd *PublicDashboard)
 	}
 
 	return &PublicDashboard{
-		Uid:                  pd.Uid,
-		IsEnabled:            isEnabled,
-		AnnotationsEnabled:   annotationsEnabled,
-		TimeSelectionEnabled: timeSelectionEnabled,
-		TimeSettings:         pd.TimeSettings,
-		Share:                share,
-		UpdatedBy:            dto.UserId,
-		UpdatedAt:            time.Now(),
+		Uid:                      pd.Uid,
+		IsEnabled:                isEnabled,
+		AnnotationsEnabled:       annotationsEnabled,
+		TimeSelectionEnabled:     timeSelectionEnabled,
+		TemplateVariablesEnabled: templateVariablesEnabled,
+		QueryVariablesEnabled:    queryVariablesEnabled,
+		TimeSettings:             pd.TimeSettings,
+		Share:                    share,
+		UpdatedBy:                dto.UserId,
+		UpdatedAt:                time.Now(),
 	}
 }
 
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -18,6 +20,8 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	dashboardsDB "github.com/grafana/grafana/pkg/services/dashboards/database"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -31,6 +35,7 @@ import (
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
 )
 
 var timeSettings = &TimeSettings{From: "now-12h", To: "now"}
@@ -1295,3 +1300,195 @@ func insertTestDashboard(t *testing.T, dashboardStore dashboards.Store, title st
 	dash.Data.Set("uid", dash.UID)
 	return dash
 }
+
+func TestFindByAccessTokenWithNamedToken(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	ips := AllowList{"10.0.0.1"}
+
+	testCases := []struct {
+		Name    string
+		Token   *PublicDashboardToken
+		ErrResp error
+	}{
+		{Name: "returns public dashboard", Token: &PublicDashboardToken{Uid: "token", ExpiresAt: &future}},
+		{Name: "returns ErrTokenExpired", Token: &PublicDashboardToken{Uid: "token", ExpiresAt: &past}, ErrResp: ErrTokenExpired},
+		{Name: "returns ErrTokenRevoked", Token: &PublicDashboardToken{Uid: "token", RevokedAt: &past}, ErrResp: ErrTokenRevoked},
+		{Name: "returns ErrTokenNotAllowed", Token: &PublicDashboardToken{Uid: "token", AllowedIPs: &ips}, ErrResp: ErrTokenNotAllowed},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			store := NewFakePublicDashboardStore(t)
+			service := &PublicDashboardServiceImpl{
+				log:   log.New("test.logger"),
+				cfg:   setting.NewCfg(),
+				store: store,
+			}
+
+			pubdash := &PublicDashboard{Uid: "pubdash", AccessToken: "abcdToken", Token: test.Token}
+			store.On("FindByAccessToken", mock.Anything, "namedToken").Return(pubdash, nil)
+			if test.ErrResp == nil {
+				store.On("UpdateTokenLastUsedAt", mock.Anything, "token", mock.Anything).Return(nil)
+			}
+
+			pd, err := service.FindByAccessToken(context.Background(), "namedToken")
+			if test.ErrResp != nil {
+				assert.ErrorIs(t, err, test.ErrResp)
+				assert.Nil(t, pd)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, pubdash, pd)
+			}
+		})
+	}
+
+	t.Run("does not record usage more than once per minute", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		service := &PublicDashboardServiceImpl{
+			log:   log.New("test.logger"),
+			cfg:   setting.NewCfg(),
+			store: store,
+		}
+
+		lastUsedAt := time.Now().Add(-time.Second)
+		pubdash := &PublicDashboard{Uid: "pubdash", Token: &PublicDashboardToken{Uid: "token", LastUsedAt: &lastUsedAt}}
+		store.On("FindByAccessToken", mock.Anything, "namedToken").Return(pubdash, nil)
+
+		_, err := service.FindByAccessToken(context.Background(), "namedToken")
+		require.NoError(t, err)
+		store.AssertNotCalled(t, "UpdateTokenLastUsedAt", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestFindByAccessTokenWithNamedTokenClient(t *testing.T) {
+	ips := AllowList{"10.0.0.1"}
+	referrers := AllowList{"example.com"}
+
+	testCases := []struct {
+		Name           string
+		Token          *PublicDashboardToken
+		RemoteAddr     string
+		Headers        map[string]string
+		PublicView     bool
+		TrustedProxies []string
+		ErrResp        error
+	}{
+		{Name: "allows IP address of the connection", Token: &PublicDashboardToken{Uid: "token", AllowedIPs: &ips}, RemoteAddr: "10.0.0.1:5000"},
+		{Name: "rejects spoofed X-Forwarded-For", Token: &PublicDashboardToken{Uid: "token", AllowedIPs: &ips}, RemoteAddr: "192.168.1.20:5000", Headers: map[string]string{"X-Forwarded-For": "10.0.0.1"}, ErrResp: ErrTokenNotAllowed},
+		{Name: "rejects spoofed X-Real-IP", Token: &PublicDashboardToken{Uid: "token", AllowedIPs: &ips}, RemoteAddr: "192.168.1.20:5000", Headers: map[string]string{"X-Real-IP": "10.0.0.1"}, ErrResp: ErrTokenNotAllowed},
+		{Name: "allows X-Forwarded-For of trusted proxy", Token: &PublicDashboardToken{Uid: "token", AllowedIPs: &ips}, RemoteAddr: "192.168.1.20:5000", Headers: map[string]string{"X-Forwarded-For": "10.0.0.1"}, TrustedProxies: []string{"192.168.1.0/24"}},
+		{Name: "allows API request of public dashboard from Grafana", Token: &PublicDashboardToken{Uid: "token", AllowedReferrers: &referrers}, RemoteAddr: "10.0.0.1:5000", Headers: map[string]string{"Referer": "http://localhost:3000/public-dashboards/abcdToken"}},
+		{Name: "rejects page of public dashboard opened from Grafana", Token: &PublicDashboardToken{Uid: "token", AllowedReferrers: &referrers}, RemoteAddr: "10.0.0.1:5000", Headers: map[string]string{"Referer": "http://localhost:3000/d/dashboard"}, PublicView: true, ErrResp: ErrTokenNotAllowed},
+		{Name: "allows page of public dashboard opened from allowed referrer", Token: &PublicDashboardToken{Uid: "token", AllowedReferrers: &referrers}, RemoteAddr: "10.0.0.1:5000", Headers: map[string]string{"Referer": "https://example.com/page"}, PublicView: true},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			store := NewFakePublicDashboardStore(t)
+			cfg := setting.NewCfg()
+			cfg.AppURL = "http://localhost:3000/"
+			cfg.PublicDashboards.TrustedProxies = test.TrustedProxies
+			service := &PublicDashboardServiceImpl{
+				log:   log.New("test.logger"),
+				cfg:   cfg,
+				store: store,
+			}
+
+			pubdash := &PublicDashboard{Uid: "pubdash", AccessToken: "abcdToken", Token: test.Token}
+			store.On("FindByAccessToken", mock.Anything, "namedToken").Return(pubdash, nil)
+			if test.ErrResp == nil {
+				store.On("UpdateTokenLastUsedAt", mock.Anything, "token", mock.Anything).Return(nil)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = test.RemoteAddr
+			for k, v := range test.Headers {
+				req.Header.Set(k, v)
+			}
+			reqCtx := &contextmodel.ReqContext{Context: &web.Context{Req: req}, IsPublicDashboardView: test.PublicView}
+			ctx := ctxkey.Set(context.Background(), reqCtx)
+
+			pd, err := service.FindByAccessToken(ctx, "namedToken")
+			if test.ErrResp != nil {
+				assert.ErrorIs(t, err, test.ErrResp)
+				assert.Nil(t, pd)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, pubdash, pd)
+			}
+		})
+	}
+}
+
+func TestCreateToken(t *testing.T) {
+	pubdash := &PublicDashboard{Uid: "pubdash", DashboardUid: "dashboard", OrgId: 1}
+
+	t.Run("creates token", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), store: store}
+
+		store.On("Find", mock.Anything, "pubdash").Return(pubdash, nil)
+		store.On("FindByAccessToken", mock.Anything, mock.Anything).Return(nil, nil)
+		store.On("CreateToken", mock.Anything, mock.Anything).Return(nil)
+
+		dto := &CreatePublicDashboardTokenDTO{Name: "viewer", AllowedIPs: []string{"10.0.0.0/8"}}
+		token, err := service.CreateToken(context.Background(), SignedInUser, "dashboard", "pubdash", dto)
+		require.NoError(t, err)
+
+		assert.NotEmpty(t, token.Uid)
+		assert.True(t, validation.IsValidAccessToken(token.AccessToken))
+		assert.Equal(t, "pubdash", token.PublicDashboardUid)
+		assert.Equal(t, int64(1), token.OrgId)
+		assert.Equal(t, "viewer", token.Name)
+		assert.Equal(t, SignedInUser.UserID, token.CreatedBy)
+		assert.Nil(t, token.AllowedReferrers)
+		assert.Equal(t, []string{"10.0.0.0/8"}, token.AllowedIPs.Values())
+		store.AssertCalled(t, "CreateToken", mock.Anything, token)
+	})
+
+	t.Run("returns ErrInvalidToken when settings are invalid", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), store: store}
+
+		_, err := service.CreateToken(context.Background(), SignedInUser, "dashboard", "pubdash", &CreatePublicDashboardTokenDTO{})
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("returns ErrPublicDashboardNotFound when public dashboard is of another dashboard", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), store: store}
+
+		store.On("Find", mock.Anything, "pubdash").Return(pubdash, nil)
+
+		_, err := service.CreateToken(context.Background(), SignedInUser, "other", "pubdash", &CreatePublicDashboardTokenDTO{Name: "viewer"})
+		assert.ErrorIs(t, err, ErrPublicDashboardNotFound)
+	})
+}
+
+func TestRevokeToken(t *testing.T) {
+	pubdash := &PublicDashboard{Uid: "pubdash", DashboardUid: "dashboard", OrgId: 1}
+
+	t.Run("revokes token", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), store: store}
+
+		store.On("Find", mock.Anything, "pubdash").Return(pubdash, nil)
+		store.On("FindToken", mock.Anything, "token").Return(&PublicDashboardToken{Uid: "token", PublicDashboardUid: "pubdash"}, nil)
+		store.On("RevokeToken", mock.Anything, "token", mock.Anything).Return(int64(1), nil)
+
+		err := service.RevokeToken(context.Background(), "dashboard", "pubdash", "token")
+		require.NoError(t, err)
+	})
+
+	t.Run("returns ErrTokenNotFound when token is of another public dashboard", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		service := &PublicDashboardServiceImpl{log: log.New("test.logger"), store: store}
+
+		store.On("Find", mock.Anything, "pubdash").Return(pubdash, nil)
+		store.On("FindToken", mock.Anything, "token").Return(&PublicDashboardToken{Uid: "token", PublicDashboardUid: "other"}, nil)
+
+		err := service.RevokeToken(context.Background(), "dashboard", "pubdash", "token")
+		assert.ErrorIs(t, err, ErrTokenNotFound)
+	})
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/components/templating"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+const (
+	allVariableValue = "$__all"
+
+	// maxQueryVariableOptions is the maximum number of options of a query variable whose value can be set by viewers
+	// of a public dashboard. The options of query variables are only validated against the options saved in the
+	// dashboard, variables with more options cannot be set.
+	maxQueryVariableOptions = 100
+)
+
+// settableVariableTypes are the types of template variables whose value can be set by viewers of a public dashboard.
+var settableVariableTypes = map[string]bool{
+	"custom":   true,
+	"constant": true,
+	"interval": true,
+}
+
+// buildTemplateVariables returns the values of the template variables of the dashboard that are used to interpolate
+// the queries of a public dashboard. The values saved in the dashboard are used unless the requested values are one of
+// the options of the variable. Returns nil if template variables are not enabled, the queries are not interpolated then.
+func buildTemplateVariables(dashboard *simplejson.Json, pd *PublicDashboard, requested map[string][]string) (templating.Variables, error) {
+	if !pd.TemplateVariablesEnabled {
+		if len(requested) > 0 {
+			return nil, ErrInvalidTemplateVariables.Errorf("buildTemplateVariables: template variables are not enabled")
+		}
+		return nil, nil
+	}
+	vars := templating.FromDashboard(dashboard)
+	if len(requested) == 0 {
+		return vars, nil
+	}
+
+	variables := make(map[string]*simplejson.Json)
+	for _, item := range dashboard.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(item)
+		variables[variable.Get("name").MustString()] = variable
+	}
+
+	overrides := make(templating.Variables, len(requested))
+	for name, values := range requested {
+		variable, ok := variables[name]
+		if !ok {
+			return nil, ErrInvalidTemplateVariables.Errorf("buildTemplateVariables: variable %s does not exist", name)
+		}
+		resolved, err := validateVariableValues(variable, pd, values)
+		if err != nil {
+			return nil, err
+		}
+		overrides[name] = resolved
+	}
+
+	return vars.Merge(overrides), nil
+}
+
+// validateVariableValues checks that the values are options of the variable and returns the values to interpolate.
+func validateVariableValues(variable *simplejson.Json, pd *PublicDashboard, values []string) ([]string, error) {
+	name := variable.Get("name").MustString()
+	variableType := variable.Get("type").MustString()
+	if !settableVariableTypes[variableType] && !(variableType == "query" && pd.QueryVariablesEnabled) {
+		return nil, ErrInvalidTemplateVariables.Errorf("validateVariableValues: variable %s of type %s cannot be set", name, variableType)
+	}
+	if len(values) == 0 {
+		return nil, ErrInvalidTemplateVariables.Errorf("validateVariableValues: no value for variable %s", name)
+	}
+	if len(values) > 1 && !variable.Get("multi").MustBool() {
+		return nil, ErrInvalidTemplateVariables.Errorf("validateVariableValues: variable %s does not allow multiple values", name)
+	}
+
+	options := variableOptions(variable)
+	if variableType == "query" && (len(options) == 0 || len(options) > maxQueryVariableOptions) {
+		return nil, ErrInvalidTemplateVariables.Errorf("validateVariableValues: variable %s has %d options, between 1 and %d are supported", name, len(options), maxQueryVariableOptions)
+	}
+
+	if len(values) == 1 && values[0] == allVariableValue {
+		if !variable.Get("includeAll").MustBool() {
+			return nil, ErrInvalidTemplateVariables.Errorf("validateVariableValues: variable %s does not allow all values", name)
+		}
+		if allValue := variable.Get("allValue").MustString(); allValue != "" {
+			return []string{allValue}, nil
+		}
+		return options, nil
+	}
+
+	permitted := make(map[string]bool, len(options))
+	for _, option := range options {
+		permitted[option] = true
+	}
+	for _, value := range values {
+		if !permitted[value] {
+			return nil, ErrInvalidTemplateVariables.Errorf("validateVariableValues: %q is not an option of variable %s", value, name)
+		}
+	}
+	return values, nil
+}
+
+// variableOptions returns the values of the options of the variable saved in the dashboard. The options of custom and
+// interval variables are parsed from their query if they are not saved.
+func variableOptions(variable *simplejson.Json) []string {
+	var options []string
+	for _, item := range variable.Get("options").MustArray() {
+		value, err := simplejson.NewFromAny(item).Get("value").String()
+		if err == nil && value != allVariableValue {
+			options = append(options, value)
+		}
+	}
+	if len(options) > 0 {
+		return options
+	}
+
+	query := variable.Get("query").MustString()
+	switch variable.Get("type").MustString() {
+	case "constant":
+		return []string{query}
+	case "custom", "interval":
+		for _, option := range strings.Split(query, ",") {
+			option = strings.TrimSpace(option)
+			// custom options can be defined as "text : value"
+			if _, value, ok := strings.Cut(option, " : "); ok {
+				option = strings.TrimSpace(value)
+			}
+			if option != "" {
+				options = append(options, option)
+			}
+		}
+	}
+	return options
+}
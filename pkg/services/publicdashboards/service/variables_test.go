@@ -0,0 +1,81 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/components/templating"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+func TestBuildTemplateVariables(t *testing.T) {
+	dashboard, err := simplejson.NewJson([]byte(`{
+		"templating": {
+			"list": [
+				{"name": "region", "type": "custom", "query": "eu : eu-west-1, us : us-east-1, ap-south-1", "multi": true, "includeAll": true, "current": {"value": "eu-west-1"}},
+				{"name": "env", "type": "constant", "query": "prod", "current": {"value": "prod"}},
+				{"name": "interval", "type": "interval", "options": [{"value": "1m"}, {"value": "5m"}], "current": {"value": "1m"}},
+				{"name": "service", "type": "query", "options": [{"value": "$__all"}, {"value": "api"}, {"value": "web"}], "includeAll": true, "allValue": ".*", "current": {"value": "api"}},
+				{"name": "host", "type": "query", "options": [], "current": {"value": "host-1"}},
+				{"name": "filter", "type": "textbox", "query": "", "current": {"value": ""}}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+	enabled := &PublicDashboard{TemplateVariablesEnabled: true, QueryVariablesEnabled: true}
+
+	t.Run("does not interpolate queries if template variables are not enabled", func(t *testing.T) {
+		vars, err := buildTemplateVariables(dashboard, &PublicDashboard{}, nil)
+		require.NoError(t, err)
+		require.Nil(t, vars)
+	})
+
+	t.Run("uses the values saved in the dashboard if no variables are requested", func(t *testing.T) {
+		vars, err := buildTemplateVariables(dashboard, &PublicDashboard{TemplateVariablesEnabled: true}, nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"eu-west-1"}, vars["region"])
+		require.Equal(t, []string{"api"}, vars["service"])
+	})
+
+	t.Run("uses the requested values if they are options of the variables", func(t *testing.T) {
+		vars, err := buildTemplateVariables(dashboard, enabled, map[string][]string{
+			"region":   {"us-east-1", "ap-south-1"},
+			"env":      {"prod"},
+			"interval": {"5m"},
+			"service":  {"$__all"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, templating.Variables{
+			"region":   {"us-east-1", "ap-south-1"},
+			"env":      {"prod"},
+			"interval": {"5m"},
+			"service":  {".*"},
+			"host":     {"host-1"},
+			"filter":   {""},
+		}, vars)
+	})
+
+	testCases := []struct {
+		desc      string
+		pd        *PublicDashboard
+		requested map[string][]string
+	}{
+		{desc: "template variables are not enabled", pd: &PublicDashboard{}, requested: map[string][]string{"region": {"us-east-1"}}},
+		{desc: "variable does not exist", pd: enabled, requested: map[string][]string{"missing": {"a"}}},
+		{desc: "value is not an option", pd: enabled, requested: map[string][]string{"region": {"eu-central-1"}}},
+		{desc: "constant is changed", pd: enabled, requested: map[string][]string{"env": {"dev"}}},
+		{desc: "multiple values for single value variable", pd: enabled, requested: map[string][]string{"interval": {"1m", "5m"}}},
+		{desc: "no value", pd: enabled, requested: map[string][]string{"region": {}}},
+		{desc: "type cannot be set", pd: enabled, requested: map[string][]string{"filter": {"anything"}}},
+		{desc: "query variables are not enabled", pd: &PublicDashboard{TemplateVariablesEnabled: true}, requested: map[string][]string{"service": {"web"}}},
+		{desc: "query variable has no saved options", pd: enabled, requested: map[string][]string{"host": {"host-1"}}},
+	}
+	for _, tc := range testCases {
+		t.Run("returns an error if "+tc.desc, func(t *testing.T) {
+			_, err := buildTemplateVariables(dashboard, tc.pd, tc.requested)
+			require.ErrorIs(t, err, ErrInvalidTemplateVariables)
+		})
+	}
+}
@@ -1,6 +1,13 @@
 package validation
 
 import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
 	"github.com/google/uuid"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/tsdb/legacydata"
@@ -25,6 +32,16 @@ func ValidateQueryPublicDashboardRequest(req PublicDashboardQueryDTO, pd *Public
 		return ErrInvalidMaxDataPoints.Errorf("ValidateQueryPublicDashboardRequest: maxDataPoints should be greater than 0")
 	}
 
+	if pd.Token != nil {
+		if err := ValidateToken(pd.Token, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	if len(req.Variables) > 0 && !pd.TemplateVariablesEnabled {
+		return ErrInvalidTemplateVariables.Errorf("ValidateQueryPublicDashboardRequest: template variables are not enabled")
+	}
+
 	if pd.TimeSelectionEnabled {
 		timeRange := legacydata.NewDataTimeRange(req.TimeRange.From, req.TimeRange.To)
 
@@ -41,6 +58,150 @@ func ValidateQueryPublicDashboardRequest(req PublicDashboardQueryDTO, pd *Public
 	return nil
 }
 
+// ValidatePublicDashboardToken validates the settings of a new access token
+func ValidatePublicDashboardToken(dto *CreatePublicDashboardTokenDTO, now time.Time) error {
+	if strings.TrimSpace(dto.Name) == "" {
+		return ErrInvalidToken.Errorf("ValidatePublicDashboardToken: name is required")
+	}
+
+	if utf8.RuneCountInString(dto.Name) > MaxTokenNameLength {
+		return ErrInvalidToken.Errorf("ValidatePublicDashboardToken: name must be at most %d characters", MaxTokenNameLength)
+	}
+
+	if dto.ExpiresAt != nil && !dto.ExpiresAt.After(now) {
+		return ErrInvalidToken.Errorf("ValidatePublicDashboardToken: expiresAt must be in the future")
+	}
+
+	for _, referrer := range dto.AllowedReferrers {
+		if ReferrerHost(referrer) == "" {
+			return ErrInvalidToken.Errorf("ValidatePublicDashboardToken: invalid referrer %q", referrer)
+		}
+	}
+
+	for _, ip := range dto.AllowedIPs {
+		if net.ParseIP(ip) == nil {
+			if _, _, err := net.ParseCIDR(ip); err != nil {
+				return ErrInvalidToken.Errorf("ValidatePublicDashboardToken: invalid IP address or CIDR range %q", ip)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateToken asserts that an access token is neither revoked nor expired
+func ValidateToken(token *PublicDashboardToken, now time.Time) error {
+	if token.IsRevoked() {
+		return ErrTokenRevoked.Errorf("ValidateToken: access token %s is revoked", token.Uid)
+	}
+
+	if token.IsExpired(now) {
+		return ErrTokenExpired.Errorf("ValidateToken: access token %s expired at %s", token.Uid, token.ExpiresAt)
+	}
+
+	return nil
+}
+
+// ValidateTokenClient asserts that the client is allowed by the allow lists of an access token. Requests from pages
+// of Grafana itself, identified by grafanaHost, are allowed by the referrer allow list because the pages of public
+// dashboards make requests to the API. Pass an empty grafanaHost to check the page of a public dashboard itself.
+// The referrer is set by the client, so the referrer allow list only keeps other sites from embedding the public
+// dashboard, and clients other than browsers can use the token from anywhere the IP allow list allows.
+func ValidateTokenClient(token *PublicDashboardToken, client TokenClient, grafanaHost string) error {
+	if referrers := token.AllowedReferrers.Values(); len(referrers) > 0 {
+		host := ReferrerHost(client.Referrer)
+		if host == "" || (!strings.EqualFold(host, grafanaHost) && !matchesHost(referrers, host)) {
+			return ErrTokenNotAllowed.Errorf("ValidateTokenClient: referrer %q is not allowed for access token %s", client.Referrer, token.Uid)
+		}
+	}
+
+	if ips := token.AllowedIPs.Values(); len(ips) > 0 {
+		if !matchesIP(ips, net.ParseIP(client.IP)) {
+			return ErrTokenNotAllowed.Errorf("ValidateTokenClient: IP address %q is not allowed for access token %s", client.IP, token.Uid)
+		}
+	}
+
+	return nil
+}
+
+// ClientIP returns the IP address of the client of the request. The X-Real-IP and X-Forwarded-For headers are used only
+// if the request comes from one of the trusted proxies, because any client can set them. Proxies append the address of
+// their client to X-Forwarded-For, so the last address that is not a trusted proxy is the client.
+func ClientIP(req *http.Request, trustedProxies []string) string {
+	peer := req.RemoteAddr
+	if host, _, err := net.SplitHostPort(peer); err == nil {
+		peer = host
+	}
+	if !matchesIP(trustedProxies, net.ParseIP(peer)) {
+		return peer
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(req.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	forwarded := strings.Split(strings.Join(req.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if ip == nil {
+			break
+		}
+		if !matchesIP(trustedProxies, ip) {
+			return ip.String()
+		}
+	}
+	return peer
+}
+
+// ReferrerHost returns the host of a referrer, which can be a URL or a host. Returns an empty string if the referrer
+// is invalid.
+func ReferrerHost(referrer string) string {
+	if referrer == "" {
+		return ""
+	}
+	if !strings.Contains(referrer, "://") {
+		referrer = "//" + referrer
+	}
+	u, err := url.Parse(referrer)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
+
+// matchesHost returns true if the host matches one of the allowed hosts. Allowed hosts can start with a wildcard to
+// allow all subdomains, for example *.example.com.
+func matchesHost(allowed []string, host string) bool {
+	for _, a := range allowed {
+		allowedHost := ReferrerHost(a)
+		if allowedHost == host {
+			return true
+		}
+		if strings.HasPrefix(allowedHost, "*.") && strings.HasSuffix(host, allowedHost[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesIP returns true if the ip address is one of the allowed IP addresses or in one of the allowed CIDR ranges.
+func matchesIP(allowed []string, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, a := range allowed {
+		if allowedIP := net.ParseIP(a); allowedIP != nil {
+			if allowedIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		if _, ipNet, err := net.ParseCIDR(a); err == nil && ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // IsValidAccessToken asserts that an accessToken is a valid uuid
 func IsValidAccessToken(token string) bool {
 	_, err := uuid.Parse(token)
@@ -1,7 +1,11 @@
 package validation
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/stretchr/testify/assert"
@@ -129,6 +133,28 @@ func TestValidateQueryPublicDashboardRequest(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Returns validation error when template variables are set but not enabled",
+			args: args{
+				req: PublicDashboardQueryDTO{
+					Variables: map[string][]string{"region": {"eu"}},
+				},
+				pd: &PublicDashboard{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Returns no error when template variables are set and enabled",
+			args: args{
+				req: PublicDashboardQueryDTO{
+					Variables: map[string][]string{"region": {"eu"}},
+				},
+				pd: &PublicDashboard{
+					TemplateVariablesEnabled: true,
+				},
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -139,6 +165,133 @@ func TestValidateQueryPublicDashboardRequest(t *testing.T) {
 	}
 }
 
+func TestValidatePublicDashboardToken(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	testCases := []struct {
+		name    string
+		dto     *CreatePublicDashboardTokenDTO
+		wantErr bool
+	}{
+		{name: "valid without restrictions", dto: &CreatePublicDashboardTokenDTO{Name: "viewer"}},
+		{
+			name: "valid with restrictions",
+			dto: &CreatePublicDashboardTokenDTO{
+				Name:             "viewer",
+				ExpiresAt:        &future,
+				AllowedReferrers: []string{"https://example.com/page", "*.example.org"},
+				AllowedIPs:       []string{"10.0.0.1", "192.168.0.0/16", "::1"},
+			},
+		},
+		{name: "missing name", dto: &CreatePublicDashboardTokenDTO{Name: " "}, wantErr: true},
+		{name: "name at the maximum length", dto: &CreatePublicDashboardTokenDTO{Name: strings.Repeat("a", 190)}},
+		{name: "name too long", dto: &CreatePublicDashboardTokenDTO{Name: strings.Repeat("a", 191)}, wantErr: true},
+		{name: "expiry in the past", dto: &CreatePublicDashboardTokenDTO{Name: "viewer", ExpiresAt: &past}, wantErr: true},
+		{name: "invalid referrer", dto: &CreatePublicDashboardTokenDTO{Name: "viewer", AllowedReferrers: []string{"https://"}}, wantErr: true},
+		{name: "invalid IP address", dto: &CreatePublicDashboardTokenDTO{Name: "viewer", AllowedIPs: []string{"10.0.0"}}, wantErr: true},
+		{name: "invalid CIDR range", dto: &CreatePublicDashboardTokenDTO{Name: "viewer", AllowedIPs: []string{"10.0.0.0/33"}}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidatePublicDashboardToken(tc.dto, now)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidToken)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateToken(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	t.Run("valid", func(t *testing.T) {
+		assert.NoError(t, ValidateToken(&PublicDashboardToken{Uid: "abc"}, now))
+		assert.NoError(t, ValidateToken(&PublicDashboardToken{Uid: "abc", ExpiresAt: &future}, now))
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		assert.ErrorIs(t, ValidateToken(&PublicDashboardToken{Uid: "abc", ExpiresAt: &past}, now), ErrTokenExpired)
+		assert.ErrorIs(t, ValidateToken(&PublicDashboardToken{Uid: "abc", ExpiresAt: &now}, now), ErrTokenExpired)
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		assert.ErrorIs(t, ValidateToken(&PublicDashboardToken{Uid: "abc", RevokedAt: &past, ExpiresAt: &future}, now), ErrTokenRevoked)
+	})
+}
+
+func TestValidateTokenClient(t *testing.T) {
+	referrers := AllowList{"https://example.com", "*.example.org"}
+	ips := AllowList{"10.0.0.1", "192.168.0.0/16"}
+	token := &PublicDashboardToken{Uid: "abc", AllowedReferrers: &referrers, AllowedIPs: &ips}
+
+	testCases := []struct {
+		name    string
+		token   *PublicDashboardToken
+		client  TokenClient
+		wantErr bool
+	}{
+		{name: "no restrictions", token: &PublicDashboardToken{Uid: "abc"}, client: TokenClient{}},
+		{name: "allowed referrer and IP", token: token, client: TokenClient{Referrer: "https://example.com/page", IP: "10.0.0.1"}},
+		{name: "referrer matching wildcard", token: token, client: TokenClient{Referrer: "https://dashboards.example.org/", IP: "192.168.1.20"}},
+		{name: "referrer of Grafana", token: token, client: TokenClient{Referrer: "http://grafana.local:3000/public-dashboards/abc", IP: "10.0.0.1"}},
+		{name: "referrer not allowed", token: token, client: TokenClient{Referrer: "https://example.net", IP: "10.0.0.1"}, wantErr: true},
+		{name: "wildcard does not match domain", token: token, client: TokenClient{Referrer: "https://example.org", IP: "10.0.0.1"}, wantErr: true},
+		{name: "missing referrer", token: token, client: TokenClient{IP: "10.0.0.1"}, wantErr: true},
+		{name: "IP not allowed", token: token, client: TokenClient{Referrer: "https://example.com", IP: "10.0.0.2"}, wantErr: true},
+		{name: "missing IP", token: token, client: TokenClient{Referrer: "https://example.com"}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateTokenClient(tc.token, tc.client, "grafana.local:3000")
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrTokenNotAllowed)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	trustedProxies := []string{"10.0.0.1", "172.16.0.0/12"}
+
+	testCases := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{name: "address of the connection", remoteAddr: "192.168.1.20:5000", want: "192.168.1.20"},
+		{name: "IPv6 address of the connection", remoteAddr: "[2001:db8::1]:5000", want: "2001:db8::1"},
+		{name: "spoofed X-Forwarded-For is ignored", remoteAddr: "192.168.1.20:5000", headers: map[string]string{"X-Forwarded-For": "10.0.0.5"}, want: "192.168.1.20"},
+		{name: "spoofed X-Real-IP is ignored", remoteAddr: "192.168.1.20:5000", headers: map[string]string{"X-Real-IP": "10.0.0.5"}, want: "192.168.1.20"},
+		{name: "X-Real-IP of trusted proxy", remoteAddr: "10.0.0.1:5000", headers: map[string]string{"X-Real-IP": "192.168.1.20"}, want: "192.168.1.20"},
+		{name: "X-Forwarded-For of trusted proxy", remoteAddr: "10.0.0.1:5000", headers: map[string]string{"X-Forwarded-For": "192.168.1.20"}, want: "192.168.1.20"},
+		{name: "last untrusted address of X-Forwarded-For", remoteAddr: "10.0.0.1:5000", headers: map[string]string{"X-Forwarded-For": "10.0.0.5, 192.168.1.20, 172.16.0.2"}, want: "192.168.1.20"},
+		{name: "trusted proxy without headers", remoteAddr: "10.0.0.1:5000", want: "10.0.0.1"},
+		{name: "invalid X-Forwarded-For of trusted proxy", remoteAddr: "10.0.0.1:5000", headers: map[string]string{"X-Forwarded-For": "unknown"}, want: "10.0.0.1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			assert.Equal(t, tc.want, ClientIP(req, trustedProxies))
+		})
+	}
+}
+
 func TestValidAccessToken(t *testing.T) {
 	t.Run("true", func(t *testing.T) {
 		uuid := "da82510c2aa64d78a2e87fef36c58e89"
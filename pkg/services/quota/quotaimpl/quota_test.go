@@ -172,6 +172,9 @@ func TestIntegrationQuotaCommandsAndQueries(t *testing.T) {
 	tag, err = quota.NewTag(ngalertmodels.QuotaTargetSrv, ngalertmodels.QuotaTarget, scope)
 	require.NoError(t, err)
 	require.Equal(t, sqlStore.Cfg.Quota.Org.AlertRule, defaultOrgLimits[tag])
+	tag, err = quota.NewTag(ngalertmodels.InstanceQuotaTargetSrv, ngalertmodels.InstanceQuotaTarget, scope)
+	require.NoError(t, err)
+	require.Equal(t, sqlStore.Cfg.Quota.Org.AlertInstance, defaultOrgLimits[tag])
 
 	// fetch default limit/usage for user
 	defaultUserLimits := make(map[quota.Tag]int64)
@@ -234,7 +237,7 @@ func TestIntegrationQuotaCommandsAndQueries(t *testing.T) {
 		t.Run("Should be able to quota list for org", func(t *testing.T) {
 			result, err := quotaService.GetQuotasByScope(context.Background(), quota.OrgScope, o.ID)
 			require.NoError(t, err)
-			require.Len(t, result, 5)
+			require.Len(t, result, 6)
 
 			require.NoError(t, err)
 			for _, res := range result {
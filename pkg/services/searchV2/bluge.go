@@ -33,6 +33,10 @@ const (
 	documentFieldTransformer = "transformer"
 	documentFieldDSUID       = "ds_uid"
 	documentFieldDSType      = "ds_type"
+	documentFieldQuery       = "query"  // raw query text of the panel targets
+	documentFieldMetric      = "metric" // metric names referenced by the panel queries
+	documentFieldLabel       = "label"  // label names referenced by the panel queries
+	documentFieldTable       = "table"  // SQL tables referenced by the panel queries
 	DocumentFieldCreatedAt   = "created_at"
 	DocumentFieldUpdatedAt   = "updated_at"
 )
@@ -168,6 +172,9 @@ func getNonFolderDashboardDoc(dash dashboard, location string) *bluge.Document {
 			SearchTermPositions())
 	}
 
+	// dashboards can be found by everything their panels query
+	addPanelQueryNameFields(doc, dash.summary.Nested...)
+
 	for _, ref := range dash.summary.References {
 		if ref.Family == entity.StandardKindDataSource {
 			if ref.Type != "" {
@@ -233,11 +240,59 @@ func getDashboardPanelDocs(dash dashboard, location string) []*bluge.Document {
 			}
 		}
 
+		for _, query := range getSummaryStrings(panel, "queries") {
+			doc.AddField(bluge.NewTextField(documentFieldQuery, query))
+		}
+		addPanelQueryNameFields(doc, panel)
+
 		docs = append(docs, doc)
 	}
 	return docs
 }
 
+// addPanelQueryNameFields adds the distinct metric, label and table names queried by the panels
+func addPanelQueryNameFields(doc *bluge.Document, panels ...*entity.EntitySummary) {
+	fields := []struct {
+		key   string
+		field string
+	}{
+		{key: "metrics", field: documentFieldMetric},
+		{key: "labels", field: documentFieldLabel},
+		{key: "tables", field: documentFieldTable},
+	}
+	for _, f := range fields {
+		seen := make(map[string]bool)
+		for _, panel := range panels {
+			for _, v := range getSummaryStrings(panel, f.key) {
+				if !seen[v] {
+					seen[v] = true
+					doc.AddField(bluge.NewKeywordField(f.field, v).Aggregatable())
+				}
+			}
+		}
+	}
+}
+
+// getSummaryStrings reads a string list field, which is []interface{} when the summary was decoded from JSON
+func getSummaryStrings(summary *entity.EntitySummary, key string) []string {
+	if summary == nil {
+		return nil
+	}
+	switch v := summary.Fields[key].(type) {
+	case []string:
+		return v
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				values = append(values, str)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
 // Names need to be indexed a few ways to support key features
 func newSearchDocument(uid string, name string, descr string, url string) *bluge.Document {
 	doc := bluge.NewDocument(uid)
@@ -435,6 +490,26 @@ func doSearchQuery(
 		hasConstraints = true
 	}
 
+	// Panel queries
+	if q.Metric != "" {
+		fullQuery.AddMust(bluge.NewTermQuery(q.Metric).SetField(documentFieldMetric))
+		hasConstraints = true
+	}
+	if q.Label != "" {
+		fullQuery.AddMust(bluge.NewTermQuery(q.Label).SetField(documentFieldLabel))
+		hasConstraints = true
+	}
+	if q.Table != "" {
+		fullQuery.AddMust(bluge.NewTermQuery(q.Table).SetField(documentFieldTable))
+		hasConstraints = true
+	}
+	if q.PanelQuery != "" {
+		fullQuery.AddMust(bluge.NewMatchQuery(q.PanelQuery).
+			SetField(documentFieldQuery).
+			SetOperator(bluge.MatchQueryOperatorAnd)) // all terms must match
+		hasConstraints = true
+	}
+
 	// Folder
 	if q.Location != "" {
 		fullQuery.AddMust(bluge.NewTermQuery(q.Location).SetField(documentFieldLocation))
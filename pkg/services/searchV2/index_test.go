@@ -548,6 +548,105 @@ func TestDashboardIndex_Panels(t *testing.T) {
 	})
 }
 
+var dashboardsWithPanelQueries = []dashboard{
+	{
+		id:  1,
+		uid: "1",
+		summary: &entity.EntitySummary{
+			Name: "Service",
+			Nested: []*entity.EntitySummary{
+				newNestedPanelWithQueries(1, 1, "Requests", map[string]interface{}{
+					"queries": []string{`sum(rate(http_requests_total{job="api"}[5m]))`},
+					"metrics": []string{"http_requests_total"},
+					"labels":  []string{"job"},
+				}),
+				newNestedPanelWithQueries(2, 1, "Orders", map[string]interface{}{
+					"queries": []string{"SELECT count(*) FROM orders"},
+					"tables":  []string{"orders"},
+				}),
+			},
+		},
+	},
+	{
+		id:  2,
+		uid: "2",
+		summary: &entity.EntitySummary{
+			Name: "Nodes",
+			Nested: []*entity.EntitySummary{
+				// decoded from JSON
+				newNestedPanelWithQueries(3, 2, "Up", map[string]interface{}{
+					"queries": []interface{}{`up{job="node"}`},
+					"metrics": []interface{}{"up"},
+					"labels":  []interface{}{"job"},
+				}),
+			},
+		},
+	},
+}
+
+func newNestedPanelWithQueries(id, dashId int64, name string, fields map[string]interface{}) *entity.EntitySummary {
+	summary := newNestedPanel(id, dashId, name)
+	summary.Fields = fields
+	return summary
+}
+
+func TestDashboardIndex_PanelQueries(t *testing.T) {
+	index := initTestOrgIndexFromDashes(t, dashboardsWithPanelQueries)
+
+	search := func(t *testing.T, query DashboardQuery) *backend.DataResponse {
+		t.Helper()
+		resp := doSearchQuery(context.Background(), testLogger, index, testAllowAllFilter, query, &NoopQueryExtender{}, "")
+		require.NoError(t, resp.Error)
+		return resp
+	}
+	names := func(t *testing.T, resp *backend.DataResponse) []string {
+		t.Helper()
+		field, idx := resp.Frames[0].FieldByName(documentFieldName)
+		require.NotEqual(t, -1, idx)
+		var values []string
+		for i := 0; i < field.Len(); i++ {
+			values = append(values, field.At(i).(string))
+		}
+		return values
+	}
+
+	t.Run("metric", func(t *testing.T) {
+		resp := search(t, DashboardQuery{Metric: "http_requests_total", Kind: []string{string(entityKindPanel)}})
+		require.Equal(t, []string{"Requests"}, names(t, resp))
+	})
+
+	t.Run("metric on dashboards", func(t *testing.T) {
+		resp := search(t, DashboardQuery{Metric: "up", Kind: []string{string(entityKindDashboard)}})
+		require.Equal(t, []string{"Nodes"}, names(t, resp))
+	})
+
+	t.Run("label", func(t *testing.T) {
+		resp := search(t, DashboardQuery{Label: "job", Kind: []string{string(entityKindPanel)}, Sort: documentFieldName_sort})
+		require.Equal(t, []string{"Requests", "Up"}, names(t, resp))
+	})
+
+	t.Run("table", func(t *testing.T) {
+		resp := search(t, DashboardQuery{Table: "orders"})
+		require.ElementsMatch(t, []string{"Service", "Orders"}, names(t, resp))
+	})
+
+	t.Run("panel query text", func(t *testing.T) {
+		resp := search(t, DashboardQuery{PanelQuery: "count orders"})
+		require.Equal(t, []string{"Orders"}, names(t, resp))
+	})
+
+	t.Run("metric facet", func(t *testing.T) {
+		resp := search(t, DashboardQuery{
+			Kind:  []string{string(entityKindPanel)},
+			Facet: []FacetField{{Field: documentFieldMetric}},
+		})
+		require.Len(t, resp.Frames, 2)
+		facet := resp.Frames[1]
+		require.Equal(t, "Facet: "+documentFieldMetric, facet.Name)
+		require.Equal(t, 2, facet.Rows())
+	})
+}
+
 var punctuationSplitNgramDashboards = []dashboard{
 	{
 		id:  1,
@@ -23,6 +23,10 @@ type DashboardQuery struct {
 	Tags               []string     `json:"tags,omitempty"`
 	Kind               []string     `json:"kind,omitempty"`
 	PanelType          string       `json:"panel_type,omitempty"`
+	Metric             string       `json:"metric,omitempty"`      // metric name used by the panel queries
+	Label              string       `json:"label,omitempty"`       // label name used by the panel queries
+	Table              string       `json:"table,omitempty"`       // SQL table used by the panel queries
+	PanelQuery         string       `json:"panel_query,omitempty"` // text matched against the panel queries
 	UIDs               []string     `json:"uid,omitempty"`
 	Explain            bool         `json:"explain,omitempty"`            // adds details on why document matched
 	WithAllowedActions bool         `json:"withAllowedActions,omitempty"` // adds allowed actions per entity
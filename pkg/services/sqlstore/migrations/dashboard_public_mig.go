@@ -96,4 +96,43 @@ func addPublicDashboardMigration(mg *Migrator) {
 	mg.AddMigration("backfill empty share column fields with default of public", NewRawSQLMigration(
 		"UPDATE dashboard_public SET share='public' WHERE share=''",
 	))
+
+	mg.AddMigration("add template_variables_enabled column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "template_variables_enabled",
+		Type:     DB_Bool,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	mg.AddMigration("add query_variables_enabled column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "query_variables_enabled",
+		Type:     DB_Bool,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	dashboardPublicTokenV1 := Table{
+		Name: "dashboard_public_token",
+		Columns: []*Column{
+			{Name: "uid", Type: DB_NVarchar, Length: 40, IsPrimaryKey: true},
+			{Name: "public_dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "access_token", Type: DB_NVarchar, Length: 32, Nullable: false},
+			{Name: "expires_at", Type: DB_DateTime, Nullable: true},
+			{Name: "allowed_referrers", Type: DB_Text, Nullable: true},
+			{Name: "allowed_ips", Type: DB_Text, Nullable: true},
+			{Name: "last_used_at", Type: DB_DateTime, Nullable: true},
+			{Name: "revoked_at", Type: DB_DateTime, Nullable: true},
+			{Name: "created_by", Type: DB_Int, Nullable: false},
+			{Name: "created_at", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"public_dashboard_uid"}},
+			{Cols: []string{"access_token"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create dashboard public token table v1", NewAddTableMigration(dashboardPublicTokenV1))
+	addTableIndicesMigrations(mg, "v1", dashboardPublicTokenV1)
 }
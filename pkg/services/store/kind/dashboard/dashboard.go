@@ -366,11 +366,12 @@ func readpanelInfo(iter *jsoniter.Iterator, lookup DatasourceLookup) panelInfo {
 	panel := panelInfo{}
 
 	targets := newTargetInfo(lookup)
+	var panelDs *DataSourceRef
 
 	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
 		if iter.WhatIsNext() == jsoniter.NilValue {
 			if l1Field == "datasource" {
-				targets.addDatasource(iter)
+				panelDs = targets.addDatasource(iter)
 				continue
 			}
 
@@ -401,7 +402,7 @@ func readpanelInfo(iter *jsoniter.Iterator, lookup DatasourceLookup) panelInfo {
 			panel.LibraryPanel = v["uid"]
 
 		case "datasource":
-			targets.addDatasource(iter)
+			panelDs = targets.addDatasource(iter)
 
 		case "targets":
 			switch iter.WhatIsNext() {
@@ -451,5 +452,11 @@ func readpanelInfo(iter *jsoniter.Iterator, lookup DatasourceLookup) panelInfo {
 
 	panel.Datasource = targets.GetDatasourceInfo()
 
+	// panels without a datasource query the default one
+	if panelDs == nil {
+		panelDs = lookup.ByRef(nil)
+	}
+	targets.fillQueryInfo(&panel, panelDs)
+
 	return panel
 }
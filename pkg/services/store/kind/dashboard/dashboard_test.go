@@ -44,6 +44,24 @@ func dsLookupForTests() DatasourceLookup {
 			Name:      "SQLite Grafana2",
 			IsDefault: false,
 		},
+		{
+			UID:       "prom-uid",
+			Type:      "prometheus",
+			Name:      "prometheus-name",
+			IsDefault: false,
+		},
+		{
+			UID:       "loki-uid",
+			Type:      "loki",
+			Name:      "loki-name",
+			IsDefault: false,
+		},
+		{
+			UID:       "mysql-uid",
+			Type:      "mysql",
+			Name:      "mysql-name",
+			IsDefault: false,
+		},
 		{
 			UID:       "default.uid",
 			Type:      "default.type",
@@ -70,6 +88,7 @@ func TestReadDashboard(t *testing.T) {
 		"mixed-datasource-with-variable",
 		"special-datasource-types",
 		"panels-without-datasources",
+		"panel-queries",
 	}
 
 	devdash := "../../../../../devenv/dev-dashboards/"
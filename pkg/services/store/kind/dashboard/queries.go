@@ -0,0 +1,425 @@
+package dashboard
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// queryInfo holds the names a single query refers to
+type queryInfo struct {
+	metrics []string
+	labels  []string
+	tables  []string
+}
+
+// extractQueryInfo finds the metric, label and table names referenced by a target query.
+// PromQL is parsed when possible, otherwise this is a best effort lexical scan, queries that can not be understood simply yield less names
+func extractQueryInfo(dsType string, field string, text string) queryInfo {
+	switch {
+	case field == "rawSql":
+		return queryInfo{tables: sqlTables(text)}
+	case field == "expr" && dsType == "prometheus":
+		metrics, labels := promQLNames(text, true)
+		return queryInfo{metrics: metrics, labels: labels}
+	case field == "expr" && dsType == "loki":
+		_, labels := promQLNames(text, false)
+		return queryInfo{labels: labels}
+	}
+	return queryInfo{}
+}
+
+var promQLKeywords = map[string]bool{
+	"and": true, "or": true, "unless": true, "bool": true, "offset": true,
+	"by": true, "without": true, "on": true, "ignoring": true, "group_left": true, "group_right": true,
+	"inf": true, "nan": true,
+	// aggregations may put the grouping before the parameters, ie: sum by (job) (...)
+	"sum": true, "min": true, "max": true, "avg": true, "group": true, "stddev": true, "stdvar": true,
+	"count": true, "count_values": true, "bottomk": true, "topk": true, "quantile": true,
+}
+
+var promQLGroupings = map[string]bool{
+	"by": true, "without": true, "on": true, "ignoring": true, "group_left": true, "group_right": true,
+}
+
+// promQLNames returns the metric and label names used in a PromQL expression.
+// LogQL stream selectors share the same syntax, so it is also used for loki without metrics
+func promQLNames(expr string, withMetrics bool) ([]string, []string) {
+	if parsed, err := parser.ParseExpr(expr); err == nil {
+		return parsedPromQLNames(parsed, withMetrics)
+	}
+	// queries with template variables, and LogQL queries, are usually not valid PromQL
+	return scannedPromQLNames(expr, withMetrics)
+}
+
+// parsedPromQLNames returns the names of the selectors and groupings of a parsed PromQL expression
+func parsedPromQLNames(expr parser.Expr, withMetrics bool) ([]string, []string) {
+	metrics := newStringSet()
+	labelNames := newStringSet()
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		switch n := node.(type) {
+		case *parser.VectorSelector:
+			for _, m := range n.LabelMatchers {
+				if m.Name != labels.MetricName {
+					labelNames.add(m.Name)
+				} else if withMetrics && m.Type == labels.MatchEqual && m.Value != "" && !strings.Contains(m.Value, "$") {
+					metrics.add(m.Value)
+				}
+			}
+		case *parser.AggregateExpr:
+			labelNames.add(n.Grouping...)
+		case *parser.BinaryExpr:
+			if n.VectorMatching != nil {
+				labelNames.add(n.VectorMatching.MatchingLabels...)
+				labelNames.add(n.VectorMatching.Include...)
+			}
+		}
+		return nil
+	})
+	return metrics.sorted(), labelNames.sorted()
+}
+
+// scannedPromQLNames finds the metric and label names of an expression that can not be parsed with a lexical scan
+func scannedPromQLNames(expr string, withMetrics bool) ([]string, []string) {
+	metrics := newStringSet()
+	labels := newStringSet()
+
+	i := 0
+	for i < len(expr) {
+		c := expr[i]
+		switch {
+		case c == '#':
+			i = skipUntil(expr, i, '\n')
+		case c == '"' || c == '\'' || c == '`':
+			_, i = readQuoted(expr, i)
+		case c == '$':
+			i = skipVariable(expr, i)
+		case c == '[':
+			i = skipUntil(expr, i, ']')
+		case c == '{':
+			i = readMatchers(expr, i+1, metrics, labels, withMetrics)
+		case isDigit(c):
+			// numbers and durations
+			for i < len(expr) && (isIdentChar(expr[i]) || expr[i] == '.') {
+				i++
+			}
+		case isIdentStart(c):
+			name, templated, next := readTemplatedIdent(expr, i)
+			i = next
+			next = skipSpaces(expr, i)
+			isCall := next < len(expr) && expr[next] == '('
+			lower := strings.ToLower(name)
+			switch {
+			case promQLGroupings[lower] && isCall:
+				i = readLabelList(expr, next+1, labels)
+			case isCall || templated || promQLKeywords[lower]:
+				// functions, aggregations, operators and names built from variables
+			case withMetrics:
+				metrics.add(name)
+			}
+		default:
+			i++
+		}
+	}
+	return metrics.sorted(), labels.sorted()
+}
+
+// readMatchers reads the label matchers of a selector up to the closing brace
+func readMatchers(expr string, i int, metrics stringSet, labels stringSet, withMetrics bool) int {
+	for i < len(expr) && expr[i] != '}' {
+		c := expr[i]
+		switch {
+		case c == '$':
+			i = skipVariable(expr, i)
+		case c == '"' || c == '\'' || c == '`':
+			_, i = readQuoted(expr, i)
+		case isIdentStart(c):
+			name, templated, next := readTemplatedIdent(expr, i)
+			i = skipSpaces(expr, next)
+			op := i
+			for i < len(expr) && (expr[i] == '=' || expr[i] == '!' || expr[i] == '~') {
+				i++
+			}
+			isEqual := expr[op:i] == "="
+			i = skipSpaces(expr, i)
+			value := ""
+			if i < len(expr) && (expr[i] == '"' || expr[i] == '\'' || expr[i] == '`') {
+				value, i = readQuoted(expr, i)
+			}
+			if templated {
+				continue
+			}
+			if name != "__name__" {
+				labels.add(name)
+			} else if withMetrics && isEqual && value != "" && !strings.Contains(value, "$") {
+				metrics.add(value)
+			}
+		default:
+			i++
+		}
+	}
+	return i + 1
+}
+
+// readLabelList reads the label names of a grouping clause up to the closing parenthesis
+func readLabelList(expr string, i int, labels stringSet) int {
+	for i < len(expr) && expr[i] != ')' {
+		c := expr[i]
+		switch {
+		case c == '$':
+			i = skipVariable(expr, i)
+		case isIdentStart(c):
+			name, templated, next := readTemplatedIdent(expr, i)
+			if !templated {
+				labels.add(name)
+			}
+			i = next
+		default:
+			i++
+		}
+	}
+	return i + 1
+}
+
+var sqlKeywords = map[string]bool{
+	"where": true, "join": true, "inner": true, "left": true, "right": true, "full": true, "outer": true,
+	"cross": true, "natural": true, "on": true, "using": true, "group": true, "order": true, "limit": true,
+	"offset": true, "having": true, "union": true, "intersect": true, "except": true, "window": true,
+	"as": true, "lateral": true, "only": true, "fetch": true, "for": true, "select": true, "from": true,
+}
+
+type sqlToken struct {
+	text   string
+	quoted bool // quoted identifiers are never keywords
+}
+
+func (t sqlToken) is(keyword string) bool {
+	return !t.quoted && strings.EqualFold(t.text, keyword)
+}
+
+func (t sqlToken) isName() bool {
+	if t.quoted {
+		return t.text != ""
+	}
+	return t.text != "" && isIdentStart(t.text[0]) && !sqlKeywords[strings.ToLower(t.text)]
+}
+
+// sqlTables returns the tables a SQL query reads from
+func sqlTables(sql string) []string {
+	tokens := sqlTokenize(sql)
+	tables := newStringSet()
+	ctes := newStringSet()
+
+	// each parenthesis level tracks if it is a (sub)query, so `EXTRACT(EPOCH FROM time)` is not read as a table
+	isQuery := []bool{false}
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case t.is("("):
+			isQuery = append(isQuery, false)
+		case t.is(")"):
+			if len(isQuery) > 1 {
+				isQuery = isQuery[:len(isQuery)-1]
+			}
+		case t.is("select"):
+			isQuery[len(isQuery)-1] = true
+		case t.isName() && i+2 < len(tokens) && tokens[i+1].is("as") && tokens[i+2].is("("):
+			// common table expression: WITH name AS (...)
+			ctes.add(t.text)
+		case t.is("join") || (t.is("from") && isQuery[len(isQuery)-1]):
+			readSQLTables(tokens, i+1, t.is("from"), tables)
+		}
+	}
+
+	for name := range ctes {
+		delete(tables, name)
+	}
+	return tables.sorted()
+}
+
+// readSQLTables reads the table names following FROM or JOIN
+func readSQLTables(tokens []sqlToken, i int, isList bool, tables stringSet) {
+	for i < len(tokens) && tokens[i].isName() {
+		name := tokens[i].text
+		i++
+		for i+1 < len(tokens) && tokens[i].is(".") && tokens[i+1].isName() {
+			name += "." + tokens[i+1].text
+			i += 2
+		}
+
+		// table functions such as generate_series(...)
+		if i < len(tokens) && tokens[i].is("(") {
+			return
+		}
+		tables.add(name)
+
+		// optional alias
+		if i < len(tokens) && tokens[i].is("as") {
+			i++
+		}
+		if i < len(tokens) && tokens[i].isName() {
+			i++
+		}
+
+		if !isList || i >= len(tokens) || !tokens[i].is(",") {
+			return
+		}
+		i++
+	}
+}
+
+// sqlTokenize splits a SQL query into words, quoted identifiers and punctuation.
+// String literals, comments, numbers and template variables are dropped
+func sqlTokenize(sql string) []sqlToken {
+	var tokens []sqlToken
+	i := 0
+	for i < len(sql) {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			i = skipUntil(sql, i, '\n')
+		case c == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 4
+		case c == '\'':
+			_, i = readQuoted(sql, i)
+		case c == '"' || c == '`':
+			var name string
+			name, i = readQuoted(sql, i)
+			tokens = append(tokens, sqlToken{text: name, quoted: true})
+		case c == '[':
+			end := strings.IndexByte(sql[i:], ']')
+			if end < 0 {
+				return tokens
+			}
+			tokens = append(tokens, sqlToken{text: sql[i+1 : i+end], quoted: true})
+			i += end + 1
+		case c == '$' || c == '@':
+			// template variables, macros and parameters
+			i = skipVariable(sql, i)
+		case isDigit(c):
+			for i < len(sql) && (isIdentChar(sql[i]) || sql[i] == '.') {
+				i++
+			}
+		case isIdentStart(c):
+			name, templated, next := readTemplatedIdent(sql, i)
+			if !templated {
+				tokens = append(tokens, sqlToken{text: name})
+			}
+			i = next
+		default:
+			tokens = append(tokens, sqlToken{text: sql[i : i+1]})
+			i++
+		}
+	}
+	return tokens
+}
+
+// readTemplatedIdent reads an identifier, which may contain template variables such as `node_$suffix`
+func readTemplatedIdent(s string, i int) (string, bool, int) {
+	start := i
+	templated := false
+	for i < len(s) {
+		if s[i] == '$' {
+			templated = true
+			i = skipVariable(s, i)
+			continue
+		}
+		if !isIdentChar(s[i]) {
+			break
+		}
+		i++
+	}
+	return s[start:i], templated, i
+}
+
+// skipVariable skips `$name`, `${name:format}` and `$__macro` style template variables
+func skipVariable(s string, i int) int {
+	i++
+	if i < len(s) && s[i] == '{' {
+		return skipUntil(s, i, '}')
+	}
+	for i < len(s) && isIdentChar(s[i]) {
+		i++
+	}
+	return i
+}
+
+// readQuoted returns the content of the quoted string starting at i and the index after its closing quote
+func readQuoted(s string, i int) (string, int) {
+	quote := s[i]
+	var sb strings.Builder
+	for i++; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' && quote != '`' && i+1 < len(s) {
+			i++
+			sb.WriteByte(s[i])
+			continue
+		}
+		if c == quote {
+			return sb.String(), i + 1
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String(), i
+}
+
+// skipUntil returns the index after the next occurrence of c
+func skipUntil(s string, i int, c byte) int {
+	end := strings.IndexByte(s[i:], c)
+	if end < 0 {
+		return len(s)
+	}
+	return i + end + 1
+}
+
+func skipSpaces(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n' || s[i] == '\r') {
+		i++
+	}
+	return i
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == ':' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+type stringSet map[string]struct{}
+
+func newStringSet() stringSet {
+	return make(stringSet)
+}
+
+func (s stringSet) add(values ...string) {
+	for _, v := range values {
+		s[v] = struct{}{}
+	}
+}
+
+// sorted returns the values in order, or nil when empty so they are omitted from JSON
+func (s stringSet) sorted() []string {
+	if len(s) == 0 {
+		return nil
+	}
+	values := make([]string, 0, len(s))
+	for v := range s {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	return values
+}
@@ -0,0 +1,110 @@
+package dashboard
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromQLNames(t *testing.T) {
+	tests := []struct {
+		expr    string
+		metrics []string
+		labels  []string
+	}{
+		{
+			expr:    `http_requests_total`,
+			metrics: []string{"http_requests_total"},
+		},
+		{
+			expr:    `sum without (instance) (rate(node_cpu_seconds_total{mode!="idle"}[5m] offset 1h))`,
+			metrics: []string{"node_cpu_seconds_total"},
+			labels:  []string{"instance", "mode"},
+		},
+		{
+			expr:    `a_total / on (job) group_left (team) b_info > bool 0.5`,
+			metrics: []string{"a_total", "b_info"},
+			labels:  []string{"job", "team"},
+		},
+		{
+			expr:    `max_over_time(up[1h:5m]) * 100 # trailing comment with metric_name`,
+			metrics: []string{"up"},
+		},
+		{
+			expr:   `node_$suffix{${label}="x", env="$env"} + ${metric}`,
+			labels: []string{"env"},
+		},
+		{
+			expr:    `sum by (job) (rate(http_requests_total{code=~"5.."}[$__rate_interval]))`,
+			metrics: []string{"http_requests_total"},
+			labels:  []string{"code", "job"},
+		},
+		{
+			expr:    `label_replace(up, "dst", "$1", "src", "(.*)")`,
+			metrics: []string{"up"},
+		},
+		{
+			expr:    `{__name__=~"job:.*", __name__="job:requests:rate5m"}`,
+			metrics: []string{"job:requests:rate5m"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			metrics, labels := promQLNames(tt.expr, true)
+			require.Equal(t, tt.metrics, metrics)
+			require.Equal(t, tt.labels, labels)
+		})
+	}
+}
+
+func TestLogQLLabels(t *testing.T) {
+	info := extractQueryInfo("loki", "expr", `sum by (pod) (rate({namespace="prod", app=~"api|web"} | logfmt | duration > 10s [1m]))`)
+	require.Nil(t, info.metrics)
+	require.Equal(t, []string{"app", "namespace", "pod"}, info.labels)
+}
+
+func TestSQLTables(t *testing.T) {
+	tests := []struct {
+		sql    string
+		tables []string
+	}{
+		{
+			sql:    `SELECT * FROM orders`,
+			tables: []string{"orders"},
+		},
+		{
+			sql:    "select a.x from `sales`.`orders` as a, customers c left join \"Regions\" r on r.id = c.region_id",
+			tables: []string{"Regions", "customers", "sales.orders"},
+		},
+		{
+			sql:    `SELECT [id] FROM [dbo].[events] WHERE kind IN (SELECT kind FROM kinds) -- FROM comments`,
+			tables: []string{"dbo.events", "kinds"},
+		},
+		{
+			sql:    `SELECT extract(epoch FROM created_at) AS time, substring(name FROM 2) FROM metrics_raw /* FROM comment */`,
+			tables: []string{"metrics_raw"},
+		},
+		{
+			sql:    `WITH recent AS (SELECT * FROM orders WHERE $__timeFilter(time)) SELECT count(*) FROM recent`,
+			tables: []string{"orders"},
+		},
+		{
+			sql:    `SELECT * FROM $table, generate_series(1, 10) JOIN (SELECT 1) sub ON true WHERE note = 'FROM quoted'`,
+			tables: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			require.Equal(t, tt.tables, sqlTables(tt.sql))
+		})
+	}
+}
+
+func TestExtractQueryInfoByDatasource(t *testing.T) {
+	// expr is only understood for datasources with a known query language
+	require.Equal(t, queryInfo{}, extractQueryInfo("elasticsearch", "expr", "up"))
+	require.Equal(t, queryInfo{}, extractQueryInfo("prometheus", "query", "up"))
+	require.Equal(t, []string{"t"}, extractQueryInfo("", "rawSql", "SELECT 1 FROM t").tables)
+}
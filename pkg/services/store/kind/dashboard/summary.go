@@ -76,6 +76,18 @@ func NewStaticDashboardSummaryBuilder(lookup DatasourceLookup, sanitize bool) en
 			p.Description = panel.Description
 			p.Fields = make(map[string]interface{}, 0)
 			p.Fields["type"] = panel.Type
+			if len(panel.Queries) > 0 {
+				p.Fields["queries"] = panel.Queries
+			}
+			if len(panel.Metrics) > 0 {
+				p.Fields["metrics"] = panel.Metrics
+			}
+			if len(panel.Labels) > 0 {
+				p.Fields["labels"] = panel.Labels
+			}
+			if len(panel.Tables) > 0 {
+				p.Fields["tables"] = panel.Tables
+			}
 
 			if panel.Type != "row" {
 				panelRefs.Add(entity.ExternalEntityReferencePlugin, string(plugins.TypePanel), panel.Type)
@@ -1,12 +1,22 @@
 package dashboard
 
 import (
+	"strings"
+
 	jsoniter "github.com/json-iterator/go"
 )
 
 type targetInfo struct {
-	lookup DatasourceLookup
-	uids   map[string]*DataSourceRef
+	lookup  DatasourceLookup
+	uids    map[string]*DataSourceRef
+	queries []targetQuery
+}
+
+// targetQuery is the raw query text of a single target along with the datasource it was written for
+type targetQuery struct {
+	datasource *DataSourceRef // nil when the target uses the panel datasource
+	field      string         // the target property holding the text (expr, rawSql, ...)
+	text       string
 }
 
 func newTargetInfo(lookup DatasourceLookup) targetInfo {
@@ -27,37 +37,36 @@ func (s *targetInfo) GetDatasourceInfo() []DataSourceRef {
 }
 
 // the node will either be string (name|uid) OR ref
-func (s *targetInfo) addDatasource(iter *jsoniter.Iterator) {
+// The resolved reference is returned so the caller can attach it to queries
+func (s *targetInfo) addDatasource(iter *jsoniter.Iterator) *DataSourceRef {
+	var ref *DataSourceRef
 	switch iter.WhatIsNext() {
 	case jsoniter.StringValue:
 		key := iter.ReadString()
 
-		dsRef := &DataSourceRef{UID: key}
-		if !isVariableRef(dsRef.UID) && !isSpecialDatasource(dsRef.UID) {
-			ds := s.lookup.ByRef(dsRef)
-			s.addRef(ds)
-		} else {
-			s.addRef(dsRef)
+		ref = &DataSourceRef{UID: key}
+		if !isVariableRef(ref.UID) && !isSpecialDatasource(ref.UID) {
+			ref = s.lookup.ByRef(ref)
 		}
 
 	case jsoniter.NilValue:
-		s.addRef(s.lookup.ByRef(nil))
+		ref = s.lookup.ByRef(nil)
 		iter.Skip()
 
 	case jsoniter.ObjectValue:
-		ref := &DataSourceRef{}
+		ref = &DataSourceRef{}
 		iter.ReadVal(ref)
 
 		if !isVariableRef(ref.UID) && !isSpecialDatasource(ref.UID) {
-			s.addRef(s.lookup.ByRef(ref))
-		} else {
-			s.addRef(ref)
+			ref = s.lookup.ByRef(ref)
 		}
 
 	default:
 		v := iter.Read()
 		logf("[Panel.datasource.unknown] %v\n", v)
 	}
+	s.addRef(ref)
+	return ref
 }
 
 func (s *targetInfo) addRef(ref *DataSourceRef) {
@@ -67,19 +76,64 @@ func (s *targetInfo) addRef(ref *DataSourceRef) {
 }
 
 func (s *targetInfo) addTarget(iter *jsoniter.Iterator) {
+	var ds *DataSourceRef
+	var queries []targetQuery
 	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
 		switch l1Field {
 		case "datasource":
-			s.addDatasource(iter)
+			ds = s.addDatasource(iter)
 
 		case "refId":
 			iter.Skip()
 
+		case "expr", "rawSql", "query", "target":
+			// some datasources use an object for "query", only raw text is indexed
+			if iter.WhatIsNext() != jsoniter.StringValue {
+				iter.Skip()
+				continue
+			}
+			if text := strings.TrimSpace(iter.ReadString()); text != "" {
+				queries = append(queries, targetQuery{field: l1Field, text: text})
+			}
+
 		default:
 			v := iter.Read()
 			logf("[Panel.TARGET] %s=%v\n", l1Field, v)
 		}
 	}
+
+	for i := range queries {
+		queries[i].datasource = ds
+		s.queries = append(s.queries, queries[i])
+	}
+}
+
+// fillQueryInfo sets the query text and the metrics, labels and tables referenced by the panel targets.
+// Targets without an explicit datasource are assumed to use the panel datasource
+func (s *targetInfo) fillQueryInfo(panel *panelInfo, panelDs *DataSourceRef) {
+	metrics := newStringSet()
+	labels := newStringSet()
+	tables := newStringSet()
+	for _, q := range s.queries {
+		panel.Queries = append(panel.Queries, q.text)
+
+		ds := q.datasource
+		if ds == nil || ds.Type == "" || isSpecialDatasource(ds.UID) {
+			ds = panelDs
+		}
+		dsType := ""
+		if ds != nil {
+			dsType = ds.Type
+		}
+
+		info := extractQueryInfo(dsType, q.field, q.text)
+		metrics.add(info.metrics...)
+		labels.add(info.labels...)
+		tables.add(info.tables...)
+	}
+	panel.Metrics = metrics.sorted()
+	panel.Labels = labels.sorted()
+	panel.Tables = tables.sorted()
 }
 
 func (s *targetInfo) addPanel(panel panelInfo) {
@@ -9,6 +9,10 @@ type panelInfo struct {
 	LibraryPanel  string          `json:"libraryPanel,omitempty"` // UID of referenced library panel
 	Datasource    []DataSourceRef `json:"datasource,omitempty"`   // UIDs
 	Transformer   []string        `json:"transformer,omitempty"`  // ids of the transformation steps
+	Queries       []string        `json:"queries,omitempty"`      // raw query text of the targets
+	Metrics       []string        `json:"metrics,omitempty"`      // metric names referenced by the queries
+	Labels        []string        `json:"labels,omitempty"`       // label names referenced by the queries
+	Tables        []string        `json:"tables,omitempty"`       // SQL tables referenced by the queries
 	// Rows define panels as sub objects
 	Collapsed []panelInfo `json:"collapsed,omitempty"`
 }
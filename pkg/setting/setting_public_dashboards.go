@@ -0,0 +1,41 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type PublicDashboardsSettings struct {
+	// QueryRateLimit is the number of queries per second a public dashboard can send to its data sources, 0 disables
+	// the limit. Queries answered from the cache don't count.
+	QueryRateLimit      float64
+	QueryRateLimitBurst int
+	// TokenQueryRateLimit is the number of queries per second a single access token of a public dashboard can send to
+	// the data sources, 0 disables the limit.
+	TokenQueryRateLimit      float64
+	TokenQueryRateLimitBurst int
+	// MinQueryCacheTTL is the minimum time query results of public dashboards are cached
+	MinQueryCacheTTL time.Duration
+	// TrustedProxies are the IP addresses and CIDR ranges of reverse proxies whose X-Real-IP and X-Forwarded-For
+	// headers are used as the IP address of the client by the IP allow lists of access tokens.
+	TrustedProxies []string
+}
+
+func readPublicDashboardsSettings(iniFile *ini.File) PublicDashboardsSettings {
+	s := PublicDashboardsSettings{}
+
+	section := iniFile.Section("public_dashboards")
+	s.QueryRateLimit = section.Key("query_rate_limit").MustFloat64(10)
+	s.QueryRateLimitBurst = section.Key("query_rate_limit_burst").MustInt(50)
+	s.TokenQueryRateLimit = section.Key("token_query_rate_limit").MustFloat64(5)
+	s.TokenQueryRateLimitBurst = section.Key("token_query_rate_limit_burst").MustInt(25)
+	s.MinQueryCacheTTL = section.Key("min_query_cache_ttl").MustDuration(10 * time.Second)
+	if s.MinQueryCacheTTL < 0 {
+		s.MinQueryCacheTTL = 0
+	}
+	s.TrustedProxies = util.SplitString(section.Key("trusted_proxies").MustString(""))
+	return s
+}
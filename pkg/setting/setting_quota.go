@@ -1,11 +1,12 @@
 package setting
 
 type OrgQuota struct {
-	User       int64 `target:"org_user"`
-	DataSource int64 `target:"data_source"`
-	Dashboard  int64 `target:"dashboard"`
-	ApiKey     int64 `target:"api_key"`
-	AlertRule  int64 `target:"alert_rule"`
+	User          int64 `target:"org_user"`
+	DataSource    int64 `target:"data_source"`
+	Dashboard     int64 `target:"dashboard"`
+	ApiKey        int64 `target:"api_key"`
+	AlertRule     int64 `target:"alert_rule"`
+	AlertInstance int64 `target:"alert_instance"`
 }
 
 type UserQuota struct {
@@ -38,17 +39,20 @@ func (cfg *Cfg) readQuotaSettings() {
 
 	var alertOrgQuota int64
 	var alertGlobalQuota int64
+	var alertInstanceOrgQuota int64
 	if cfg.UnifiedAlerting.IsEnabled() {
 		alertOrgQuota = quota.Key("org_alert_rule").MustInt64(100)
 		alertGlobalQuota = quota.Key("global_alert_rule").MustInt64(-1)
+		alertInstanceOrgQuota = quota.Key("org_alert_instance").MustInt64(-1)
 	}
 	// per ORG Limits
 	cfg.Quota.Org = OrgQuota{
-		User:       quota.Key("org_user").MustInt64(10),
-		DataSource: quota.Key("org_data_source").MustInt64(10),
-		Dashboard:  quota.Key("org_dashboard").MustInt64(10),
-		ApiKey:     quota.Key("org_api_key").MustInt64(10),
-		AlertRule:  alertOrgQuota,
+		User:          quota.Key("org_user").MustInt64(10),
+		DataSource:    quota.Key("org_data_source").MustInt64(10),
+		Dashboard:     quota.Key("org_dashboard").MustInt64(10),
+		ApiKey:        quota.Key("org_api_key").MustInt64(10),
+		AlertRule:     alertOrgQuota,
+		AlertInstance: alertInstanceOrgQuota,
 	}
 
 	// per User limits
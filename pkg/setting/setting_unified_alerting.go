@@ -59,13 +59,16 @@ const (
 	// with intervals that are not exactly divided by this number not to be evaluated
 	SchedulerBaseInterval = 10 * time.Second
 	// DefaultRuleEvaluationInterval indicates a default interval of for how long a rule should be evaluated to change state from Pending to Alerting
-	DefaultRuleEvaluationInterval = SchedulerBaseInterval * 6 // == 60 seconds
-	stateHistoryDefaultEnabled    = true
+	DefaultRuleEvaluationInterval   = SchedulerBaseInterval * 6 // == 60 seconds
+	stateHistoryDefaultEnabled      = true
+	stateHistoryDefaultSQLRetention = 30 * 24 * time.Hour
+	recordingRulesDefaultTimeout    = 10 * time.Second
 )
 
 type UnifiedAlertingSettings struct {
 	AdminConfigPollInterval        time.Duration
 	AlertmanagerConfigPollInterval time.Duration
+	ProvisioningPollInterval       time.Duration
 	HAListenAddr                   string
 	HAAdvertiseAddr                string
 	HAPeers                        []string
@@ -80,6 +83,7 @@ type UnifiedAlertingSettings struct {
 	HARedisPassword                string
 	HARedisDB                      int
 	HARedisMaxConns                int
+	HAEvaluationSharding           bool
 	MaxAttempts                    int64
 	MinInterval                    time.Duration
 	EvaluationTimeout              time.Duration
@@ -95,8 +99,12 @@ type UnifiedAlertingSettings struct {
 	Screenshots                   UnifiedAlertingScreenshotSettings
 	ReservedLabels                UnifiedAlertingReservedLabelSettings
 	StateHistory                  UnifiedAlertingStateHistorySettings
+	RecordingRules                UnifiedAlertingRecordingRuleSettings
 	// MaxStateSaveConcurrency controls the number of goroutines (per rule) that can save alert state in parallel.
 	MaxStateSaveConcurrency int
+	// MaxInstancesPerRule and MaxInstancesPerGroup limit the number of alert instances of a rule and of a rule group. Zero means no limit.
+	MaxInstancesPerRule  int64
+	MaxInstancesPerGroup int64
 }
 
 type UnifiedAlertingScreenshotSettings struct {
@@ -124,6 +132,19 @@ type UnifiedAlertingStateHistorySettings struct {
 	MultiPrimary          string
 	MultiSecondaries      []string
 	ExternalLabels        map[string]string
+	// SQLRetention is the period of time for which the "sql" backend keeps state history. Zero means forever.
+	SQLRetention time.Duration
+}
+
+type UnifiedAlertingRecordingRuleSettings struct {
+	Enabled bool
+	// URL is the Prometheus remote write endpoint the results of recording rules are written to.
+	// If it is empty, the results are discarded.
+	URL string
+	// BasicAuthUsername and BasicAuthPassword are used for basic auth if the username is set.
+	BasicAuthUsername string
+	BasicAuthPassword string
+	Timeout           time.Duration
 }
 
 // IsEnabled returns true if UnifiedAlertingSettings.Enabled is either nil or true.
@@ -221,6 +242,13 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	if err != nil {
 		return err
 	}
+	uaCfg.ProvisioningPollInterval, err = gtime.ParseDuration(valueAsString(ua, "provisioning_poll_interval", "0s"))
+	if err != nil {
+		return err
+	}
+	if uaCfg.ProvisioningPollInterval < 0 {
+		return fmt.Errorf("value of setting 'provisioning_poll_interval' cannot be negative")
+	}
 	uaCfg.HAPeerTimeout, err = gtime.ParseDuration(valueAsString(ua, "ha_peer_timeout", (alertmanagerDefaultPeerTimeout).String()))
 	if err != nil {
 		return err
@@ -243,6 +271,7 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	uaCfg.HARedisPassword = ua.Key("ha_redis_password").MustString("")
 	uaCfg.HARedisDB = ua.Key("ha_redis_db").MustInt(0)
 	uaCfg.HARedisMaxConns = ua.Key("ha_redis_max_conns").MustInt(alertmanagerRedisDefaultMaxConns)
+	uaCfg.HAEvaluationSharding = ua.Key("ha_evaluation_sharding").MustBool(false)
 	peers := ua.Key("ha_peers").MustString("")
 	uaCfg.HAPeers = make([]string, 0)
 	if peers != "" {
@@ -376,10 +405,36 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		MultiSecondaries:      splitTrim(stateHistory.Key("secondaries").MustString(""), ","),
 		ExternalLabels:        stateHistoryLabels.KeysHash(),
 	}
+	uaCfgStateHistory.SQLRetention, err = gtime.ParseDuration(valueAsString(stateHistory, "sql_retention", stateHistoryDefaultSQLRetention.String()))
+	if err != nil {
+		return err
+	}
 	uaCfg.StateHistory = uaCfgStateHistory
 
+	recordingRules := iniFile.Section("unified_alerting.recording_rules")
+	uaCfgRecordingRules := UnifiedAlertingRecordingRuleSettings{
+		Enabled:           recordingRules.Key("enabled").MustBool(false),
+		URL:               recordingRules.Key("url").MustString(""),
+		BasicAuthUsername: recordingRules.Key("basic_auth_username").MustString(""),
+		BasicAuthPassword: recordingRules.Key("basic_auth_password").MustString(""),
+	}
+	uaCfgRecordingRules.Timeout, err = gtime.ParseDuration(valueAsString(recordingRules, "timeout", recordingRulesDefaultTimeout.String()))
+	if err != nil {
+		return err
+	}
+	uaCfg.RecordingRules = uaCfgRecordingRules
+
 	uaCfg.MaxStateSaveConcurrency = ua.Key("max_state_save_concurrency").MustInt(1)
 
+	uaCfg.MaxInstancesPerRule = ua.Key("max_instances_per_rule").MustInt64(0)
+	if uaCfg.MaxInstancesPerRule < 0 {
+		return errors.New("value of setting 'max_instances_per_rule' cannot be negative")
+	}
+	uaCfg.MaxInstancesPerGroup = ua.Key("max_instances_per_group").MustInt64(0)
+	if uaCfg.MaxInstancesPerGroup < 0 {
+		return errors.New("value of setting 'max_instances_per_group' cannot be negative")
+	}
+
 	cfg.UnifiedAlerting = uaCfg
 	return nil
 }
@@ -87,6 +87,8 @@ func TestIntegrationPrometheusRules(t *testing.T) {
 	{
 		rules := apimodels.PostableRuleGroupConfig{
 			Name: "arulegroup",
+			// evaluation offsets depend on UIDs of the rules that are generated
+			DisableEvaluationOffset: true,
 			Rules: []apimodels.PostableExtendedRuleNode{
 				{
 					ApiRuleNode: &apimodels.ApiRuleNode{
@@ -365,6 +367,8 @@ func TestIntegrationPrometheusRulesFilterByDashboard(t *testing.T) {
 	{
 		rules := apimodels.PostableRuleGroupConfig{
 			Name: "anotherrulegroup",
+			// evaluation offsets depend on UIDs of the rules that are generated
+			DisableEvaluationOffset: true,
 			Rules: []apimodels.PostableExtendedRuleNode{
 				{
 					ApiRuleNode: &apimodels.ApiRuleNode{
@@ -16,6 +16,10 @@ export interface SearchQuery {
   tags?: string[];
   kind?: string[];
   panel_type?: string;
+  metric?: string; // metric name used by the panel queries
+  label?: string; // label name used by the panel queries
+  table?: string; // SQL table used by the panel queries
+  panel_query?: string; // text matched against the panel queries
   uid?: string[];
   facet?: FacetField[];
   explain?: boolean;